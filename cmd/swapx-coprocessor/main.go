@@ -1,15 +1,14 @@
 package main
 
 import (
-	"log"
 	"os"
 
 	"github.com/henriquemarlon/swapx/cmd/swapx-coprocessor/root"
 )
 
 func main() {
+	// cobra already prints the error, unless the command silenced it on purpose
 	if err := root.Cmd.Execute(); err != nil {
-		log.Fatalf("Error executing command: %v", err)
 		os.Exit(1)
 	}
 }
@@ -0,0 +1,98 @@
+package codec
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/henriquemarlon/swapx/cmd/swapx-coprocessor/root/output"
+	"github.com/henriquemarlon/swapx/configs"
+	"github.com/henriquemarlon/swapx/internal/domain"
+	"github.com/henriquemarlon/swapx/internal/infra/cartesi"
+	"github.com/spf13/cobra"
+)
+
+var (
+	format    string
+	decimals0 uint8
+	decimals1 uint8
+)
+
+func addOutputFlags(cmd *cobra.Command) {
+	// Logs would get mixed with the decoded output on stdout
+	cmd.PersistentPreRun = func(cmd *cobra.Command, args []string) {
+		configs.ConfigureLogger(slog.LevelError)
+	}
+	cmd.PersistentFlags().StringVar(&format, "format", output.FORMAT_TABLE, "Output format, json or table")
+	cmd.PersistentFlags().Uint8Var(&decimals0, "decimals0", 18, "Decimals of currency0, used to render human prices")
+	cmd.PersistentFlags().Uint8Var(&decimals1, "decimals1", 18, "Decimals of currency1, used to render human prices")
+}
+
+// readHex takes the hex blob from the first argument, or from stdin when it is
+// missing or "-", so calldata can be piped straight from cast or a block explorer.
+func readHex(args []string) ([]byte, error) {
+	var raw string
+	if len(args) > 0 && args[0] != "-" {
+		raw = args[0]
+	} else {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return nil, err
+		}
+		raw = string(data)
+	}
+
+	raw = strings.TrimSpace(raw)
+	data, err := hexDecode(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid hex input: %v", err)
+	}
+	return data, nil
+}
+
+func hexDecode(raw string) ([]byte, error) {
+	if !strings.HasPrefix(raw, "0x") {
+		raw = "0x" + raw
+	}
+	return hexutil.Decode(raw)
+}
+
+type taskView struct {
+	OrderId      uint64           `json:"order_id"`
+	Side         domain.OrderType `json:"side"`
+	SqrtPriceX96 string           `json:"sqrt_price_x96"`
+	Price        string           `json:"price"`
+	Amount       string           `json:"amount"`
+	Flags        uint64           `json:"flags"`
+	FlagNames    []string         `json:"flag_names"`
+}
+
+func newTaskView(task *cartesi.TaskPayload) *taskView {
+	return &taskView{
+		OrderId:      task.OrderId,
+		Side:         task.Type,
+		SqrtPriceX96: task.SqrtPrice.Dec(),
+		Price:        domain.SqrtPriceX96ToPrice(task.SqrtPrice, decimals0, decimals1).Text('g', 18),
+		Amount:       task.Amount.Dec(),
+		Flags:        uint64(task.Flags),
+		FlagNames:    task.Flags.Names(),
+	}
+}
+
+func (v *taskView) table() output.Table {
+	return output.Table{
+		Title:  "Task payload",
+		Header: []string{"field", "value"},
+		Rows: [][]string{
+			{"order_id", fmt.Sprintf("%d (index %d on the hook)", v.OrderId, v.OrderId-1)},
+			{"side", string(v.Side)},
+			{"sqrt_price_x96", v.SqrtPriceX96},
+			{"price", v.Price},
+			{"amount", v.Amount},
+			{"flags", fmt.Sprintf("%d %v", v.Flags, v.FlagNames)},
+		},
+	}
+}
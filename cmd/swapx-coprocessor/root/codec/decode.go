@@ -0,0 +1,188 @@
+package codec
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/henriquemarlon/swapx/cmd/swapx-coprocessor/root/output"
+	"github.com/henriquemarlon/swapx/internal/infra/cartesi"
+	"github.com/henriquemarlon/swapx/pkg/coprocessor"
+	"github.com/spf13/cobra"
+)
+
+var (
+	DecodeCmd = &cobra.Command{
+		Use:   "decode",
+		Short: "Decode advance inputs, task payloads and notices",
+		Long:  `Decodes the blobs the coprocessor exchanges with the base layer, reading the hex from the first argument or from stdin`,
+	}
+	decodeAdvanceCmd = &cobra.Command{
+		Use:          "advance [hex]",
+		Short:        "Decode an EvmAdvance input, along with the task payload it carries",
+		Args:         cobra.MaximumNArgs(1),
+		SilenceUsage: true,
+		RunE:         decodeAdvance,
+	}
+	decodeTaskCmd = &cobra.Command{
+		Use:          "task [hex]",
+		Short:        "Decode the task payload created by the hook for an order",
+		Args:         cobra.MaximumNArgs(1),
+		SilenceUsage: true,
+		RunE:         decodeTask,
+	}
+	decodeNoticeCmd = &cobra.Command{
+		Use:          "notice [hex]",
+		Short:        "Decode a SettleBatch or ExecuteVouchers notice",
+		Args:         cobra.MaximumNArgs(1),
+		SilenceUsage: true,
+		RunE:         decodeNotice,
+	}
+)
+
+func init() {
+	addOutputFlags(DecodeCmd)
+	DecodeCmd.AddCommand(decodeAdvanceCmd, decodeTaskCmd, decodeNoticeCmd)
+}
+
+type advanceView struct {
+	Metadata coprocessor.Metadata `json:"metadata"`
+	Payload  string               `json:"payload"`
+	Task     *taskView            `json:"task,omitempty"`
+}
+
+func decodeAdvance(cmd *cobra.Command, args []string) error {
+	data, err := readHex(args)
+	if err != nil {
+		return err
+	}
+
+	advance, err := coprocessor.EvmAdvanceParser(hexutil.Encode(data))
+	if err != nil {
+		return err
+	}
+
+	view := advanceView{Metadata: advance.Metadata, Payload: advance.Payload}
+	tables := []output.Table{{
+		Title:  "EvmAdvance",
+		Header: []string{"field", "value"},
+		Rows: [][]string{
+			{"chain_id", strconv.FormatUint(advance.Metadata.ChainId, 10)},
+			{"task_manager", advance.Metadata.TaskManager.Hex()},
+			{"msg_sender", advance.Metadata.MsgSender.Hex()},
+			{"block_hash", advance.Metadata.BlockHash},
+			{"block_number", strconv.FormatUint(advance.Metadata.BlockNumber, 10)},
+			{"timestamp", strconv.FormatUint(advance.Metadata.Timestamp, 10)},
+			{"prev_randao", advance.Metadata.PrevRandao},
+			{"payload", view.Payload},
+		},
+	}}
+
+	// Inputs from other task issuers are still decoded, only without the task
+	if task, err := cartesi.DecodeTaskPayload(common.FromHex(advance.Payload)); err == nil {
+		view.Task = newTaskView(task)
+		tables = append(tables, view.Task.table())
+	}
+
+	return output.Render(format, view, tables...)
+}
+
+func decodeTask(cmd *cobra.Command, args []string) error {
+	data, err := readHex(args)
+	if err != nil {
+		return err
+	}
+
+	task, err := cartesi.DecodeTaskPayload(data)
+	if err != nil {
+		return err
+	}
+
+	view := newTaskView(task)
+	return output.Render(format, view, view.table())
+}
+
+type fillView struct {
+	FillId      common.Hash `json:"fill_id"`
+	BuyOrderId  string      `json:"buy_order_id"`
+	SellOrderId string      `json:"sell_order_id"`
+	Quantity    string      `json:"quantity"`
+	ExpiryBlock string      `json:"expiry_block"`
+}
+
+type voucherView struct {
+	Action  string `json:"action,omitempty"`
+	OrderId string `json:"order_id,omitempty"`
+	Side    string `json:"side,omitempty"`
+	Amount  string `json:"amount,omitempty"`
+	Payload string `json:"payload"`
+	Error   string `json:"error,omitempty"`
+}
+
+type noticeView struct {
+	Kind     string          `json:"kind"`
+	Hook     *common.Address `json:"hook,omitempty"`
+	Fills    []fillView      `json:"fills,omitempty"`
+	Vouchers []voucherView   `json:"vouchers,omitempty"`
+}
+
+// The order ids inside of notices are already the 0-based indexes of the hook arrays
+func decodeNotice(cmd *cobra.Command, args []string) error {
+	data, err := readHex(args)
+	if err != nil {
+		return err
+	}
+
+	if settlement, err := cartesi.DecodeSettlementNotice(data); err == nil {
+		view := noticeView{Kind: "settle_batch", Hook: &settlement.Hook}
+		fills := output.Table{
+			Title:  fmt.Sprintf("SettleBatch for hook %s", settlement.Hook.Hex()),
+			Header: []string{"fill_id", "buy_order_id", "sell_order_id", "quantity", "expiry_block"},
+		}
+		for _, fill := range settlement.Fills {
+			fv := fillView{
+				FillId:      common.Hash(fill.FillId),
+				BuyOrderId:  fill.BuyOrderId.String(),
+				SellOrderId: fill.SellOrderId.String(),
+				Quantity:    fill.Quantity.String(),
+				ExpiryBlock: fill.ExpiryBlock.String(),
+			}
+			view.Fills = append(view.Fills, fv)
+			fills.Rows = append(fills.Rows, []string{fv.FillId.Hex(), fv.BuyOrderId, fv.SellOrderId, fv.Quantity, fv.ExpiryBlock})
+		}
+		return output.Render(format, view, fills)
+	}
+
+	notice, err := cartesi.DecodeVoucherNotice(data)
+	if err != nil {
+		return fmt.Errorf("payload is neither a SettleBatch nor an ExecuteVouchers notice")
+	}
+
+	view := noticeView{Kind: "execute_vouchers", Hook: &notice.Hook}
+	rows := output.Table{
+		Title:  fmt.Sprintf("ExecuteVouchers for hook %s", notice.Hook.Hex()),
+		Header: []string{"action", "order_id", "side", "amount"},
+	}
+	for _, voucher := range notice.Vouchers {
+		vv := voucherView{Payload: hexutil.Encode(voucher.Payload)}
+		if action, err := cartesi.DecodeActionVoucher(&voucher); err == nil {
+			vv.Action = string(action.Kind)
+			vv.OrderId = strconv.FormatUint(action.OrderId-1, 10)
+			vv.Side = string(*action.OrderType)
+			if action.Amount != nil {
+				vv.Amount = action.Amount.Dec()
+			}
+		} else {
+			vv.Error = err.Error()
+		}
+		view.Vouchers = append(view.Vouchers, vv)
+
+		action := vv.Action
+		if action == "" {
+			action = "unknown " + vv.Payload
+		}
+		rows.Rows = append(rows.Rows, []string{action, vv.OrderId, vv.Side, vv.Amount})
+	}
+	return output.Render(format, view, rows)
+}
@@ -0,0 +1,112 @@
+package codec
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/henriquemarlon/swapx/cmd/swapx-coprocessor/root/output"
+	"github.com/henriquemarlon/swapx/internal/domain"
+	"github.com/henriquemarlon/swapx/internal/infra/cartesi"
+	"github.com/holiman/uint256"
+	"github.com/spf13/cobra"
+)
+
+var (
+	orderId           uint64
+	side              string
+	amount            string
+	sqrtPriceX96      string
+	price             string
+	immediateOrCancel bool
+	routeToPool       bool
+	flags             uint64
+	EncodeCmd         = &cobra.Command{
+		Use:   "encode",
+		Short: "Encode task payloads from human-readable values",
+	}
+	encodeTaskCmd = &cobra.Command{
+		Use:          "task",
+		Short:        "Encode the task payload the hook would create for an order",
+		Long:         `Encodes abi.encode(orderId, sqrtPrice, amount, side, flags), taking the price either as sqrtPriceX96 or as a human price adjusted by --decimals0 and --decimals1`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE:         encodeTask,
+	}
+)
+
+func init() {
+	addOutputFlags(EncodeCmd)
+	encodeTaskCmd.Flags().Uint64Var(&orderId, "order-id", 0, "Order id as sent by the hook, the length of the order array after the push (index + 1)")
+	encodeTaskCmd.Flags().StringVar(&side, "side", "", "Order side, buy or sell")
+	encodeTaskCmd.Flags().StringVar(&amount, "amount", "", "Order amount in base units of the specified currency")
+	encodeTaskCmd.Flags().StringVar(&sqrtPriceX96, "sqrt-price-x96", "", "Limit price as sqrtPriceX96")
+	encodeTaskCmd.Flags().StringVar(&price, "price", "", "Limit price as a human price of currency0 in currency1")
+	encodeTaskCmd.Flags().BoolVar(&immediateOrCancel, "immediate-or-cancel", false, "Refund the remainder of the order right after matching")
+	encodeTaskCmd.Flags().BoolVar(&routeToPool, "route-to-pool", false, "Swap the remainder of the order through the pool once the book is exhausted")
+	encodeTaskCmd.Flags().Uint64Var(&flags, "flags", 0, "Raw flags word, combined with the flag options")
+	encodeTaskCmd.MarkFlagRequired("order-id")
+	encodeTaskCmd.MarkFlagRequired("side")
+	encodeTaskCmd.MarkFlagRequired("amount")
+	encodeTaskCmd.MarkFlagsMutuallyExclusive("sqrt-price-x96", "price")
+	encodeTaskCmd.MarkFlagsOneRequired("sqrt-price-x96", "price")
+	EncodeCmd.AddCommand(encodeTaskCmd)
+}
+
+type encodedTaskView struct {
+	*taskView
+	Payload string `json:"payload"`
+}
+
+func encodeTask(cmd *cobra.Command, args []string) error {
+	task := &cartesi.TaskPayload{
+		OrderId: orderId,
+		Type:    domain.OrderType(side),
+		Flags:   domain.OrderFlag(flags),
+	}
+	if task.OrderId == 0 {
+		return errors.New("order id is 1-based, it must be greater than zero")
+	}
+	if task.Type != domain.OrderTypeBuy && task.Type != domain.OrderTypeSell {
+		return fmt.Errorf("invalid side %q, use buy or sell", side)
+	}
+	if immediateOrCancel {
+		task.Flags |= domain.OrderFlagImmediateOrCancel
+	}
+	if routeToPool {
+		task.Flags |= domain.OrderFlagRouteToPool
+	}
+
+	var err error
+	task.Amount, err = uint256.FromDecimal(amount)
+	if err != nil {
+		return fmt.Errorf("invalid amount: %v", err)
+	}
+
+	if sqrtPriceX96 != "" {
+		task.SqrtPrice, err = uint256.FromDecimal(sqrtPriceX96)
+		if err != nil {
+			return fmt.Errorf("invalid sqrtPriceX96: %v", err)
+		}
+	} else {
+		humanPrice, ok := new(big.Float).SetPrec(domain.PRICE_PRECISION).SetString(price)
+		if !ok {
+			return fmt.Errorf("invalid price %q", price)
+		}
+		task.SqrtPrice, err = domain.PriceToSqrtPriceX96(humanPrice, decimals0, decimals1)
+		if err != nil {
+			return err
+		}
+	}
+
+	payload, err := cartesi.EncodeTaskPayload(task)
+	if err != nil {
+		return err
+	}
+
+	view := encodedTaskView{taskView: newTaskView(task), Payload: hexutil.Encode(payload)}
+	t := view.table()
+	t.Rows = append(t.Rows, []string{"payload", view.Payload})
+	return output.Render(format, view, t)
+}
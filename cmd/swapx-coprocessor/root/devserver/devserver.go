@@ -0,0 +1,102 @@
+package devserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/henriquemarlon/swapx/configs"
+	"github.com/henriquemarlon/swapx/pkg/devserver"
+	"github.com/spf13/cobra"
+)
+
+const (
+	CMD_NAME = "devserver"
+)
+
+var (
+	listen      string
+	inputsPath  string
+	storagePath string
+	outputsPath string
+	Cmd         = &cobra.Command{
+		Use:   CMD_NAME,
+		Short: "Run a local stand-in for the rollup HTTP server and GIO",
+		Long:  `Serves /finish, /notice, /report, /voucher, /exception and /gio in-process, feeding a scripted queue of EvmAdvance inputs and answering 0x27 storage requests from an in-memory state, so the coprocessor can run end to end without the devnet stack`,
+		Run:   run,
+	}
+)
+
+func init() {
+	Cmd.Flags().StringVar(&listen, "listen", "127.0.0.1:5004", "Address to listen on, point ROLLUP_HTTP_SERVER_URL at it")
+	Cmd.Flags().StringVar(&inputsPath, "inputs", "", "JSON or JSONL file with the EvmAdvance inputs to feed, in order")
+	Cmd.Flags().StringVar(&storagePath, "storage", "", "JSON storage snapshot in the {\"<address>\": {\"<slot>\": \"<value>\"}} format")
+	Cmd.Flags().StringVar(&outputsPath, "outputs", "", "File to write the recorded outputs to on exit")
+	Cmd.MarkFlagRequired("inputs")
+	Cmd.PreRun = func(cmd *cobra.Command, args []string) {
+		configs.ConfigureLogger(slog.LevelInfo)
+	}
+}
+
+func run(cmd *cobra.Command, args []string) {
+	inputs, err := devserver.LoadInputs(inputsPath)
+	if err != nil {
+		slog.Error("Error: could not load inputs", "err", err)
+		os.Exit(1)
+	}
+
+	storage := devserver.NewMemoryStorage()
+	if storagePath != "" {
+		storage, err = devserver.LoadMemoryStorage(storagePath)
+		if err != nil {
+			slog.Error("Error: could not load storage snapshot", "err", err)
+			os.Exit(1)
+		}
+	}
+
+	server := devserver.NewServer(inputs, storage)
+	httpServer := &http.Server{Addr: listen, Handler: server.Handler()}
+
+	go func() {
+		slog.Info("Devserver listening", "address", listen, "inputs", len(inputs))
+		if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("Error: devserver stopped", "err", err)
+			os.Exit(1)
+		}
+	}()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	select {
+	case <-server.Drained():
+		slog.Info("All inputs processed, press Ctrl+C to exit")
+		<-signals
+	case <-signals:
+	}
+
+	if err := httpServer.Shutdown(context.Background()); err != nil {
+		slog.Error("Error: shutting down devserver", "err", err)
+	}
+
+	outputs, results := server.Snapshot()
+	if outputsPath == "" {
+		return
+	}
+
+	data, err := json.MarshalIndent(map[string]any{"outputs": outputs, "results": results}, "", "  ")
+	if err != nil {
+		slog.Error("Error: could not encode outputs", "err", err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(outputsPath, data, 0o644); err != nil {
+		slog.Error("Error: could not write outputs", "err", err)
+		os.Exit(1)
+	}
+	slog.Info("Outputs written", "path", outputsPath, "outputs", len(outputs))
+}
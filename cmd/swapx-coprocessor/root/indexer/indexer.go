@@ -0,0 +1,211 @@
+package indexer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/henriquemarlon/swapx/cmd/swapx-coprocessor/root/output"
+	"github.com/henriquemarlon/swapx/configs"
+	"github.com/henriquemarlon/swapx/internal/domain"
+	"github.com/henriquemarlon/swapx/internal/infra/api"
+	"github.com/henriquemarlon/swapx/internal/infra/indexer"
+	"github.com/henriquemarlon/swapx/internal/infra/repository"
+	"github.com/spf13/cobra"
+)
+
+const (
+	CMD_NAME = "indexer"
+)
+
+var (
+	rpcUrl       string
+	hookAddress  string
+	fromBlock    uint64
+	reorgDepth   uint64
+	pollInterval time.Duration
+	once         bool
+	format       string
+	snapshotPath string
+	listen       string
+	decimals0    uint8
+	decimals1    uint8
+	Cmd          = &cobra.Command{
+		Use:   CMD_NAME,
+		Short: "Mirror the book of a hook from its events",
+		Long:  `Follows the OrderCreated, OrderFulfilled, OrderPartiallyFulfilled and OrderCancelled events of a hook from a JSON-RPC node, keeping a live book and the trade history. Blocks are chained by parent hash and the book is rolled back to the common ancestor when a reorg replaces them`,
+		Run:   run,
+	}
+)
+
+func init() {
+	Cmd.Flags().StringVar(&rpcUrl, "rpc-url", envOrDefault("RPC_URL", "http://127.0.0.1:8545"), "JSON-RPC endpoint to follow (env: RPC_URL)")
+	Cmd.Flags().StringVar(&hookAddress, "hook", "", "Address of the hook")
+	Cmd.Flags().Uint64Var(&fromBlock, "from-block", 0, "Block to start indexing at, usually the one the hook was deployed at")
+	Cmd.Flags().Uint64Var(&reorgDepth, "reorg-depth", indexer.DEFAULT_REORG_DEPTH, "Number of recent blocks that can be rolled back")
+	Cmd.Flags().DurationVar(&pollInterval, "poll-interval", 2*time.Second, "Interval between two syncs with the head of the chain")
+	Cmd.Flags().BoolVar(&once, "once", false, "Sync up to the current head, print the book and the trades and exit")
+	Cmd.Flags().StringVar(&format, "format", output.FORMAT_TABLE, "Output format of --once, json or table")
+	Cmd.Flags().StringVar(&snapshotPath, "export-snapshot", "", "File to write a snapshot of the book to on exit")
+	Cmd.Flags().StringVar(&listen, "listen", "", "Address to serve the query API on, such as 127.0.0.1:8080, no API when empty")
+	Cmd.Flags().Uint8Var(&decimals0, "decimals0", 18, "Decimals of currency0, used to render human prices")
+	Cmd.Flags().Uint8Var(&decimals1, "decimals1", 18, "Decimals of currency1, used to render human prices")
+	Cmd.MarkFlagRequired("hook")
+	Cmd.PreRun = func(cmd *cobra.Command, args []string) {
+		// --once prints the book on stdout, where the logs would get in the way
+		if once {
+			configs.ConfigureLogger(slog.LevelError)
+			return
+		}
+		configs.ConfigureLogger(slog.LevelInfo)
+	}
+}
+
+func envOrDefault(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+func run(cmd *cobra.Command, args []string) {
+	if !common.IsHexAddress(hookAddress) {
+		slog.Error("Error: invalid hook address", "hook", hookAddress)
+		os.Exit(1)
+	}
+	hook := common.HexToAddress(hookAddress)
+
+	client, err := ethclient.Dial(rpcUrl)
+	if err != nil {
+		slog.Error("Error: could not connect to the node", "rpc_url", rpcUrl, "err", err)
+		os.Exit(1)
+	}
+	defer client.Close()
+
+	db, err := configs.SetupInMemoryDB()
+	if err != nil {
+		slog.Error("Error: could not setup in-memory DB", "err", err)
+		os.Exit(1)
+	}
+
+	ix, err := indexer.NewIndexer(indexer.NewRpcChain(client), db, hook, fromBlock, reorgDepth)
+	if err != nil {
+		slog.Error("Error: could not setup the indexer", "err", err)
+		os.Exit(1)
+	}
+
+	if once {
+		output, err := ix.Sync(context.Background())
+		if err != nil {
+			slog.Error("Error: could not sync", "err", err)
+			os.Exit(1)
+		}
+		exportSnapshot(db)
+		if err := render(db, hook, output); err != nil {
+			slog.Error("Error: could not render the book", "err", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if listen != "" {
+		market := api.NewMarket(api.Pair{Hook: hook, Decimals0: decimals0, Decimals1: decimals1}, db)
+		ix.Publisher = market.Stream
+		server := &http.Server{
+			Addr:    listen,
+			Handler: api.NewServer(market).Handler(),
+			// streams end with the indexer rather than holding up the shutdown
+			BaseContext: func(net.Listener) context.Context { return ctx },
+		}
+		go func() {
+			slog.Info("Query API listening", "address", listen)
+			if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				slog.Error("Error: query API stopped", "err", err)
+				os.Exit(1)
+			}
+		}()
+		defer server.Shutdown(context.Background())
+	}
+
+	slog.Info("Indexer started", "hook", hook.Hex(), "from_block", fromBlock, "reorg_depth", reorgDepth, "poll_interval", pollInterval)
+	if err := ix.Run(ctx, pollInterval); err != nil {
+		slog.Error("Error: indexer stopped", "err", err)
+		exportSnapshot(db)
+		os.Exit(1)
+	}
+	exportSnapshot(db)
+}
+
+func exportSnapshot(db *configs.InMemoryDB) {
+	if snapshotPath == "" {
+		return
+	}
+	snapshot, err := repository.ExportSnapshot(db)
+	if err != nil {
+		slog.Error("Error: could not export snapshot", "err", err)
+		os.Exit(1)
+	}
+	if err := repository.WriteSnapshot(snapshotPath, snapshot); err != nil {
+		slog.Error("Error: could not write snapshot", "err", err)
+		os.Exit(1)
+	}
+	slog.Info("Snapshot exported", "path", snapshotPath, "state_hash", snapshot.StateHash.Hex())
+}
+
+type bookView struct {
+	Hook   common.Address         `json:"hook"`
+	Sync   *indexer.SyncOutputDTO `json:"sync"`
+	Cursor *domain.SyncCursor     `json:"cursor"`
+	Orders []*domain.Order        `json:"orders"`
+	Fills  []*domain.Fill         `json:"fills"`
+}
+
+func render(db *configs.InMemoryDB, hook common.Address, sync *indexer.SyncOutputDTO) error {
+	orders, err := repository.NewOrderRepositoryInMemory(db).FindAllOrders()
+	if err != nil {
+		return err
+	}
+	fills, err := repository.NewFillRepositoryInMemory(db).FindFills()
+	if err != nil {
+		return err
+	}
+	cursor, err := repository.NewCursorRepositoryInMemory(db).FindCursor()
+	if err != nil {
+		return err
+	}
+	view := bookView{Hook: hook, Sync: sync, Cursor: cursor, Orders: orders, Fills: fills}
+
+	orderRows := make([][]string, 0, len(orders))
+	for _, o := range orders {
+		orderRows = append(orderRows, []string{string(*o.Type), strconv.FormatUint(o.Id, 10), o.SqrtPrice.Dec(), o.Amount.Dec(), o.MatchedAmount.Dec(), o.Remaining().Dec(), string(*o.Status)})
+	}
+	fillRows := make([][]string, 0, len(fills))
+	for _, f := range fills {
+		fillRows = append(fillRows, []string{strconv.FormatUint(f.BlockNumber, 10), f.TxHash.Hex(), string(f.OrderType), strconv.FormatUint(f.OrderId, 10), f.Account.Hex(), f.SqrtPrice.Dec(), f.Quantity.Dec()})
+	}
+	return output.Render(format, view,
+		output.Table{
+			Title:  fmt.Sprintf("Book of hook %s at block %d (%s)", hook.Hex(), cursor.BlockNumber, cursor.BlockHash.Hex()),
+			Header: []string{"side", "id", "sqrt_price_x96", "amount", "matched", "remaining", "status"},
+			Rows:   orderRows,
+		},
+		output.Table{
+			Title:  "Trades",
+			Header: []string{"block", "tx", "side", "id", "account", "sqrt_price_x96", "quantity"},
+			Rows:   fillRows,
+		},
+	)
+}
@@ -0,0 +1,82 @@
+//go:build wireinject
+// +build wireinject
+
+package inject
+
+import (
+	"github.com/google/wire"
+	"github.com/henriquemarlon/swapx/configs"
+	"github.com/henriquemarlon/swapx/internal/domain"
+	"github.com/henriquemarlon/swapx/internal/infra/cartesi"
+	"github.com/henriquemarlon/swapx/internal/infra/repository"
+	"github.com/henriquemarlon/swapx/internal/infra/service"
+	"github.com/henriquemarlon/swapx/internal/usecase"
+	"github.com/henriquemarlon/swapx/pkg/gio"
+)
+
+var setHookStorageService = wire.NewSet(
+	service.NewOrderStorageService,
+	wire.Bind(new(service.OrderStorageServiceInterface), new(*service.OrderStorageService)),
+)
+
+var setBlockHeaderService = wire.NewSet(
+	service.NewBlockHeaderService,
+	wire.Bind(new(service.BlockHeaderServiceInterface), new(*service.BlockHeaderService)),
+)
+
+var setPoolStateService = wire.NewSet(
+	service.NewPoolStateService,
+	wire.Bind(new(service.PoolStateServiceInterface), new(*service.PoolStateService)),
+)
+
+var setOrderRepositoryDependency = wire.NewSet(
+	repository.NewOrderRepositoryInMemory,
+	wire.Bind(new(domain.OrderRepository), new(*repository.OrderRepositoryInMemory)),
+)
+
+var setCursorRepositoryDependency = wire.NewSet(
+	repository.NewCursorRepositoryInMemory,
+	wire.Bind(new(domain.CursorRepository), new(*repository.CursorRepositoryInMemory)),
+)
+
+var setMarketRepositoryDependency = wire.NewSet(
+	repository.NewMarketRepositoryInMemory,
+	wire.Bind(new(domain.MarketRepository), new(*repository.MarketRepositoryInMemory)),
+)
+
+var setSettlementRepositoryDependency = wire.NewSet(
+	repository.NewSettlementRepositoryInMemory,
+	wire.Bind(new(domain.SettlementRepository), new(*repository.SettlementRepositoryInMemory)),
+)
+
+var setLineageRepositoryDependency = wire.NewSet(
+	repository.NewLineageRepositoryInMemory,
+	wire.Bind(new(domain.LineageRepository), new(*repository.LineageRepositoryInMemory)),
+)
+
+var setMatchOrdersHandler = wire.NewSet(
+	cartesi.NewMatchOrdersHandler,
+)
+
+func NewMatchOrdersHandler(db *configs.InMemoryDB, allowlist *configs.Allowlist, policy *usecase.MatchingPolicy, gioHandlerFactory gio.GioHandlerFactory) (*cartesi.MatchOrdersHandler, error) {
+	wire.Build(
+		setOrderRepositoryDependency,
+		setCursorRepositoryDependency,
+		setMarketRepositoryDependency,
+		setSettlementRepositoryDependency,
+		setLineageRepositoryDependency,
+		setHookStorageService,
+		setBlockHeaderService,
+		setPoolStateService,
+		setMatchOrdersHandler,
+	)
+	return &cartesi.MatchOrdersHandler{}, nil
+}
+
+func NewInspectHandler(db *configs.InMemoryDB) (*cartesi.InspectHandler, error) {
+	wire.Build(
+		setMarketRepositoryDependency,
+		cartesi.NewInspectHandler,
+	)
+	return &cartesi.InspectHandler{}, nil
+}
@@ -0,0 +1,59 @@
+// Code generated by Wire. DO NOT EDIT.
+
+//go:generate go run -mod=mod github.com/google/wire/cmd/wire
+//go:build !wireinject
+// +build !wireinject
+
+package inject
+
+import (
+	"github.com/google/wire"
+	"github.com/henriquemarlon/swapx/configs"
+	"github.com/henriquemarlon/swapx/internal/domain"
+	"github.com/henriquemarlon/swapx/internal/infra/cartesi"
+	"github.com/henriquemarlon/swapx/internal/infra/repository"
+	"github.com/henriquemarlon/swapx/internal/infra/service"
+	"github.com/henriquemarlon/swapx/internal/usecase"
+	"github.com/henriquemarlon/swapx/pkg/gio"
+)
+
+// Injectors from wire.go:
+
+func NewMatchOrdersHandler(db *configs.InMemoryDB, allowlist *configs.Allowlist, policy *usecase.MatchingPolicy, gioHandlerFactory gio.GioHandlerFactory) (*cartesi.MatchOrdersHandler, error) {
+	orderRepositoryInMemory := repository.NewOrderRepositoryInMemory(db)
+	cursorRepositoryInMemory := repository.NewCursorRepositoryInMemory(db)
+	marketRepositoryInMemory := repository.NewMarketRepositoryInMemory(db)
+	settlementRepositoryInMemory := repository.NewSettlementRepositoryInMemory(db)
+	lineageRepositoryInMemory := repository.NewLineageRepositoryInMemory(db)
+	orderStorageService := service.NewOrderStorageService(gioHandlerFactory)
+	blockHeaderService := service.NewBlockHeaderService(gioHandlerFactory)
+	poolStateService := service.NewPoolStateService(gioHandlerFactory)
+	matchOrdersHandler := cartesi.NewMatchOrdersHandler(allowlist, policy, orderRepositoryInMemory, cursorRepositoryInMemory, marketRepositoryInMemory, settlementRepositoryInMemory, lineageRepositoryInMemory, orderStorageService, blockHeaderService, poolStateService)
+	return matchOrdersHandler, nil
+}
+
+func NewInspectHandler(db *configs.InMemoryDB) (*cartesi.InspectHandler, error) {
+	marketRepositoryInMemory := repository.NewMarketRepositoryInMemory(db)
+	inspectHandler := cartesi.NewInspectHandler(marketRepositoryInMemory)
+	return inspectHandler, nil
+}
+
+// wire.go:
+
+var setHookStorageService = wire.NewSet(service.NewOrderStorageService, wire.Bind(new(service.OrderStorageServiceInterface), new(*service.OrderStorageService)))
+
+var setBlockHeaderService = wire.NewSet(service.NewBlockHeaderService, wire.Bind(new(service.BlockHeaderServiceInterface), new(*service.BlockHeaderService)))
+
+var setPoolStateService = wire.NewSet(service.NewPoolStateService, wire.Bind(new(service.PoolStateServiceInterface), new(*service.PoolStateService)))
+
+var setOrderRepositoryDependency = wire.NewSet(repository.NewOrderRepositoryInMemory, wire.Bind(new(domain.OrderRepository), new(*repository.OrderRepositoryInMemory)))
+
+var setCursorRepositoryDependency = wire.NewSet(repository.NewCursorRepositoryInMemory, wire.Bind(new(domain.CursorRepository), new(*repository.CursorRepositoryInMemory)))
+
+var setMarketRepositoryDependency = wire.NewSet(repository.NewMarketRepositoryInMemory, wire.Bind(new(domain.MarketRepository), new(*repository.MarketRepositoryInMemory)))
+
+var setSettlementRepositoryDependency = wire.NewSet(repository.NewSettlementRepositoryInMemory, wire.Bind(new(domain.SettlementRepository), new(*repository.SettlementRepositoryInMemory)))
+
+var setLineageRepositoryDependency = wire.NewSet(repository.NewLineageRepositoryInMemory, wire.Bind(new(domain.LineageRepository), new(*repository.LineageRepositoryInMemory)))
+
+var setMatchOrdersHandler = wire.NewSet(cartesi.NewMatchOrdersHandler)
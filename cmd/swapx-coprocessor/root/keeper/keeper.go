@@ -0,0 +1,185 @@
+package keeper
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/henriquemarlon/swapx/configs"
+	"github.com/henriquemarlon/swapx/internal/infra/indexer"
+	"github.com/henriquemarlon/swapx/internal/infra/keeper"
+	"github.com/henriquemarlon/swapx/internal/infra/service"
+	"github.com/henriquemarlon/swapx/internal/usecase"
+	"github.com/henriquemarlon/swapx/pkg/gio"
+	"github.com/henriquemarlon/swapx/pkg/swapx"
+	"github.com/spf13/cobra"
+)
+
+const (
+	CMD_NAME = "keeper"
+)
+
+var (
+	errMissingKey         = errors.New("a private key is needed to send tasks, use --private-key or --dry-run")
+	errInvalidTaskManager = errors.New("invalid task manager address")
+	errMissingPoolManager = errors.New("a pool manager is needed to read the spot price of the band, use --pool-manager")
+)
+
+var (
+	rpcUrl       string
+	hookAddress  string
+	taskManager  string
+	privateKey   string
+	fromBlock    uint64
+	reorgDepth   uint64
+	pollInterval time.Duration
+	minAge       uint64
+	cooldown     uint64
+	backoff      uint64
+	bandBps      uint64
+	poolManager  string
+	dryRun       bool
+	Cmd          = &cobra.Command{
+		Use:   CMD_NAME,
+		Short: "Ask for a rematch when a crossed book is left unmatched",
+		Long:  `Mirrors the book of a hook from its events like the indexer and, when a bid and an ask have been crossing for --min-age blocks, creates a rematch task through the task manager so the coprocessor matches the book without a new order. This happens when the task of the order that crossed them failed or its settlement reverted`,
+		Run:   run,
+	}
+)
+
+func init() {
+	Cmd.Flags().StringVar(&rpcUrl, "rpc-url", envOrDefault("RPC_URL", "http://127.0.0.1:8545"), "JSON-RPC endpoint to follow and send the tasks to (env: RPC_URL)")
+	Cmd.Flags().StringVar(&hookAddress, "hook", "", "Address of the hook")
+	Cmd.Flags().StringVar(&taskManager, "task-manager", "", "Address of the task manager, read from the hook when empty")
+	Cmd.Flags().StringVar(&privateKey, "private-key", os.Getenv("KEEPER_PRIVATE_KEY"), "Hex private key of the account creating the tasks (env: KEEPER_PRIVATE_KEY)")
+	Cmd.Flags().Uint64Var(&fromBlock, "from-block", 0, "Block to start indexing at, usually the one the hook was deployed at")
+	Cmd.Flags().Uint64Var(&reorgDepth, "reorg-depth", indexer.DEFAULT_REORG_DEPTH, "Number of recent blocks that can be rolled back")
+	Cmd.Flags().DurationVar(&pollInterval, "poll-interval", 2*time.Second, "Interval between two syncs with the head of the chain")
+	Cmd.Flags().Uint64Var(&minAge, "min-age", keeper.DEFAULT_MIN_AGE, "Blocks both orders of a crossing have to be in the book before a rematch")
+	Cmd.Flags().Uint64Var(&cooldown, "cooldown", keeper.DEFAULT_COOLDOWN, "Blocks to wait after a rematch before sending another one")
+	Cmd.Flags().Uint64Var(&backoff, "backoff", keeper.DEFAULT_BACKOFF, "Blocks to wait before rematching a crossing the last rematch left unmatched")
+	Cmd.Flags().Uint64Var(&bandBps, "band-bps", 0, "Price band of the hook in basis points, crossings outside of it are not rematched")
+	Cmd.Flags().StringVar(&poolManager, "pool-manager", "", "Address of the pool manager the spot price of --band-bps is read from")
+	Cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Log the crossings that would be rematched without sending anything")
+	Cmd.MarkFlagRequired("hook")
+	Cmd.PreRun = func(cmd *cobra.Command, args []string) {
+		configs.ConfigureLogger(slog.LevelInfo)
+	}
+}
+
+func envOrDefault(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// dryRunRematcher stands in for the task manager with --dry-run
+type dryRunRematcher struct{}
+
+func (dryRunRematcher) Rematch(ctx context.Context, hook common.Address) (common.Hash, error) {
+	slog.Info("Dry run, no rematch sent", "hook", hook.Hex())
+	return common.Hash{}, nil
+}
+
+func run(cmd *cobra.Command, args []string) {
+	if !common.IsHexAddress(hookAddress) {
+		slog.Error("Error: invalid hook address", "hook", hookAddress)
+		os.Exit(1)
+	}
+	hook := common.HexToAddress(hookAddress)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	client, err := ethclient.Dial(rpcUrl)
+	if err != nil {
+		slog.Error("Error: could not connect to the node", "rpc_url", rpcUrl, "err", err)
+		os.Exit(1)
+	}
+	defer client.Close()
+
+	var rematcher keeper.Rematcher = dryRunRematcher{}
+	if !dryRun {
+		rematcher, err = newRematcher(ctx, client, hook)
+		if err != nil {
+			slog.Error("Error: could not setup the task manager", "err", err)
+			os.Exit(1)
+		}
+	}
+
+	db, err := configs.SetupInMemoryDB()
+	if err != nil {
+		slog.Error("Error: could not setup in-memory DB", "err", err)
+		os.Exit(1)
+	}
+	ix, err := indexer.NewIndexer(indexer.NewRpcChain(client), db, hook, fromBlock, reorgDepth)
+	if err != nil {
+		slog.Error("Error: could not setup the indexer", "err", err)
+		os.Exit(1)
+	}
+
+	k := keeper.NewKeeper(hook, db, rematcher, minAge, cooldown)
+	k.Backoff = backoff
+	if cmd.Flags().Changed("band-bps") {
+		if !common.IsHexAddress(poolManager) {
+			slog.Error("Error: could not setup the price band", "err", errMissingPoolManager)
+			os.Exit(1)
+		}
+		pools := service.NewPoolStateService(gio.NewRpcGioHandlerFactory(client))
+		k.BandBps, k.Spot = &bandBps, keeper.NewPoolSpotPricer(pools, common.HexToAddress(poolManager), usecase.DefaultStorageSlots.PoolKey)
+	}
+
+	slog.Info("Keeper started", "hook", hook.Hex(), "from_block", fromBlock, "min_age", minAge, "cooldown", cooldown, "backoff", backoff, "dry_run", dryRun)
+	if err := k.Run(ctx, ix, pollInterval); err != nil {
+		slog.Error("Error: keeper stopped", "err", err)
+		os.Exit(1)
+	}
+}
+
+func newRematcher(ctx context.Context, client *ethclient.Client, hook common.Address) (*keeper.TaskManagerRematcher, error) {
+	if privateKey == "" {
+		return nil, errMissingKey
+	}
+	key, err := crypto.HexToECDSA(strings.TrimPrefix(privateKey, "0x"))
+	if err != nil {
+		return nil, err
+	}
+	chainId, err := client.ChainID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	opts, err := bind.NewKeyedTransactorWithChainID(key, chainId)
+	if err != nil {
+		return nil, err
+	}
+
+	address := common.HexToAddress(taskManager)
+	if taskManager == "" {
+		contract, err := swapx.NewSwapXHook(hook, client)
+		if err != nil {
+			return nil, err
+		}
+		if address, err = contract.SwapXTaskManager(&bind.CallOpts{Context: ctx}); err != nil {
+			return nil, err
+		}
+	} else if !common.IsHexAddress(taskManager) {
+		return nil, errInvalidTaskManager
+	}
+
+	contract, err := swapx.NewSwapXTaskManager(address, client)
+	if err != nil {
+		return nil, err
+	}
+	slog.Info("Rematch tasks go through the task manager", "task_manager", address.Hex(), "sender", opts.From.Hex())
+	return keeper.NewTaskManagerRematcher(contract, opts), nil
+}
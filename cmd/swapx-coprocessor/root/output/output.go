@@ -0,0 +1,52 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+const (
+	FORMAT_JSON  = "json"
+	FORMAT_TABLE = "table"
+)
+
+type Table struct {
+	Title  string
+	Header []string
+	Rows   [][]string
+}
+
+// Render prints value as indented JSON, or the tables built for it.
+func Render(format string, value any, tables ...Table) error {
+	switch format {
+	case FORMAT_JSON:
+		data, err := json.MarshalIndent(value, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(os.Stdout, string(data))
+		return err
+	case FORMAT_TABLE:
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for i, t := range tables {
+			if i > 0 {
+				fmt.Fprintln(w)
+			}
+			if t.Title != "" {
+				fmt.Fprintln(w, t.Title)
+			}
+			if len(t.Header) > 0 {
+				fmt.Fprintln(w, strings.ToUpper(strings.Join(t.Header, "\t")))
+			}
+			for _, row := range t.Rows {
+				fmt.Fprintln(w, strings.Join(row, "\t"))
+			}
+		}
+		return w.Flush()
+	default:
+		return fmt.Errorf("unknown format %q, use json or table", format)
+	}
+}
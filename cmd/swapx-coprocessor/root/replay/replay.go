@@ -0,0 +1,162 @@
+package replay
+
+import (
+	"encoding/json"
+	"log/slog"
+	"os"
+
+	"github.com/henriquemarlon/swapx/cmd/swapx-coprocessor/root/inject"
+	"github.com/henriquemarlon/swapx/configs"
+	"github.com/henriquemarlon/swapx/internal/infra/cartesi"
+	"github.com/henriquemarlon/swapx/internal/infra/repository"
+	"github.com/henriquemarlon/swapx/pkg/coprocessor"
+	"github.com/henriquemarlon/swapx/pkg/devserver"
+	"github.com/henriquemarlon/swapx/pkg/gio"
+	"github.com/spf13/cobra"
+)
+
+const (
+	CMD_NAME = "replay"
+)
+
+var (
+	verbose      bool
+	inputsPath   string
+	storagePath  string
+	outputPath   string
+	snapshotPath string
+	config       *configs.Config
+	Cmd          = &cobra.Command{
+		Use:   CMD_NAME,
+		Short: "Re-run recorded coprocessor inputs from a file",
+		Long:  `Feeds a JSON or JSONL file of EvmAdvance inputs, one at a time and in order, to a fresh order handler whose GIO requests are answered from a storage snapshot, then prints every notice, report, voucher and exception produced along with the status of each input. The handler reads the same configuration as the coprocessor, except for the rollup and GIO urls`,
+		Run:   run,
+	}
+)
+
+func init() {
+	Cmd.Flags().BoolVar(&verbose, "verbose", false, "Show the handler logs, which are written to stdout as well")
+	Cmd.Flags().StringVar(&inputsPath, "inputs", "", "JSON or JSONL file with the EvmAdvance inputs to replay, in order")
+	Cmd.Flags().StringVar(&storagePath, "storage", "", "JSON storage snapshot in the {\"<address>\": {\"<slot>\": \"<value>\"}} format")
+	Cmd.Flags().StringVar(&outputPath, "output", "", "File to write the outputs to instead of stdout")
+	Cmd.Flags().StringVar(&snapshotPath, "export-snapshot", "", "File to write a snapshot of the book to once every input was replayed")
+	configs.AddConfigFlags(Cmd.Flags())
+	Cmd.MarkFlagRequired("inputs")
+	Cmd.PreRunE = func(cmd *cobra.Command, args []string) error {
+		var err error
+		config, err = configs.LoadConfigFromFlags(cmd.Flags())
+		if err != nil {
+			return err
+		}
+
+		if verbose {
+			level, _ := config.LogLevel()
+			configs.ConfigureLoggerWithFormat(level, config.Log.Format)
+		} else {
+			configs.ConfigureLogger(slog.LevelError)
+		}
+		return nil
+	}
+}
+
+func run(cmd *cobra.Command, args []string) {
+	inputs, err := devserver.LoadInputs(inputsPath)
+	if err != nil {
+		slog.Error("Error: could not load inputs", "err", err)
+		os.Exit(1)
+	}
+
+	storage := devserver.NewMemoryStorage()
+	if storagePath != "" {
+		storage, err = devserver.LoadMemoryStorage(storagePath)
+		if err != nil {
+			slog.Error("Error: could not load storage snapshot", "err", err)
+			os.Exit(1)
+		}
+	}
+
+	allowlist, err := config.NewAllowlist()
+	if err != nil {
+		slog.Error("Error: could not setup allowlist", "err", err)
+		os.Exit(1)
+	}
+
+	policy, err := config.MatchingPolicy()
+	if err != nil {
+		slog.Error("Error: invalid matching policy", "err", err)
+		os.Exit(1)
+	}
+
+	hash, err := config.Hash()
+	if err != nil {
+		slog.Error("Error: could not hash config", "err", err)
+		os.Exit(1)
+	}
+
+	db, err := configs.SetupInMemoryDB()
+	if err != nil {
+		slog.Error("Error: could not setup in-memory DB", "err", err)
+		os.Exit(1)
+	}
+	if config.Snapshot.ImportPath != "" {
+		if _, err := repository.ImportSnapshotFile(db, config.Snapshot.ImportPath); err != nil {
+			slog.Error("Error: could not import snapshot", "err", err)
+			os.Exit(1)
+		}
+	}
+
+	server := devserver.NewServer(inputs, storage)
+	url, shutdown, err := server.Listen("127.0.0.1:0")
+	if err != nil {
+		slog.Error("Error: could not start the rollup server", "err", err)
+		os.Exit(1)
+	}
+	defer shutdown()
+	coprocessor.ROLLUP_HTTP_SERVER_URL = url
+
+	// Same wiring as the coprocessor, with a fresh state so every replay of the
+	// same inputs and snapshot starts from the same place
+	gioHandlerFactory := gio.NewCachedGioHandlerFactory(gio.NewGioHandlerFactory(url), config.Cache.GioSize)
+	oh, err := inject.NewMatchOrdersHandler(db, allowlist, policy, gioHandlerFactory)
+	if err != nil {
+		slog.Error("Error: could not setup match orders handler", "err", err)
+		os.Exit(1)
+	}
+
+	handle := func(input *coprocessor.AdvanceResponse) error {
+		defer configs.ScopeLogger(cartesi.InputLogArgs(input)...)()
+		return oh.MatchOrdersHandler(input)
+	}
+	if err := devserver.Drive(handle); err != nil {
+		slog.Error("Error: replay interrupted", "err", err)
+		os.Exit(1)
+	}
+
+	if snapshotPath != "" {
+		snapshot, err := repository.ExportSnapshot(db)
+		if err != nil {
+			slog.Error("Error: could not export snapshot", "err", err)
+			os.Exit(1)
+		}
+		if err := repository.WriteSnapshot(snapshotPath, snapshot); err != nil {
+			slog.Error("Error: could not write snapshot", "err", err)
+			os.Exit(1)
+		}
+	}
+
+	outputs, results := server.Snapshot()
+	data, err := json.MarshalIndent(map[string]any{"config_hash": hash, "outputs": outputs, "results": results}, "", "  ")
+	if err != nil {
+		slog.Error("Error: could not encode outputs", "err", err)
+		os.Exit(1)
+	}
+
+	if outputPath == "" {
+		os.Stdout.Write(append(data, '\n'))
+		return
+	}
+	if err := os.WriteFile(outputPath, data, 0o644); err != nil {
+		slog.Error("Error: could not write outputs", "err", err)
+		os.Exit(1)
+	}
+}
@@ -1,15 +1,24 @@
 package root
 
 import (
-	"encoding/json"
-	"io"
+	"errors"
 	"log/slog"
 	"os"
-	"strconv"
 	"time"
 
+	"github.com/henriquemarlon/swapx/cmd/swapx-coprocessor/root/codec"
+	"github.com/henriquemarlon/swapx/cmd/swapx-coprocessor/root/devserver"
+	"github.com/henriquemarlon/swapx/cmd/swapx-coprocessor/root/indexer"
+	"github.com/henriquemarlon/swapx/cmd/swapx-coprocessor/root/inject"
+	"github.com/henriquemarlon/swapx/cmd/swapx-coprocessor/root/keeper"
+	"github.com/henriquemarlon/swapx/cmd/swapx-coprocessor/root/replay"
+	"github.com/henriquemarlon/swapx/cmd/swapx-coprocessor/root/snapshot"
+	"github.com/henriquemarlon/swapx/cmd/swapx-coprocessor/root/storage"
 	"github.com/henriquemarlon/swapx/configs"
+	"github.com/henriquemarlon/swapx/internal/infra/cartesi"
+	"github.com/henriquemarlon/swapx/internal/infra/repository"
 	"github.com/henriquemarlon/swapx/pkg/coprocessor"
+	"github.com/henriquemarlon/swapx/pkg/gio"
 	"github.com/spf13/cobra"
 )
 
@@ -19,79 +28,136 @@ const (
 
 var (
 	verbose bool
+	config  *configs.Config
 	Cmd     = &cobra.Command{
 		Use:   CMD_NAME,
 		Short: "Run SwapX Coprocessor",
 		Long:  `EVM Linux Coprocessor as an orderbook for UniswapV4 Hooks`,
 		Run:   run,
 	}
-	ROLLUP_HTTP_SERVER_URL = os.Getenv("ROLLUP_HTTP_SERVER_URL")
 )
 
 func init() {
-	Cmd.Flags().BoolVar(&verbose, "verbose", false, "Show detailed logs")
-	Cmd.PreRun = func(cmd *cobra.Command, args []string) {
-		configs.ConfigureLogger(slog.LevelInfo)
+	Cmd.Flags().BoolVar(&verbose, "verbose", false, "Show detailed logs, same as --log-level debug")
+	configs.AddConfigFlags(Cmd.Flags())
+	Cmd.PreRunE = func(cmd *cobra.Command, args []string) error {
+		if verbose && !cmd.Flags().Changed("log-level") {
+			cmd.Flags().Set("log-level", "debug")
+		}
+
+		var err error
+		config, err = configs.LoadConfigFromFlags(cmd.Flags())
+		if err != nil {
+			return err
+		}
+
+		level, _ := config.LogLevel()
+		configs.ConfigureLoggerWithFormat(level, config.Log.Format)
+		return nil
 	}
+	Cmd.AddCommand(devserver.Cmd)
+	Cmd.AddCommand(replay.Cmd)
+	Cmd.AddCommand(codec.DecodeCmd, codec.EncodeCmd)
+	Cmd.AddCommand(storage.Cmd)
+	Cmd.AddCommand(snapshot.Cmd)
+	Cmd.AddCommand(indexer.Cmd)
+	Cmd.AddCommand(keeper.Cmd)
 }
 
 func run(cmd *cobra.Command, args []string) {
+	hash, err := config.Hash()
+	if err != nil {
+		slog.Error("Error: could not hash config", "err", err)
+		os.Exit(1)
+	}
+	slog.Info("Configuration loaded", "hash", hash, "rollup_http_server_url", config.RollupHttpServerUrl, "gio_url", config.GioUrl)
+	coprocessor.ROLLUP_HTTP_SERVER_URL = config.RollupHttpServerUrl
+
 	db, err := configs.SetupInMemoryDB()
 	if err != nil {
 		slog.Error("Error: could not setup in-memory DB", "err", err)
 	}
 	slog.Info("In-memory database initialized")
 
-	oh, err := NewMatchOrdersHandler(db, ROLLUP_HTTP_SERVER_URL)
+	if config.Snapshot.ImportPath != "" {
+		snapshot, err := repository.ImportSnapshotFile(db, config.Snapshot.ImportPath)
+		if err != nil {
+			slog.Error("Error: could not import snapshot", "err", err)
+			os.Exit(1)
+		}
+		slog.Info("Snapshot imported", "state_hash", snapshot.StateHash.Hex(), "orders", len(snapshot.Orders), "block_number", snapshot.Cursor.BlockNumber, "inputs", snapshot.Cursor.Inputs)
+	}
+
+	if err := config.RequireAllowlist(); err != nil {
+		slog.Error("Error: refusing to accept tasks about any hook", "err", err)
+		os.Exit(1)
+	}
+	allowlist, err := config.NewAllowlist()
+	if err != nil {
+		slog.Error("Error: could not setup allowlist", "err", err)
+		os.Exit(1)
+	}
+	if allowlist.IsUnrestricted() {
+		slog.Warn("Allowlist is unrestricted, accepting tasks from any hook, task manager and chain")
+	} else {
+		slog.Info("Allowlist initialized", "hooks", len(allowlist.Hooks), "task_managers", len(allowlist.TaskManagers), "chain_ids", len(allowlist.ChainIds))
+	}
+
+	policy, err := config.MatchingPolicy()
+	if err != nil {
+		slog.Error("Error: invalid matching policy", "err", err)
+		os.Exit(1)
+	}
+
+	gioHandlerFactory := gio.NewCachedGioHandlerFactory(gio.NewGioHandlerFactory(config.GioUrl), config.Cache.GioSize)
+	oh, err := inject.NewMatchOrdersHandler(db, allowlist, policy, gioHandlerFactory)
 	if err != nil {
 		slog.Error("Failed to initialize OrderHandler: %v", "err", err)
 	}
 	slog.Info("Order handler initialized")
+	ih, err := inject.NewInspectHandler(db)
+	if err != nil {
+		slog.Error("Failed to initialize InspectHandler", "err", err)
+	}
 
+	finish := coprocessor.FinishRequest{Status: "accept"}
 	for {
-		slog.Info("Sending finish request")
-		finish := coprocessor.FinishRequest{Status: "accept"}
-		res, err := coprocessor.SendFinish(&finish)
-		if err != nil {
-			slog.Error("Error: making HTTP request: %v", "err", err)
-		}
-		slog.Info("Received finish status", "status_code", strconv.Itoa(res.StatusCode))
-
-		if res.StatusCode == 202 {
-			slog.Info("No pending rollup request, retrying...")
+		slog.Debug("Sending finish request", "status", finish.Status)
+		request, err := coprocessor.FetchRequest(&finish)
+		if err != nil && !errors.Is(err, coprocessor.ErrInvalidRequest) {
+			slog.Error("Error: making HTTP request", "err", err)
 			time.Sleep(1 * time.Second)
 			continue
 		}
+		finish.Status = "accept"
 
-		resBody, err := io.ReadAll(res.Body)
 		if err != nil {
-			slog.Error("Error: could not read response body: %v", "err", err)
-		}
-
-		var finishResponse coprocessor.FinishResponse
-		if err := json.Unmarshal(resBody, &finishResponse); err != nil {
-			slog.Error("Error: unmarshaling response body: %v", "err", err)
+			slog.Error("Error parsing rollup request", "err", err)
+			finish.Status = "reject"
+			continue
 		}
 
-		var rawPayload struct {
-			Data string `json:"payload"`
-		}
-		if err := json.Unmarshal(finishResponse.Data, &rawPayload); err != nil {
-			slog.Error("Error unmarshaling payload", "err", err)
-			finish.Status = "reject"
+		if request == nil {
+			slog.Debug("No pending rollup request, retrying...")
+			time.Sleep(1 * time.Second)
 			continue
 		}
 
-		advanceResponse, err := coprocessor.EvmAdvanceParser(rawPayload.Data)
-		if err != nil {
-			slog.Error("Error parsing advance response", "err", err)
-			finish.Status = "reject"
+		if request.Inspect != nil {
+			if err := ih.InspectHandler(request.Inspect); err != nil {
+				slog.Error("Error handling inspect request", "err", err)
+				finish.Status = "reject"
+			}
 			continue
 		}
 
-		if err := oh.MatchOrdersHandler(&advanceResponse); err != nil {
+		advanceResponse := request.Advance
+		restore := configs.ScopeLogger(cartesi.InputLogArgs(advanceResponse)...)
+		slog.Info("Handling input")
+		if err := oh.MatchOrdersHandler(advanceResponse); err != nil {
 			slog.Error("Error handling order book", "err", err)
 			finish.Status = "reject"
 		}
+		restore()
 	}
 }
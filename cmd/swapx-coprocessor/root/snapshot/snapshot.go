@@ -0,0 +1,111 @@
+package snapshot
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"github.com/henriquemarlon/swapx/cmd/swapx-coprocessor/root/output"
+	"github.com/henriquemarlon/swapx/configs"
+	"github.com/henriquemarlon/swapx/internal/infra/repository"
+	"github.com/spf13/cobra"
+)
+
+const (
+	CMD_NAME = "snapshot"
+)
+
+var ErrSnapshotsDiffer = errors.New("snapshots differ")
+
+var (
+	format string
+	Cmd    = &cobra.Command{
+		Use:   CMD_NAME,
+		Short: "Inspect and compare book snapshots",
+		Long:  `Works with the book snapshots written by replay --export-snapshot and loaded by the coprocessor through snapshot.import_path. Snapshots are checked against the state hash they carry before being shown`,
+	}
+	showCmd = &cobra.Command{
+		Use:          "show <snapshot>",
+		Short:        "Print the cursor and the orders of a snapshot",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE:         runShow,
+	}
+	diffCmd = &cobra.Command{
+		Use:           "diff <before> <after>",
+		Short:         "List the fields that differ between two snapshots, exiting with an error when any does",
+		Args:          cobra.ExactArgs(2),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE:          runDiff,
+	}
+)
+
+func init() {
+	Cmd.PersistentPreRun = func(cmd *cobra.Command, args []string) {
+		configs.ConfigureLogger(slog.LevelError)
+	}
+	Cmd.PersistentFlags().StringVar(&format, "format", output.FORMAT_TABLE, "Output format, json or table")
+	Cmd.AddCommand(showCmd, diffCmd)
+}
+
+func runShow(cmd *cobra.Command, args []string) error {
+	snapshot, err := repository.LoadSnapshot(args[0])
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]string, 0, len(snapshot.Orders))
+	for _, o := range snapshot.Orders {
+		rows = append(rows, []string{string(o.Type), strconv.FormatUint(o.Id, 10), o.Hook.Hex(), o.SqrtPrice, o.Amount, o.MatchedAmount, string(o.Status), strconv.FormatUint(uint64(o.Flags), 10), strconv.FormatUint(o.TerminalSince, 10)})
+	}
+	var pruned uint64
+	for _, hook := range snapshot.Tombstones {
+		pruned += hook.Buy.Floor + uint64(len(hook.Buy.Ids)) + hook.Sell.Floor + uint64(len(hook.Sell.Ids))
+	}
+	return output.Render(format, snapshot, output.Table{
+		Title:  fmt.Sprintf("Snapshot v%d, state hash %s, %d inputs up to block %d (%s), %d orders pruned across %d hooks", snapshot.Version, snapshot.StateHash.Hex(), snapshot.Cursor.Inputs, snapshot.Cursor.BlockNumber, snapshot.Cursor.BlockHash.Hex(), pruned, len(snapshot.Tombstones)),
+		Header: []string{"type", "id", "hook", "sqrt_price", "amount", "matched", "status", "flags", "terminal_since"},
+		Rows:   rows,
+	})
+}
+
+func runDiff(cmd *cobra.Command, args []string) error {
+	before, err := repository.LoadSnapshot(args[0])
+	if err != nil {
+		cmd.PrintErrln("Error:", err)
+		return err
+	}
+	after, err := repository.LoadSnapshot(args[1])
+	if err != nil {
+		cmd.PrintErrln("Error:", err)
+		return err
+	}
+
+	changes := repository.DiffSnapshots(before, after)
+	rows := make([][]string, 0, len(changes))
+	for _, c := range changes {
+		order, hook := "", ""
+		if c.Type != "" {
+			order = fmt.Sprintf("%s %d", c.Type, c.Id)
+		}
+		if c.Hook != nil {
+			hook = c.Hook.Hex()
+		}
+		rows = append(rows, []string{hook, order, c.Field, c.Before, c.After})
+	}
+	if err := output.Render(format, changes, output.Table{
+		Title:  fmt.Sprintf("%d differences between %s (%s) and %s (%s)", len(changes), args[0], before.StateHash.Hex(), args[1], after.StateHash.Hex()),
+		Header: []string{"hook", "order", "field", "before", "after"},
+		Rows:   rows,
+	}); err != nil {
+		return err
+	}
+
+	// Like diff(1), differences are reported through the exit status
+	if len(changes) > 0 {
+		return ErrSnapshotsDiffer
+	}
+	return nil
+}
@@ -0,0 +1,266 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/henriquemarlon/swapx/cmd/swapx-coprocessor/root/output"
+	"github.com/henriquemarlon/swapx/configs"
+	"github.com/henriquemarlon/swapx/internal/domain"
+	"github.com/henriquemarlon/swapx/internal/infra/service"
+	"github.com/henriquemarlon/swapx/internal/usecase"
+	"github.com/henriquemarlon/swapx/pkg/gio"
+	"github.com/spf13/cobra"
+)
+
+const (
+	CMD_NAME = "storage"
+)
+
+var (
+	format         string
+	decimals0      uint8
+	decimals1      uint8
+	buyOrdersSlot  uint64
+	buyStatusSlot  uint64
+	buyFlagsSlot   uint64
+	sellOrdersSlot uint64
+	sellStatusSlot uint64
+	sellFlagsSlot  uint64
+	side           string
+	index          uint64
+	rpcUrl         string
+	hookAddress    string
+	block          string
+	Cmd            = &cobra.Command{
+		Use:   CMD_NAME,
+		Short: "Inspect the hook storage the way the coprocessor reads it",
+	}
+	slotCmd = &cobra.Command{
+		Use:          "slot",
+		Short:        "Compute the storage slots of an order, of its cancel flag and of its flags",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE:         runSlot,
+	}
+	dumpCmd = &cobra.Command{
+		Use:          "dump",
+		Short:        "Dump every order of a hook at a block through eth_getStorageAt",
+		Long:         `Reads the orders of a hook at a block with the same code the coprocessor uses, answering its storage requests from a JSON-RPC node instead of GIO`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE:         runDump,
+	}
+)
+
+func init() {
+	Cmd.PersistentPreRun = func(cmd *cobra.Command, args []string) {
+		configs.ConfigureLogger(slog.LevelError)
+	}
+	Cmd.PersistentFlags().StringVar(&format, "format", output.FORMAT_TABLE, "Output format, json or table")
+	Cmd.PersistentFlags().Uint64Var(&buyOrdersSlot, "buy-orders-slot", usecase.BUY_ORDERS_STORAGE_SLOT, "Slot of the buyOrders array")
+	Cmd.PersistentFlags().Uint64Var(&buyStatusSlot, "buy-status-slot", usecase.BUY_ORDERS_STATUS_STORAGE_SLOT, "Slot of the buyOrderCancelled mapping")
+	Cmd.PersistentFlags().Uint64Var(&buyFlagsSlot, "buy-flags-slot", usecase.BUY_ORDER_FLAGS_STORAGE_SLOT, "Slot of the buyOrderFlags mapping")
+	Cmd.PersistentFlags().Uint64Var(&sellOrdersSlot, "sell-orders-slot", usecase.SELL_ORDERS_STORAGE_SLOT, "Slot of the sellOrders array")
+	Cmd.PersistentFlags().Uint64Var(&sellStatusSlot, "sell-status-slot", usecase.SELL_ORDERS_STATUS_STORAGE_SLOT, "Slot of the sellOrderCancelled mapping")
+	Cmd.PersistentFlags().Uint64Var(&sellFlagsSlot, "sell-flags-slot", usecase.SELL_ORDER_FLAGS_STORAGE_SLOT, "Slot of the sellOrderFlags mapping")
+
+	slotCmd.Flags().StringVar(&side, "side", "", "Order side, buy or sell")
+	slotCmd.Flags().Uint64Var(&index, "index", 0, "Index of the order in the hook array, the order id minus one")
+	slotCmd.MarkFlagRequired("side")
+
+	dumpCmd.Flags().StringVar(&rpcUrl, "rpc-url", envOrDefault("RPC_URL", "http://127.0.0.1:8545"), "JSON-RPC endpoint to read the storage from (env: RPC_URL)")
+	dumpCmd.Flags().StringVar(&hookAddress, "hook", "", "Address of the hook")
+	dumpCmd.Flags().StringVar(&block, "block", "latest", "Block to read at, as a hash, a number or latest")
+	dumpCmd.Flags().StringVar(&side, "side", "", "Only dump one side, buy or sell")
+	dumpCmd.Flags().Uint8Var(&decimals0, "decimals0", 18, "Decimals of currency0, used to render human prices")
+	dumpCmd.Flags().Uint8Var(&decimals1, "decimals1", 18, "Decimals of currency1, used to render human prices")
+	dumpCmd.MarkFlagRequired("hook")
+
+	Cmd.AddCommand(slotCmd, dumpCmd)
+}
+
+func envOrDefault(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+func slotsOf(orderType domain.OrderType) (common.Hash, common.Hash, common.Hash, error) {
+	switch orderType {
+	case domain.OrderTypeBuy:
+		return common.BigToHash(new(big.Int).SetUint64(buyOrdersSlot)), common.BigToHash(new(big.Int).SetUint64(buyStatusSlot)), common.BigToHash(new(big.Int).SetUint64(buyFlagsSlot)), nil
+	case domain.OrderTypeSell:
+		return common.BigToHash(new(big.Int).SetUint64(sellOrdersSlot)), common.BigToHash(new(big.Int).SetUint64(sellStatusSlot)), common.BigToHash(new(big.Int).SetUint64(sellFlagsSlot)), nil
+	default:
+		return common.Hash{}, common.Hash{}, common.Hash{}, fmt.Errorf("invalid side %q, use buy or sell", orderType)
+	}
+}
+
+type slotView struct {
+	Side          domain.OrderType `json:"side"`
+	Index         uint64           `json:"index"`
+	Length        common.Hash      `json:"length"`
+	Account       common.Hash      `json:"account"`
+	SqrtPrice     common.Hash      `json:"sqrt_price"`
+	Amount        common.Hash      `json:"amount"`
+	MatchedAmount common.Hash      `json:"matched_amount"`
+	Cancelled     common.Hash      `json:"cancelled"`
+	Flags         common.Hash      `json:"flags"`
+}
+
+func runSlot(cmd *cobra.Command, args []string) error {
+	orderType := domain.OrderType(side)
+	ordersSlot, statusSlot, flagsSlot, err := slotsOf(orderType)
+	if err != nil {
+		return err
+	}
+
+	view := slotView{
+		Side:          orderType,
+		Index:         index,
+		Length:        ordersSlot,
+		Account:       service.OrderFieldSlot(ordersSlot, index, service.ORDER_ACCOUNT_FIELD),
+		SqrtPrice:     service.OrderFieldSlot(ordersSlot, index, service.ORDER_SQRT_PRICE_FIELD),
+		Amount:        service.OrderFieldSlot(ordersSlot, index, service.ORDER_AMOUNT_FIELD),
+		MatchedAmount: service.OrderFieldSlot(ordersSlot, index, service.ORDER_MATCHED_AMOUNT_FIELD),
+		Cancelled:     service.CancelledSlot(statusSlot, index),
+		Flags:         service.MappingSlot(common.BigToHash(new(big.Int).SetUint64(index)), flagsSlot),
+	}
+
+	return output.Render(format, view, output.Table{
+		Title:  fmt.Sprintf("Slots of %s order %d (id %d)", orderType, index, index+1),
+		Header: []string{"field", "slot"},
+		Rows: [][]string{
+			{"length", view.Length.Hex()},
+			{"account", view.Account.Hex()},
+			{"sqrt_price", view.SqrtPrice.Hex()},
+			{"amount", view.Amount.Hex()},
+			{"matched_amount", view.MatchedAmount.Hex()},
+			{"cancelled", view.Cancelled.Hex()},
+			{"flags", view.Flags.Hex()},
+		},
+	})
+}
+
+type orderView struct {
+	Side          domain.OrderType   `json:"side"`
+	Id            uint64             `json:"id"`
+	Index         uint64             `json:"index"`
+	SqrtPriceX96  string             `json:"sqrt_price_x96"`
+	Price         string             `json:"price"`
+	Amount        string             `json:"amount"`
+	MatchedAmount string             `json:"matched_amount"`
+	Remaining     string             `json:"remaining"`
+	Status        domain.OrderStatus `json:"status"`
+	Flags         []string           `json:"flags"`
+}
+
+type dumpView struct {
+	Hook        common.Address `json:"hook"`
+	BlockHash   common.Hash    `json:"block_hash"`
+	BlockNumber uint64         `json:"block_number"`
+	Orders      []orderView    `json:"orders"`
+}
+
+func runDump(cmd *cobra.Command, args []string) error {
+	if !common.IsHexAddress(hookAddress) {
+		return fmt.Errorf("invalid hook address %q", hookAddress)
+	}
+	hook := common.HexToAddress(hookAddress)
+
+	client, err := ethclient.Dial(rpcUrl)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	blockHash, blockNumber, err := resolveBlock(cmd.Context(), client, block)
+	if err != nil {
+		return err
+	}
+
+	sides := []domain.OrderType{domain.OrderTypeBuy, domain.OrderTypeSell}
+	if side != "" {
+		sides = []domain.OrderType{domain.OrderType(side)}
+	}
+
+	storageService := service.NewOrderStorageService(gio.NewRpcGioHandlerFactory(client))
+	view := dumpView{Hook: hook, BlockHash: blockHash, BlockNumber: blockNumber, Orders: []orderView{}}
+	for _, orderType := range sides {
+		ordersSlot, statusSlot, flagsSlot, err := slotsOf(orderType)
+		if err != nil {
+			return err
+		}
+
+		orders, err := storageService.FindOrdersBySlot(hook, blockHash, ordersSlot, statusSlot, flagsSlot)
+		if err != nil && !errors.Is(err, service.ErrNoOrdersFound) {
+			return err
+		}
+		for _, order := range orders {
+			view.Orders = append(view.Orders, orderView{
+				Side:          orderType,
+				Id:            order.Id,
+				Index:         order.Id - 1,
+				SqrtPriceX96:  order.SqrtPrice.Dec(),
+				Price:         domain.SqrtPriceX96ToPrice(order.SqrtPrice, decimals0, decimals1).Text('g', 18),
+				Amount:        order.Amount.Dec(),
+				MatchedAmount: order.MatchedAmount.Dec(),
+				Remaining:     order.Remaining().Dec(),
+				Status:        *order.Status,
+				Flags:         order.Flags.Names(),
+			})
+		}
+	}
+
+	rows := make([][]string, 0, len(view.Orders))
+	for _, o := range view.Orders {
+		rows = append(rows, []string{string(o.Side), strconv.FormatUint(o.Id, 10), strconv.FormatUint(o.Index, 10), o.SqrtPriceX96, o.Price, o.Amount, o.MatchedAmount, o.Remaining, string(o.Status), strings.Join(o.Flags, ",")})
+	}
+	return output.Render(format, view, output.Table{
+		Title:  fmt.Sprintf("Orders of hook %s at block %d (%s)", hook.Hex(), blockNumber, blockHash.Hex()),
+		Header: []string{"side", "id", "index", "sqrt_price_x96", "price", "amount", "matched", "remaining", "status", "flags"},
+		Rows:   rows,
+	})
+}
+
+// resolveBlock asks the node for the hash of the block, since the coprocessor
+// always reads at a block hash. The hash is taken as reported rather than
+// recomputed from the header, which depends on the fork rules of the client.
+func resolveBlock(ctx context.Context, client *ethclient.Client, block string) (common.Hash, uint64, error) {
+	var header struct {
+		Hash   common.Hash    `json:"hash"`
+		Number hexutil.Uint64 `json:"number"`
+	}
+
+	var err error
+	switch {
+	case len(block) == 2+2*common.HashLength && strings.HasPrefix(block, "0x"):
+		err = client.Client().CallContext(ctx, &header, "eth_getBlockByHash", common.HexToHash(block), false)
+	case block == "latest":
+		err = client.Client().CallContext(ctx, &header, "eth_getBlockByNumber", "latest", false)
+	default:
+		number, parseErr := strconv.ParseUint(block, 0, 64)
+		if parseErr != nil {
+			return common.Hash{}, 0, fmt.Errorf("invalid block %q, use a hash, a number or latest", block)
+		}
+		err = client.Client().CallContext(ctx, &header, "eth_getBlockByNumber", hexutil.EncodeUint64(number), false)
+	}
+	if err != nil {
+		return common.Hash{}, 0, err
+	}
+	if header.Hash == (common.Hash{}) {
+		return common.Hash{}, 0, fmt.Errorf("block %s not found", block)
+	}
+	return header.Hash, uint64(header.Number), nil
+}
@@ -0,0 +1,96 @@
+package configs
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/henriquemarlon/swapx/pkg/coprocessor"
+)
+
+var (
+	ErrHookNotAllowed        = errors.New("hook not allowed")
+	ErrTaskManagerNotAllowed = errors.New("task manager not allowed")
+	ErrChainIdNotAllowed     = errors.New("chain id not allowed")
+)
+
+// Allowlist restricts which hooks, task managers and chains the coprocessor
+// accepts tasks from. An empty set means that dimension is not restricted.
+type Allowlist struct {
+	Hooks        map[common.Address]struct{}
+	TaskManagers map[common.Address]struct{}
+	ChainIds     map[uint64]struct{}
+}
+
+func NewAllowlist(hooks, taskManagers, chainIds []string) (*Allowlist, error) {
+	allowlist := &Allowlist{
+		Hooks:        make(map[common.Address]struct{}),
+		TaskManagers: make(map[common.Address]struct{}),
+		ChainIds:     make(map[uint64]struct{}),
+	}
+
+	for _, hook := range hooks {
+		address, err := parseAddress(hook)
+		if err != nil {
+			return nil, fmt.Errorf("invalid hook address: %w", err)
+		}
+		allowlist.Hooks[address] = struct{}{}
+	}
+
+	for _, taskManager := range taskManagers {
+		address, err := parseAddress(taskManager)
+		if err != nil {
+			return nil, fmt.Errorf("invalid task manager address: %w", err)
+		}
+		allowlist.TaskManagers[address] = struct{}{}
+	}
+
+	for _, chainId := range chainIds {
+		id, err := strconv.ParseUint(strings.TrimSpace(chainId), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid chain id %q: %w", chainId, err)
+		}
+		allowlist.ChainIds[id] = struct{}{}
+	}
+
+	return allowlist, nil
+}
+
+func (a *Allowlist) IsUnrestricted() bool {
+	return len(a.Hooks) == 0 && len(a.TaskManagers) == 0 && len(a.ChainIds) == 0
+}
+
+// Check accepts the tasks of orders, which are created by the hook itself
+func (a *Allowlist) Check(metadata coprocessor.Metadata) error {
+	return a.CheckTask(metadata, metadata.MsgSender)
+}
+
+// CheckTask accepts a task about the book of hook, whoever created it
+func (a *Allowlist) CheckTask(metadata coprocessor.Metadata, hook common.Address) error {
+	if len(a.ChainIds) > 0 {
+		if _, ok := a.ChainIds[metadata.ChainId]; !ok {
+			return fmt.Errorf("%w: %d", ErrChainIdNotAllowed, metadata.ChainId)
+		}
+	}
+	if len(a.TaskManagers) > 0 {
+		if _, ok := a.TaskManagers[metadata.TaskManager]; !ok {
+			return fmt.Errorf("%w: %s", ErrTaskManagerNotAllowed, metadata.TaskManager.Hex())
+		}
+	}
+	if len(a.Hooks) > 0 {
+		if _, ok := a.Hooks[hook]; !ok {
+			return fmt.Errorf("%w: %s", ErrHookNotAllowed, hook.Hex())
+		}
+	}
+	return nil
+}
+
+func parseAddress(value string) (common.Address, error) {
+	value = strings.TrimSpace(value)
+	if !common.IsHexAddress(value) {
+		return common.Address{}, fmt.Errorf("%q is not a hex address", value)
+	}
+	return common.HexToAddress(value), nil
+}
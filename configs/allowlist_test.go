@@ -0,0 +1,58 @@
+package configs
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/henriquemarlon/swapx/pkg/coprocessor"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	testHook        = common.HexToAddress("0x1")
+	testTaskManager = common.HexToAddress("0x2")
+)
+
+func TestEmptyAllowlistAcceptsAnySender(t *testing.T) {
+	allowlist, err := NewAllowlist(nil, nil, nil)
+	assert.NoError(t, err)
+	assert.True(t, allowlist.IsUnrestricted())
+	assert.NoError(t, allowlist.Check(coprocessor.Metadata{ChainId: 1, MsgSender: common.HexToAddress("0x3")}))
+}
+
+func TestAllowlistAcceptsConfiguredSender(t *testing.T) {
+	allowlist, err := NewAllowlist([]string{testHook.Hex()}, []string{testTaskManager.Hex()}, []string{"31337"})
+	assert.NoError(t, err)
+	assert.NoError(t, allowlist.Check(coprocessor.Metadata{ChainId: 31337, TaskManager: testTaskManager, MsgSender: testHook}))
+}
+
+func TestAllowlistRejectsUnknownSender(t *testing.T) {
+	allowlist, err := NewAllowlist([]string{testHook.Hex()}, []string{testTaskManager.Hex()}, []string{"31337"})
+	assert.NoError(t, err)
+
+	err = allowlist.Check(coprocessor.Metadata{ChainId: 31337, TaskManager: testTaskManager, MsgSender: common.HexToAddress("0x3")})
+	assert.ErrorIs(t, err, ErrHookNotAllowed)
+
+	err = allowlist.Check(coprocessor.Metadata{ChainId: 31337, TaskManager: common.HexToAddress("0x3"), MsgSender: testHook})
+	assert.ErrorIs(t, err, ErrTaskManagerNotAllowed)
+
+	err = allowlist.Check(coprocessor.Metadata{ChainId: 1, TaskManager: testTaskManager, MsgSender: testHook})
+	assert.ErrorIs(t, err, ErrChainIdNotAllowed)
+}
+
+func TestAllowlistChecksTheHookOfATask(t *testing.T) {
+	allowlist, err := NewAllowlist([]string{testHook.Hex()}, []string{testTaskManager.Hex()}, nil)
+	assert.NoError(t, err)
+
+	keeper := coprocessor.Metadata{TaskManager: testTaskManager, MsgSender: common.HexToAddress("0x3")}
+	assert.NoError(t, allowlist.CheckTask(keeper, testHook))
+	assert.ErrorIs(t, allowlist.CheckTask(keeper, common.HexToAddress("0x4")), ErrHookNotAllowed)
+}
+
+func TestAllowlistRejectsMalformedEntries(t *testing.T) {
+	_, err := NewAllowlist([]string{"not-an-address"}, nil, nil)
+	assert.Error(t, err)
+
+	_, err = NewAllowlist(nil, nil, []string{"mainnet"})
+	assert.Error(t, err)
+}
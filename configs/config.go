@@ -0,0 +1,529 @@
+package configs
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"math/big"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/henriquemarlon/swapx/internal/domain"
+	"github.com/henriquemarlon/swapx/internal/usecase"
+	"github.com/holiman/uint256"
+	"github.com/spf13/pflag"
+)
+
+// DEFAULT_CONFIG_PATH is where the machine image ships its configuration, see the Dockerfile.
+const (
+	DEFAULT_CONFIG_PATH = "/etc/swapx/config.json"
+	CONFIG_PATH_ENV     = "SWAPX_CONFIG"
+	CONFIG_PATH_FLAG    = "config"
+)
+
+var (
+	ErrInvalidConfig     = errors.New("invalid config")
+	ErrAllowlistRequired = errors.New("allowlist required")
+)
+
+const (
+	LOG_FORMAT_TEXT = "text"
+	LOG_FORMAT_JSON = "json"
+)
+
+type Config struct {
+	RollupHttpServerUrl string           `json:"rollup_http_server_url"`
+	GioUrl              string           `json:"gio_url"`
+	Log                 LogConfig        `json:"log"`
+	StorageSlots        StorageSlots     `json:"storage_slots"`
+	Allowlist           AllowlistConfig  `json:"allowlist"`
+	Matching            MatchingConfig   `json:"matching"`
+	Cache               CacheConfig      `json:"cache"`
+	Snapshot            SnapshotConfig   `json:"snapshot"`
+	Compaction          CompactionConfig `json:"compaction"`
+	Settlement          SettlementConfig `json:"settlement"`
+	Lineage             LineageConfig    `json:"lineage"`
+	Guardrails          GuardrailsConfig `json:"guardrails"`
+	Reports             ReportsConfig    `json:"reports"`
+}
+
+type LogConfig struct {
+	Level  string `json:"level"`
+	Format string `json:"format"`
+}
+
+// StorageSlots are the slots of the SwapXHook state read through GIO, see storage-layout.
+type StorageSlots struct {
+	BuyOrders           uint64 `json:"buy_orders"`
+	BuyOrdersCancelled  uint64 `json:"buy_orders_cancelled"`
+	BuyOrderFlags       uint64 `json:"buy_order_flags"`
+	SellOrders          uint64 `json:"sell_orders"`
+	SellOrdersCancelled uint64 `json:"sell_orders_cancelled"`
+	SellOrderFlags      uint64 `json:"sell_order_flags"`
+	ConsumedFills       uint64 `json:"consumed_fills"`
+	PoolKey             uint64 `json:"pool_key"`
+}
+
+type AllowlistConfig struct {
+	Hooks        []string `json:"hooks"`
+	TaskManagers []string `json:"task_managers"`
+	ChainIds     []string `json:"chain_ids"`
+	// Unrestricted lets the coprocessor run without allowed hooks, accepting
+	// tasks about any hook. Only meant for local networks.
+	Unrestricted bool `json:"unrestricted"`
+}
+
+type MatchingConfig struct {
+	// DustThreshold is a decimal amount, remainders below it are returned to the
+	// order owner after a fill. Zero disables it.
+	DustThreshold string `json:"dust_threshold"`
+	// MaxTradesPerInput caps the trades a single input can produce. Zero means no cap.
+	MaxTradesPerInput int `json:"max_trades_per_input"`
+	// ConfirmationDepthBlocks is how many blocks a new order is queued before
+	// it is matched. Zero matches it at once.
+	ConfirmationDepthBlocks uint64 `json:"confirmation_depth_blocks"`
+}
+
+type CacheConfig struct {
+	// GioSize is the number of GIO responses kept in memory. Zero disables the cache.
+	GioSize int `json:"gio_size"`
+}
+
+type SnapshotConfig struct {
+	// ImportPath is a book snapshot loaded before the first input, empty starts from an empty book
+	ImportPath string `json:"import_path"`
+}
+
+type CompactionConfig struct {
+	// RetentionBlocks is how many blocks cancelled and fulfilled orders are kept
+	// before being pruned. Zero keeps them forever.
+	RetentionBlocks uint64 `json:"retention_blocks"`
+}
+
+type SettlementConfig struct {
+	// TimeoutBlocks is how many blocks after its input a sent fill can be
+	// executed in, its quantity is released back to the book once it expired.
+	// Zero never expires the fills and waits forever.
+	TimeoutBlocks uint64 `json:"timeout_blocks"`
+}
+
+type LineageConfig struct {
+	// ReorgDepthBlocks is how many blocks back a reorg is followed by rolling
+	// back the inputs read at the dropped blocks. Zero does not follow reorgs.
+	ReorgDepthBlocks uint64 `json:"reorg_depth_blocks"`
+}
+
+type GuardrailsConfig struct {
+	// PoolManager is the Uniswap v4 PoolManager the spot prices are read from
+	PoolManager string `json:"pool_manager"`
+	// PriceBands are the bands of the hooks that have one, the others match
+	// at any price
+	PriceBands []PriceBandConfig `json:"price_bands"`
+	// RouteToPool swaps through the pool the remainder of the orders flagged
+	// to route to it. Otherwise the flag is ignored and the remainder stays
+	// on the book.
+	RouteToPool bool `json:"route_to_pool"`
+}
+
+type PriceBandConfig struct {
+	Hook string `json:"hook"`
+	// BandBps is how far from the spot price, in basis points of the price, a
+	// match of the hook may execute
+	BandBps uint64 `json:"band_bps"`
+	// Mode is hold, which leaves the order out of the match on the book, or
+	// reject, which refunds it
+	Mode string `json:"mode"`
+}
+
+type ReportsConfig struct {
+	// RuntimeMemory adds the Go heap statistics to the state reports
+	RuntimeMemory bool `json:"runtime_memory"`
+}
+
+func DefaultConfig() *Config {
+	return &Config{
+		RollupHttpServerUrl: "http://127.0.0.1:5004",
+		Log: LogConfig{
+			Level:  "info",
+			Format: LOG_FORMAT_TEXT,
+		},
+		StorageSlots: StorageSlots{
+			BuyOrders:           8,
+			BuyOrdersCancelled:  6,
+			BuyOrderFlags:       11,
+			SellOrders:          9,
+			SellOrdersCancelled: 7,
+			SellOrderFlags:      12,
+			ConsumedFills:       10,
+		},
+		Allowlist: AllowlistConfig{
+			Hooks:        []string{},
+			TaskManagers: []string{},
+			ChainIds:     []string{},
+		},
+		Matching: MatchingConfig{
+			DustThreshold: "0",
+		},
+		Settlement: SettlementConfig{
+			TimeoutBlocks: usecase.DEFAULT_SETTLEMENT_TIMEOUT_BLOCKS,
+		},
+		Guardrails: GuardrailsConfig{
+			PriceBands: []PriceBandConfig{},
+		},
+	}
+}
+
+// Setting binds a config value to its environment variable and command line flag.
+type Setting struct {
+	Env   string
+	Flag  string
+	Usage string
+	Set   func(c *Config, value string) error
+}
+
+var Settings = []Setting{
+	{"ROLLUP_HTTP_SERVER_URL", "rollup-http-server-url", "Base url of the rollup HTTP server", setString(func(c *Config) *string { return &c.RollupHttpServerUrl })},
+	{"GIO_URL", "gio-url", "Base url of the GIO server, defaults to the rollup HTTP server", setString(func(c *Config) *string { return &c.GioUrl })},
+	{"LOG_LEVEL", "log-level", "Log level: debug, info, warn or error", setString(func(c *Config) *string { return &c.Log.Level })},
+	{"LOG_FORMAT", "log-format", "Log format: text or json", setString(func(c *Config) *string { return &c.Log.Format })},
+	{"BUY_ORDERS_SLOT", "buy-orders-slot", "Slot of the buyOrders array", setUint64(func(c *Config) *uint64 { return &c.StorageSlots.BuyOrders })},
+	{"BUY_ORDERS_CANCELLED_SLOT", "buy-orders-cancelled-slot", "Slot of the buyOrderCancelled mapping", setUint64(func(c *Config) *uint64 { return &c.StorageSlots.BuyOrdersCancelled })},
+	{"SELL_ORDERS_SLOT", "sell-orders-slot", "Slot of the sellOrders array", setUint64(func(c *Config) *uint64 { return &c.StorageSlots.SellOrders })},
+	{"SELL_ORDERS_CANCELLED_SLOT", "sell-orders-cancelled-slot", "Slot of the sellOrderCancelled mapping", setUint64(func(c *Config) *uint64 { return &c.StorageSlots.SellOrdersCancelled })},
+	{"BUY_ORDER_FLAGS_SLOT", "buy-order-flags-slot", "Slot of the buyOrderFlags mapping", setUint64(func(c *Config) *uint64 { return &c.StorageSlots.BuyOrderFlags })},
+	{"SELL_ORDER_FLAGS_SLOT", "sell-order-flags-slot", "Slot of the sellOrderFlags mapping", setUint64(func(c *Config) *uint64 { return &c.StorageSlots.SellOrderFlags })},
+	{"CONSUMED_FILLS_SLOT", "consumed-fills-slot", "Slot of the consumedFills mapping", setUint64(func(c *Config) *uint64 { return &c.StorageSlots.ConsumedFills })},
+	{"POOL_KEY_SLOT", "pool-key-slot", "First of the three slots of the poolKey", setUint64(func(c *Config) *uint64 { return &c.StorageSlots.PoolKey })},
+	{"ALLOWED_HOOKS", "allowed-hooks", "Comma separated hook addresses allowed to create tasks", setList(func(c *Config) *[]string { return &c.Allowlist.Hooks })},
+	{"ALLOWED_TASK_MANAGERS", "allowed-task-managers", "Comma separated task manager addresses allowed to issue tasks", setList(func(c *Config) *[]string { return &c.Allowlist.TaskManagers })},
+	{"ALLOWED_CHAIN_IDS", "allowed-chain-ids", "Comma separated chain ids allowed to issue tasks", setList(func(c *Config) *[]string { return &c.Allowlist.ChainIds })},
+	{"ALLOWLIST_UNRESTRICTED", "allowlist-unrestricted", "Accept tasks about any hook when no hook is allowed, true or false", setBool(func(c *Config) *bool { return &c.Allowlist.Unrestricted })},
+	{"DUST_THRESHOLD", "dust-threshold", "Remainders below this amount are returned to the order owner after a fill, 0 disables it", setString(func(c *Config) *string { return &c.Matching.DustThreshold })},
+	{"MAX_TRADES_PER_INPUT", "max-trades-per-input", "Maximum number of trades produced by a single input, 0 disables the cap", setInt(func(c *Config) *int { return &c.Matching.MaxTradesPerInput })},
+	{"CONFIRMATION_DEPTH_BLOCKS", "confirmation-depth-blocks", "Blocks a new order is queued before it is matched, 0 matches it at once", setUint64(func(c *Config) *uint64 { return &c.Matching.ConfirmationDepthBlocks })},
+	{"GIO_CACHE_SIZE", "gio-cache-size", "Number of GIO responses kept in memory, 0 disables the cache", setInt(func(c *Config) *int { return &c.Cache.GioSize })},
+	{"SNAPSHOT_IMPORT_PATH", "snapshot-import", "Book snapshot to load before the first input", setString(func(c *Config) *string { return &c.Snapshot.ImportPath })},
+	{"RETENTION_BLOCKS", "retention-blocks", "Blocks a cancelled or fulfilled order is kept before being pruned, 0 keeps them forever", setUint64(func(c *Config) *uint64 { return &c.Compaction.RetentionBlocks })},
+	{"SETTLEMENT_TIMEOUT_BLOCKS", "settlement-timeout-blocks", "Blocks after its input a fill or pool swap can be executed in before it expires and its quantity is released, 0 never expires them", setUint64(func(c *Config) *uint64 { return &c.Settlement.TimeoutBlocks })},
+	{"REORG_DEPTH_BLOCKS", "reorg-depth-blocks", "Blocks back a reorg is followed by rolling back the inputs read at the dropped blocks, 0 does not follow reorgs", setUint64(func(c *Config) *uint64 { return &c.Lineage.ReorgDepthBlocks })},
+	{"POOL_MANAGER", "pool-manager", "Uniswap v4 PoolManager the spot prices of the price bands are read from", setString(func(c *Config) *string { return &c.Guardrails.PoolManager })},
+	{"PRICE_BANDS", "price-bands", "Comma separated price bands as hook:band_bps:mode, mode being hold or reject", setPriceBands},
+	{"ROUTE_TO_POOL", "route-to-pool", "Swap through the pool the remainder of the orders flagged to route to it, true or false", setBool(func(c *Config) *bool { return &c.Guardrails.RouteToPool })},
+	{"REPORT_RUNTIME_MEMORY", "report-runtime-memory", "Add the Go heap statistics to the state reports, true or false", setBool(func(c *Config) *bool { return &c.Reports.RuntimeMemory })},
+}
+
+// AddConfigFlags registers the config file flag and one string flag per
+// setting, so that only the flags set on the command line override the file
+// and the environment.
+func AddConfigFlags(flags *pflag.FlagSet) {
+	flags.String(CONFIG_PATH_FLAG, "", fmt.Sprintf("JSON config file, defaults to %s when it exists (env: %s)", DEFAULT_CONFIG_PATH, CONFIG_PATH_ENV))
+	for _, setting := range Settings {
+		flags.String(setting.Flag, "", fmt.Sprintf("%s (env: %s)", setting.Usage, setting.Env))
+	}
+}
+
+// ChangedConfigFlags collects the settings given on the command line.
+func ChangedConfigFlags(flags *pflag.FlagSet) map[string]string {
+	overrides := make(map[string]string)
+	for _, setting := range Settings {
+		if flag := flags.Lookup(setting.Flag); flag != nil && flag.Changed {
+			overrides[setting.Flag] = flag.Value.String()
+		}
+	}
+	return overrides
+}
+
+// LoadConfigFromFlags loads the config of a command that called AddConfigFlags.
+func LoadConfigFromFlags(flags *pflag.FlagSet) (*Config, error) {
+	path, required := DEFAULT_CONFIG_PATH, false
+	if value, ok := os.LookupEnv(CONFIG_PATH_ENV); ok && value != "" {
+		path, required = value, true
+	}
+	if flag := flags.Lookup(CONFIG_PATH_FLAG); flag != nil && flag.Changed {
+		path, required = flag.Value.String(), true
+	}
+	return LoadConfig(path, required, os.LookupEnv, ChangedConfigFlags(flags))
+}
+
+// LoadConfig layers the defaults, the config file, the environment and the
+// flag overrides, in this order, and validates the result. A missing file is
+// only an error when the path was asked for explicitly.
+func LoadConfig(path string, required bool, lookupEnv func(string) (string, bool), overrides map[string]string) (*Config, error) {
+	config := DefaultConfig()
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		switch {
+		case err == nil:
+			decoder := json.NewDecoder(strings.NewReader(string(data)))
+			decoder.DisallowUnknownFields()
+			if err := decoder.Decode(config); err != nil {
+				return nil, fmt.Errorf("%w: %s: %v", ErrInvalidConfig, path, err)
+			}
+		case errors.Is(err, fs.ErrNotExist) && !required:
+		default:
+			return nil, err
+		}
+	}
+
+	for _, setting := range Settings {
+		if value, ok := lookupEnv(setting.Env); ok && value != "" {
+			if err := setting.Set(config, value); err != nil {
+				return nil, fmt.Errorf("%w: %s: %v", ErrInvalidConfig, setting.Env, err)
+			}
+		}
+	}
+
+	for _, setting := range Settings {
+		if value, ok := overrides[setting.Flag]; ok {
+			if err := setting.Set(config, value); err != nil {
+				return nil, fmt.Errorf("%w: --%s: %v", ErrInvalidConfig, setting.Flag, err)
+			}
+		}
+	}
+
+	if config.GioUrl == "" {
+		config.GioUrl = config.RollupHttpServerUrl
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+func (c *Config) Validate() error {
+	for name, value := range map[string]string{"rollup_http_server_url": c.RollupHttpServerUrl, "gio_url": c.GioUrl} {
+		parsed, err := url.Parse(value)
+		if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+			return fmt.Errorf("%w: %s must be an absolute url, got %q", ErrInvalidConfig, name, value)
+		}
+	}
+
+	if _, err := c.LogLevel(); err != nil {
+		return err
+	}
+	if c.Log.Format != LOG_FORMAT_TEXT && c.Log.Format != LOG_FORMAT_JSON {
+		return fmt.Errorf("%w: log format must be text or json, got %q", ErrInvalidConfig, c.Log.Format)
+	}
+
+	slots := map[uint64]struct{}{}
+	for _, slot := range []uint64{c.StorageSlots.BuyOrders, c.StorageSlots.BuyOrdersCancelled, c.StorageSlots.SellOrders, c.StorageSlots.SellOrdersCancelled, c.StorageSlots.BuyOrderFlags, c.StorageSlots.SellOrderFlags, c.StorageSlots.ConsumedFills, c.StorageSlots.PoolKey} {
+		if _, ok := slots[slot]; ok {
+			return fmt.Errorf("%w: storage slots must be distinct, %d is used twice", ErrInvalidConfig, slot)
+		}
+		slots[slot] = struct{}{}
+	}
+
+	if _, err := NewAllowlist(c.Allowlist.Hooks, c.Allowlist.TaskManagers, c.Allowlist.ChainIds); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+	}
+	if c.Allowlist.Unrestricted && (len(c.Allowlist.Hooks) > 0 || len(c.Allowlist.TaskManagers) > 0 || len(c.Allowlist.ChainIds) > 0) {
+		return fmt.Errorf("%w: an unrestricted allowlist cannot list hooks, task managers or chain ids", ErrInvalidConfig)
+	}
+
+	if _, err := c.DustThreshold(); err != nil {
+		return err
+	}
+	if c.Matching.MaxTradesPerInput < 0 {
+		return fmt.Errorf("%w: max trades per input must not be negative", ErrInvalidConfig)
+	}
+	if c.Cache.GioSize < 0 {
+		return fmt.Errorf("%w: gio cache size must not be negative", ErrInvalidConfig)
+	}
+	// an order pruned while one of its fills is pending could not get the fill back
+	if c.Compaction.RetentionBlocks != 0 && (c.Settlement.TimeoutBlocks == 0 || c.Compaction.RetentionBlocks < c.Settlement.TimeoutBlocks) {
+		return fmt.Errorf("%w: retention blocks must be at least the settlement timeout, got %d and %d", ErrInvalidConfig, c.Compaction.RetentionBlocks, c.Settlement.TimeoutBlocks)
+	}
+	if _, _, err := c.PriceBands(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// PriceBands returns the PoolManager and the bands by hook, the PoolManager is
+// only required when there is a band or orders are routed to the pool
+func (c *Config) PriceBands() (common.Address, map[common.Address]*domain.PriceBand, error) {
+	bands := make(map[common.Address]*domain.PriceBand, len(c.Guardrails.PriceBands))
+	for _, band := range c.Guardrails.PriceBands {
+		hook, err := parseAddress(band.Hook)
+		if err != nil {
+			return common.Address{}, nil, fmt.Errorf("%w: price band: %v", ErrInvalidConfig, err)
+		}
+		if _, ok := bands[hook]; ok {
+			return common.Address{}, nil, fmt.Errorf("%w: price band: hook %s has two bands", ErrInvalidConfig, hook.Hex())
+		}
+		if band.BandBps == 0 {
+			return common.Address{}, nil, fmt.Errorf("%w: price band: the band of %s must not be zero", ErrInvalidConfig, hook.Hex())
+		}
+		mode := domain.BandMode(band.Mode)
+		if mode != domain.BandModeHold && mode != domain.BandModeReject {
+			return common.Address{}, nil, fmt.Errorf("%w: price band: mode must be hold or reject, got %q", ErrInvalidConfig, band.Mode)
+		}
+		bands[hook] = &domain.PriceBand{BandBps: band.BandBps, Mode: mode}
+	}
+	if len(bands) == 0 && !c.Guardrails.RouteToPool {
+		return common.Address{}, nil, nil
+	}
+
+	poolManager, err := parseAddress(c.Guardrails.PoolManager)
+	if err != nil {
+		return common.Address{}, nil, fmt.Errorf("%w: pool manager: %v", ErrInvalidConfig, err)
+	}
+	return poolManager, bands, nil
+}
+
+func (c *Config) DustThreshold() (*uint256.Int, error) {
+	dust, err := uint256.FromDecimal(c.Matching.DustThreshold)
+	if err != nil {
+		return nil, fmt.Errorf("%w: dust threshold: %v", ErrInvalidConfig, err)
+	}
+	return dust, nil
+}
+
+func (c *Config) LogLevel() (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(c.Log.Level)); err != nil {
+		return level, fmt.Errorf("%w: log level: %v", ErrInvalidConfig, err)
+	}
+	return level, nil
+}
+
+func (c *Config) MatchingPolicy() (*usecase.MatchingPolicy, error) {
+	dust, err := c.DustThreshold()
+	if err != nil {
+		return nil, err
+	}
+	poolManager, bands, err := c.PriceBands()
+	if err != nil {
+		return nil, err
+	}
+	return &usecase.MatchingPolicy{
+		Slots: usecase.StorageSlots{
+			BuyOrders:        common.BigToHash(new(big.Int).SetUint64(c.StorageSlots.BuyOrders)),
+			BuyOrdersStatus:  common.BigToHash(new(big.Int).SetUint64(c.StorageSlots.BuyOrdersCancelled)),
+			BuyOrderFlags:    common.BigToHash(new(big.Int).SetUint64(c.StorageSlots.BuyOrderFlags)),
+			SellOrders:       common.BigToHash(new(big.Int).SetUint64(c.StorageSlots.SellOrders)),
+			SellOrdersStatus: common.BigToHash(new(big.Int).SetUint64(c.StorageSlots.SellOrdersCancelled)),
+			SellOrderFlags:   common.BigToHash(new(big.Int).SetUint64(c.StorageSlots.SellOrderFlags)),
+			ConsumedFills:    common.BigToHash(new(big.Int).SetUint64(c.StorageSlots.ConsumedFills)),
+			PoolKey:          common.BigToHash(new(big.Int).SetUint64(c.StorageSlots.PoolKey)),
+		},
+		DustThreshold:           dust,
+		MaxTradesPerInput:       c.Matching.MaxTradesPerInput,
+		ConfirmationDepthBlocks: c.Matching.ConfirmationDepthBlocks,
+		RetentionBlocks:         c.Compaction.RetentionBlocks,
+		SettlementTimeoutBlocks: c.Settlement.TimeoutBlocks,
+		ReorgDepthBlocks:        c.Lineage.ReorgDepthBlocks,
+		PoolManager:             poolManager,
+		PriceBands:              bands,
+		RouteToPool:             c.Guardrails.RouteToPool,
+		ReportRuntimeMemory:     c.Reports.RuntimeMemory,
+	}, nil
+}
+
+// RequireAllowlist refuses to let the coprocessor accept tasks about any hook
+// unless the allowlist is explicitly unrestricted. Replay and the devserver
+// run recorded or scripted inputs and do not require it.
+func (c *Config) RequireAllowlist() error {
+	if len(c.Allowlist.Hooks) == 0 && !c.Allowlist.Unrestricted {
+		return fmt.Errorf("%w: set allowlist.hooks (env: ALLOWED_HOOKS), or allowlist.unrestricted (env: ALLOWLIST_UNRESTRICTED) to accept any hook", ErrAllowlistRequired)
+	}
+	return nil
+}
+
+func (c *Config) NewAllowlist() (*Allowlist, error) {
+	return NewAllowlist(c.Allowlist.Hooks, c.Allowlist.TaskManagers, c.Allowlist.ChainIds)
+}
+
+// Hash is the sha256 of the canonical JSON encoding of the config. Two machines
+// logging the same hash run with identical settings.
+func (c *Config) Hash() (string, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return "0x" + hex.EncodeToString(sum[:]), nil
+}
+
+func setString(field func(*Config) *string) func(*Config, string) error {
+	return func(c *Config, value string) error {
+		*field(c) = value
+		return nil
+	}
+}
+
+func setUint64(field func(*Config) *uint64) func(*Config, string) error {
+	return func(c *Config, value string) error {
+		parsed, err := strconv.ParseUint(value, 0, 64)
+		if err != nil {
+			return err
+		}
+		*field(c) = parsed
+		return nil
+	}
+}
+
+func setInt(field func(*Config) *int) func(*Config, string) error {
+	return func(c *Config, value string) error {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		*field(c) = parsed
+		return nil
+	}
+}
+
+func setBool(field func(*Config) *bool) func(*Config, string) error {
+	return func(c *Config, value string) error {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		*field(c) = parsed
+		return nil
+	}
+}
+
+// setPriceBands parses hook:band_bps:mode entries
+func setPriceBands(c *Config, value string) error {
+	bands := []PriceBandConfig{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		fields := strings.Split(item, ":")
+		if len(fields) != 3 {
+			return fmt.Errorf("%q is not hook:band_bps:mode", item)
+		}
+		bps, err := strconv.ParseUint(fields[1], 0, 64)
+		if err != nil {
+			return err
+		}
+		bands = append(bands, PriceBandConfig{Hook: fields[0], BandBps: bps, Mode: fields[2]})
+	}
+	c.Guardrails.PriceBands = bands
+	return nil
+}
+
+func setList(field func(*Config) *[]string) func(*Config, string) error {
+	return func(c *Config, value string) error {
+		list := []string{}
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		*field(c) = list
+		return nil
+	}
+}
@@ -0,0 +1,175 @@
+package configs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/henriquemarlon/swapx/internal/domain"
+	"github.com/henriquemarlon/swapx/internal/usecase"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func envOf(values map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		value, ok := values[key]
+		return value, ok
+	}
+}
+
+func writeConfig(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func TestDefaultsMatchTheHookLayout(t *testing.T) {
+	config, err := LoadConfig("", false, envOf(nil), nil)
+	require.NoError(t, err)
+
+	policy, err := config.MatchingPolicy()
+	require.NoError(t, err)
+	assert.Equal(t, usecase.DefaultMatchingPolicy(), policy)
+	assert.Equal(t, config.RollupHttpServerUrl, config.GioUrl)
+}
+
+func TestFileThenEnvThenFlags(t *testing.T) {
+	path := writeConfig(t, `{
+		"rollup_http_server_url": "http://file:5004",
+		"log": {"level": "warn"},
+		"allowlist": {"hooks": ["0x00000000000000000000000000000000000000aa"]},
+		"matching": {"dust_threshold": "10", "max_trades_per_input": 4}
+	}`)
+
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	AddConfigFlags(flags)
+	require.NoError(t, flags.Parse([]string{"--dust-threshold", "30"}))
+
+	config, err := LoadConfig(path, true, envOf(map[string]string{
+		"DUST_THRESHOLD": "20",
+		"LOG_LEVEL":      "debug",
+	}), ChangedConfigFlags(flags))
+	require.NoError(t, err)
+
+	assert.Equal(t, "http://file:5004", config.RollupHttpServerUrl)
+	assert.Equal(t, "http://file:5004", config.GioUrl)
+	assert.Equal(t, "debug", config.Log.Level)
+	assert.Equal(t, "30", config.Matching.DustThreshold)
+	assert.Equal(t, 4, config.Matching.MaxTradesPerInput)
+	assert.Equal(t, []string{"0x00000000000000000000000000000000000000aa"}, config.Allowlist.Hooks)
+}
+
+func TestEnvOverridesStorageSlots(t *testing.T) {
+	config, err := LoadConfig("", false, envOf(map[string]string{"BUY_ORDERS_SLOT": "0x10", "SELL_ORDER_FLAGS_SLOT": "0x11"}), nil)
+	require.NoError(t, err)
+
+	policy, err := config.MatchingPolicy()
+	require.NoError(t, err)
+	assert.Equal(t, common.HexToHash("0x10"), policy.Slots.BuyOrders)
+	assert.Equal(t, common.HexToHash("0x11"), policy.Slots.SellOrderFlags)
+	assert.Equal(t, usecase.DefaultStorageSlots.SellOrders, policy.Slots.SellOrders)
+	assert.Equal(t, usecase.DefaultStorageSlots.BuyOrderFlags, policy.Slots.BuyOrderFlags)
+}
+
+func TestEnvSetsPriceBands(t *testing.T) {
+	config, err := LoadConfig("", false, envOf(map[string]string{
+		"POOL_MANAGER": "0x00000000000000000000000000000000000000bb",
+		"PRICE_BANDS":  "0x00000000000000000000000000000000000000aa:500:hold, 0x00000000000000000000000000000000000000cc:100:reject",
+	}), nil)
+	require.NoError(t, err)
+
+	policy, err := config.MatchingPolicy()
+	require.NoError(t, err)
+	assert.Equal(t, common.HexToAddress("0xbb"), policy.PoolManager)
+	assert.Equal(t, map[common.Address]*domain.PriceBand{
+		common.HexToAddress("0xaa"): {BandBps: 500, Mode: domain.BandModeHold},
+		common.HexToAddress("0xcc"): {BandBps: 100, Mode: domain.BandModeReject},
+	}, policy.PriceBands)
+	assert.False(t, policy.RouteToPool)
+}
+
+func TestEnvRoutesToThePool(t *testing.T) {
+	config, err := LoadConfig("", false, envOf(map[string]string{
+		"POOL_MANAGER":  "0x00000000000000000000000000000000000000bb",
+		"ROUTE_TO_POOL": "true",
+	}), nil)
+	require.NoError(t, err)
+
+	policy, err := config.MatchingPolicy()
+	require.NoError(t, err)
+	assert.Equal(t, common.HexToAddress("0xbb"), policy.PoolManager)
+	assert.True(t, policy.RouteToPool)
+	assert.Empty(t, policy.PriceBands)
+}
+
+func TestCoprocessorRequiresAllowedHooks(t *testing.T) {
+	tests := map[string]struct {
+		env map[string]string
+		err error
+	}{
+		"empty":        {nil, ErrAllowlistRequired},
+		"chain only":   {map[string]string{"ALLOWED_CHAIN_IDS": "31337"}, ErrAllowlistRequired},
+		"hooks":        {map[string]string{"ALLOWED_HOOKS": "0x00000000000000000000000000000000000000aa"}, nil},
+		"unrestricted": {map[string]string{"ALLOWLIST_UNRESTRICTED": "true"}, nil},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			config, err := LoadConfig("", false, envOf(test.env), nil)
+			require.NoError(t, err)
+			assert.ErrorIs(t, config.RequireAllowlist(), test.err)
+		})
+	}
+}
+
+func TestMissingFile(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing.json")
+
+	_, err := LoadConfig(missing, false, envOf(nil), nil)
+	assert.NoError(t, err)
+
+	_, err = LoadConfig(missing, true, envOf(nil), nil)
+	assert.Error(t, err)
+}
+
+func TestInvalidConfigIsRejected(t *testing.T) {
+	tests := map[string]string{
+		"unknown field":      `{"dust": "1"}`,
+		"relative url":       `{"gio_url": "localhost:5004"}`,
+		"log level":          `{"log": {"level": "loud"}}`,
+		"log format":         `{"log": {"format": "xml"}}`,
+		"shared slot":        `{"storage_slots": {"buy_orders": 9}}`,
+		"shared flags slot":  `{"storage_slots": {"sell_order_flags": 10}}`,
+		"bad hook":           `{"allowlist": {"hooks": ["0x1234"]}}`,
+		"unrestricted hooks": `{"allowlist": {"hooks": ["0x00000000000000000000000000000000000000aa"], "unrestricted": true}}`,
+		"bad dust":           `{"matching": {"dust_threshold": "-1"}}`,
+		"negative trades":    `{"matching": {"max_trades_per_input": -1}}`,
+		"negative gio size":  `{"cache": {"gio_size": -1}}`,
+		"short retention":    `{"compaction": {"retention_blocks": 10}}`,
+		"band without pool":  `{"guardrails": {"price_bands": [{"hook": "0x00000000000000000000000000000000000000aa", "band_bps": 500, "mode": "hold"}]}}`,
+		"route without pool": `{"guardrails": {"route_to_pool": true}}`,
+		"band mode":          `{"guardrails": {"pool_manager": "0x00000000000000000000000000000000000000bb", "price_bands": [{"hook": "0x00000000000000000000000000000000000000aa", "band_bps": 500, "mode": "skip"}]}}`,
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := LoadConfig(writeConfig(t, content), true, envOf(nil), nil)
+			assert.ErrorIs(t, err, ErrInvalidConfig)
+		})
+	}
+}
+
+func TestHashFollowsTheSettings(t *testing.T) {
+	first, err := DefaultConfig().Hash()
+	require.NoError(t, err)
+	second, err := DefaultConfig().Hash()
+	require.NoError(t, err)
+	assert.Equal(t, first, second)
+
+	changed := DefaultConfig()
+	changed.Matching.MaxTradesPerInput = 1
+	third, err := changed.Hash()
+	require.NoError(t, err)
+	assert.NotEqual(t, first, third)
+}
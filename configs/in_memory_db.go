@@ -3,19 +3,37 @@ package configs
 import (
 	"sync"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/henriquemarlon/swapx/internal/domain"
 )
 
 type InMemoryDB struct {
-	BuyOrders  map[uint64]*domain.Order
-	SellOrders map[uint64]*domain.Order
-	Mutex      *sync.RWMutex
+	BuyOrders      map[domain.OrderKey]*domain.Order
+	SellOrders     map[domain.OrderKey]*domain.Order
+	BuyTombstones  map[common.Address]*domain.TombstoneSet
+	SellTombstones map[common.Address]*domain.TombstoneSet
+	Cursor         *domain.SyncCursor
+	Fills          *[]*domain.Fill
+	Candles        map[common.Address]*domain.Candles
+	PendingFills   *[]*domain.PendingFill
+	// PendingPoolSwaps are replaced, never changed in place
+	PendingPoolSwaps *[]*domain.PendingPoolSwap
+	Checkpoints      *[]*domain.Checkpoint
+	Mutex            *sync.RWMutex
 }
 
 func SetupInMemoryDB() (*InMemoryDB, error) {
 	return &InMemoryDB{
-		BuyOrders:  make(map[uint64]*domain.Order),
-		SellOrders: make(map[uint64]*domain.Order),
-		Mutex:      &sync.RWMutex{},
+		BuyOrders:        make(map[domain.OrderKey]*domain.Order),
+		SellOrders:       make(map[domain.OrderKey]*domain.Order),
+		BuyTombstones:    make(map[common.Address]*domain.TombstoneSet),
+		SellTombstones:   make(map[common.Address]*domain.TombstoneSet),
+		Cursor:           &domain.SyncCursor{},
+		Fills:            &[]*domain.Fill{},
+		Candles:          make(map[common.Address]*domain.Candles),
+		PendingFills:     &[]*domain.PendingFill{},
+		PendingPoolSwaps: &[]*domain.PendingPoolSwap{},
+		Checkpoints:      &[]*domain.Checkpoint{},
+		Mutex:            &sync.RWMutex{},
 	}, nil
 }
@@ -3,8 +3,11 @@ package configs
 import (
 	"context"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
+	"strings"
+	"sync"
 )
 
 const ansiGreen = "\033[32m"
@@ -12,11 +15,16 @@ const ansiGray = "\033[90m"
 const ansiReset = "\033[0m"
 
 type CustomTextHandler struct {
-	level slog.Leveler
+	level  slog.Leveler
+	writer io.Writer
+	mutex  *sync.Mutex
+	// attrs are already qualified with the groups that were open when they were added
+	attrs  []slog.Attr
+	groups []string
 }
 
-func NewCustomTextHandler(level slog.Leveler) slog.Handler {
-	return &CustomTextHandler{level: level}
+func NewCustomTextHandler(writer io.Writer, level slog.Leveler) slog.Handler {
+	return &CustomTextHandler{level: level, writer: writer, mutex: &sync.Mutex{}}
 }
 
 func (h *CustomTextHandler) Enabled(_ context.Context, lvl slog.Level) bool {
@@ -32,21 +40,69 @@ func (h *CustomTextHandler) Handle(_ context.Context, r slog.Record) error {
 		r.Message,
 	)
 
+	for _, a := range h.attrs {
+		msg += formatAttr("", a)
+	}
+	prefix := groupPrefix(h.groups)
 	r.Attrs(func(a slog.Attr) bool {
-		msg += fmt.Sprintf(" %s=%v", a.Key, a.Value)
+		msg += formatAttr(prefix, a)
 		return true
 	})
 
-	fmt.Fprintln(os.Stdout, msg)
-	return nil
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	_, err := fmt.Fprintln(h.writer, msg)
+	return err
+}
+
+func (h *CustomTextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	clone := *h
+	clone.attrs = make([]slog.Attr, 0, len(h.attrs)+len(attrs))
+	clone.attrs = append(clone.attrs, h.attrs...)
+	prefix := groupPrefix(h.groups)
+	for _, a := range attrs {
+		a.Key = prefix + a.Key
+		clone.attrs = append(clone.attrs, a)
+	}
+	return &clone
 }
 
-func (h *CustomTextHandler) WithAttrs(_ []slog.Attr) slog.Handler {
-	return h
+func (h *CustomTextHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	clone := *h
+	clone.groups = append(append([]string{}, h.groups...), name)
+	return &clone
 }
 
-func (h *CustomTextHandler) WithGroup(_ string) slog.Handler {
-	return h
+// formatAttr flattens groups into dotted keys, the same way the JSON handler nests them
+func formatAttr(prefix string, a slog.Attr) string {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return ""
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		var out string
+		for _, member := range a.Value.Group() {
+			out += formatAttr(prefix, member)
+		}
+		return out
+	}
+	return fmt.Sprintf(" %s%s=%v", prefix, a.Key, a.Value)
+}
+
+func groupPrefix(groups []string) string {
+	if len(groups) == 0 {
+		return ""
+	}
+	return strings.Join(groups, ".") + "."
 }
 
 func (h *CustomTextHandler) formatLevel(lvl slog.Level) string {
@@ -65,7 +121,29 @@ func (h *CustomTextHandler) formatLevel(lvl slog.Level) string {
 }
 
 func ConfigureLogger(level slog.Leveler) {
-	handler := NewCustomTextHandler(level)
-	logger := slog.New(handler)
-	slog.SetDefault(logger)
+	ConfigureLoggerWithFormat(level, LOG_FORMAT_TEXT)
+}
+
+// ConfigureLoggerWithFormat switches to one JSON object per line for the json
+// format, which log collectors can parse without the terminal colors.
+func ConfigureLoggerWithFormat(level slog.Leveler, format string) {
+	slog.SetDefault(slog.New(NewLogHandler(os.Stdout, level, format)))
+}
+
+func NewLogHandler(writer io.Writer, level slog.Leveler, format string) slog.Handler {
+	if format == LOG_FORMAT_JSON {
+		return slog.NewJSONHandler(writer, &slog.HandlerOptions{Level: level})
+	}
+	return NewCustomTextHandler(writer, level)
+}
+
+// ScopeLogger makes the default logger carry args until the returned function
+// is called. Inputs are handled one at a time, so every line logged while one is
+// processed, from any package, can be correlated with it.
+func ScopeLogger(args ...any) (restore func()) {
+	previous := slog.Default()
+	slog.SetDefault(previous.With(args...))
+	return func() {
+		slog.SetDefault(previous)
+	}
 }
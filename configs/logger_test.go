@@ -0,0 +1,51 @@
+package configs
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTextHandlerKeepsAttrsAndGroups(t *testing.T) {
+	var buffer bytes.Buffer
+	logger := slog.New(NewLogHandler(&buffer, slog.LevelInfo, LOG_FORMAT_TEXT))
+
+	logger.With("block_number", 7).WithGroup("gio").With("domain", 39).Info("Request", "slot", 8, slog.Group("order", "id", 1))
+	logger.Debug("Hidden")
+
+	assert.Contains(t, buffer.String(), "Request block_number=7 gio.domain=39 gio.slot=8 gio.order.id=1\n")
+	assert.NotContains(t, buffer.String(), "Hidden")
+}
+
+func TestJsonHandlerNestsGroups(t *testing.T) {
+	var buffer bytes.Buffer
+	logger := slog.New(NewLogHandler(&buffer, slog.LevelDebug, LOG_FORMAT_JSON))
+
+	logger.With("msg_sender", "0xaa").WithGroup("gio").Debug("Request", "slot", 8)
+
+	var line map[string]any
+	require.NoError(t, json.Unmarshal(buffer.Bytes(), &line))
+	assert.Equal(t, "DEBUG", line["level"])
+	assert.Equal(t, "0xaa", line["msg_sender"])
+	assert.Equal(t, map[string]any{"slot": float64(8)}, line["gio"])
+}
+
+func TestScopeLoggerRestoresTheDefault(t *testing.T) {
+	previous := slog.Default()
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	var buffer bytes.Buffer
+	slog.SetDefault(slog.New(NewLogHandler(&buffer, slog.LevelInfo, LOG_FORMAT_TEXT)))
+
+	restore := ScopeLogger("task_index", 3)
+	slog.Info("Inside")
+	restore()
+	slog.Info("Outside")
+
+	assert.Contains(t, buffer.String(), "Inside task_index=3\n")
+	assert.Contains(t, buffer.String(), "Outside\n")
+}
@@ -10,13 +10,18 @@ import {PoolKey} from "v4-core/src/types/PoolKey.sol";
 import {BeforeSwapDelta, BeforeSwapDeltaLibrary, toBeforeSwapDelta} from "v4-core/src/types/BeforeSwapDelta.sol";
 import {ISwapXHook, ISwapXTaskManager} from "./interface/ISwapXHook.sol";
 import {Hooks} from "v4-core/src/libraries/Hooks.sol";
+import {IUnlockCallback} from "v4-core/src/interfaces/callback/IUnlockCallback.sol";
+import {BalanceDelta} from "v4-core/src/types/BalanceDelta.sol";
 
-contract SwapXHook is ISwapXHook, BaseAsyncSwap {
+contract SwapXHook is ISwapXHook, IUnlockCallback, BaseAsyncSwap {
     using SafeCast for uint256;
     using CurrencySettler for Currency;
 
     bytes constant ZERO_BYTES = new bytes(0);
 
+    uint256 public constant FLAG_IMMEDIATE_OR_CANCEL = 1 << 0;
+    uint256 public constant FLAG_ROUTE_TO_POOL = 1 << 1;
+
     PoolKey public poolKey;
     Currency public currency0;
     Currency public currency1;
@@ -35,6 +40,11 @@ contract SwapXHook is ISwapXHook, BaseAsyncSwap {
     Order[] public buyOrders;
     Order[] public sellOrders;
 
+    mapping(bytes32 => bool) public consumedFills;
+
+    mapping(uint256 => uint256) public buyOrderFlags;
+    mapping(uint256 => uint256) public sellOrderFlags;
+
     event OrderCreated(uint256 indexed orderId, address indexed account, uint256 sqrtPrice, uint256 amount, bool isBuy);
     event OrderFulfilled(
         uint256 indexed orderId, address indexed account, uint256 sqrtPrice, uint256 amount, bool isBuy
@@ -45,18 +55,30 @@ contract SwapXHook is ISwapXHook, BaseAsyncSwap {
     event OrderPartiallyFulfilled(
         uint256 indexed orderId, address indexed account, uint256 sqrtPrice, uint256 amount, bool isBuy
     );
+    event OrderRefunded(uint256 indexed orderId, address indexed account, uint256 amount, bool isBuy);
+    event OrderDustReturned(uint256 indexed orderId, address indexed account, uint256 amount, bool isBuy);
+    event OrderRoutedToPool(
+        uint256 indexed orderId, address indexed account, uint256 amountIn, uint256 amountOut, bool isBuy
+    );
 
     error OrderWasCancelled();
     error OrderDoesNotExist();
     error OrderAlreadyFulfilled();
     error OnlyOrderCreatorCanCancel();
     error OrderSqrtPricesDoNotMatch();
+    error InvalidFillQuantity();
+    error FillAlreadyConsumed(bytes32 fillId);
+    error FillExpired(bytes32 fillId);
+    error SwapExpired();
+    error OnlyTaskManager();
+    error OnlyPoolManager();
 
     constructor(IPoolManager _poolManager, ISwapXTaskManager _swapXTaskManager) BaseAsyncSwap(_poolManager) {
         swapXTaskManager = _swapXTaskManager;
     }
 
     function _beforeInitialize(address, PoolKey calldata key, uint160) internal virtual override returns (bytes4) {
+        poolKey = key;
         currency0 = key.currency0;
         currency1 = key.currency1;
         return this.beforeInitialize.selector;
@@ -74,7 +96,7 @@ contract SwapXHook is ISwapXHook, BaseAsyncSwap {
 
             specified.take(poolManager, address(this), specifiedAmount, false);
 
-            (uint256 sqrtPrice, address sender) = abi.decode(hookData, (uint256, address));
+            (uint256 sqrtPrice, address sender, uint256 flags) = _decodeHookData(hookData);
 
             Order memory order = Order(sender, sqrtPrice, specifiedAmount, 0);
 
@@ -86,7 +108,17 @@ contract SwapXHook is ISwapXHook, BaseAsyncSwap {
 
             uint256 orderId = params.zeroForOne ? buyOrders.length : sellOrders.length;
 
-            swapXTaskManager.createTask(abi.encode(orderId, sqrtPrice, specifiedAmount, params.zeroForOne ? 0 : 1));
+            if (flags != 0) {
+                if (params.zeroForOne) {
+                    buyOrderFlags[orderId - 1] = flags;
+                } else {
+                    sellOrderFlags[orderId - 1] = flags;
+                }
+            }
+
+            swapXTaskManager.createTask(
+                abi.encode(orderId, sqrtPrice, specifiedAmount, params.zeroForOne ? 0 : 1, flags)
+            );
             emit OrderCreated(orderId, sender, sqrtPrice, specifiedAmount, params.zeroForOne);
 
             return (this.beforeSwap.selector, toBeforeSwapDelta(specifiedAmount.toInt128(), 0), 0);
@@ -95,6 +127,18 @@ contract SwapXHook is ISwapXHook, BaseAsyncSwap {
         return (this.beforeSwap.selector, BeforeSwapDeltaLibrary.ZERO_DELTA, 0);
     }
 
+    /// @dev hookData is abi.encode(sqrtPrice, sender) or abi.encode(sqrtPrice, sender, flags)
+    function _decodeHookData(bytes calldata hookData)
+        internal
+        pure
+        returns (uint256 sqrtPrice, address sender, uint256 flags)
+    {
+        if (hookData.length >= 96) {
+            return abi.decode(hookData, (uint256, address, uint256));
+        }
+        (sqrtPrice, sender) = abi.decode(hookData, (uint256, address));
+    }
+
     function getHookPermissions() public pure virtual override returns (Hooks.Permissions memory permissions) {
         return Hooks.Permissions({
             beforeInitialize: true,
@@ -114,7 +158,16 @@ contract SwapXHook is ISwapXHook, BaseAsyncSwap {
         });
     }
 
-    function executeAsyncSwap(uint256 buyOrderId, uint256 sellOrderId) public {
+    function executeAsyncSwap(
+        bytes32 fillId,
+        uint256 buyOrderId,
+        uint256 sellOrderId,
+        uint256 quantity,
+        uint256 expiryBlock
+    ) public {
+        if (msg.sender != address(swapXTaskManager)) revert OnlyTaskManager();
+        if (consumedFills[fillId]) revert FillAlreadyConsumed(fillId);
+        if (block.number > expiryBlock) revert FillExpired(fillId);
         if (buyOrderId >= buyOrders.length || sellOrderId >= sellOrders.length) revert OrderDoesNotExist();
         if (buyOrderCancelled[buyOrderId] || sellOrderCancelled[sellOrderId]) revert OrderWasCancelled();
 
@@ -127,25 +180,119 @@ contract SwapXHook is ISwapXHook, BaseAsyncSwap {
 
         if (buyOrder.sqrtPrice < sellOrder.sqrtPrice) revert OrderSqrtPricesDoNotMatch();
 
-        uint256 tradeAmount = buyOrder.amount < sellOrder.amount ? buyOrder.amount : sellOrder.amount;
+        if (
+            quantity == 0 || quantity > buyOrder.amount - buyOrder.matchedAmount
+                || quantity > sellOrder.amount - sellOrder.matchedAmount
+        ) revert InvalidFillQuantity();
 
-        currency1.transfer(buyOrder.account, tradeAmount);
-        currency0.transfer(sellOrder.account, tradeAmount);
+        consumedFills[fillId] = true;
 
-        buyOrder.matchedAmount += tradeAmount;
-        sellOrder.matchedAmount += tradeAmount;
+        currency1.transfer(buyOrder.account, quantity);
+        currency0.transfer(sellOrder.account, quantity);
+
+        buyOrder.matchedAmount += quantity;
+        sellOrder.matchedAmount += quantity;
 
         if (buyOrder.matchedAmount == buyOrder.amount) {
-            emit OrderFulfilled(buyOrderId, buyOrder.account, buyOrder.sqrtPrice, tradeAmount, true);
+            emit OrderFulfilled(buyOrderId, buyOrder.account, buyOrder.sqrtPrice, quantity, true);
         } else {
-            emit OrderPartiallyFulfilled(buyOrderId, buyOrder.account, buyOrder.sqrtPrice, tradeAmount, true);
+            emit OrderPartiallyFulfilled(buyOrderId, buyOrder.account, buyOrder.sqrtPrice, quantity, true);
         }
 
         if (sellOrder.matchedAmount == sellOrder.amount) {
-            emit OrderFulfilled(sellOrderId, sellOrder.account, sellOrder.sqrtPrice, tradeAmount, false);
+            emit OrderFulfilled(sellOrderId, sellOrder.account, sellOrder.sqrtPrice, quantity, false);
+        } else {
+            emit OrderPartiallyFulfilled(sellOrderId, sellOrder.account, sellOrder.sqrtPrice, quantity, false);
+        }
+    }
+
+    function refundOrder(uint256 orderId, bool isBuy) public {
+        if (msg.sender != address(swapXTaskManager)) revert OnlyTaskManager();
+
+        Order storage order = _openOrder(orderId, isBuy);
+
+        if (isBuy) {
+            buyOrderCancelled[orderId] = true;
         } else {
-            emit OrderPartiallyFulfilled(sellOrderId, sellOrder.account, sellOrder.sqrtPrice, tradeAmount, false);
+            sellOrderCancelled[orderId] = true;
         }
+
+        uint256 remainingAmount = order.amount - order.matchedAmount;
+        (isBuy ? currency0 : currency1).transfer(order.account, remainingAmount);
+
+        emit OrderRefunded(orderId, order.account, remainingAmount, isBuy);
+    }
+
+    function transferDust(uint256 orderId, bool isBuy, uint256 amount) public {
+        if (msg.sender != address(swapXTaskManager)) revert OnlyTaskManager();
+
+        Order storage order = _openOrder(orderId, isBuy);
+
+        if (amount == 0 || amount > order.amount - order.matchedAmount) revert InvalidFillQuantity();
+
+        order.amount -= amount;
+        (isBuy ? currency0 : currency1).transfer(order.account, amount);
+
+        emit OrderDustReturned(orderId, order.account, amount, isBuy);
+    }
+
+    /// @dev Swaps up to amount of the escrow of the order through the pool, the
+    /// price never going past sqrtPriceLimitX96. The input the pool takes counts
+    /// as matched and the output is paid to the owner of the order. Past
+    /// expiryBlock the coprocessor gives the amount back to the order, so the
+    /// swap is refused from then on.
+    function swapRemainder(uint256 orderId, bool isBuy, uint256 amount, uint160 sqrtPriceLimitX96, uint256 expiryBlock)
+        public
+    {
+        if (msg.sender != address(swapXTaskManager)) revert OnlyTaskManager();
+        if (block.number > expiryBlock) revert SwapExpired();
+
+        Order storage order = _openOrder(orderId, isBuy);
+
+        if (amount == 0 || amount > order.amount - order.matchedAmount) revert InvalidFillQuantity();
+
+        (uint256 amountIn, uint256 amountOut) =
+            abi.decode(poolManager.unlock(abi.encode(isBuy, amount, sqrtPriceLimitX96)), (uint256, uint256));
+
+        order.matchedAmount += amountIn;
+        (isBuy ? currency1 : currency0).transfer(order.account, amountOut);
+
+        emit OrderRoutedToPool(orderId, order.account, amountIn, amountOut, isBuy);
+    }
+
+    /// @dev The swap skips _beforeSwap, the PoolManager does not call the hook
+    /// that is swapping
+    function unlockCallback(bytes calldata data) external returns (bytes memory) {
+        if (msg.sender != address(poolManager)) revert OnlyPoolManager();
+
+        (bool zeroForOne, uint256 amount, uint160 sqrtPriceLimitX96) = abi.decode(data, (bool, uint256, uint160));
+
+        BalanceDelta delta = poolManager.swap(
+            poolKey,
+            IPoolManager.SwapParams({
+                zeroForOne: zeroForOne,
+                amountSpecified: -amount.toInt256(),
+                sqrtPriceLimitX96: sqrtPriceLimitX96
+            }),
+            ZERO_BYTES
+        );
+
+        uint256 amountIn = uint256(uint128(-(zeroForOne ? delta.amount0() : delta.amount1())));
+        uint256 amountOut = uint256(uint128(zeroForOne ? delta.amount1() : delta.amount0()));
+
+        (zeroForOne ? currency0 : currency1).settle(poolManager, address(this), amountIn, false);
+        (zeroForOne ? currency1 : currency0).take(poolManager, address(this), amountOut, false);
+
+        return abi.encode(amountIn, amountOut);
+    }
+
+    function _openOrder(uint256 orderId, bool isBuy) internal view returns (Order storage order) {
+        if (orderId >= (isBuy ? buyOrders.length : sellOrders.length)) revert OrderDoesNotExist();
+        if (isBuy ? buyOrderCancelled[orderId] : sellOrderCancelled[orderId]) revert OrderWasCancelled();
+
+        order = isBuy ? buyOrders[orderId] : sellOrders[orderId];
+
+        if (order.matchedAmount == order.amount) revert OrderAlreadyFulfilled();
     }
 
     function cancelBuyOrder(uint256 orderId) public {
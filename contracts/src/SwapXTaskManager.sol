@@ -2,12 +2,29 @@
 pragma solidity 0.8.26;
 
 import {Inputs} from "./common/Inputs.sol";
+import {Outputs} from "./common/Outputs.sol";
 import {CanonicalMachine} from "./common/CanonicalMachine.sol";
 import {CoprocessorAdapter} from "../lib/coprocessor-base-contract/src/CoprocessorAdapter.sol";
-import {SwapXHook} from "./SwapXHook.sol";
+import {ISwapXHook} from "./interface/ISwapXHook.sol";
 
 contract SwapXTaskManager is CoprocessorAdapter {
+    uint256 public constant MAX_FILLS_PER_BATCH = 32;
+
+    event FillFailed(
+        address indexed hook,
+        bytes32 indexed fillId,
+        uint256 buyOrderId,
+        uint256 sellOrderId,
+        uint256 quantity,
+        bytes reason
+    );
+
+    event VoucherFailed(address indexed destination, bytes payload, bytes reason);
+
     error InputTooLarge(address appContract, uint256 inputLength, uint256 maxInputLength);
+    error UnsupportedNotice(bytes4 selector);
+    error BatchTooLarge(uint256 batchSize, uint256 maxBatchSize);
+    error ActionNotAllowed(bytes4 selector);
 
     constructor(address _taskIssuer, bytes32 _machineHash) CoprocessorAdapter(_taskIssuer, _machineHash) {}
 
@@ -35,8 +52,61 @@ contract SwapXTaskManager is CoprocessorAdapter {
     }
 
     function handleNotice(bytes32, /* payloadHash8 */ bytes memory notice) internal override {
-        (address hookAddress, uint256 buyOrderId, uint256 sellOrderId) = abi.decode(notice, (address, uint256, uint256));
-        SwapXHook hook = SwapXHook(hookAddress);
-        hook.executeAsyncSwap(buyOrderId, sellOrderId);
+        bytes4 selector = bytes4(notice);
+
+        if (selector == Outputs.SettleBatch.selector) {
+            (address hookAddress, Outputs.Fill[] memory fills) =
+                abi.decode(_stripSelector(notice), (address, Outputs.Fill[]));
+            if (fills.length > MAX_FILLS_PER_BATCH) revert BatchTooLarge(fills.length, MAX_FILLS_PER_BATCH);
+            _settleBatch(ISwapXHook(hookAddress), fills);
+        } else if (selector == Outputs.ExecuteVouchers.selector) {
+            (address hookAddress, bytes[] memory payloads) = abi.decode(_stripSelector(notice), (address, bytes[]));
+            _executeVouchers(hookAddress, payloads);
+        } else {
+            revert UnsupportedNotice(selector);
+        }
+    }
+
+    /// @dev Each fill is settled on its own so that a single reverting swap
+    /// does not take down the rest of the batch with it.
+    function _settleBatch(ISwapXHook hook, Outputs.Fill[] memory fills) internal {
+        for (uint256 i = 0; i < fills.length; i++) {
+            Outputs.Fill memory fill = fills[i];
+            try hook.executeAsyncSwap(fill.fillId, fill.buyOrderId, fill.sellOrderId, fill.quantity, fill.expiryBlock) {}
+            catch (bytes memory reason) {
+                emit FillFailed(address(hook), fill.fillId, fill.buyOrderId, fill.sellOrderId, fill.quantity, reason);
+            }
+        }
+    }
+
+    /// @dev Vouchers can only reach the hook of the notice, and only its actions
+    /// below, anything else is reported as failed without being called.
+    function _executeVouchers(address hook, bytes[] memory payloads) internal {
+        for (uint256 i = 0; i < payloads.length; i++) {
+            bytes memory payload = payloads[i];
+            bytes4 selector = bytes4(payload);
+
+            if (!_isAllowedAction(selector)) {
+                emit VoucherFailed(hook, payload, abi.encodeWithSelector(ActionNotAllowed.selector, selector));
+                continue;
+            }
+
+            (bool success, bytes memory reason) = hook.call(payload);
+            if (!success) {
+                emit VoucherFailed(hook, payload, reason);
+            }
+        }
+    }
+
+    function _isAllowedAction(bytes4 selector) internal pure returns (bool) {
+        return selector == ISwapXHook.refundOrder.selector || selector == ISwapXHook.transferDust.selector
+            || selector == ISwapXHook.swapRemainder.selector;
+    }
+
+    function _stripSelector(bytes memory data) internal pure returns (bytes memory arguments) {
+        assembly {
+            arguments := add(data, 4)
+            mstore(arguments, sub(mload(data), 4))
+        }
     }
 }
@@ -0,0 +1,20 @@
+// SPDX-License-Identifier: MIT
+
+pragma solidity 0.8.26;
+
+interface Outputs {
+    struct Fill {
+        bytes32 fillId;
+        uint256 buyOrderId;
+        uint256 sellOrderId;
+        uint256 quantity;
+        /// @dev Last block the fill can be executed in, the coprocessor gives
+        /// its quantity back to the orders past it
+        uint256 expiryBlock;
+    }
+
+    function SettleBatch(address hook, Fill[] calldata fills) external;
+
+    /// @dev Each payload is a call to one of the actions of the hook
+    function ExecuteVouchers(address hook, bytes[] calldata payloads) external;
+}
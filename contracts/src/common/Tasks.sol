@@ -0,0 +1,9 @@
+// SPDX-License-Identifier: MIT
+
+pragma solidity 0.8.26;
+
+/// @dev Task payloads other than the orders created by SwapXHook. They are
+/// encoded as calls, anyone can hand them to SwapXTaskManager.createTask.
+interface Tasks {
+    function Rematch(address hook) external;
+}
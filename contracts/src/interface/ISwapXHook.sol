@@ -4,7 +4,17 @@ pragma solidity 0.8.26;
 import {Hooks} from "v4-core/src/libraries/Hooks.sol";
 
 interface ISwapXHook {
-    function executeAsyncSwap(uint256 buyOrderId, uint256 sellOrderId) external;
+    function executeAsyncSwap(
+        bytes32 fillId,
+        uint256 buyOrderId,
+        uint256 sellOrderId,
+        uint256 quantity,
+        uint256 expiryBlock
+    ) external;
+    function refundOrder(uint256 orderId, bool isBuy) external;
+    function transferDust(uint256 orderId, bool isBuy, uint256 amount) external;
+    function swapRemainder(uint256 orderId, bool isBuy, uint256 amount, uint160 sqrtPriceLimitX96, uint256 expiryBlock)
+        external;
     function cancelBuyOrder(uint256 orderId) external;
     function cancelSellOrder(uint256 orderId) external;
 }
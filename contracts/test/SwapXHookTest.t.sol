@@ -25,6 +25,7 @@ contract SwapXHookTest is Test, Deployers {
     using ProtocolFeeLibrary for uint16;
 
     SwapXHook hook;
+    ISwapXTaskManager swapXManager;
 
     event Swap(
         PoolId indexed poolId,
@@ -43,7 +44,7 @@ contract SwapXHookTest is Test, Deployers {
     function setUp() public {
         deployFreshManagerAndRouters();
 
-        ISwapXTaskManager swapXManager = ISwapXTaskManager(address(new SwapXManagerMock()));
+        swapXManager = ISwapXTaskManager(address(new SwapXManagerMock()));
 
         hook = SwapXHook(
             address(
@@ -185,7 +186,8 @@ contract SwapXHookTest is Test, Deployers {
         assertEq(currency0.balanceOf(SELLER), sellerBalance0Before);
         assertEq(currency1.balanceOf(SELLER), sellerBalance1Before - 100);
         //execute async swap
-        hook.executeAsyncSwap(0, 0);
+        vm.prank(address(swapXManager));
+        hook.executeAsyncSwap(keccak256("fill"), 0, 0, 100, block.number);
 
         assertEq(currency0.balanceOf(BUYER), buyerBalance0Before - 100);
         assertEq(currency1.balanceOf(BUYER), buyerBalance1Before + 100);
@@ -233,7 +235,8 @@ contract SwapXHookTest is Test, Deployers {
         assertEq(currency0.balanceOf(SELLER), sellerBalance0Before);
         assertEq(currency1.balanceOf(SELLER), sellerBalance1Before - 100);
         //execute async swap
-        hook.executeAsyncSwap(0, 0);
+        vm.prank(address(swapXManager));
+        hook.executeAsyncSwap(keccak256("fill"), 0, 0, 100, block.number);
 
         assertEq(currency0.balanceOf(BUYER), buyerBalance0Before - 100);
         assertEq(currency1.balanceOf(BUYER), buyerBalance1Before + 100);
@@ -242,5 +245,191 @@ contract SwapXHookTest is Test, Deployers {
         assertEq(currency1.balanceOf(SELLER), sellerBalance1Before - 100);
     }
 
-    //
+    function test_executeAsyncSwap_partialQuantity_succeeds() public {
+        _placeOrders(1000000000000000000, 1000000000000000000);
+
+        uint256 buyerBalance1Before = currency1.balanceOf(BUYER);
+        uint256 sellerBalance0Before = currency0.balanceOf(SELLER);
+
+        vm.prank(address(swapXManager));
+        hook.executeAsyncSwap(keccak256("fill-0"), 0, 0, 40, block.number);
+
+        assertEq(currency1.balanceOf(BUYER), buyerBalance1Before + 40);
+        assertEq(currency0.balanceOf(SELLER), sellerBalance0Before + 40);
+
+        (,,, uint256 buyMatchedAmount) = hook.buyOrders(0);
+        (,,, uint256 sellMatchedAmount) = hook.sellOrders(0);
+        assertEq(buyMatchedAmount, 40);
+        assertEq(sellMatchedAmount, 40);
+
+        vm.prank(address(swapXManager));
+        hook.executeAsyncSwap(keccak256("fill-1"), 0, 0, 60, block.number);
+
+        assertEq(currency1.balanceOf(BUYER), buyerBalance1Before + 100);
+        assertEq(currency0.balanceOf(SELLER), sellerBalance0Before + 100);
+    }
+
+    function test_executeAsyncSwap_quantityAboveRemaining_reverts() public {
+        _placeOrders(1000000000000000000, 1000000000000000000);
+
+        vm.prank(address(swapXManager));
+        hook.executeAsyncSwap(keccak256("fill-0"), 0, 0, 60, block.number);
+
+        vm.prank(address(swapXManager));
+        vm.expectRevert(SwapXHook.InvalidFillQuantity.selector);
+        hook.executeAsyncSwap(keccak256("fill-1"), 0, 0, 41, block.number);
+    }
+
+    function test_executeAsyncSwap_replayedFill_reverts() public {
+        _placeOrders(1000000000000000000, 1000000000000000000);
+
+        bytes32 fillId = keccak256("fill");
+
+        vm.prank(address(swapXManager));
+        hook.executeAsyncSwap(fillId, 0, 0, 40, block.number);
+        assertTrue(hook.consumedFills(fillId));
+
+        uint256 buyerBalance1Before = currency1.balanceOf(BUYER);
+
+        vm.prank(address(swapXManager));
+        vm.expectRevert(abi.encodeWithSelector(SwapXHook.FillAlreadyConsumed.selector, fillId));
+        hook.executeAsyncSwap(fillId, 0, 0, 40, block.number);
+
+        assertEq(currency1.balanceOf(BUYER), buyerBalance1Before);
+    }
+
+    function test_executeAsyncSwap_expiredFill_reverts() public {
+        _placeOrders(1000000000000000000, 1000000000000000000);
+
+        bytes32 fillId = keccak256("fill");
+        uint256 expiryBlock = block.number;
+        vm.roll(expiryBlock + 1);
+
+        vm.prank(address(swapXManager));
+        vm.expectRevert(abi.encodeWithSelector(SwapXHook.FillExpired.selector, fillId));
+        hook.executeAsyncSwap(fillId, 0, 0, 40, expiryBlock);
+
+        assertFalse(hook.consumedFills(fillId));
+    }
+
+    function test_executeAsyncSwap_notTaskManager_reverts() public {
+        _placeOrders(1000000000000000000, 1000000000000000000);
+
+        vm.expectRevert(SwapXHook.OnlyTaskManager.selector);
+        hook.executeAsyncSwap(keccak256("fill"), 0, 0, 100, block.number);
+    }
+
+    function test_swap_withFlags_storesFlags() public {
+        IPoolManager.SwapParams memory swapParams =
+            IPoolManager.SwapParams({zeroForOne: true, amountSpecified: -100, sqrtPriceLimitX96: SQRT_PRICE_1_2});
+        PoolSwapTest.TestSettings memory testSettings =
+            PoolSwapTest.TestSettings({takeClaims: false, settleUsingBurn: false});
+
+        uint256 flags = hook.FLAG_IMMEDIATE_OR_CANCEL();
+        swapRouter.swap(key, swapParams, testSettings, abi.encode(100, BUYER, flags));
+
+        assertEq(hook.buyOrderFlags(0), flags);
+    }
+
+    function test_refundOrder_returnsRemainder() public {
+        _placeOrders(1000000000000000000, 1000000000000000000);
+
+        vm.prank(address(swapXManager));
+        hook.executeAsyncSwap(keccak256("fill"), 0, 0, 60, block.number);
+
+        uint256 buyerBalance0Before = currency0.balanceOf(BUYER);
+
+        vm.prank(address(swapXManager));
+        hook.refundOrder(0, true);
+
+        assertEq(currency0.balanceOf(BUYER), buyerBalance0Before + 40);
+        assertTrue(hook.buyOrderCancelled(0));
+
+        vm.prank(address(swapXManager));
+        vm.expectRevert(SwapXHook.OrderWasCancelled.selector);
+        hook.refundOrder(0, true);
+    }
+
+    function test_transferDust_shrinksOrder() public {
+        _placeOrders(1000000000000000000, 1000000000000000000);
+
+        vm.prank(address(swapXManager));
+        hook.executeAsyncSwap(keccak256("fill"), 0, 0, 95, block.number);
+
+        uint256 sellerBalance1Before = currency1.balanceOf(SELLER);
+
+        vm.prank(address(swapXManager));
+        hook.transferDust(0, false, 5);
+
+        assertEq(currency1.balanceOf(SELLER), sellerBalance1Before + 5);
+        (,, uint256 amount, uint256 matchedAmount) = hook.sellOrders(0);
+        assertEq(amount, matchedAmount);
+    }
+
+    function test_refundOrder_notTaskManager_reverts() public {
+        _placeOrders(1000000000000000000, 1000000000000000000);
+
+        vm.expectRevert(SwapXHook.OnlyTaskManager.selector);
+        hook.refundOrder(0, true);
+    }
+
+    function test_swapRemainder_swapsThroughPool() public {
+        _placeOrders(1000000000000000000, 1000000000000000000);
+
+        vm.prank(address(swapXManager));
+        hook.executeAsyncSwap(keccak256("fill"), 0, 0, 60, block.number);
+
+        uint256 buyerBalance1Before = currency1.balanceOf(BUYER);
+
+        vm.prank(address(swapXManager));
+        hook.swapRemainder(0, true, 40, SQRT_PRICE_1_2, block.number);
+
+        assertGt(currency1.balanceOf(BUYER), buyerBalance1Before);
+        (,, uint256 amount, uint256 matchedAmount) = hook.buyOrders(0);
+        assertEq(amount, matchedAmount);
+    }
+
+    function test_swapRemainder_aboveRemaining_reverts() public {
+        _placeOrders(1000000000000000000, 1000000000000000000);
+
+        vm.prank(address(swapXManager));
+        vm.expectRevert(SwapXHook.InvalidFillQuantity.selector);
+        hook.swapRemainder(0, false, 101, SQRT_PRICE_2_1, block.number);
+    }
+
+    function test_swapRemainder_expired_reverts() public {
+        _placeOrders(1000000000000000000, 1000000000000000000);
+
+        uint256 expiryBlock = block.number;
+        vm.roll(expiryBlock + 1);
+
+        vm.prank(address(swapXManager));
+        vm.expectRevert(SwapXHook.SwapExpired.selector);
+        hook.swapRemainder(0, true, 40, SQRT_PRICE_1_2, expiryBlock);
+
+        (,,, uint256 matchedAmount) = hook.buyOrders(0);
+        assertEq(matchedAmount, 0);
+    }
+
+    function _placeOrders(uint256 buySqrtPrice, uint256 sellSqrtPrice) internal {
+        IPoolManager.SwapParams memory buyParams =
+            IPoolManager.SwapParams({zeroForOne: true, amountSpecified: -100, sqrtPriceLimitX96: SQRT_PRICE_1_2});
+        IPoolManager.SwapParams memory sellParams =
+            IPoolManager.SwapParams({zeroForOne: false, amountSpecified: -100, sqrtPriceLimitX96: SQRT_PRICE_1_2});
+        PoolSwapTest.TestSettings memory testSettings =
+            PoolSwapTest.TestSettings({takeClaims: false, settleUsingBurn: false});
+
+        currency0.transfer(BUYER, 1e20);
+        currency1.transfer(SELLER, 1e20);
+
+        vm.prank(BUYER);
+        MockERC20(Currency.unwrap(currency0)).approve(address(swapRouter), 1e20);
+        vm.prank(BUYER);
+        swapRouter.swap(key, buyParams, testSettings, abi.encode(buySqrtPrice, BUYER));
+
+        vm.prank(SELLER);
+        MockERC20(Currency.unwrap(currency1)).approve(address(swapRouter), 1e20);
+        vm.prank(SELLER);
+        swapRouter.swap(key, sellParams, testSettings, abi.encode(sellSqrtPrice, SELLER));
+    }
 }
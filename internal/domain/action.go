@@ -0,0 +1,113 @@
+package domain
+
+import (
+	"github.com/holiman/uint256"
+)
+
+type ActionKind string
+
+var (
+	// ActionRefund closes the order and returns its whole unfilled remainder.
+	ActionRefund ActionKind = "refund"
+	// ActionTransfer returns part of the order escrow to its owner, shrinking the order.
+	ActionTransfer ActionKind = "transfer"
+	// ActionPoolSwap swaps part of the order escrow through the pool, the price
+	// not going past SqrtPriceLimit, and pays the output to its owner.
+	ActionPoolSwap ActionKind = "pool_swap"
+)
+
+type Action struct {
+	Kind           ActionKind   `json:"kind"`
+	OrderId        uint64       `json:"order_id"`
+	OrderType      *OrderType   `json:"order_type"`
+	Amount         *uint256.Int `json:"amount"`
+	SqrtPriceLimit *uint256.Int `json:"sqrt_price_limit,omitempty"`
+	// ExpiryBlock is the last block the hook executes a pool swap in
+	ExpiryBlock uint64 `json:"expiry_block,omitempty"`
+}
+
+// PlanActions decides what has to be given back to the order owners once the
+// trades have been applied: the remainder of the immediate-or-cancel orders
+// matched for the first time and the dust left behind on the orders that took
+// part in a trade. Orders that get an action are closed on the book. takers
+// are the incoming order, unless it is queued, and the queued orders opened by
+// this match.
+func PlanActions(takers []*Order, touched []*Order, dustThreshold *uint256.Int) []*Action {
+	var actions []*Action
+
+	for _, taker := range takers {
+		if !taker.HasFlag(OrderFlagImmediateOrCancel) || taker.IsTerminal() || taker.Remaining().IsZero() {
+			continue
+		}
+		actions = append(actions, &Action{
+			Kind:      ActionRefund,
+			OrderId:   taker.Id,
+			OrderType: taker.Type,
+			Amount:    taker.Remaining(),
+		})
+		taker.Status = &OrderCancelledOrFulfilled
+	}
+
+	if dustThreshold == nil || dustThreshold.IsZero() {
+		return actions
+	}
+
+	visited := make(map[*Order]bool)
+	for _, order := range touched {
+		if visited[order] || *order.Status == OrderCancelledOrFulfilled {
+			continue
+		}
+		visited[order] = true
+
+		remaining := order.Remaining()
+		if remaining.IsZero() || remaining.Cmp(dustThreshold) >= 0 {
+			continue
+		}
+
+		actions = append(actions, &Action{
+			Kind:      ActionTransfer,
+			OrderId:   order.Id,
+			OrderType: order.Type,
+			Amount:    remaining,
+		})
+		order.Status = &OrderCancelledOrFulfilled
+	}
+	return actions
+}
+
+// PlanRejections refunds the remainder of the orders a price band rejected and
+// closes them
+func PlanRejections(rejected []*Order) []*Action {
+	var actions []*Action
+	for _, order := range rejected {
+		if order.IsTerminal() || order.Remaining().IsZero() {
+			continue
+		}
+		actions = append(actions, &Action{
+			Kind:      ActionRefund,
+			OrderId:   order.Id,
+			OrderType: order.Type,
+			Amount:    order.Remaining(),
+		})
+		order.Status = &OrderCancelledOrFulfilled
+	}
+	return actions
+}
+
+// PlanPoolSwap routes amount of the remainder of order to the pool, with the
+// price of the order as the limit of the swap. The amount counts as matched
+// and the order is closed once nothing remains, until the swap is reconciled
+// with the chain like a fill.
+func PlanPoolSwap(order *Order, amount *uint256.Int) *Action {
+	order.MatchedAmount = new(uint256.Int).Add(order.MatchedAmount, amount)
+	if order.Remaining().IsZero() {
+		order.Status = &OrderCancelledOrFulfilled
+	}
+	return &Action{
+		Kind:           ActionPoolSwap,
+		OrderId:        order.Id,
+		OrderType:      order.Type,
+		Amount:         amount,
+		SqrtPriceLimit: order.SqrtPrice,
+	}
+}
@@ -0,0 +1,80 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestOrder(id uint64, orderType *OrderType, amount, matched uint64) *Order {
+	return &Order{
+		Id:            id,
+		Hook:          testHook,
+		SqrtPrice:     uint256.NewInt(100),
+		Amount:        uint256.NewInt(amount),
+		MatchedAmount: uint256.NewInt(matched),
+		Type:          orderType,
+		Status:        &OrderNotCancelledOrFulfilled,
+	}
+}
+
+func TestImmediateOrCancelRemainderIsRefunded(t *testing.T) {
+	incoming := newTestOrder(1, &OrderTypeBuy, 100, 60)
+	incoming.Flags = OrderFlagImmediateOrCancel
+
+	actions := PlanActions([]*Order{incoming}, nil, nil)
+
+	assert.Equal(t, []*Action{{Kind: ActionRefund, OrderId: 1, OrderType: &OrderTypeBuy, Amount: uint256.NewInt(40)}}, actions)
+	assert.Equal(t, OrderCancelledOrFulfilled, *incoming.Status)
+}
+
+func TestFilledImmediateOrCancelIsNotRefunded(t *testing.T) {
+	incoming := newTestOrder(1, &OrderTypeBuy, 100, 100)
+	incoming.Flags = OrderFlagImmediateOrCancel
+
+	assert.Empty(t, PlanActions([]*Order{incoming}, nil, nil))
+}
+
+func TestDustIsTransferredBack(t *testing.T) {
+	incoming := newTestOrder(1, &OrderTypeBuy, 100, 100)
+	dusty := newTestOrder(2, &OrderTypeSell, 100, 95)
+	open := newTestOrder(3, &OrderTypeSell, 100, 50)
+
+	actions := PlanActions([]*Order{incoming}, []*Order{incoming, dusty, incoming, open}, uint256.NewInt(10))
+
+	assert.Equal(t, []*Action{{Kind: ActionTransfer, OrderId: 2, OrderType: &OrderTypeSell, Amount: uint256.NewInt(5)}}, actions)
+	assert.Equal(t, OrderCancelledOrFulfilled, *dusty.Status)
+	assert.Equal(t, OrderNotCancelledOrFulfilled, *open.Status)
+}
+
+func TestRefundedOrderIsNotTransferredAgain(t *testing.T) {
+	incoming := newTestOrder(1, &OrderTypeSell, 100, 95)
+	incoming.Flags = OrderFlagImmediateOrCancel
+
+	actions := PlanActions([]*Order{incoming}, []*Order{incoming}, uint256.NewInt(10))
+
+	assert.Len(t, actions, 1)
+	assert.Equal(t, ActionRefund, actions[0].Kind)
+}
+
+func TestRejectedOrderIsRefundedOnce(t *testing.T) {
+	rejected := newTestOrder(1, &OrderTypeSell, 100, 30)
+
+	actions := PlanRejections([]*Order{rejected, rejected})
+
+	assert.Equal(t, []*Action{{Kind: ActionRefund, OrderId: 1, OrderType: &OrderTypeSell, Amount: uint256.NewInt(70)}}, actions)
+	assert.True(t, rejected.IsTerminal())
+}
+
+func TestPoolSwapCountsAsMatchedAndClosesTheFilledOrder(t *testing.T) {
+	partial := newTestOrder(1, &OrderTypeBuy, 100, 60)
+	action := PlanPoolSwap(partial, uint256.NewInt(30))
+
+	assert.Equal(t, &Action{Kind: ActionPoolSwap, OrderId: 1, OrderType: &OrderTypeBuy, Amount: uint256.NewInt(30), SqrtPriceLimit: uint256.NewInt(100)}, action)
+	assert.Equal(t, uint256.NewInt(90), partial.MatchedAmount)
+	assert.Equal(t, OrderNotCancelledOrFulfilled, *partial.Status)
+
+	PlanPoolSwap(partial, uint256.NewInt(10))
+	assert.Equal(t, OrderCancelledOrFulfilled, *partial.Status)
+}
@@ -0,0 +1,222 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/holiman/uint256"
+)
+
+const (
+	// STATS_WINDOW is the period covered by MarketStats, in seconds
+	STATS_WINDOW = 24 * 60 * 60
+	// CANDLES_KEPT is a day of 1m candles, which the stats are taken from
+	CANDLES_KEPT = 1440
+)
+
+var ErrInvalidCandleInterval = errors.New("invalid candle interval")
+
+type CandleInterval string
+
+var (
+	CandleInterval1m CandleInterval = "1m"
+	CandleInterval5m CandleInterval = "5m"
+	CandleInterval1h CandleInterval = "1h"
+	CandleInterval1d CandleInterval = "1d"
+)
+
+var CandleIntervals = map[CandleInterval]uint64{
+	CandleInterval1m: 60,
+	CandleInterval5m: 5 * 60,
+	CandleInterval1h: 60 * 60,
+	CandleInterval1d: 24 * 60 * 60,
+}
+
+func ParseCandleInterval(value string) (CandleInterval, error) {
+	interval := CandleInterval(value)
+	if _, ok := CandleIntervals[interval]; !ok {
+		return "", fmt.Errorf("%w %q, use 1m, 5m, 1h or 1d", ErrInvalidCandleInterval, value)
+	}
+	return interval, nil
+}
+
+// PricedTrade is a trade at a point in time. A trade is priced at the limit
+// price of its sell order, which is also the price the hook emits with the
+// fill of the sell order.
+type PricedTrade struct {
+	SqrtPrice *uint256.Int
+	Quantity  *uint256.Int
+	Timestamp uint64
+}
+
+// Candle holds prices as sqrtPriceX96. Notional is the sum of sqrtPrice^2 *
+// quantity over the trades, from which the VWAP is taken.
+type Candle struct {
+	Start    uint64
+	Open     *uint256.Int
+	High     *uint256.Int
+	Low      *uint256.Int
+	Close    *uint256.Int
+	Volume   *uint256.Int
+	Notional *big.Int
+	Trades   int
+}
+
+func newCandle(start uint64, trade PricedTrade) *Candle {
+	return &Candle{
+		Start:    start,
+		Open:     trade.SqrtPrice,
+		High:     trade.SqrtPrice,
+		Low:      trade.SqrtPrice,
+		Close:    trade.SqrtPrice,
+		Volume:   new(uint256.Int),
+		Notional: new(big.Int),
+	}
+}
+
+func (c *Candle) add(trade PricedTrade) {
+	if trade.SqrtPrice.Gt(c.High) {
+		c.High = trade.SqrtPrice
+	}
+	if trade.SqrtPrice.Lt(c.Low) {
+		c.Low = trade.SqrtPrice
+	}
+	c.Close = trade.SqrtPrice
+	c.Volume = new(uint256.Int).Add(c.Volume, trade.Quantity)
+	c.Notional = new(big.Int).Add(c.Notional, notional(trade.SqrtPrice, trade.Quantity))
+	c.Trades++
+}
+
+// VWAP returns the volume weighted average price as a sqrtPriceX96, rounded down
+func (c *Candle) VWAP() *uint256.Int {
+	return vwap(c.Notional, c.Volume)
+}
+
+// Candles aggregates trades into candles of every interval, keeping the last
+// Limit candles of each, or all of them when Limit is 0. Trades are expected
+// in time order, as they come from the chain.
+type Candles struct {
+	Limit int
+	// LastTimestamp is the time of the latest trade
+	LastTimestamp uint64
+	series        map[CandleInterval][]*Candle
+}
+
+func NewCandles(limit int) *Candles {
+	return &Candles{Limit: limit, series: make(map[CandleInterval][]*Candle)}
+}
+
+func (c *Candles) Add(trade PricedTrade) {
+	c.LastTimestamp = max(c.LastTimestamp, trade.Timestamp)
+	for interval, seconds := range CandleIntervals {
+		start := trade.Timestamp - trade.Timestamp%seconds
+		series := c.series[interval]
+
+		i := sort.Search(len(series), func(i int) bool { return series[i].Start >= start })
+		if i == len(series) || series[i].Start != start {
+			series = append(series, nil)
+			copy(series[i+1:], series[i:])
+			series[i] = newCandle(start, trade)
+		}
+		series[i].add(trade)
+
+		if c.Limit > 0 && len(series) > c.Limit {
+			series = series[len(series)-c.Limit:]
+		}
+		c.series[interval] = series
+	}
+}
+
+// Copy returns candles that the trades added to c from now on leave alone
+func (c *Candles) Copy() *Candles {
+	copied := &Candles{Limit: c.Limit, LastTimestamp: c.LastTimestamp, series: make(map[CandleInterval][]*Candle, len(c.series))}
+	for interval, series := range c.series {
+		candles := make([]*Candle, 0, len(series))
+		for _, candle := range series {
+			// a candle replaces its fields rather than changing them in place
+			candle := *candle
+			candles = append(candles, &candle)
+		}
+		copied.series[interval] = candles
+	}
+	return copied
+}
+
+// Find returns the candles starting from from to to, both included
+func (c *Candles) Find(interval CandleInterval, from, to uint64) []*Candle {
+	candles := []*Candle{}
+	for _, candle := range c.series[interval] {
+		if candle.Start >= from && candle.Start <= to {
+			candles = append(candles, candle)
+		}
+	}
+	return candles
+}
+
+// Stats summarizes the trades of the STATS_WINDOW seconds up to at, at the
+// granularity of the 1m candles.
+func (c *Candles) Stats(at uint64) *MarketStats {
+	from := uint64(0)
+	if at > STATS_WINDOW {
+		from = at - STATS_WINDOW
+	}
+	stats := &MarketStats{From: from, To: at, Volume: new(uint256.Int), Notional: new(big.Int)}
+	for _, candle := range c.Find(CandleInterval1m, from, at) {
+		if stats.Trades == 0 {
+			stats.Open, stats.High, stats.Low = candle.Open, candle.High, candle.Low
+		}
+		if candle.High.Gt(stats.High) {
+			stats.High = candle.High
+		}
+		if candle.Low.Lt(stats.Low) {
+			stats.Low = candle.Low
+		}
+		stats.Last = candle.Close
+		stats.Volume = new(uint256.Int).Add(stats.Volume, candle.Volume)
+		stats.Notional = new(big.Int).Add(stats.Notional, candle.Notional)
+		stats.Trades += candle.Trades
+	}
+	return stats
+}
+
+// MarketRepository keeps the candles of each hook apart
+type MarketRepository interface {
+	// RecordTrades adds the trades of an input for hook, made at its timestamp
+	RecordTrades(hook common.Address, timestamp uint64, trades []*Trade) error
+	FindCandles(hook common.Address, interval CandleInterval, from, to uint64) ([]*Candle, error)
+	// FindStats takes the stats up to at, or up to the latest trade when at is 0
+	FindStats(hook common.Address, at uint64) (*MarketStats, error)
+}
+
+// MarketStats holds prices as sqrtPriceX96, left nil when there was no trade
+type MarketStats struct {
+	From     uint64
+	To       uint64
+	Trades   int
+	Volume   *uint256.Int
+	Notional *big.Int
+	Open     *uint256.Int
+	High     *uint256.Int
+	Low      *uint256.Int
+	Last     *uint256.Int
+}
+
+func (s *MarketStats) VWAP() *uint256.Int {
+	return vwap(s.Notional, s.Volume)
+}
+
+func notional(sqrtPrice, quantity *uint256.Int) *big.Int {
+	square := new(big.Int).Mul(sqrtPrice.ToBig(), sqrtPrice.ToBig())
+	return square.Mul(square, quantity.ToBig())
+}
+
+func vwap(notional *big.Int, volume *uint256.Int) *uint256.Int {
+	if volume.IsZero() {
+		return nil
+	}
+	average := new(big.Int).Quo(notional, volume.ToBig())
+	return uint256.MustFromBig(average.Sqrt(average))
+}
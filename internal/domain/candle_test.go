@@ -0,0 +1,91 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func trade(sqrtPrice, quantity, timestamp uint64) PricedTrade {
+	return PricedTrade{SqrtPrice: uint256.NewInt(sqrtPrice), Quantity: uint256.NewInt(quantity), Timestamp: timestamp}
+}
+
+func TestCandlesAggregateEveryInterval(t *testing.T) {
+	candles := NewCandles(0)
+	candles.Add(trade(10, 1, 3600))
+	candles.Add(trade(30, 3, 3630))
+	candles.Add(trade(20, 2, 3700))
+	candles.Add(trade(40, 1, 7200))
+
+	minutes := candles.Find(CandleInterval1m, 0, 10000)
+	require.Len(t, minutes, 3)
+	assert.Equal(t, uint64(3600), minutes[0].Start)
+	assert.Equal(t, 2, minutes[0].Trades)
+	assert.Equal(t, uint64(10), minutes[0].Open.Uint64())
+	assert.Equal(t, uint64(30), minutes[0].Close.Uint64())
+
+	hours := candles.Find(CandleInterval1h, 0, 10000)
+	require.Len(t, hours, 2)
+	first := hours[0]
+	assert.Equal(t, []uint64{10, 30, 10, 20}, []uint64{first.Open.Uint64(), first.High.Uint64(), first.Low.Uint64(), first.Close.Uint64()})
+	assert.Equal(t, uint64(6), first.Volume.Uint64())
+	// (100*1 + 900*3 + 400*2) / 6 = 600, and sqrt(600) rounds down to 24
+	assert.Equal(t, uint64(24), first.VWAP().Uint64())
+
+	assert.Len(t, candles.Find(CandleInterval1d, 0, 10000), 1)
+	assert.Len(t, candles.Find(CandleInterval5m, 3600, 3600), 1)
+}
+
+func TestCandlesKeepTheLastOnes(t *testing.T) {
+	candles := NewCandles(2)
+	for i := range uint64(4) {
+		candles.Add(trade(10+i, 1, i*60))
+	}
+
+	minutes := candles.Find(CandleInterval1m, 0, 1000)
+	require.Len(t, minutes, 2)
+	assert.Equal(t, uint64(120), minutes[0].Start)
+	assert.Equal(t, uint64(180), minutes[1].Start)
+	assert.Equal(t, uint64(180), candles.LastTimestamp)
+
+	_, err := ParseCandleInterval("2m")
+	assert.ErrorIs(t, err, ErrInvalidCandleInterval)
+}
+
+func TestCandlesCopyIsLeftAloneByLaterTrades(t *testing.T) {
+	candles := NewCandles(0)
+	candles.Add(trade(10, 1, 0))
+	copied := candles.Copy()
+
+	candles.Add(trade(12, 2, 30))
+	candles.Add(trade(11, 1, 90))
+
+	minutes := copied.Find(CandleInterval1m, 0, 1000)
+	require.Len(t, minutes, 1)
+	assert.Equal(t, uint256.NewInt(1), minutes[0].Volume)
+	assert.Equal(t, uint256.NewInt(10), minutes[0].High)
+	assert.Equal(t, 1, minutes[0].Trades)
+	assert.Equal(t, uint64(0), copied.LastTimestamp)
+	assert.Len(t, candles.Find(CandleInterval1m, 0, 1000), 2)
+}
+
+func TestStatsCoverTheLastDay(t *testing.T) {
+	candles := NewCandles(CANDLES_KEPT)
+	candles.Add(trade(50, 5, 1000))
+	candles.Add(trade(20, 1, 1000+STATS_WINDOW))
+	candles.Add(trade(40, 1, 2000+STATS_WINDOW))
+
+	stats := candles.Stats(2000 + STATS_WINDOW)
+	assert.Equal(t, 2, stats.Trades)
+	assert.Equal(t, uint64(2), stats.Volume.Uint64())
+	assert.Equal(t, []uint64{20, 40, 20, 40}, []uint64{stats.Open.Uint64(), stats.High.Uint64(), stats.Low.Uint64(), stats.Last.Uint64()})
+	// (400 + 1600) / 2 = 1000, and sqrt(1000) rounds down to 31
+	assert.Equal(t, uint64(31), stats.VWAP().Uint64())
+
+	empty := candles.Stats(10 * STATS_WINDOW)
+	assert.Zero(t, empty.Trades)
+	assert.Nil(t, empty.Last)
+	assert.Nil(t, empty.VWAP())
+}
@@ -0,0 +1,16 @@
+package domain
+
+import "github.com/ethereum/go-ethereum/common"
+
+// SyncCursor records the last input the book was synced at, so a machine
+// started from a snapshot knows where the previous one stopped.
+type SyncCursor struct {
+	BlockNumber uint64      `json:"block_number"`
+	BlockHash   common.Hash `json:"block_hash"`
+	Inputs      uint64      `json:"inputs"`
+}
+
+type CursorRepository interface {
+	FindCursor() (*SyncCursor, error)
+	UpdateCursor(cursor *SyncCursor) error
+}
@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/holiman/uint256"
+)
+
+type HookEventType string
+
+var (
+	HookEventOrderCreated            HookEventType = "order_created"
+	HookEventOrderFulfilled          HookEventType = "order_fulfilled"
+	HookEventOrderPartiallyFulfilled HookEventType = "order_partially_fulfilled"
+	HookEventOrderCancelled          HookEventType = "order_cancelled"
+	HookEventOrderRefunded           HookEventType = "order_refunded"
+	HookEventOrderDustReturned       HookEventType = "order_dust_returned"
+	HookEventOrderRoutedToPool       HookEventType = "order_routed_to_pool"
+)
+
+// HookEvent is an order event emitted by the hook, with the order id already
+// turned into the 1-based id of Order: OrderCreated carries the length of the
+// order array while the other events carry the index of the order.
+type HookEvent struct {
+	Type      HookEventType  `json:"type"`
+	Hook      common.Address `json:"hook"`
+	OrderType OrderType      `json:"order_type"`
+	OrderId   uint64         `json:"order_id"`
+	Account   common.Address `json:"account"`
+	// SqrtPrice is nil for refunds, dust and pool routes, whose events do not carry it
+	SqrtPrice *uint256.Int `json:"sqrt_price"`
+	// Amount is the quantity filled for fill events, the quantity given back
+	// for refunds and dust, the input the pool took for pool routes and the
+	// order amount otherwise
+	Amount *uint256.Int `json:"amount"`
+	// AmountOut is what the pool paid the owner, for pool routes only
+	AmountOut   *uint256.Int `json:"amount_out,omitempty"`
+	BlockNumber uint64       `json:"block_number"`
+	BlockHash   common.Hash  `json:"block_hash"`
+	Timestamp   uint64       `json:"timestamp"`
+	TxHash      common.Hash  `json:"tx_hash"`
+	LogIndex    uint         `json:"log_index"`
+}
+
+// Fill is one side of a settled trade, each settlement fills a buy and a sell order
+type Fill struct {
+	Hook        common.Address `json:"hook"`
+	OrderType   OrderType      `json:"order_type"`
+	OrderId     uint64         `json:"order_id"`
+	Account     common.Address `json:"account"`
+	SqrtPrice   *uint256.Int   `json:"sqrt_price"`
+	Quantity    *uint256.Int   `json:"quantity"`
+	BlockNumber uint64         `json:"block_number"`
+	BlockHash   common.Hash    `json:"block_hash"`
+	Timestamp   uint64         `json:"timestamp"`
+	TxHash      common.Hash    `json:"tx_hash"`
+	LogIndex    uint           `json:"log_index"`
+}
+
+type FillRepository interface {
+	CreateFill(fill *Fill) error
+	// FindFills returns every fill in chain order
+	FindFills() ([]*Fill, error)
+	FindFillsByOrder(orderType OrderType, id uint64) ([]*Fill, error)
+	// DeleteFillsAfter drops the fills of the blocks above blockNumber
+	DeleteFillsAfter(blockNumber uint64) error
+}
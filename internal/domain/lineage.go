@@ -0,0 +1,68 @@
+package domain
+
+import "github.com/ethereum/go-ethereum/common"
+
+// BlockRef names a block by number and hash
+type BlockRef struct {
+	Number uint64      `json:"number"`
+	Hash   common.Hash `json:"hash"`
+}
+
+type BlockHeader struct {
+	BlockRef
+	ParentHash common.Hash `json:"parent_hash"`
+}
+
+// Checkpoint is a copy of the state an input started from, kept to go back to
+// when the block the input read at leaves the chain
+type Checkpoint struct {
+	Block          BlockRef
+	BuyOrders      []*Order
+	SellOrders     []*Order
+	BuyTombstones  map[common.Address]*TombstoneSet
+	SellTombstones map[common.Address]*TombstoneSet
+	Cursor         SyncCursor
+	PendingFills   []*PendingFill
+	PendingSwaps   []*PendingPoolSwap
+	// Candles hold the trades of the inputs before, a dropped input takes its
+	// trades out of the market data along with the rest
+	Candles map[common.Address]*Candles
+}
+
+type LineageRepository interface {
+	// FindCheckpoints returns the blocks of the kept checkpoints in the order
+	// of their inputs
+	FindCheckpoints() ([]BlockRef, error)
+	// SaveCheckpoint copies the current state as the one the input reading at
+	// block starts from
+	SaveCheckpoint(block BlockRef) error
+	// RestoreCheckpoint brings back the state of the checkpoint at index and
+	// forgets it along with the later ones
+	RestoreCheckpoint(index int) error
+	// PruneCheckpoints forgets the checkpoints of the blocks up to number
+	PruneCheckpoints(number uint64) error
+}
+
+// FindForkPoint returns how many of the checkpoints are still on the chain of
+// head, walking its ancestors back with header. Checkpoints at least depth
+// blocks below head are final and are not looked up.
+func FindForkPoint(checkpoints []BlockRef, head *BlockHeader, depth uint64, header func(common.Hash) (*BlockHeader, error)) (int, error) {
+	current := head
+	for i := len(checkpoints) - 1; i >= 0; i-- {
+		checkpoint := checkpoints[i]
+		if checkpoint.Number+depth <= head.Number {
+			return i + 1, nil
+		}
+		for current.Number > checkpoint.Number {
+			parent, err := header(current.ParentHash)
+			if err != nil {
+				return 0, err
+			}
+			current = parent
+		}
+		if current.BlockRef == checkpoint {
+			return i + 1, nil
+		}
+	}
+	return 0, nil
+}
@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testChain map[common.Hash]*BlockHeader
+
+// extend mines count blocks on top of parent, branch tells apart the forks
+func (c testChain) extend(parent *BlockHeader, count int, branch string) []*BlockHeader {
+	var blocks []*BlockHeader
+	for range count {
+		block := &BlockHeader{
+			BlockRef:   BlockRef{Number: parent.Number + 1, Hash: common.BytesToHash([]byte(fmt.Sprintf("%s%d", branch, parent.Number+1)))},
+			ParentHash: parent.Hash,
+		}
+		c[block.Hash] = block
+		blocks = append(blocks, block)
+		parent = block
+	}
+	return blocks
+}
+
+func (c testChain) header(hash common.Hash) (*BlockHeader, error) {
+	if block, ok := c[hash]; ok {
+		return block, nil
+	}
+	return nil, fmt.Errorf("unknown block %s", hash.Hex())
+}
+
+func TestFindForkPoint(t *testing.T) {
+	chain := testChain{}
+	genesis := &BlockHeader{}
+	main := chain.extend(genesis, 10, "a")
+	fork := chain.extend(main[5], 4, "b") // blocks 7 to 10
+
+	checkpoints := []BlockRef{main[2].BlockRef, main[5].BlockRef, main[6].BlockRef, main[6].BlockRef, main[8].BlockRef}
+
+	keep, err := FindForkPoint(checkpoints, main[9], 64, chain.header)
+	require.NoError(t, err)
+	assert.Equal(t, 5, keep)
+
+	keep, err = FindForkPoint(checkpoints, fork[3], 64, chain.header)
+	require.NoError(t, err)
+	assert.Equal(t, 2, keep, "the inputs read at blocks 7 and 9 are gone")
+
+	// block 7 is too deep to be rolled back, only the input of block 9 is
+	keep, err = FindForkPoint(checkpoints, fork[3], 2, chain.header)
+	require.NoError(t, err)
+	assert.Equal(t, 4, keep)
+
+	_, err = FindForkPoint(checkpoints, &BlockHeader{BlockRef: BlockRef{Number: 11}, ParentHash: common.HexToHash("0x1")}, 64, chain.header)
+	assert.Error(t, err)
+}
@@ -13,6 +13,7 @@ var (
 	ErrOrderNotFound      = errors.New("order not found")
 	ErrNoOrdersFound      = errors.New("no orders found")
 	ErrOrderAlreadyExists = errors.New("order with this id already exists")
+	ErrOrderPruned        = errors.New("order was pruned")
 )
 
 type OrderType string
@@ -25,24 +26,118 @@ type OrderStatus string
 var (
 	OrderCancelledOrFulfilled    OrderStatus = "cancelled_or_fulfilled"
 	OrderNotCancelledOrFulfilled OrderStatus = "not_cancelled_or_fulfilled"
+	// OrderQueued is an open order held out of matching until it is old enough
+	OrderQueued OrderStatus = "queued"
 )
 
+// QueueReason tells why an open order is not matched yet
+type QueueReason string
+var (
+	QueueReasonConfirmationDepth QueueReason = "confirmation_depth"
+)
+
+type OrderFlag uint64
+
+const (
+	// OrderFlagImmediateOrCancel marks an order whose unfilled remainder is
+	// refunded right after matching instead of resting on the book.
+	OrderFlagImmediateOrCancel OrderFlag = 1 << 0
+	// OrderFlagRouteToPool marks an order whose remainder, once the book is
+	// exhausted, is swapped through the pool as far as its price allows.
+	OrderFlagRouteToPool OrderFlag = 1 << 1
+)
+
+var OrderFlagNames = map[OrderFlag]string{
+	OrderFlagImmediateOrCancel: "immediate_or_cancel",
+	OrderFlagRouteToPool:       "route_to_pool",
+}
+
+// Names lists the known flags that are set, unknown bits are rendered in hex.
+func (f OrderFlag) Names() []string {
+	names := []string{}
+	for bit := OrderFlag(1); bit != 0; bit <<= 1 {
+		if f&bit == 0 {
+			continue
+		}
+		if name, ok := OrderFlagNames[bit]; ok {
+			names = append(names, name)
+		} else {
+			names = append(names, fmt.Sprintf("%#x", uint64(bit)))
+		}
+	}
+	return names
+}
+
+// OrderKey identifies an order within its side. Every hook numbers its orders
+// from 1, so the id alone is not enough.
+type OrderKey struct {
+	Hook common.Address
+	Id   uint64
+}
+
 type OrderRepository interface {
+	// FindAllOrders returns the orders of every hook
 	FindAllOrders() ([]*Order, error)
 	CreateOrder(order *Order) (*Order, error)
-	FindOrdersByType(orderType OrderType) ([]*Order, error)
-	FindOrderById(orderType OrderType, id uint64) (*Order, error)
-	FindOrdersByTypeAndStatus(orderType OrderType, orderStatus OrderStatus) ([]*Order, error)
+	UpdateOrder(order *Order) (*Order, error)
+	FindOrdersByType(hook common.Address, orderType OrderType) ([]*Order, error)
+	FindOrderById(hook common.Address, orderType OrderType, id uint64) (*Order, error)
+	FindOrdersByTypeAndStatus(hook common.Address, orderType OrderType, orderStatus OrderStatus) ([]*Order, error)
+	// DeleteOrder prunes the order and leaves a tombstone behind, so creating
+	// it again fails with ErrOrderPruned.
+	DeleteOrder(hook common.Address, orderType OrderType, id uint64) error
+	Stats() (*BookStats, error)
+}
+
+type BookStats struct {
+	BuyOrders      int    `json:"buy_orders"`
+	SellOrders     int    `json:"sell_orders"`
+	TerminalOrders int    `json:"terminal_orders"`
+	QueuedOrders   int    `json:"queued_orders"`
+	BuyTombstones  uint64 `json:"buy_tombstones"`
+	SellTombstones uint64 `json:"sell_tombstones"`
 }
 
 type Order struct {
 	Id            uint64         `json:"id"`
 	Hook          common.Address `json:"hook"`
+	// Account is the owner of the order, only known when the order is read
+	// from the events of the hook rather than from its storage.
+	Account       common.Address `json:"account"`
 	SqrtPrice     *uint256.Int   `json:"sqrt_price"`
 	Amount        *uint256.Int   `json:"amount"`
 	MatchedAmount *uint256.Int   `json:"matched_amount"`
 	Type          *OrderType     `json:"type"`
 	Status        *OrderStatus   `json:"status"`
+	Flags         OrderFlag      `json:"flags"`
+	// TerminalSince is the block at which the order was first seen cancelled
+	// or fulfilled by the compaction, 0 while it is open or not yet seen.
+	TerminalSince uint64 `json:"terminal_since"`
+	// QueuedAt is the block at which the order was first seen while it had to
+	// wait for the confirmation depth, 0 when it never had to.
+	QueuedAt uint64 `json:"queued_at"`
+}
+
+// QueuedOrder explains why an order is held out of matching and from which
+// block it is matched
+type QueuedOrder struct {
+	Id          uint64         `json:"id"`
+	Hook        common.Address `json:"hook"`
+	Type        OrderType      `json:"type"`
+	QueuedAt    uint64         `json:"queued_at"`
+	MatchableAt uint64         `json:"matchable_at"`
+	Reason      QueueReason    `json:"reason"`
+}
+
+func NewQueuedOrder(order *Order, depth uint64) *QueuedOrder {
+	return &QueuedOrder{
+		Id:          order.Id,
+		Hook:        order.Hook,
+		Type:        *order.Type,
+		QueuedAt:    order.QueuedAt,
+		MatchableAt: order.QueuedAt + depth,
+		Reason:      QueueReasonConfirmationDepth,
+	}
 }
 
 func NewOrder(id uint64, hook common.Address, sqrtPrice, amount *uint256.Int, matchedAmount *uint256.Int, orderType *OrderType, orderStatus *OrderStatus) (*Order, error) {
@@ -75,4 +170,39 @@ func (o *Order) Validate() error {
 		return fmt.Errorf("order amount must be greater than zero: %w", ErrInvalidOrder)
 	}
 	return nil
+}
+
+func (o *Order) HasFlag(flag OrderFlag) bool {
+	return o.Flags&flag != 0
+}
+
+func (o *Order) IsTerminal() bool {
+	return o.Status != nil && *o.Status == OrderCancelledOrFulfilled
+}
+
+func (o *Order) IsQueued() bool {
+	return o.Status != nil && *o.Status == OrderQueued
+}
+
+// Queue holds a new order out of matching when it has to wait depth blocks
+func (o *Order) Queue(blockNumber, depth uint64) {
+	if depth == 0 || o.IsTerminal() {
+		return
+	}
+	o.Status = &OrderQueued
+	o.QueuedAt = blockNumber
+}
+
+// Confirm opens a queued order that has been on chain for the depth at
+// blockNumber, and tells whether it did
+func (o *Order) Confirm(blockNumber, depth uint64) bool {
+	if !o.IsQueued() || blockNumber < o.QueuedAt+depth {
+		return false
+	}
+	o.Status = &OrderNotCancelledOrFulfilled
+	return true
+}
+
+func (o *Order) Remaining() *uint256.Int {
+	return new(uint256.Int).Sub(o.Amount, o.MatchedAmount)
 }
\ No newline at end of file
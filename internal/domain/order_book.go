@@ -9,14 +9,23 @@ import (
 
 var ErrNoMatch = errors.New("no match found")
 
+// Trade is priced at the limit price of its ask, see PricedTrade
 type Trade struct {
-	BidId uint64
-	AskId uint64
+	BidId     uint64
+	AskId     uint64
+	Quantity  *uint256.Int
+	SqrtPrice *uint256.Int
 }
 
 type OrderBook struct {
 	Bids *MaxHeap
 	Asks *MinHeap
+	// MaxTrades stops matching once that many trades were made, 0 means no limit
+	MaxTrades int
+	// Band keeps the orders that would match too far from the spot price out
+	// of the match, into Banded. Nil matches at any price.
+	Band   *PriceBand
+	Banded []*Order
 }
 
 func NewOrderBook() *OrderBook {
@@ -90,13 +99,45 @@ func (ob *OrderBook) MatchOrders() ([]*Trade, error) {
 	var trades []*Trade
 
 	for ob.Bids.Len() > 0 && ob.Asks.Len() > 0 {
+		if ob.MaxTrades > 0 && len(trades) >= ob.MaxTrades {
+			break
+		}
+
 		bestBid := (*ob.Bids)[0]
 		bestAsk := (*ob.Asks)[0]
+
+		// orders filled by a previous input may still be on the book
+		if bestBid.Remaining().IsZero() {
+			heap.Pop(ob.Bids)
+			continue
+		}
+		if bestAsk.Remaining().IsZero() {
+			heap.Pop(ob.Asks)
+			continue
+		}
 	
 		if bestBid.SqrtPrice.Cmp(bestAsk.SqrtPrice) < 0 {
 			break
 		}
 
+		// the match executes at the ask price: below the band the ask sells too
+		// cheap, above it the bid buys too dear
+		if ob.Band != nil {
+			// without a spot price there is no band to match within
+			if ob.Band.Spot.IsZero() {
+				ob.Banded = append(ob.Banded, heap.Pop(ob.Bids).(*Order), heap.Pop(ob.Asks).(*Order))
+				continue
+			}
+			switch ob.Band.Compare(bestAsk.SqrtPrice) {
+			case -1:
+				ob.Banded = append(ob.Banded, heap.Pop(ob.Asks).(*Order))
+				continue
+			case 1:
+				ob.Banded = append(ob.Banded, heap.Pop(ob.Bids).(*Order))
+				continue
+			}
+		}
+
 		remainingBid := new(uint256.Int).Sub(bestBid.Amount, bestBid.MatchedAmount)
 		remainingAsk := new(uint256.Int).Sub(bestAsk.Amount, bestAsk.MatchedAmount)
 
@@ -108,8 +149,10 @@ func (ob *OrderBook) MatchOrders() ([]*Trade, error) {
 		}
 			
 		trade := &Trade{
-			BidId: bestBid.Id,
-			AskId: bestAsk.Id,
+			BidId:     bestBid.Id,
+			AskId:     bestAsk.Id,
+			Quantity:  matchedQty,
+			SqrtPrice: bestAsk.SqrtPrice,
 		}
 		trades = append(trades, trade)
 
@@ -120,10 +163,12 @@ func (ob *OrderBook) MatchOrders() ([]*Trade, error) {
 		remainingAsk = new(uint256.Int).Sub(bestAsk.Amount, bestAsk.MatchedAmount)
 
 		if remainingBid.IsZero() {
+			bestBid.Status = &OrderCancelledOrFulfilled
 			heap.Pop(ob.Bids)
 		}
 
 		if remainingAsk.IsZero() {
+			bestAsk.Status = &OrderCancelledOrFulfilled
 			heap.Pop(ob.Asks)
 		}
 	}
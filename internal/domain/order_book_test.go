@@ -44,7 +44,7 @@ func TestBidFullyMatchedBySingleAsk(t *testing.T) {
 		},
 	}
 	orderBook := setupOrderBook(bids, asks)
-	expectedTrades := []*Trade{{BidId: 1, AskId: 2}}
+	expectedTrades := []*Trade{{BidId: 1, AskId: 2, Quantity: uint256.NewInt(50), SqrtPrice: uint256.NewInt(90)}}
 	trades, err := orderBook.MatchOrders()
 
 	assert.NoError(t, err)
@@ -82,7 +82,7 @@ func TestBidFullyMatchedByMultipleAsks(t *testing.T) {
 		},
 	}
 	orderBook := setupOrderBook(bids, asks)
-	expectedTrades := []*Trade{{BidId: 1, AskId: 3}, {BidId: 1, AskId: 2}}
+	expectedTrades := []*Trade{{BidId: 1, AskId: 3, Quantity: uint256.NewInt(60), SqrtPrice: uint256.NewInt(85)}, {BidId: 1, AskId: 2, Quantity: uint256.NewInt(40), SqrtPrice: uint256.NewInt(90)}}
 	trades, err := orderBook.MatchOrders()
 
 	assert.NoError(t, err)
@@ -120,7 +120,7 @@ func TestBidPartiallyMatched(t *testing.T) {
 		},
 	}
 	orderBook := setupOrderBook(bids, asks)
-	expectedTrades := []*Trade{{BidId: 1, AskId: 2}, {BidId: 1, AskId: 3}}
+	expectedTrades := []*Trade{{BidId: 1, AskId: 2, Quantity: uint256.NewInt(50), SqrtPrice: uint256.NewInt(90)}, {BidId: 1, AskId: 3, Quantity: uint256.NewInt(30), SqrtPrice: uint256.NewInt(100)}}
 	trades, err := orderBook.MatchOrders()
 
 	assert.NoError(t, err)
@@ -150,7 +150,7 @@ func TestAskFullyMatchedBySingleBid(t *testing.T) {
 		},
 	}
 	orderBook := setupOrderBook(bids, asks)
-	expectedTrades := []*Trade{{BidId: 1, AskId: 2}}
+	expectedTrades := []*Trade{{BidId: 1, AskId: 2, Quantity: uint256.NewInt(50), SqrtPrice: uint256.NewInt(100)}}
 	trades, err := orderBook.MatchOrders()
 
 	assert.NoError(t, err)
@@ -188,7 +188,7 @@ func TestAskFullyMatchedByMultipleBids(t *testing.T) {
 		},
 	}
 	orderBook := setupOrderBook(bids, asks)
-	expectedTrades := []*Trade{{BidId: 1, AskId: 3}, {BidId: 2, AskId: 3}}
+	expectedTrades := []*Trade{{BidId: 1, AskId: 3, Quantity: uint256.NewInt(60), SqrtPrice: uint256.NewInt(90)}, {BidId: 2, AskId: 3, Quantity: uint256.NewInt(40), SqrtPrice: uint256.NewInt(90)}}
 	trades, err := orderBook.MatchOrders()
 
 	assert.NoError(t, err)
@@ -234,4 +234,83 @@ func TestAskNoMatchingBid(t *testing.T) {
 	assert.Error(t, err)
 	assert.Nil(t, trades)
 	assert.Equal(t, ErrNoMatch, err)
-}
\ No newline at end of file
+}
+
+func TestFilledOrdersAreSkipped(t *testing.T) {
+	bids := []*Order{
+		{
+			Id:            1,
+			Hook:          testHook,
+			SqrtPrice:     uint256.NewInt(100),
+			Amount:        uint256.NewInt(50),
+			MatchedAmount: uint256.NewInt(0),
+			Type:          &OrderTypeBuy,
+		},
+	}
+	asks := []*Order{
+		{
+			Id:            2,
+			Hook:          testHook,
+			SqrtPrice:     uint256.NewInt(80),
+			Amount:        uint256.NewInt(50),
+			MatchedAmount: uint256.NewInt(50),
+			Type:          &OrderTypeSell,
+		},
+		{
+			Id:            3,
+			Hook:          testHook,
+			SqrtPrice:     uint256.NewInt(90),
+			Amount:        uint256.NewInt(50),
+			MatchedAmount: uint256.NewInt(0),
+			Type:          &OrderTypeSell,
+		},
+	}
+	orderBook := setupOrderBook(bids, asks)
+	expectedTrades := []*Trade{{BidId: 1, AskId: 3, Quantity: uint256.NewInt(50), SqrtPrice: uint256.NewInt(90)}}
+	trades, err := orderBook.MatchOrders()
+
+	assert.NoError(t, err)
+	assert.Equal(t, expectedTrades, trades)
+	assert.Equal(t, OrderCancelledOrFulfilled, *bids[0].Status)
+	assert.Equal(t, OrderCancelledOrFulfilled, *asks[1].Status)
+}
+
+func TestMatchingStopsAtMaxTrades(t *testing.T) {
+	bids := []*Order{
+		{
+			Id:            1,
+			Hook:          testHook,
+			SqrtPrice:     uint256.NewInt(100),
+			Amount:        uint256.NewInt(100),
+			MatchedAmount: uint256.NewInt(0),
+			Type:          &OrderTypeBuy,
+		},
+	}
+	asks := []*Order{
+		{
+			Id:            2,
+			Hook:          testHook,
+			SqrtPrice:     uint256.NewInt(80),
+			Amount:        uint256.NewInt(30),
+			MatchedAmount: uint256.NewInt(0),
+			Type:          &OrderTypeSell,
+		},
+		{
+			Id:            3,
+			Hook:          testHook,
+			SqrtPrice:     uint256.NewInt(90),
+			Amount:        uint256.NewInt(30),
+			MatchedAmount: uint256.NewInt(0),
+			Type:          &OrderTypeSell,
+		},
+	}
+	orderBook := setupOrderBook(bids, asks)
+	orderBook.MaxTrades = 1
+	expectedTrades := []*Trade{{BidId: 1, AskId: 2, Quantity: uint256.NewInt(30), SqrtPrice: uint256.NewInt(80)}}
+	trades, err := orderBook.MatchOrders()
+
+	assert.NoError(t, err)
+	assert.Equal(t, expectedTrades, trades)
+	assert.Equal(t, uint256.NewInt(30), bids[0].MatchedAmount)
+	assert.True(t, asks[1].MatchedAmount.IsZero())
+}
@@ -0,0 +1,29 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueuedOrderOpensAtTheConfirmationDepth(t *testing.T) {
+	order := newTestOrder(1, &OrderTypeBuy, 100, 0)
+	order.Queue(10, 0)
+	assert.False(t, order.IsQueued(), "no depth, nothing to wait for")
+
+	order.Queue(10, 3)
+	assert.True(t, order.IsQueued())
+	assert.Equal(t, &QueuedOrder{Id: 1, Hook: testHook, Type: OrderTypeBuy, QueuedAt: 10, MatchableAt: 13, Reason: QueueReasonConfirmationDepth}, NewQueuedOrder(order, 3))
+
+	assert.False(t, order.Confirm(12, 3))
+	assert.True(t, order.IsQueued())
+	assert.True(t, order.Confirm(13, 3))
+	assert.Equal(t, OrderNotCancelledOrFulfilled, *order.Status)
+	assert.Equal(t, uint64(10), order.QueuedAt)
+	assert.False(t, order.Confirm(14, 3), "only queued orders are opened")
+}
+
+func TestOrderFlagNames(t *testing.T) {
+	assert.Equal(t, []string{}, OrderFlag(0).Names())
+	assert.Equal(t, []string{"immediate_or_cancel", "0x4"}, (OrderFlagImmediateOrCancel | 1<<2).Names())
+}
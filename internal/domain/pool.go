@@ -0,0 +1,85 @@
+package domain
+
+import (
+	"encoding/binary"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/holiman/uint256"
+)
+
+const BPS = 10_000
+
+// PoolKey mirrors the Uniswap v4 PoolKey the hook stores when its pool is initialized
+type PoolKey struct {
+	Currency0   common.Address `json:"currency0"`
+	Currency1   common.Address `json:"currency1"`
+	Fee         uint32         `json:"fee"`
+	TickSpacing int32          `json:"tick_spacing"`
+	Hooks       common.Address `json:"hooks"`
+}
+
+// Id is keccak256(abi.encode(key)), the PoolId of the PoolManager
+func (k *PoolKey) Id() common.Hash {
+	encoded := make([]byte, 5*32)
+	copy(encoded[12:32], k.Currency0.Bytes())
+	copy(encoded[44:64], k.Currency1.Bytes())
+	binary.BigEndian.PutUint32(encoded[92:96], k.Fee)
+	// int24 is sign extended to the whole word
+	if k.TickSpacing < 0 {
+		for i := 96; i < 124; i++ {
+			encoded[i] = 0xff
+		}
+	}
+	binary.BigEndian.PutUint32(encoded[124:128], uint32(k.TickSpacing))
+	copy(encoded[140:160], k.Hooks.Bytes())
+	return crypto.Keccak256Hash(encoded)
+}
+
+type BandMode string
+
+var (
+	// BandModeHold leaves an order out of the match on the book, to be matched
+	// once the spot price comes closer
+	BandModeHold BandMode = "hold"
+	// BandModeReject refunds the order and closes it
+	BandModeReject BandMode = "reject"
+)
+
+// PriceBand bounds the execution price of a match to BandBps basis points of
+// the price around Spot. Prices are sqrtPriceX96, the band is applied to the
+// price they stand for.
+type PriceBand struct {
+	BandBps uint64
+	Mode    BandMode
+	Spot    *uint256.Int
+}
+
+// Compare tells whether sqrtPrice is below (-1), within (0) or above (1) the band
+func (b *PriceBand) Compare(sqrtPrice *uint256.Int) int {
+	price := new(big.Int).Mul(sqrtPrice.ToBig(), sqrtPrice.ToBig())
+	price.Mul(price, big.NewInt(BPS))
+	spot := new(big.Int).Mul(b.Spot.ToBig(), b.Spot.ToBig())
+
+	upper := new(big.Int).Mul(spot, new(big.Int).SetUint64(BPS+b.BandBps))
+	if price.Cmp(upper) > 0 {
+		return 1
+	}
+	if b.BandBps >= BPS {
+		return 0
+	}
+	lower := new(big.Int).Mul(spot, new(big.Int).SetUint64(BPS-b.BandBps))
+	if price.Cmp(lower) < 0 {
+		return -1
+	}
+	return 0
+}
+
+// BandedOrder is an order a price band kept out of a match
+type BandedOrder struct {
+	Id        uint64       `json:"id"`
+	Type      OrderType    `json:"type"`
+	SqrtPrice *uint256.Int `json:"sqrt_price"`
+	Mode      BandMode     `json:"mode"`
+}
@@ -0,0 +1,333 @@
+package domain
+
+import (
+	"errors"
+	"math/big"
+	"math/bits"
+
+	"github.com/holiman/uint256"
+)
+
+// Bounds of the Uniswap v4 TickMath
+const (
+	MIN_TICK = -887272
+	MAX_TICK = 887272
+)
+
+// MAX_SWAP_STEPS caps the ticks a quote walks, each step reads the pool
+// storage, the quote stops where the cap leaves it
+const MAX_SWAP_STEPS = 64
+
+const PIPS = 1_000_000
+
+var (
+	MinSqrtPrice    = big.NewInt(4295128739)
+	MaxSqrtPrice, _ = new(big.Int).SetString("1461446703485210103287273052203988822378723970342", 10)
+
+	q96        = new(big.Int).Lsh(big.NewInt(1), 96)
+	q128       = new(big.Int).Lsh(big.NewInt(1), 128)
+	maxUint256 = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+)
+
+var ErrPriceLimitOutOfRange = errors.New("price limit out of range")
+
+// PoolState is the part of the state of a v4 pool a swap walks through
+type PoolState struct {
+	SqrtPriceX96 *uint256.Int `json:"sqrt_price_x96"`
+	Tick         int32        `json:"tick"`
+	ProtocolFee  uint32       `json:"protocol_fee"`
+	LpFee        uint32       `json:"lp_fee"`
+	Liquidity    *uint256.Int `json:"liquidity"`
+}
+
+// PoolTicks reads the initialized ticks of a pool
+type PoolTicks interface {
+	TickBitmap(wordPos int16) (*uint256.Int, error)
+	LiquidityNet(tick int32) (*big.Int, error)
+}
+
+// PoolQuote is what an exact input swap would take from and give to the
+// swapper, and the state it would leave the pool in
+type PoolQuote struct {
+	AmountIn  *uint256.Int `json:"amount_in"`
+	AmountOut *uint256.Int `json:"amount_out"`
+	State     *PoolState   `json:"state"`
+}
+
+// SwapFee is the fee taken on the input of a swap in pips, the LP fee with
+// the protocol fee of the direction taken first
+func (s *PoolState) SwapFee(zeroForOne bool) uint32 {
+	protocolFee := s.ProtocolFee & 0xfff
+	if !zeroForOne {
+		protocolFee = s.ProtocolFee >> 12
+	}
+	if protocolFee == 0 {
+		return s.LpFee
+	}
+	return protocolFee + s.LpFee - uint32(uint64(protocolFee)*uint64(s.LpFee)/PIPS)
+}
+
+// QuoteExactInput runs the swap math of Pool.swap for amountIn of the input
+// currency, stopping at sqrtPriceLimit. A limit the price is already past
+// quotes nothing.
+func QuoteExactInput(state *PoolState, tickSpacing int32, zeroForOne bool, amountIn, sqrtPriceLimit *uint256.Int, ticks PoolTicks) (*PoolQuote, error) {
+	sqrtPrice := state.SqrtPriceX96.ToBig()
+	limit := sqrtPriceLimit.ToBig()
+	liquidity := state.Liquidity.ToBig()
+	tick := state.Tick
+	fee := state.SwapFee(zeroForOne)
+
+	quote := &PoolQuote{AmountIn: uint256.NewInt(0), AmountOut: uint256.NewInt(0), State: state}
+	if limit.Cmp(MinSqrtPrice) <= 0 || limit.Cmp(MaxSqrtPrice) >= 0 {
+		return nil, ErrPriceLimitOutOfRange
+	}
+	if zeroForOne && limit.Cmp(sqrtPrice) >= 0 || !zeroForOne && limit.Cmp(sqrtPrice) <= 0 {
+		return quote, nil
+	}
+
+	remaining := amountIn.ToBig()
+	out := new(big.Int)
+	for step := 0; step < MAX_SWAP_STEPS && remaining.Sign() > 0 && sqrtPrice.Cmp(limit) != 0; step++ {
+		tickNext, initialized, err := nextInitializedTick(ticks, tick, tickSpacing, zeroForOne)
+		if err != nil {
+			return nil, err
+		}
+		tickNext = max(MIN_TICK, min(MAX_TICK, tickNext))
+		sqrtPriceNextTick := SqrtPriceAtTick(tickNext)
+
+		target := sqrtPriceNextTick
+		if zeroForOne && target.Cmp(limit) < 0 || !zeroForOne && target.Cmp(limit) > 0 {
+			target = limit
+		}
+
+		stepStart := sqrtPrice
+		var stepIn, stepFee, stepOut *big.Int
+		sqrtPrice, stepIn, stepOut, stepFee = swapStep(sqrtPrice, target, liquidity, remaining, fee)
+		remaining.Sub(remaining, new(big.Int).Add(stepIn, stepFee))
+		out.Add(out, stepOut)
+
+		if sqrtPrice.Cmp(sqrtPriceNextTick) == 0 {
+			if initialized {
+				net, err := ticks.LiquidityNet(tickNext)
+				if err != nil {
+					return nil, err
+				}
+				if zeroForOne {
+					net = new(big.Int).Neg(net)
+				}
+				liquidity = new(big.Int).Add(liquidity, net)
+			}
+			tick = tickNext
+			if zeroForOne {
+				tick--
+			}
+		} else if sqrtPrice.Cmp(stepStart) != 0 {
+			tick = TickAtSqrtPrice(sqrtPrice)
+		}
+	}
+
+	quote.AmountIn = uint256.MustFromBig(new(big.Int).Sub(amountIn.ToBig(), remaining))
+	quote.AmountOut = uint256.MustFromBig(out)
+	quote.State = &PoolState{
+		SqrtPriceX96: uint256.MustFromBig(sqrtPrice),
+		Tick:         tick,
+		ProtocolFee:  state.ProtocolFee,
+		LpFee:        state.LpFee,
+		Liquidity:    uint256.MustFromBig(liquidity),
+	}
+	return quote, nil
+}
+
+// swapStep is SwapMath.computeSwapStep for an exact input
+func swapStep(sqrtPrice, target, liquidity, remaining *big.Int, fee uint32) (next, amountIn, amountOut, feeAmount *big.Int) {
+	zeroForOne := sqrtPrice.Cmp(target) >= 0
+	remainingLessFee := mulDiv(remaining, big.NewInt(int64(PIPS-fee)), big.NewInt(PIPS), false)
+
+	if zeroForOne {
+		amountIn = amount0Delta(target, sqrtPrice, liquidity, true)
+	} else {
+		amountIn = amount1Delta(sqrtPrice, target, liquidity, true)
+	}
+
+	if remainingLessFee.Cmp(amountIn) >= 0 {
+		next = target
+		if fee == PIPS {
+			feeAmount = new(big.Int).Set(amountIn)
+		} else {
+			feeAmount = mulDiv(amountIn, big.NewInt(int64(fee)), big.NewInt(int64(PIPS-fee)), true)
+		}
+	} else {
+		amountIn = remainingLessFee
+		if zeroForOne {
+			next = nextSqrtPriceFromAmount0(sqrtPrice, liquidity, amountIn)
+		} else {
+			next = nextSqrtPriceFromAmount1(sqrtPrice, liquidity, amountIn)
+		}
+		feeAmount = new(big.Int).Sub(remaining, amountIn)
+	}
+
+	if zeroForOne {
+		amountOut = amount1Delta(next, sqrtPrice, liquidity, false)
+	} else {
+		amountOut = amount0Delta(sqrtPrice, next, liquidity, false)
+	}
+	return next, amountIn, amountOut, feeAmount
+}
+
+// amount0Delta is SqrtPriceMath.getAmount0Delta, lower and upper sorted
+func amount0Delta(lower, upper, liquidity *big.Int, roundUp bool) *big.Int {
+	numerator1 := new(big.Int).Lsh(liquidity, 96)
+	numerator2 := new(big.Int).Sub(upper, lower)
+	if roundUp {
+		return divRoundingUp(mulDiv(numerator1, numerator2, upper, true), lower)
+	}
+	return new(big.Int).Quo(mulDiv(numerator1, numerator2, upper, false), lower)
+}
+
+// amount1Delta is SqrtPriceMath.getAmount1Delta, lower and upper sorted
+func amount1Delta(lower, upper, liquidity *big.Int, roundUp bool) *big.Int {
+	return mulDiv(liquidity, new(big.Int).Sub(upper, lower), q96, roundUp)
+}
+
+// nextSqrtPriceFromAmount0 is SqrtPriceMath.getNextSqrtPriceFromAmount0RoundingUp adding amount
+func nextSqrtPriceFromAmount0(sqrtPrice, liquidity, amount *big.Int) *big.Int {
+	if amount.Sign() == 0 {
+		return sqrtPrice
+	}
+	numerator1 := new(big.Int).Lsh(liquidity, 96)
+	product := new(big.Int).Mul(amount, sqrtPrice)
+	if product.Cmp(maxUint256) <= 0 {
+		denominator := new(big.Int).Add(numerator1, product)
+		if denominator.Cmp(numerator1) >= 0 && denominator.Cmp(maxUint256) <= 0 {
+			return mulDiv(numerator1, sqrtPrice, denominator, true)
+		}
+	}
+	return divRoundingUp(numerator1, new(big.Int).Add(new(big.Int).Quo(numerator1, sqrtPrice), amount))
+}
+
+// nextSqrtPriceFromAmount1 is SqrtPriceMath.getNextSqrtPriceFromAmount1RoundingDown adding amount
+func nextSqrtPriceFromAmount1(sqrtPrice, liquidity, amount *big.Int) *big.Int {
+	return new(big.Int).Add(sqrtPrice, mulDiv(amount, q96, liquidity, false))
+}
+
+// nextInitializedTick is TickBitmap.nextInitializedTickWithinOneWord
+func nextInitializedTick(ticks PoolTicks, tick, tickSpacing int32, lte bool) (int32, bool, error) {
+	compressed := tick / tickSpacing
+	if tick < 0 && tick%tickSpacing != 0 {
+		compressed--
+	}
+	if !lte {
+		compressed++
+	}
+
+	wordPos := int16(compressed >> 8)
+	bitPos := uint(uint8(compressed & 0xff))
+	word, err := ticks.TickBitmap(wordPos)
+	if err != nil {
+		return 0, false, err
+	}
+
+	if lte {
+		// wraps around to all ones for the last bit
+		mask := new(uint256.Int).Sub(new(uint256.Int).Lsh(uint256.NewInt(1), bitPos+1), uint256.NewInt(1))
+		masked := new(uint256.Int).And(word, mask)
+		if masked.IsZero() {
+			return (compressed - int32(bitPos)) * tickSpacing, false, nil
+		}
+		return (compressed - int32(bitPos) + int32(masked.BitLen()-1)) * tickSpacing, true, nil
+	}
+
+	mask := new(uint256.Int).Not(new(uint256.Int).Sub(new(uint256.Int).Lsh(uint256.NewInt(1), bitPos), uint256.NewInt(1)))
+	masked := new(uint256.Int).And(word, mask)
+	if masked.IsZero() {
+		return (compressed + int32(255-bitPos)) * tickSpacing, false, nil
+	}
+	return (compressed + int32(leastSignificantBit(masked)) - int32(bitPos)) * tickSpacing, true, nil
+}
+
+func leastSignificantBit(x *uint256.Int) int {
+	for i, limb := range x {
+		if limb != 0 {
+			return i*64 + bits.TrailingZeros64(limb)
+		}
+	}
+	return 256
+}
+
+// tickRatios are the Q128 values of 1/sqrt(1.0001)^(2^i) used by TickMath
+var tickRatios = []string{
+	"fffcb933bd6fad37aa2d162d1a594001",
+	"fff97272373d413259a46990580e213a",
+	"fff2e50f5f656932ef12357cf3c7fdcc",
+	"ffe5caca7e10e4e61c3624eaa0941cd0",
+	"ffcb9843d60f6159c9db58835c926644",
+	"ff973b41fa98c081472e6896dfb254c0",
+	"ff2ea16466c96a3843ec78b326b52861",
+	"fe5dee046a99a2a811c461f1969c3053",
+	"fcbe86c7900a88aedcffc83b479aa3a4",
+	"f987a7253ac413176f2b074cf7815e54",
+	"f3392b0822b70005940c7a398e4b70f3",
+	"e7159475a2c29b7443b29c7fa6e889d9",
+	"d097f3bdfd2022b8845ad8f792aa5825",
+	"a9f746462d870fdf8a65dc1f90e061e5",
+	"70d869a156d2a1b890bb3df62baf32f7",
+	"31be135f97d08fd981231505542fcfa6",
+	"9aa508b5b7a84e1c677de54f3e99bc9",
+	"5d6af8dedb81196699c329225ee604",
+	"2216e584f5fa1ea926041bedfe98",
+	"48a170391f7dc42444e8fa2",
+}
+
+// SqrtPriceAtTick is TickMath.getSqrtPriceAtTick
+func SqrtPriceAtTick(tick int32) *big.Int {
+	absTick := tick
+	if absTick < 0 {
+		absTick = -absTick
+	}
+
+	ratio := new(big.Int).Set(q128)
+	for i, hex := range tickRatios {
+		if absTick&(1<<i) == 0 {
+			continue
+		}
+		factor, _ := new(big.Int).SetString(hex, 16)
+		ratio.Rsh(ratio.Mul(ratio, factor), 128)
+	}
+	if tick > 0 {
+		ratio.Quo(maxUint256, ratio)
+	}
+
+	// from Q128 to Q96, rounding up
+	return divRoundingUp(ratio, new(big.Int).Lsh(big.NewInt(1), 32))
+}
+
+// TickAtSqrtPrice is TickMath.getTickAtSqrtPrice, the greatest tick whose
+// price is at most sqrtPrice
+func TickAtSqrtPrice(sqrtPrice *big.Int) int32 {
+	low, high := int32(MIN_TICK), int32(MAX_TICK)
+	for low < high {
+		mid := low + (high-low+1)/2
+		if SqrtPriceAtTick(mid).Cmp(sqrtPrice) <= 0 {
+			low = mid
+		} else {
+			high = mid - 1
+		}
+	}
+	return low
+}
+
+func mulDiv(a, b, denominator *big.Int, roundUp bool) *big.Int {
+	product := new(big.Int).Mul(a, b)
+	if roundUp {
+		return divRoundingUp(product, denominator)
+	}
+	return product.Quo(product, denominator)
+}
+
+func divRoundingUp(a, b *big.Int) *big.Int {
+	quotient, remainder := new(big.Int).QuoRem(a, b, new(big.Int))
+	if remainder.Sign() != 0 {
+		quotient.Add(quotient, big.NewInt(1))
+	}
+	return quotient
+}
@@ -0,0 +1,160 @@
+package domain
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeTicks struct {
+	bitmap map[int16]*uint256.Int
+	net    map[int32]*big.Int
+}
+
+func (f *fakeTicks) TickBitmap(wordPos int16) (*uint256.Int, error) {
+	if word, ok := f.bitmap[wordPos]; ok {
+		return word, nil
+	}
+	return uint256.NewInt(0), nil
+}
+
+func (f *fakeTicks) LiquidityNet(tick int32) (*big.Int, error) {
+	if net, ok := f.net[tick]; ok {
+		return net, nil
+	}
+	return new(big.Int), nil
+}
+
+// initialize flags tick in the bitmap, with liquidityNet net
+func (f *fakeTicks) initialize(tick, tickSpacing int32, net *big.Int) {
+	compressed := tick / tickSpacing
+	wordPos, bitPos := int16(compressed>>8), uint(uint8(compressed&0xff))
+	word, ok := f.bitmap[wordPos]
+	if !ok {
+		word = uint256.NewInt(0)
+	}
+	f.bitmap[wordPos] = new(uint256.Int).Or(word, new(uint256.Int).Lsh(uint256.NewInt(1), bitPos))
+	f.net[tick] = net
+}
+
+func newFakeTicks() *fakeTicks {
+	return &fakeTicks{bitmap: map[int16]*uint256.Int{}, net: map[int32]*big.Int{}}
+}
+
+func TestSqrtPriceAtTickMatchesTheTickMathBounds(t *testing.T) {
+	assert.Equal(t, q96, SqrtPriceAtTick(0))
+	assert.Equal(t, MinSqrtPrice, SqrtPriceAtTick(MIN_TICK))
+	assert.Equal(t, MaxSqrtPrice, SqrtPriceAtTick(MAX_TICK))
+}
+
+func TestSqrtPriceAtTickFollowsTheSquareRootOfOnePointZeroZeroOne(t *testing.T) {
+	for i := 0; i < len(tickRatios); i++ {
+		tick := int32(1) << i
+		if tick > MAX_TICK {
+			tick = MAX_TICK
+		}
+		for _, signed := range []int32{tick, -tick} {
+			expected, _ := new(big.Float).SetPrec(512).SetString("1.0001")
+			expected.Sqrt(expected)
+			power := new(big.Float).SetPrec(512).SetInt64(1)
+			base := new(big.Float).SetPrec(512).Set(expected)
+			if signed < 0 {
+				base.Quo(new(big.Float).SetPrec(512).SetInt64(1), base)
+			}
+			for e := tick; e > 0; e >>= 1 {
+				if e&1 == 1 {
+					power.Mul(power, base)
+				}
+				base.Mul(base, base)
+			}
+			power.Mul(power, new(big.Float).SetInt(q96))
+
+			actual := new(big.Float).SetPrec(512).SetInt(SqrtPriceAtTick(signed))
+			diff := new(big.Float).Sub(actual, power)
+			diff.Quo(diff.Abs(diff), power)
+			assert.True(t, diff.Cmp(big.NewFloat(1e-12)) < 0, "tick %d", signed)
+		}
+	}
+}
+
+func TestTickAtSqrtPriceIsTheGreatestTickBelowThePrice(t *testing.T) {
+	for _, tick := range []int32{MIN_TICK, -60, -1, 0, 1, 60, 123456, MAX_TICK - 1} {
+		price := SqrtPriceAtTick(tick)
+		assert.Equal(t, tick, TickAtSqrtPrice(price))
+		assert.Equal(t, tick, TickAtSqrtPrice(new(big.Int).Add(price, big.NewInt(1))))
+	}
+	assert.Equal(t, int32(-1), TickAtSqrtPrice(new(big.Int).Sub(q96, big.NewInt(1))))
+}
+
+func TestQuoteExactInputWithinOneRange(t *testing.T) {
+	state := &PoolState{
+		SqrtPriceX96: uint256.MustFromBig(q96),
+		Liquidity:    uint256.NewInt(1e18),
+	}
+
+	quote, err := QuoteExactInput(state, 60, true, uint256.NewInt(100), uint256.MustFromBig(SqrtPriceAtTick(-600)), newFakeTicks())
+	require.NoError(t, err)
+	assert.Equal(t, uint256.NewInt(100), quote.AmountIn)
+	assert.Equal(t, uint256.NewInt(99), quote.AmountOut)
+	assert.Equal(t, int32(-1), quote.State.Tick)
+
+	state.LpFee = 3000
+	quote, err = QuoteExactInput(state, 60, false, uint256.NewInt(100), uint256.MustFromBig(SqrtPriceAtTick(600)), newFakeTicks())
+	require.NoError(t, err)
+	assert.Equal(t, uint256.NewInt(100), quote.AmountIn)
+	assert.Equal(t, uint256.NewInt(98), quote.AmountOut)
+	assert.Equal(t, int32(0), quote.State.Tick)
+}
+
+func TestQuoteExactInputStopsAtTheLimit(t *testing.T) {
+	state := &PoolState{
+		SqrtPriceX96: uint256.MustFromBig(q96),
+		Liquidity:    uint256.NewInt(1e18),
+	}
+	limit := SqrtPriceAtTick(-10)
+
+	quote, err := QuoteExactInput(state, 60, true, uint256.NewInt(1e18), uint256.MustFromBig(limit), newFakeTicks())
+	require.NoError(t, err)
+	assert.Equal(t, amount0Delta(limit, q96, big.NewInt(1e18), true), quote.AmountIn.ToBig())
+	assert.Equal(t, limit, quote.State.SqrtPriceX96.ToBig())
+
+	// the price is already past the limit
+	quote, err = QuoteExactInput(state, 60, false, uint256.NewInt(1e18), uint256.MustFromBig(limit), newFakeTicks())
+	require.NoError(t, err)
+	assert.True(t, quote.AmountIn.IsZero())
+
+	_, err = QuoteExactInput(state, 60, true, uint256.NewInt(1e18), uint256.MustFromBig(MinSqrtPrice), newFakeTicks())
+	assert.ErrorIs(t, err, ErrPriceLimitOutOfRange)
+}
+
+func TestQuoteExactInputCrossesInitializedTicks(t *testing.T) {
+	ticks := newFakeTicks()
+	// a single position on [-60, 60]
+	ticks.initialize(-60, 60, big.NewInt(1e18))
+	ticks.initialize(60, 60, big.NewInt(-1e18))
+	state := &PoolState{
+		SqrtPriceX96: uint256.MustFromBig(q96),
+		Liquidity:    uint256.NewInt(1e18),
+	}
+
+	quote, err := QuoteExactInput(state, 60, true, uint256.NewInt(1e18), uint256.MustFromBig(SqrtPriceAtTick(-120)), ticks)
+	require.NoError(t, err)
+	assert.Equal(t, amount0Delta(SqrtPriceAtTick(-60), q96, big.NewInt(1e18), true), quote.AmountIn.ToBig())
+	assert.True(t, quote.State.Liquidity.IsZero())
+	assert.Equal(t, SqrtPriceAtTick(-120), quote.State.SqrtPriceX96.ToBig())
+
+	quote, err = QuoteExactInput(state, 60, false, uint256.NewInt(1e18), uint256.MustFromBig(SqrtPriceAtTick(120)), ticks)
+	require.NoError(t, err)
+	assert.Equal(t, amount1Delta(q96, SqrtPriceAtTick(60), big.NewInt(1e18), true), quote.AmountIn.ToBig())
+	assert.True(t, quote.State.Liquidity.IsZero())
+}
+
+func TestSwapFeeTakesTheProtocolFeeOfTheDirection(t *testing.T) {
+	state := &PoolState{LpFee: 3000, ProtocolFee: 1000 | 500<<12}
+	assert.Equal(t, uint32(3997), state.SwapFee(true))
+	assert.Equal(t, uint32(3499), state.SwapFee(false))
+	assert.Equal(t, uint32(3000), (&PoolState{LpFee: 3000}).SwapFee(true))
+}
@@ -0,0 +1,77 @@
+package domain
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPoolKeyIdIsTheAbiEncodedHash(t *testing.T) {
+	address, _ := abi.NewType("address", "", nil)
+	uint24, _ := abi.NewType("uint24", "", nil)
+	int24, _ := abi.NewType("int24", "", nil)
+	arguments := abi.Arguments{{Type: address}, {Type: address}, {Type: uint24}, {Type: int24}, {Type: address}}
+
+	for _, tickSpacing := range []int32{60, -60} {
+		key := &PoolKey{
+			Currency0:   common.HexToAddress("0xa0"),
+			Currency1:   common.HexToAddress("0xb0"),
+			Fee:         3000,
+			TickSpacing: tickSpacing,
+			Hooks:       testHook,
+		}
+		encoded, err := arguments.Pack(key.Currency0, key.Currency1, big.NewInt(int64(key.Fee)), big.NewInt(int64(key.TickSpacing)), key.Hooks)
+		require.NoError(t, err)
+		assert.Equal(t, crypto.Keccak256Hash(encoded), key.Id())
+	}
+}
+
+func TestPriceBandComparesThePrices(t *testing.T) {
+	// 5% of the price around a sqrt price of 100
+	band := &PriceBand{BandBps: 500, Spot: uint256.NewInt(100)}
+	for sqrtPrice, expected := range map[uint64]int{97: -1, 98: 0, 100: 0, 102: 0, 103: 1} {
+		assert.Equal(t, expected, band.Compare(uint256.NewInt(sqrtPrice)), "sqrt price %d", sqrtPrice)
+	}
+
+	wide := &PriceBand{BandBps: 20_000, Spot: uint256.NewInt(100)}
+	assert.Equal(t, 0, wide.Compare(uint256.NewInt(1)))
+	assert.Equal(t, 1, wide.Compare(uint256.NewInt(174)))
+}
+
+func TestOrdersOutsideThePriceBandAreLeftOut(t *testing.T) {
+	order := func(id, sqrtPrice, amount uint64, orderType *OrderType) *Order {
+		o := newTestOrder(id, orderType, amount, 0)
+		o.SqrtPrice = uint256.NewInt(sqrtPrice)
+		return o
+	}
+	band := &PriceBand{BandBps: 500, Spot: uint256.NewInt(100)}
+
+	// the first ask sells far below spot
+	orderBook := setupOrderBook(
+		[]*Order{order(1, 120, 10, &OrderTypeBuy), order(2, 101, 10, &OrderTypeBuy)},
+		[]*Order{order(3, 90, 10, &OrderTypeSell), order(4, 100, 20, &OrderTypeSell)},
+	)
+	orderBook.Band = band
+	trades, err := orderBook.MatchOrders()
+	require.NoError(t, err)
+	assert.Equal(t, []*Trade{
+		{BidId: 1, AskId: 4, Quantity: uint256.NewInt(10), SqrtPrice: uint256.NewInt(100)},
+		{BidId: 2, AskId: 4, Quantity: uint256.NewInt(10), SqrtPrice: uint256.NewInt(100)},
+	}, trades)
+	require.Len(t, orderBook.Banded, 1)
+	assert.Equal(t, uint64(3), orderBook.Banded[0].Id)
+
+	// the bid would buy far above spot
+	orderBook = setupOrderBook([]*Order{order(1, 200, 10, &OrderTypeBuy)}, []*Order{order(2, 110, 10, &OrderTypeSell)})
+	orderBook.Band = band
+	_, err = orderBook.MatchOrders()
+	assert.ErrorIs(t, err, ErrNoMatch)
+	require.Len(t, orderBook.Banded, 1)
+	assert.Equal(t, OrderTypeBuy, *orderBook.Banded[0].Type)
+}
@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/holiman/uint256"
+)
+
+const PRICE_PRECISION = 256
+
+var (
+	ErrInvalidPrice = errors.New("invalid price")
+	Q96             = new(big.Int).Lsh(big.NewInt(1), 96)
+)
+
+// SqrtPriceX96ToPrice turns a Uniswap sqrtPriceX96 into the human price of
+// currency0 in currency1: (sqrtPriceX96 / 2^96)^2 scaled by the decimals.
+func SqrtPriceX96ToPrice(sqrtPriceX96 *uint256.Int, decimals0, decimals1 uint8) *big.Float {
+	sqrtPrice := new(big.Float).SetPrec(PRICE_PRECISION).SetInt(sqrtPriceX96.ToBig())
+	sqrtPrice.Quo(sqrtPrice, new(big.Float).SetInt(Q96))
+
+	price := new(big.Float).SetPrec(PRICE_PRECISION).Mul(sqrtPrice, sqrtPrice)
+	return price.Mul(price, decimalsScale(int(decimals0)-int(decimals1)))
+}
+
+// PriceToSqrtPriceX96 is the inverse of SqrtPriceX96ToPrice, rounded down.
+func PriceToSqrtPriceX96(price *big.Float, decimals0, decimals1 uint8) (*uint256.Int, error) {
+	if price.Sign() <= 0 {
+		return nil, ErrInvalidPrice
+	}
+
+	raw := new(big.Float).SetPrec(PRICE_PRECISION).Mul(price, decimalsScale(int(decimals1)-int(decimals0)))
+	sqrtPrice := new(big.Float).SetPrec(PRICE_PRECISION).Sqrt(raw)
+	sqrtPrice.Mul(sqrtPrice, new(big.Float).SetInt(Q96))
+
+	sqrtPriceX96, _ := sqrtPrice.Int(nil)
+	result, overflow := uint256.FromBig(sqrtPriceX96)
+	if overflow || result.IsZero() {
+		return nil, ErrInvalidPrice
+	}
+	return result, nil
+}
+
+func decimalsScale(exponent int) *big.Float {
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(exponent))), nil)
+	if exponent < 0 {
+		return new(big.Float).SetPrec(PRICE_PRECISION).Quo(big.NewFloat(1), new(big.Float).SetInt(scale))
+	}
+	return new(big.Float).SetPrec(PRICE_PRECISION).SetInt(scale)
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
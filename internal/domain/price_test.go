@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSqrtPriceX96ToPrice(t *testing.T) {
+	one := uint256.MustFromBig(Q96)
+	assert.Equal(t, "1", SqrtPriceX96ToPrice(one, 18, 18).Text('g', 10))
+
+	// twice the square root is four times the price
+	two := new(uint256.Int).Lsh(one, 1)
+	assert.Equal(t, "4", SqrtPriceX96ToPrice(two, 18, 18).Text('g', 10))
+
+	// 1 wei of an 18 decimals currency0 for 1 unit of a 6 decimals currency1 is 10^12 human
+	assert.Equal(t, "1e+12", SqrtPriceX96ToPrice(one, 18, 6).Text('g', 10))
+}
+
+func TestPriceToSqrtPriceX96RoundTrips(t *testing.T) {
+	sqrtPriceX96, err := PriceToSqrtPriceX96(big.NewFloat(2500), 18, 6)
+	assert.NoError(t, err)
+	price, _ := SqrtPriceX96ToPrice(sqrtPriceX96, 18, 6).Float64()
+	assert.InDelta(t, 2500, price, 1e-9)
+
+	sqrtPriceX96, err = PriceToSqrtPriceX96(big.NewFloat(1), 18, 18)
+	assert.NoError(t, err)
+	assert.Equal(t, uint256.MustFromBig(Q96), sqrtPriceX96)
+
+	_, err = PriceToSqrtPriceX96(big.NewFloat(0), 18, 18)
+	assert.ErrorIs(t, err, ErrInvalidPrice)
+}
@@ -0,0 +1,76 @@
+package domain
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/holiman/uint256"
+)
+
+// PendingFill is a fill sent in a settlement notice that the hook has not been
+// seen consuming yet. BidMatched and AskMatched are the matched amounts the
+// orders were left with by the match, the ones the chain shows once every
+// fill up to this one is executed.
+type PendingFill struct {
+	FillId     common.Hash    `json:"fill_id"`
+	Hook       common.Address `json:"hook"`
+	BidId      uint64         `json:"bid_id"`
+	AskId      uint64         `json:"ask_id"`
+	Quantity   *uint256.Int   `json:"quantity"`
+	BidMatched *uint256.Int   `json:"bid_matched"`
+	AskMatched *uint256.Int   `json:"ask_matched"`
+	// SentAt is the block number of the input that sent the notice
+	SentAt uint64 `json:"sent_at"`
+	// ExpiryBlock is the last block the hook executes the fill in
+	ExpiryBlock uint64 `json:"expiry_block"`
+}
+
+// PendingPoolSwap is a swap of the remainder of an order through the pool that
+// the chain has not been seen executing yet. Matched is the matched amount the
+// order was left with by the match, the one the chain shows once the swap and
+// the fills sent before it are executed.
+type PendingPoolSwap struct {
+	Hook      common.Address `json:"hook"`
+	TaskId    common.Hash    `json:"task_id"`
+	OrderType OrderType      `json:"order_type"`
+	OrderId   uint64         `json:"order_id"`
+	AmountIn  *uint256.Int   `json:"amount_in"`
+	Matched   *uint256.Int   `json:"matched"`
+	// SentAt is the block number of the input that sent the swap
+	SentAt uint64 `json:"sent_at"`
+	// ExpiryBlock is the last block the hook executes the swap in
+	ExpiryBlock uint64 `json:"expiry_block"`
+}
+
+// Is tells whether other is the swap of the same order sent by the same task
+func (s *PendingPoolSwap) Is(other *PendingPoolSwap) bool {
+	return s.Hook == other.Hook && s.TaskId == other.TaskId && s.OrderType == other.OrderType && s.OrderId == other.OrderId
+}
+
+type SettlementRepository interface {
+	CreatePendingFill(fill *PendingFill) error
+	// FindPendingFills returns the pending fills of hook in the order they were sent
+	FindPendingFills(hook common.Address) ([]*PendingFill, error)
+	DeletePendingFill(fillId common.Hash) error
+	CountPendingFills() (int, error)
+	CreatePendingPoolSwap(swap *PendingPoolSwap) error
+	// FindPendingPoolSwaps returns the pending swaps of hook in the order they were sent
+	FindPendingPoolSwaps(hook common.Address) ([]*PendingPoolSwap, error)
+	// UpdatePendingPoolSwap replaces the pending swap of the same order and task
+	UpdatePendingPoolSwap(swap *PendingPoolSwap) error
+	DeletePendingPoolSwap(swap *PendingPoolSwap) error
+	CountPendingPoolSwaps() (int, error)
+}
+
+// ReleaseFill gives the quantity of a fill that never landed back to the
+// order and reopens it when something is left to match. A release never takes
+// the order below zero, the chain has the last word when the book is synced.
+func ReleaseFill(order *Order, quantity *uint256.Int) {
+	if order.MatchedAmount.Cmp(quantity) <= 0 {
+		order.MatchedAmount = new(uint256.Int)
+	} else {
+		order.MatchedAmount = new(uint256.Int).Sub(order.MatchedAmount, quantity)
+	}
+	if order.IsTerminal() && !order.Remaining().IsZero() {
+		order.Status = &OrderNotCancelledOrFulfilled
+		order.TerminalSince = 0
+	}
+}
@@ -0,0 +1,24 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReleaseFillReopensTheOrder(t *testing.T) {
+	order, err := NewOrder(1, common.HexToAddress("0xaa"), uint256.NewInt(10), uint256.NewInt(100), uint256.NewInt(100), &OrderTypeSell, &OrderCancelledOrFulfilled)
+	require.NoError(t, err)
+	order.TerminalSince = 7
+
+	ReleaseFill(order, uint256.NewInt(60))
+	assert.Equal(t, uint256.NewInt(40), order.MatchedAmount)
+	assert.False(t, order.IsTerminal())
+	assert.Zero(t, order.TerminalSince)
+
+	ReleaseFill(order, uint256.NewInt(60))
+	assert.True(t, order.MatchedAmount.IsZero())
+}
@@ -0,0 +1,119 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/holiman/uint256"
+)
+
+// OrderState is the canonical form of an order: fixed field order and amounts as
+// decimal strings, so equal books encode to equal bytes. The account and the
+// queued block are the only optional fields, left out when unknown or unused so
+// the books that never have them keep their hash.
+type OrderState struct {
+	Type          OrderType       `json:"type"`
+	Id            uint64          `json:"id"`
+	Hook          common.Address  `json:"hook"`
+	Account       *common.Address `json:"account,omitempty"`
+	SqrtPrice     string          `json:"sqrt_price"`
+	Amount        string          `json:"amount"`
+	MatchedAmount string          `json:"matched_amount"`
+	Status        OrderStatus     `json:"status"`
+	Flags         OrderFlag       `json:"flags"`
+	TerminalSince uint64          `json:"terminal_since"`
+	QueuedAt      uint64          `json:"queued_at,omitempty"`
+}
+
+func NewOrderState(order *Order) OrderState {
+	state := OrderState{
+		Id:            order.Id,
+		Hook:          order.Hook,
+		SqrtPrice:     order.SqrtPrice.Dec(),
+		Amount:        order.Amount.Dec(),
+		MatchedAmount: order.MatchedAmount.Dec(),
+		Flags:         order.Flags,
+		TerminalSince: order.TerminalSince,
+		QueuedAt:      order.QueuedAt,
+	}
+	if order.Account != (common.Address{}) {
+		account := order.Account
+		state.Account = &account
+	}
+	if order.Type != nil {
+		state.Type = *order.Type
+	}
+	if order.Status != nil {
+		state.Status = *order.Status
+	}
+	return state
+}
+
+// Order rebuilds the order, validating it the same way as a new one
+func (s OrderState) Order() (*Order, error) {
+	if s.Type != OrderTypeBuy && s.Type != OrderTypeSell {
+		return nil, fmt.Errorf("order %d has an unknown type %q: %w", s.Id, s.Type, ErrInvalidOrder)
+	}
+	if s.Status != OrderCancelledOrFulfilled && s.Status != OrderNotCancelledOrFulfilled && s.Status != OrderQueued {
+		return nil, fmt.Errorf("order %d has an unknown status %q: %w", s.Id, s.Status, ErrInvalidOrder)
+	}
+
+	amounts := make([]*uint256.Int, 3)
+	for i, value := range []string{s.SqrtPrice, s.Amount, s.MatchedAmount} {
+		amount, err := uint256.FromDecimal(value)
+		if err != nil {
+			return nil, fmt.Errorf("order %d has an invalid amount %q: %w", s.Id, value, ErrInvalidOrder)
+		}
+		amounts[i] = amount
+	}
+
+	orderType, status := s.Type, s.Status
+	order, err := NewOrder(s.Id, s.Hook, amounts[0], amounts[1], amounts[2], &orderType, &status)
+	if err != nil {
+		return nil, err
+	}
+	if s.Account != nil {
+		order.Account = *s.Account
+	}
+	order.Flags = s.Flags
+	order.TerminalSince = s.TerminalSince
+	order.QueuedAt = s.QueuedAt
+	return order, nil
+}
+
+// CanonicalState encodes the book with buy orders before sell orders, each by
+// ascending id and then hook, whatever order the orders are given in.
+func CanonicalState(orders []*Order) ([]byte, error) {
+	return json.Marshal(CanonicalOrderStates(orders))
+}
+
+func CanonicalOrderStates(orders []*Order) []OrderState {
+	states := make([]OrderState, 0, len(orders))
+	for _, order := range orders {
+		states = append(states, NewOrderState(order))
+	}
+	sort.Slice(states, func(i, j int) bool {
+		if states[i].Type != states[j].Type {
+			return states[i].Type == OrderTypeBuy
+		}
+		if states[i].Id != states[j].Id {
+			return states[i].Id < states[j].Id
+		}
+		return bytes.Compare(states[i].Hook[:], states[j].Hook[:]) < 0
+	})
+	return states
+}
+
+// StateHash is the keccak256 of the canonical state, operators that processed
+// the same inputs must agree on it.
+func StateHash(orders []*Order) (common.Hash, error) {
+	data, err := CanonicalState(orders)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return crypto.Keccak256Hash(data), nil
+}
@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStateHashIgnoresOrderOfOrders(t *testing.T) {
+	newOrder := func(id uint64, orderType *OrderType) *Order {
+		return &Order{
+			Id:            id,
+			Hook:          testHook,
+			SqrtPrice:     uint256.NewInt(100),
+			Amount:        uint256.NewInt(50),
+			MatchedAmount: uint256.NewInt(0),
+			Type:          orderType,
+			Status:        &OrderNotCancelledOrFulfilled,
+		}
+	}
+	buy, sell, otherSell := newOrder(1, &OrderTypeBuy), newOrder(1, &OrderTypeSell), newOrder(2, &OrderTypeSell)
+
+	first, err := StateHash([]*Order{buy, sell, otherSell})
+	require.NoError(t, err)
+	second, err := StateHash([]*Order{otherSell, sell, buy})
+	require.NoError(t, err)
+	assert.Equal(t, first, second)
+
+	otherSell.MatchedAmount = uint256.NewInt(1)
+	third, err := StateHash([]*Order{buy, sell, otherSell})
+	require.NoError(t, err)
+	assert.NotEqual(t, first, third)
+}
+
+func TestOrderStateLeavesOutAnUnknownAccount(t *testing.T) {
+	order, err := NewOrder(1, testHook, uint256.NewInt(100), uint256.NewInt(50), uint256.NewInt(0), &OrderTypeBuy, &OrderNotCancelledOrFulfilled)
+	require.NoError(t, err)
+	data, err := CanonicalState([]*Order{order})
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "account")
+
+	order.Account = common.HexToAddress("0x0000000000000000000000000000000000000b01")
+	restored, err := NewOrderState(order).Order()
+	require.NoError(t, err)
+	assert.Equal(t, order.Account, restored.Account)
+}
@@ -0,0 +1,65 @@
+package domain
+
+import "sort"
+
+// TombstoneSet remembers the ids of pruned orders in little memory. The hook
+// hands out ids in sequence and orders mostly end in that order too, so every
+// id up to the floor is pruned and only the ids above it are kept one by one.
+type TombstoneSet struct {
+	floor uint64
+	ids   map[uint64]struct{}
+}
+
+func NewTombstoneSet() *TombstoneSet {
+	return &TombstoneSet{ids: make(map[uint64]struct{})}
+}
+
+// RestoreTombstoneSet rebuilds a set from the values returned by Floor and Ids
+func RestoreTombstoneSet(floor uint64, ids []uint64) *TombstoneSet {
+	set := &TombstoneSet{floor: floor, ids: make(map[uint64]struct{})}
+	for _, id := range ids {
+		set.Add(id)
+	}
+	return set
+}
+
+func (s *TombstoneSet) Add(id uint64) {
+	if id <= s.floor {
+		return
+	}
+	s.ids[id] = struct{}{}
+	for {
+		if _, ok := s.ids[s.floor+1]; !ok {
+			return
+		}
+		delete(s.ids, s.floor+1)
+		s.floor++
+	}
+}
+
+func (s *TombstoneSet) Contains(id uint64) bool {
+	if id <= s.floor {
+		return true
+	}
+	_, ok := s.ids[id]
+	return ok
+}
+
+// Len is the number of pruned ids, not the memory taken by them
+func (s *TombstoneSet) Len() uint64 {
+	return s.floor + uint64(len(s.ids))
+}
+
+func (s *TombstoneSet) Floor() uint64 {
+	return s.floor
+}
+
+// Ids lists the pruned ids above the floor in ascending order
+func (s *TombstoneSet) Ids() []uint64 {
+	ids := make([]uint64, 0, len(s.ids))
+	for id := range s.ids {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
@@ -0,0 +1,30 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTombstoneSetCompactsIntoTheFloor(t *testing.T) {
+	set := NewTombstoneSet()
+	for _, id := range []uint64{2, 5, 1, 3} {
+		set.Add(id)
+	}
+
+	assert.Equal(t, uint64(3), set.Floor())
+	assert.Equal(t, []uint64{5}, set.Ids())
+	assert.Equal(t, uint64(4), set.Len())
+	assert.True(t, set.Contains(2))
+	assert.True(t, set.Contains(5))
+	assert.False(t, set.Contains(4))
+
+	set.Add(4)
+	assert.Equal(t, uint64(5), set.Floor())
+	assert.Empty(t, set.Ids())
+
+	restored := RestoreTombstoneSet(set.Floor(), []uint64{7})
+	assert.True(t, restored.Contains(3))
+	assert.True(t, restored.Contains(7))
+	assert.False(t, restored.Contains(6))
+}
@@ -0,0 +1,499 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/henriquemarlon/swapx/configs"
+	"github.com/henriquemarlon/swapx/internal/domain"
+	"github.com/henriquemarlon/swapx/internal/infra/repository"
+	"github.com/holiman/uint256"
+)
+
+const (
+	DEFAULT_PAGE_LIMIT   = 50
+	MAX_PAGE_LIMIT       = 500
+	DEFAULT_DEPTH_LEVELS = 20
+	STREAM_KEEP_ALIVE    = 15 * time.Second
+)
+
+// Error codes, along with the HTTP status they are sent with
+const (
+	ERR_INVALID_ARGUMENT   = "invalid_argument"
+	ERR_NOT_FOUND          = "not_found"
+	ERR_METHOD_NOT_ALLOWED = "method_not_allowed"
+	ERR_INTERNAL           = "internal"
+)
+
+var errorStatus = map[string]int{
+	ERR_INVALID_ARGUMENT:   http.StatusBadRequest,
+	ERR_NOT_FOUND:          http.StatusNotFound,
+	ERR_METHOD_NOT_ALLOWED: http.StatusMethodNotAllowed,
+	ERR_INTERNAL:           http.StatusInternalServerError,
+}
+
+// Pair is a hook along with the decimals of its currencies, which the hook does
+// not expose and are only used to render human prices.
+type Pair struct {
+	Hook      common.Address
+	Decimals0 uint8
+	Decimals1 uint8
+}
+
+// Market is the indexed book of one pair
+type Market struct {
+	Pair   Pair
+	Orders domain.OrderRepository
+	Fills  domain.FillRepository
+	Cursor domain.CursorRepository
+	// Stream has to be set as the publisher of the indexer of the pair
+	Stream *Stream
+}
+
+func NewMarket(pair Pair, db *configs.InMemoryDB) *Market {
+	orders := repository.NewOrderRepositoryInMemory(db)
+	return &Market{
+		Pair:   pair,
+		Orders: orders,
+		Fills:  repository.NewFillRepositoryInMemory(db),
+		Cursor: repository.NewCursorRepositoryInMemory(db),
+		Stream: NewStream(pair, orders),
+	}
+}
+
+// Server answers read-only queries over the books kept by the indexer
+type Server struct {
+	Markets map[common.Address]*Market
+	hooks   []common.Address
+}
+
+func NewServer(markets ...*Market) *Server {
+	s := &Server{Markets: make(map[common.Address]*Market, len(markets))}
+	for _, market := range markets {
+		s.Markets[market.Pair.Hook] = market
+		s.hooks = append(s.hooks, market.Pair.Hook)
+	}
+	return s
+}
+
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /pairs", s.handleListPairs)
+	mux.HandleFunc("GET /pairs/{hook}", s.withMarket(s.handleGetPair))
+	mux.HandleFunc("GET /pairs/{hook}/depth", s.withMarket(s.handleDepth))
+	mux.HandleFunc("GET /pairs/{hook}/orders", s.withMarket(s.handleListOrders))
+	mux.HandleFunc("GET /pairs/{hook}/orders/{side}/{id}", s.withMarket(s.handleGetOrder))
+	mux.HandleFunc("GET /pairs/{hook}/trades", s.withMarket(s.handleListTrades))
+	mux.HandleFunc("GET /pairs/{hook}/candles", s.withMarket(s.handleListCandles))
+	mux.HandleFunc("GET /pairs/{hook}/stats", s.withMarket(s.handleStats))
+	mux.HandleFunc("GET /pairs/{hook}/stream", s.withMarket(s.handleStream))
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeError(w, ERR_METHOD_NOT_ALLOWED, "only GET is supported")
+			return
+		}
+		writeError(w, ERR_NOT_FOUND, fmt.Sprintf("no route for %s", r.URL.Path))
+	})
+	return mux
+}
+
+func (s *Server) withMarket(handle func(w http.ResponseWriter, r *http.Request, market *Market)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		hook := r.PathValue("hook")
+		if !common.IsHexAddress(hook) {
+			writeError(w, ERR_INVALID_ARGUMENT, fmt.Sprintf("invalid hook address %q", hook))
+			return
+		}
+		market, ok := s.Markets[common.HexToAddress(hook)]
+		if !ok {
+			writeError(w, ERR_NOT_FOUND, fmt.Sprintf("pair %s is not indexed", hook))
+			return
+		}
+		handle(w, r, market)
+	}
+}
+
+func (s *Server) handleListPairs(w http.ResponseWriter, r *http.Request) {
+	pairs := make([]pairView, 0, len(s.hooks))
+	for _, hook := range s.hooks {
+		pair, err := newPairView(s.Markets[hook])
+		if err != nil {
+			writeInternalError(w, err)
+			return
+		}
+		pairs = append(pairs, *pair)
+	}
+	writeJSON(w, http.StatusOK, pairs)
+}
+
+func (s *Server) handleGetPair(w http.ResponseWriter, r *http.Request, market *Market) {
+	pair, err := newPairView(market)
+	if err != nil {
+		writeInternalError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, pair)
+}
+
+func (s *Server) handleDepth(w http.ResponseWriter, r *http.Request, market *Market) {
+	levels, err := intParam(r, "levels", DEFAULT_DEPTH_LEVELS, 1, MAX_PAGE_LIMIT)
+	if err != nil {
+		writeError(w, ERR_INVALID_ARGUMENT, err.Error())
+		return
+	}
+	orders, err := openOrders(market)
+	if err != nil {
+		writeInternalError(w, err)
+		return
+	}
+	cursor, err := market.Cursor.FindCursor()
+	if err != nil {
+		writeInternalError(w, err)
+		return
+	}
+
+	depth := depthView{
+		Hook:        market.Pair.Hook,
+		BlockNumber: cursor.BlockNumber,
+		BlockHash:   cursor.BlockHash,
+		Bids:        aggregateLevels(market.Pair, orders, domain.OrderTypeBuy, levels),
+		Asks:        aggregateLevels(market.Pair, orders, domain.OrderTypeSell, levels),
+	}
+	writeJSON(w, http.StatusOK, depth)
+}
+
+func (s *Server) handleListOrders(w http.ResponseWriter, r *http.Request, market *Market) {
+	offset, limit, err := pageParams(r)
+	if err != nil {
+		writeError(w, ERR_INVALID_ARGUMENT, err.Error())
+		return
+	}
+
+	query := r.URL.Query()
+	var account *common.Address
+	if value := query.Get("account"); value != "" {
+		if !common.IsHexAddress(value) {
+			writeError(w, ERR_INVALID_ARGUMENT, fmt.Sprintf("invalid account %q", value))
+			return
+		}
+		address := common.HexToAddress(value)
+		account = &address
+	}
+	var side *domain.OrderType
+	if value := query.Get("side"); value != "" {
+		orderType, err := parseSide(value)
+		if err != nil {
+			writeError(w, ERR_INVALID_ARGUMENT, err.Error())
+			return
+		}
+		side = &orderType
+	}
+	status := query.Get("status")
+	if status == "" {
+		status = "open"
+	}
+	if status != "open" && status != "closed" && status != "all" {
+		writeError(w, ERR_INVALID_ARGUMENT, fmt.Sprintf("invalid status %q, use open, closed or all", status))
+		return
+	}
+
+	orders, err := market.Orders.FindAllOrders()
+	if err != nil && !errors.Is(err, domain.ErrNoOrdersFound) {
+		writeInternalError(w, err)
+		return
+	}
+	views := []orderView{}
+	for _, order := range orders {
+		if account != nil && order.Account != *account {
+			continue
+		}
+		if side != nil && *order.Type != *side {
+			continue
+		}
+		open := *order.Status == domain.OrderNotCancelledOrFulfilled
+		if status == "open" && !open || status == "closed" && open {
+			continue
+		}
+		views = append(views, newOrderView(market.Pair, order))
+	}
+	writePage(w, views, offset, limit)
+}
+
+func (s *Server) handleGetOrder(w http.ResponseWriter, r *http.Request, market *Market) {
+	side, err := parseSide(r.PathValue("side"))
+	if err != nil {
+		writeError(w, ERR_INVALID_ARGUMENT, err.Error())
+		return
+	}
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+	if err != nil || id == 0 {
+		writeError(w, ERR_INVALID_ARGUMENT, fmt.Sprintf("invalid order id %q, ids start at 1", r.PathValue("id")))
+		return
+	}
+
+	order, err := market.Orders.FindOrderById(market.Pair.Hook, side, id)
+	if errors.Is(err, domain.ErrOrderNotFound) {
+		writeError(w, ERR_NOT_FOUND, fmt.Sprintf("%s order %d not found", side, id))
+		return
+	}
+	if err != nil {
+		writeInternalError(w, err)
+		return
+	}
+	fills, err := market.Fills.FindFillsByOrder(side, id)
+	if err != nil {
+		writeInternalError(w, err)
+		return
+	}
+
+	detail := orderDetailView{orderView: newOrderView(market.Pair, order), Fills: make([]tradeView, 0, len(fills))}
+	for _, fill := range fills {
+		detail.Fills = append(detail.Fills, newTradeView(market.Pair, fill))
+	}
+	writeJSON(w, http.StatusOK, detail)
+}
+
+// handleListTrades lists the fills of the pair, the most recent first
+func (s *Server) handleListTrades(w http.ResponseWriter, r *http.Request, market *Market) {
+	offset, limit, err := pageParams(r)
+	if err != nil {
+		writeError(w, ERR_INVALID_ARGUMENT, err.Error())
+		return
+	}
+	fills, err := market.Fills.FindFills()
+	if err != nil {
+		writeInternalError(w, err)
+		return
+	}
+
+	trades := make([]tradeView, 0, len(fills))
+	for i := len(fills) - 1; i >= 0; i-- {
+		trades = append(trades, newTradeView(market.Pair, fills[i]))
+	}
+	writePage(w, trades, offset, limit)
+}
+
+// handleListCandles lists the candles of an interval starting from from to to,
+// the oldest first
+func (s *Server) handleListCandles(w http.ResponseWriter, r *http.Request, market *Market) {
+	interval, err := domain.ParseCandleInterval(r.URL.Query().Get("interval"))
+	if err != nil {
+		writeError(w, ERR_INVALID_ARGUMENT, err.Error())
+		return
+	}
+	from, err := intParam(r, "from", 0, 0, math.MaxInt)
+	if err != nil {
+		writeError(w, ERR_INVALID_ARGUMENT, err.Error())
+		return
+	}
+	to, err := intParam(r, "to", math.MaxInt, 0, math.MaxInt)
+	if err != nil {
+		writeError(w, ERR_INVALID_ARGUMENT, err.Error())
+		return
+	}
+	candles, err := marketCandles(market)
+	if err != nil {
+		writeInternalError(w, err)
+		return
+	}
+
+	view := candlesView{Hook: market.Pair.Hook, Interval: interval, Candles: []candleView{}}
+	for _, candle := range candles.Find(interval, uint64(from), uint64(to)) {
+		view.Candles = append(view.Candles, newCandleView(market.Pair, candle))
+	}
+	writeJSON(w, http.StatusOK, view)
+}
+
+// handleStats summarizes the trades of the day up to at, now by default
+func (s *Server) handleStats(w http.ResponseWriter, r *http.Request, market *Market) {
+	at, err := intParam(r, "at", int(time.Now().Unix()), 0, math.MaxInt)
+	if err != nil {
+		writeError(w, ERR_INVALID_ARGUMENT, err.Error())
+		return
+	}
+	candles, err := marketCandles(market)
+	if err != nil {
+		writeInternalError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newStatsView(market.Pair, candles.Stats(uint64(at))))
+}
+
+// handleStream sends a snapshot of the book and then its changes as they are
+// indexed, as server-sent events named after the messages
+func (s *Server) handleStream(w http.ResponseWriter, r *http.Request, market *Market) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeInternalError(w, errors.New("the connection does not support streaming"))
+		return
+	}
+	snapshot, messages, unsubscribe := market.Stream.Subscribe()
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	if err := writeEvent(w, snapshot); err != nil {
+		return
+	}
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(STREAM_KEEP_ALIVE)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		case message, ok := <-messages:
+			if !ok {
+				// fell behind, the client has to subscribe again
+				return
+			}
+			if err := writeEvent(w, message); err != nil {
+				return
+			}
+		}
+		flusher.Flush()
+	}
+}
+
+func openOrders(market *Market) ([]*domain.Order, error) {
+	orders := []*domain.Order{}
+	for _, orderType := range []domain.OrderType{domain.OrderTypeBuy, domain.OrderTypeSell} {
+		found, err := market.Orders.FindOrdersByTypeAndStatus(market.Pair.Hook, orderType, domain.OrderNotCancelledOrFulfilled)
+		if err != nil && !errors.Is(err, domain.ErrNoOrdersFound) {
+			return nil, err
+		}
+		orders = append(orders, found...)
+	}
+	return orders, nil
+}
+
+// aggregateLevels sums the remaining amounts of the open orders of one side by
+// sqrtPrice, best price first: highest for bids and lowest for asks.
+// marketCandles aggregates the fills of the sell orders, one per trade, which
+// carry the price the trade was made at
+func marketCandles(market *Market) (*domain.Candles, error) {
+	fills, err := market.Fills.FindFills()
+	if err != nil {
+		return nil, err
+	}
+	candles := domain.NewCandles(0)
+	for _, fill := range fills {
+		if fill.OrderType != domain.OrderTypeSell {
+			continue
+		}
+		candles.Add(domain.PricedTrade{SqrtPrice: fill.SqrtPrice, Quantity: fill.Quantity, Timestamp: fill.Timestamp})
+	}
+	return candles, nil
+}
+
+func aggregateLevels(pair Pair, orders []*domain.Order, orderType domain.OrderType, limit int) []levelView {
+	byPrice := make(map[uint256.Int]*levelView)
+	prices := []*uint256.Int{}
+	for _, order := range orders {
+		if *order.Type != orderType || order.Remaining().IsZero() {
+			continue
+		}
+		level, ok := byPrice[*order.SqrtPrice]
+		if !ok {
+			level = &levelView{priceView: newPriceView(pair, order.SqrtPrice), sqrtPrice: order.SqrtPrice, quantity: new(uint256.Int)}
+			byPrice[*order.SqrtPrice] = level
+			prices = append(prices, order.SqrtPrice)
+		}
+		level.quantity.Add(level.quantity, order.Remaining())
+		level.Orders++
+	}
+
+	sort.Slice(prices, func(i, j int) bool {
+		if orderType == domain.OrderTypeBuy {
+			return prices[i].Gt(prices[j])
+		}
+		return prices[i].Lt(prices[j])
+	})
+	levels := make([]levelView, 0, min(limit, len(prices)))
+	for _, price := range prices[:min(limit, len(prices))] {
+		level := byPrice[*price]
+		level.Quantity = level.quantity.Dec()
+		levels = append(levels, *level)
+	}
+	return levels
+}
+
+func parseSide(value string) (domain.OrderType, error) {
+	switch orderType := domain.OrderType(value); orderType {
+	case domain.OrderTypeBuy, domain.OrderTypeSell:
+		return orderType, nil
+	default:
+		return "", fmt.Errorf("invalid side %q, use buy or sell", value)
+	}
+}
+
+func intParam(r *http.Request, name string, fallback, lowest, highest int) (int, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return fallback, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < lowest || n > highest {
+		return 0, fmt.Errorf("invalid %s %q, use a number from %d to %d", name, value, lowest, highest)
+	}
+	return n, nil
+}
+
+func pageParams(r *http.Request) (int, int, error) {
+	offset, err := intParam(r, "offset", 0, 0, int(^uint32(0)>>1))
+	if err != nil {
+		return 0, 0, err
+	}
+	limit, err := intParam(r, "limit", DEFAULT_PAGE_LIMIT, 1, MAX_PAGE_LIMIT)
+	if err != nil {
+		return 0, 0, err
+	}
+	return offset, limit, nil
+}
+
+func writePage[T any](w http.ResponseWriter, items []T, offset, limit int) {
+	start := min(offset, len(items))
+	end := min(start+limit, len(items))
+	writeJSON(w, http.StatusOK, pageView[T]{
+		Data:       items[start:end],
+		Pagination: paginationView{Offset: offset, Limit: limit, Total: len(items)},
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		writeInternalError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(append(data, '\n'))
+}
+
+func writeError(w http.ResponseWriter, code, message string) {
+	data, _ := json.Marshal(errorView{Error: errorBody{Code: code, Message: message}})
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(errorStatus[code])
+	w.Write(append(data, '\n'))
+}
+
+// writeInternalError keeps the details in the logs rather than in the response
+func writeInternalError(w http.ResponseWriter, err error) {
+	slog.Error("Error: answering query", "err", err)
+	writeError(w, ERR_INTERNAL, "internal error")
+}
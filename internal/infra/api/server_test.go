@@ -0,0 +1,187 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/henriquemarlon/swapx/configs"
+	"github.com/henriquemarlon/swapx/internal/domain"
+	"github.com/henriquemarlon/swapx/internal/infra/repository"
+	"github.com/henriquemarlon/swapx/internal/usecase"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testHook  = common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	testAlice = common.HexToAddress("0x0000000000000000000000000000000000000a11")
+	testBob   = common.HexToAddress("0x0000000000000000000000000000000000000b0b")
+)
+
+func newTestServer(t *testing.T) http.Handler {
+	db, err := configs.SetupInMemoryDB()
+	require.NoError(t, err)
+	apply := usecase.NewApplyHookEventUseCase(repository.NewOrderRepositoryInMemory(db), repository.NewFillRepositoryInMemory(db))
+
+	sqrtPrice := new(uint256.Int).Lsh(uint256.NewInt(1), 96) // price 1
+	for i, event := range []domain.HookEvent{
+		{Type: domain.HookEventOrderCreated, OrderType: domain.OrderTypeBuy, OrderId: 1, Account: testAlice, SqrtPrice: sqrtPrice, Amount: uint256.NewInt(100)},
+		{Type: domain.HookEventOrderCreated, OrderType: domain.OrderTypeBuy, OrderId: 2, Account: testBob, SqrtPrice: sqrtPrice, Amount: uint256.NewInt(50)},
+		{Type: domain.HookEventOrderCreated, OrderType: domain.OrderTypeBuy, OrderId: 3, Account: testAlice, SqrtPrice: new(uint256.Int).Rsh(sqrtPrice, 1), Amount: uint256.NewInt(10)},
+		{Type: domain.HookEventOrderCreated, OrderType: domain.OrderTypeSell, OrderId: 1, Account: testBob, SqrtPrice: sqrtPrice, Amount: uint256.NewInt(30)},
+		{Type: domain.HookEventOrderPartiallyFulfilled, OrderType: domain.OrderTypeBuy, OrderId: 1, Account: testAlice, SqrtPrice: sqrtPrice, Amount: uint256.NewInt(30)},
+		{Type: domain.HookEventOrderFulfilled, OrderType: domain.OrderTypeSell, OrderId: 1, Account: testBob, SqrtPrice: sqrtPrice, Amount: uint256.NewInt(30)},
+		{Type: domain.HookEventOrderCreated, OrderType: domain.OrderTypeSell, OrderId: 2, Account: testBob, SqrtPrice: new(uint256.Int).Lsh(sqrtPrice, 1), Amount: uint256.NewInt(20)},
+	} {
+		event.Hook = testHook
+		event.BlockNumber = uint64(i + 1)
+		require.NoError(t, apply.Execute(&event))
+	}
+	require.NoError(t, repository.NewCursorRepositoryInMemory(db).UpdateCursor(&domain.SyncCursor{BlockNumber: 7}))
+
+	return NewServer(NewMarket(Pair{Hook: testHook, Decimals0: 18, Decimals1: 18}, db)).Handler()
+}
+
+func get(t *testing.T, handler http.Handler, method, path string, value any) int {
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(method, path, nil))
+	assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), value), recorder.Body.String())
+	return recorder.Code
+}
+
+func TestPairsAndDepth(t *testing.T) {
+	handler := newTestServer(t)
+
+	var pairs []pairView
+	require.Equal(t, http.StatusOK, get(t, handler, http.MethodGet, "/pairs", &pairs))
+	require.Len(t, pairs, 1)
+	assert.Equal(t, 3, pairs[0].OpenBuyOrders)
+	assert.Equal(t, 1, pairs[0].OpenSellOrders)
+	assert.Equal(t, "1", pairs[0].BestBid.Price)
+	assert.Equal(t, "4", pairs[0].BestAsk.Price)
+	assert.Equal(t, "1", pairs[0].LastTrade.Price)
+
+	var depth depthView
+	require.Equal(t, http.StatusOK, get(t, handler, http.MethodGet, "/pairs/"+testHook.Hex()+"/depth", &depth))
+	assert.Equal(t, uint64(7), depth.BlockNumber)
+	require.Len(t, depth.Bids, 2)
+	assert.Equal(t, "1", depth.Bids[0].Price)
+	assert.Equal(t, "120", depth.Bids[0].Quantity)
+	assert.Equal(t, 2, depth.Bids[0].Orders)
+	assert.Equal(t, "0.25", depth.Bids[1].Price)
+	require.Len(t, depth.Asks, 1)
+	assert.Equal(t, "20", depth.Asks[0].Quantity)
+
+	require.Equal(t, http.StatusOK, get(t, handler, http.MethodGet, "/pairs/"+testHook.Hex()+"/depth?levels=1", &depth))
+	assert.Len(t, depth.Bids, 1)
+}
+
+func TestOrdersByAccountAndOrderDetail(t *testing.T) {
+	handler := newTestServer(t)
+	base := "/pairs/" + testHook.Hex()
+
+	var page pageView[orderView]
+	require.Equal(t, http.StatusOK, get(t, handler, http.MethodGet, base+"/orders?account="+testAlice.Hex(), &page))
+	assert.Equal(t, paginationView{Offset: 0, Limit: DEFAULT_PAGE_LIMIT, Total: 2}, page.Pagination)
+	require.Len(t, page.Data, 2)
+	assert.Equal(t, uint64(1), page.Data[0].Id)
+	assert.Equal(t, "70", page.Data[0].Remaining)
+
+	require.Equal(t, http.StatusOK, get(t, handler, http.MethodGet, base+"/orders?account="+testBob.Hex()+"&status=closed", &page))
+	require.Len(t, page.Data, 1)
+	assert.Equal(t, domain.OrderTypeSell, page.Data[0].Side)
+
+	require.Equal(t, http.StatusOK, get(t, handler, http.MethodGet, base+"/orders?status=all&offset=1&limit=2", &page))
+	assert.Equal(t, 5, page.Pagination.Total)
+	require.Len(t, page.Data, 2)
+	assert.Equal(t, uint64(2), page.Data[0].Id)
+
+	var detail orderDetailView
+	require.Equal(t, http.StatusOK, get(t, handler, http.MethodGet, base+"/orders/buy/1", &detail))
+	assert.Equal(t, testAlice, detail.Account)
+	assert.Equal(t, uint64(0), detail.Index)
+	require.Len(t, detail.Fills, 1)
+	assert.Equal(t, "30", detail.Fills[0].Quantity)
+
+	var trades pageView[tradeView]
+	require.Equal(t, http.StatusOK, get(t, handler, http.MethodGet, base+"/trades?limit=1", &trades))
+	assert.Equal(t, 2, trades.Pagination.Total)
+	require.Len(t, trades.Data, 1)
+	assert.Equal(t, domain.OrderTypeSell, trades.Data[0].Side)
+}
+
+func TestErrorCodes(t *testing.T) {
+	handler := newTestServer(t)
+	base := "/pairs/" + testHook.Hex()
+
+	for _, tc := range []struct {
+		method string
+		path   string
+		status int
+		code   string
+	}{
+		{http.MethodGet, "/pairs/0x1234", http.StatusBadRequest, ERR_INVALID_ARGUMENT},
+		{http.MethodGet, "/pairs/" + testAlice.Hex(), http.StatusNotFound, ERR_NOT_FOUND},
+		{http.MethodGet, base + "/orders/buy/9", http.StatusNotFound, ERR_NOT_FOUND},
+		{http.MethodGet, base + "/orders/both/1", http.StatusBadRequest, ERR_INVALID_ARGUMENT},
+		{http.MethodGet, base + "/orders/buy/0", http.StatusBadRequest, ERR_INVALID_ARGUMENT},
+		{http.MethodGet, base + "/orders?limit=0", http.StatusBadRequest, ERR_INVALID_ARGUMENT},
+		{http.MethodGet, base + "/orders?status=pending", http.StatusBadRequest, ERR_INVALID_ARGUMENT},
+		{http.MethodGet, base + "/depth?levels=x", http.StatusBadRequest, ERR_INVALID_ARGUMENT},
+		{http.MethodGet, "/markets", http.StatusNotFound, ERR_NOT_FOUND},
+		{http.MethodPost, "/pairs", http.StatusMethodNotAllowed, ERR_METHOD_NOT_ALLOWED},
+	} {
+		var body errorView
+		assert.Equal(t, tc.status, get(t, handler, tc.method, tc.path, &body), tc.path)
+		assert.Equal(t, tc.code, body.Error.Code, tc.path)
+		assert.NotEmpty(t, body.Error.Message, tc.path)
+	}
+}
+
+func TestCandlesAndStats(t *testing.T) {
+	m := newTestMarket(t)
+	sqrtPrice := new(uint256.Int).Lsh(uint256.NewInt(1), 96) // price 1
+	for _, event := range []domain.HookEvent{
+		{Type: domain.HookEventOrderCreated, OrderType: domain.OrderTypeBuy, OrderId: 1, Account: testAlice, SqrtPrice: new(uint256.Int).Lsh(sqrtPrice, 1), Amount: uint256.NewInt(10)},
+		{Type: domain.HookEventOrderCreated, OrderType: domain.OrderTypeSell, OrderId: 1, Account: testBob, SqrtPrice: sqrtPrice, Amount: uint256.NewInt(4)},
+		{Type: domain.HookEventOrderCreated, OrderType: domain.OrderTypeSell, OrderId: 2, Account: testBob, SqrtPrice: new(uint256.Int).Lsh(sqrtPrice, 1), Amount: uint256.NewInt(6)},
+		{Type: domain.HookEventOrderPartiallyFulfilled, OrderType: domain.OrderTypeBuy, OrderId: 1, Account: testAlice, SqrtPrice: new(uint256.Int).Lsh(sqrtPrice, 1), Amount: uint256.NewInt(4), Timestamp: 60},
+		{Type: domain.HookEventOrderFulfilled, OrderType: domain.OrderTypeSell, OrderId: 1, Account: testBob, SqrtPrice: sqrtPrice, Amount: uint256.NewInt(4), Timestamp: 60},
+		{Type: domain.HookEventOrderFulfilled, OrderType: domain.OrderTypeBuy, OrderId: 1, Account: testAlice, SqrtPrice: new(uint256.Int).Lsh(sqrtPrice, 1), Amount: uint256.NewInt(6), Timestamp: 3660},
+		{Type: domain.HookEventOrderFulfilled, OrderType: domain.OrderTypeSell, OrderId: 2, Account: testBob, SqrtPrice: new(uint256.Int).Lsh(sqrtPrice, 1), Amount: uint256.NewInt(6), Timestamp: 3660},
+	} {
+		m.mine(event)
+	}
+	handler := NewServer(m.market).Handler()
+	path := "/pairs/" + testHook.Hex()
+
+	var candles candlesView
+	assert.Equal(t, http.StatusOK, get(t, handler, http.MethodGet, path+"/candles?interval=1h", &candles))
+	require.Len(t, candles.Candles, 2)
+	assert.Equal(t, uint64(3600), candles.Candles[1].Start)
+	assert.Equal(t, "1", candles.Candles[0].Close.Price)
+	assert.Equal(t, "4", candles.Candles[1].Close.Price)
+	assert.Equal(t, "6", candles.Candles[1].Volume)
+
+	var day candlesView
+	get(t, handler, http.MethodGet, path+"/candles?interval=1d&to=0", &day)
+	require.Len(t, day.Candles, 1)
+	assert.Equal(t, "10", day.Candles[0].Volume)
+	assert.Equal(t, 2, day.Candles[0].Trades)
+
+	var stats statsView
+	assert.Equal(t, http.StatusOK, get(t, handler, http.MethodGet, path+"/stats?at=3660", &stats))
+	assert.Equal(t, 2, stats.Trades)
+	assert.Equal(t, "1", stats.Low.Price)
+	assert.Equal(t, "4", stats.High.Price)
+	assert.Equal(t, "4", stats.Last.Price)
+
+	var failure errorView
+	assert.Equal(t, http.StatusBadRequest, get(t, handler, http.MethodGet, path+"/candles?interval=2m", &failure))
+	assert.Equal(t, ERR_INVALID_ARGUMENT, failure.Error.Code)
+}
@@ -0,0 +1,250 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sort"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/henriquemarlon/swapx/internal/domain"
+	"github.com/henriquemarlon/swapx/internal/infra/indexer"
+	"github.com/holiman/uint256"
+)
+
+// STREAM_BUFFER is the number of messages a subscriber can lag behind before
+// it is dropped, and has to subscribe again to get a new snapshot
+const STREAM_BUFFER = 256
+
+// Stream messages, sent as the event name of the SSE feed
+const (
+	STREAM_SNAPSHOT = "snapshot"
+	STREAM_BOOK     = "book"
+	STREAM_FILL     = "fill"
+	STREAM_CANCEL   = "cancel"
+	STREAM_REORG    = "reorg"
+)
+
+type StreamMessage struct {
+	Event    string
+	Sequence uint64
+	Data     any
+}
+
+// streamHeader starts every message. Sequences of a market increase by one
+// from message to message, a client that sees a gap has missed some.
+type streamHeader struct {
+	Sequence    uint64         `json:"sequence"`
+	Hook        common.Address `json:"hook"`
+	BlockNumber uint64         `json:"block_number"`
+	BlockHash   common.Hash    `json:"block_hash"`
+}
+
+type bookMessage struct {
+	streamHeader
+	Bids []levelView `json:"bids"`
+	Asks []levelView `json:"asks"`
+}
+
+type fillMessage struct {
+	streamHeader
+	Trade tradeView `json:"trade"`
+}
+
+type cancelMessage struct {
+	streamHeader
+	Side    domain.OrderType `json:"side"`
+	Id      uint64           `json:"id"`
+	Account common.Address   `json:"account"`
+}
+
+// Stream publishes the changes of the book of a market to its subscribers.
+// It keeps the levels it last published, so a book message carries only the
+// levels that changed since the previous one, a level with a zero quantity
+// being gone, and a subscriber starts from a snapshot of those same levels.
+type Stream struct {
+	Pair   Pair
+	Orders domain.OrderRepository
+
+	mutex       sync.Mutex
+	sequence    uint64
+	block       indexer.Block
+	bids        map[uint256.Int]levelView
+	asks        map[uint256.Int]levelView
+	subscribers map[chan StreamMessage]struct{}
+}
+
+func NewStream(pair Pair, orders domain.OrderRepository) *Stream {
+	return &Stream{
+		Pair:        pair,
+		Orders:      orders,
+		bids:        make(map[uint256.Int]levelView),
+		asks:        make(map[uint256.Int]levelView),
+		subscribers: make(map[chan StreamMessage]struct{}),
+	}
+}
+
+// Subscribe returns the snapshot to start from and the messages that follow
+// it. The channel is closed when the subscriber falls behind.
+func (s *Stream) Subscribe() (StreamMessage, <-chan StreamMessage, func()) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	snapshot := StreamMessage{
+		Event:    STREAM_SNAPSHOT,
+		Sequence: s.sequence,
+		Data: bookMessage{
+			streamHeader: s.header(s.sequence),
+			Bids:         sortedLevels(s.bids, domain.OrderTypeBuy),
+			Asks:         sortedLevels(s.asks, domain.OrderTypeSell),
+		},
+	}
+	messages := make(chan StreamMessage, STREAM_BUFFER)
+	s.subscribers[messages] = struct{}{}
+
+	return snapshot, messages, func() {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		if _, ok := s.subscribers[messages]; ok {
+			delete(s.subscribers, messages)
+			close(messages)
+		}
+	}
+}
+
+func (s *Stream) PublishBlock(block indexer.Block, events []*domain.HookEvent) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.block = block
+	for _, event := range events {
+		switch event.Type {
+		case domain.HookEventOrderFulfilled, domain.HookEventOrderPartiallyFulfilled:
+			s.send(STREAM_FILL, func(header streamHeader) any {
+				return fillMessage{streamHeader: header, Trade: newTradeView(s.Pair, &domain.Fill{
+					Hook:        event.Hook,
+					OrderType:   event.OrderType,
+					OrderId:     event.OrderId,
+					Account:     event.Account,
+					SqrtPrice:   event.SqrtPrice,
+					Quantity:    event.Amount,
+					BlockNumber: event.BlockNumber,
+					BlockHash:   event.BlockHash,
+					Timestamp:   event.Timestamp,
+					TxHash:      event.TxHash,
+					LogIndex:    event.LogIndex,
+				})}
+			})
+		case domain.HookEventOrderCancelled, domain.HookEventOrderRefunded:
+			s.send(STREAM_CANCEL, func(header streamHeader) any {
+				return cancelMessage{streamHeader: header, Side: event.OrderType, Id: event.OrderId, Account: event.Account}
+			})
+		}
+	}
+	s.publishBook()
+}
+
+// PublishRollback tells the subscribers that the fills and cancels of the
+// blocks above the given one are void, and sends the levels that changed back.
+func (s *Stream) PublishRollback(block indexer.Block) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.block = block
+	s.send(STREAM_REORG, func(header streamHeader) any { return header })
+	s.publishBook()
+}
+
+func (s *Stream) publishBook() {
+	orders, err := s.Orders.FindAllOrders()
+	if err != nil && !errors.Is(err, domain.ErrNoOrdersFound) {
+		slog.Error("Error: reading the book to stream", "err", err)
+		return
+	}
+	open := make([]*domain.Order, 0, len(orders))
+	for _, order := range orders {
+		if *order.Status == domain.OrderNotCancelledOrFulfilled {
+			open = append(open, order)
+		}
+	}
+
+	bids := diffLevels(s.bids, aggregateLevels(s.Pair, open, domain.OrderTypeBuy, len(open)), domain.OrderTypeBuy)
+	asks := diffLevels(s.asks, aggregateLevels(s.Pair, open, domain.OrderTypeSell, len(open)), domain.OrderTypeSell)
+	if len(bids) == 0 && len(asks) == 0 {
+		return
+	}
+	s.send(STREAM_BOOK, func(header streamHeader) any {
+		return bookMessage{streamHeader: header, Bids: bids, Asks: asks}
+	})
+}
+
+// send must be called with the mutex held
+func (s *Stream) send(event string, data func(header streamHeader) any) {
+	s.sequence++
+	message := StreamMessage{Event: event, Sequence: s.sequence, Data: data(s.header(s.sequence))}
+	for subscriber := range s.subscribers {
+		select {
+		case subscriber <- message:
+		default:
+			slog.Warn("Stream subscriber fell behind, dropping it", "hook", s.Pair.Hook.Hex(), "sequence", s.sequence)
+			delete(s.subscribers, subscriber)
+			close(subscriber)
+		}
+	}
+}
+
+func (s *Stream) header(sequence uint64) streamHeader {
+	return streamHeader{Sequence: sequence, Hook: s.Pair.Hook, BlockNumber: s.block.Number, BlockHash: s.block.Hash}
+}
+
+func writeEvent(w io.Writer, message StreamMessage) error {
+	data, err := json.Marshal(message.Data)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", message.Sequence, message.Event, data)
+	return err
+}
+
+// diffLevels updates the kept levels to the current ones and returns the
+// levels that changed, best first
+func diffLevels(kept map[uint256.Int]levelView, current []levelView, orderType domain.OrderType) []levelView {
+	changed := make(map[uint256.Int]levelView)
+	seen := make(map[uint256.Int]bool, len(current))
+	for _, level := range current {
+		key := *level.sqrtPrice
+		seen[key] = true
+		if previous, ok := kept[key]; !ok || previous.Quantity != level.Quantity || previous.Orders != level.Orders {
+			kept[key] = level
+			changed[key] = level
+		}
+	}
+	for key, level := range kept {
+		if !seen[key] {
+			delete(kept, key)
+			changed[key] = levelView{priceView: level.priceView, Quantity: "0", sqrtPrice: level.sqrtPrice}
+		}
+	}
+	return sortedLevels(changed, orderType)
+}
+
+func sortedLevels(levels map[uint256.Int]levelView, orderType domain.OrderType) []levelView {
+	keys := make([]uint256.Int, 0, len(levels))
+	for key := range levels {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if orderType == domain.OrderTypeBuy {
+			return keys[i].Gt(&keys[j])
+		}
+		return keys[i].Lt(&keys[j])
+	})
+	sorted := make([]levelView, 0, len(keys))
+	for _, key := range keys {
+		sorted = append(sorted, levels[key])
+	}
+	return sorted
+}
@@ -0,0 +1,159 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/henriquemarlon/swapx/configs"
+	"github.com/henriquemarlon/swapx/internal/domain"
+	"github.com/henriquemarlon/swapx/internal/infra/indexer"
+	"github.com/henriquemarlon/swapx/internal/infra/repository"
+	"github.com/henriquemarlon/swapx/internal/usecase"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testMarket struct {
+	t      *testing.T
+	db     *configs.InMemoryDB
+	market *Market
+	apply  *usecase.ApplyHookEventUseCase
+	block  uint64
+}
+
+func newTestMarket(t *testing.T) *testMarket {
+	db, err := configs.SetupInMemoryDB()
+	require.NoError(t, err)
+	market := NewMarket(Pair{Hook: testHook, Decimals0: 18, Decimals1: 18}, db)
+	return &testMarket{t: t, db: db, market: market, apply: usecase.NewApplyHookEventUseCase(market.Orders, market.Fills)}
+}
+
+// mine applies the events in a new block and publishes them, as the indexer does
+func (m *testMarket) mine(events ...domain.HookEvent) {
+	m.block++
+	published := make([]*domain.HookEvent, 0, len(events))
+	for _, event := range events {
+		event.Hook = testHook
+		event.BlockNumber = m.block
+		require.NoError(m.t, m.apply.Execute(&event))
+		published = append(published, &event)
+	}
+	m.market.Stream.PublishBlock(indexer.Block{Number: m.block}, published)
+}
+
+func levelQuantities(levels []levelView) map[string]string {
+	quantities := make(map[string]string, len(levels))
+	for _, level := range levels {
+		quantities[level.SqrtPriceX96] = level.Quantity
+	}
+	return quantities
+}
+
+func TestStreamSendsDiffsAfterTheSnapshot(t *testing.T) {
+	m := newTestMarket(t)
+	m.mine(
+		domain.HookEvent{Type: domain.HookEventOrderCreated, OrderType: domain.OrderTypeBuy, OrderId: 1, Account: testAlice, SqrtPrice: uint256.NewInt(100), Amount: uint256.NewInt(50)},
+		domain.HookEvent{Type: domain.HookEventOrderCreated, OrderType: domain.OrderTypeBuy, OrderId: 2, Account: testAlice, SqrtPrice: uint256.NewInt(90), Amount: uint256.NewInt(10)},
+	)
+	before, err := repository.ExportSnapshot(m.db)
+	require.NoError(t, err)
+
+	snapshot, messages, unsubscribe := m.market.Stream.Subscribe()
+	defer unsubscribe()
+	assert.Equal(t, STREAM_SNAPSHOT, snapshot.Event)
+	assert.Equal(t, uint64(1), snapshot.Sequence)
+	assert.Equal(t, map[string]string{"100": "50", "90": "10"}, levelQuantities(snapshot.Data.(bookMessage).Bids))
+
+	m.mine(
+		domain.HookEvent{Type: domain.HookEventOrderPartiallyFulfilled, OrderType: domain.OrderTypeBuy, OrderId: 1, Account: testAlice, SqrtPrice: uint256.NewInt(100), Amount: uint256.NewInt(20)},
+		domain.HookEvent{Type: domain.HookEventOrderCancelled, OrderType: domain.OrderTypeBuy, OrderId: 2, Account: testAlice, SqrtPrice: uint256.NewInt(90), Amount: uint256.NewInt(10)},
+	)
+	fill := <-messages
+	assert.Equal(t, STREAM_FILL, fill.Event)
+	assert.Equal(t, uint64(2), fill.Sequence)
+	assert.Equal(t, "20", fill.Data.(fillMessage).Trade.Quantity)
+	cancel := <-messages
+	assert.Equal(t, STREAM_CANCEL, cancel.Event)
+	assert.Equal(t, uint64(2), cancel.Data.(cancelMessage).Id)
+	book := <-messages
+	assert.Equal(t, STREAM_BOOK, book.Event)
+	assert.Equal(t, uint64(4), book.Sequence)
+	assert.Equal(t, map[string]string{"100": "30", "90": "0"}, levelQuantities(book.Data.(bookMessage).Bids))
+
+	// a reorg back to the first block brings the levels back
+	require.NoError(t, repository.ImportSnapshot(m.db, before))
+	m.market.Stream.PublishRollback(indexer.Block{Number: 1})
+	reorg := <-messages
+	assert.Equal(t, STREAM_REORG, reorg.Event)
+	assert.Equal(t, uint64(1), reorg.Data.(streamHeader).BlockNumber)
+	book = <-messages
+	assert.Equal(t, uint64(6), book.Sequence)
+	assert.Equal(t, map[string]string{"100": "50", "90": "10"}, levelQuantities(book.Data.(bookMessage).Bids))
+}
+
+func TestStreamDropsSubscribersThatFallBehind(t *testing.T) {
+	m := newTestMarket(t)
+	_, messages, unsubscribe := m.market.Stream.Subscribe()
+	defer unsubscribe()
+
+	for i := range STREAM_BUFFER + 1 {
+		m.mine(domain.HookEvent{Type: domain.HookEventOrderCreated, OrderType: domain.OrderTypeSell, OrderId: uint64(i + 1), Account: testBob, SqrtPrice: uint256.NewInt(uint64(i + 1)), Amount: uint256.NewInt(1)})
+	}
+
+	received := 0
+	for range messages {
+		received++
+	}
+	assert.Equal(t, STREAM_BUFFER, received)
+}
+
+func TestStreamEndpointStartsWithASnapshot(t *testing.T) {
+	m := newTestMarket(t)
+	m.mine(domain.HookEvent{Type: domain.HookEventOrderCreated, OrderType: domain.OrderTypeSell, OrderId: 1, Account: testBob, SqrtPrice: uint256.NewInt(100), Amount: uint256.NewInt(5)})
+	server := httptest.NewServer(NewServer(m.market).Handler())
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/pairs/"+testHook.Hex()+"/stream", nil)
+	require.NoError(t, err)
+	response, err := http.DefaultClient.Do(request)
+	require.NoError(t, err)
+	defer response.Body.Close()
+	assert.Equal(t, "text/event-stream", response.Header.Get("Content-Type"))
+
+	reader := bufio.NewReader(response.Body)
+	readEvent := func() (string, string, bookMessage) {
+		fields := map[string]string{}
+		for {
+			line, err := reader.ReadString('\n')
+			require.NoError(t, err)
+			line = strings.TrimSuffix(line, "\n")
+			if line == "" {
+				break
+			}
+			name, value, _ := strings.Cut(line, ": ")
+			fields[name] = value
+		}
+		var message bookMessage
+		require.NoError(t, json.Unmarshal([]byte(fields["data"]), &message))
+		return fields["id"], fields["event"], message
+	}
+
+	id, event, snapshot := readEvent()
+	assert.Equal(t, "1", id)
+	assert.Equal(t, STREAM_SNAPSHOT, event)
+	assert.Equal(t, map[string]string{"100": "5"}, levelQuantities(snapshot.Asks))
+
+	m.mine(domain.HookEvent{Type: domain.HookEventOrderCreated, OrderType: domain.OrderTypeSell, OrderId: 2, Account: testBob, SqrtPrice: uint256.NewInt(100), Amount: uint256.NewInt(7)})
+	id, event, book := readEvent()
+	assert.Equal(t, "2", id)
+	assert.Equal(t, STREAM_BOOK, event)
+	assert.Equal(t, map[string]string{"100": "12"}, levelQuantities(book.Asks))
+}
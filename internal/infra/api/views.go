@@ -0,0 +1,237 @@
+package api
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/henriquemarlon/swapx/internal/domain"
+	"github.com/holiman/uint256"
+)
+
+// priceView renders a price both as the raw sqrtPriceX96 and as the human
+// price of currency0 in currency1
+type priceView struct {
+	SqrtPriceX96 string `json:"sqrt_price_x96"`
+	Price        string `json:"price"`
+}
+
+func newPriceView(pair Pair, sqrtPriceX96 *uint256.Int) priceView {
+	return priceView{
+		SqrtPriceX96: sqrtPriceX96.Dec(),
+		Price:        domain.SqrtPriceX96ToPrice(sqrtPriceX96, pair.Decimals0, pair.Decimals1).Text('g', 18),
+	}
+}
+
+func optionalPriceView(pair Pair, sqrtPriceX96 *uint256.Int) *priceView {
+	if sqrtPriceX96 == nil {
+		return nil
+	}
+	view := newPriceView(pair, sqrtPriceX96)
+	return &view
+}
+
+type pairView struct {
+	Hook           common.Address `json:"hook"`
+	Decimals0      uint8          `json:"decimals0"`
+	Decimals1      uint8          `json:"decimals1"`
+	BlockNumber    uint64         `json:"block_number"`
+	BlockHash      common.Hash    `json:"block_hash"`
+	OpenBuyOrders  int            `json:"open_buy_orders"`
+	OpenSellOrders int            `json:"open_sell_orders"`
+	BestBid        *priceView     `json:"best_bid"`
+	BestAsk        *priceView     `json:"best_ask"`
+	LastTrade      *priceView     `json:"last_trade"`
+}
+
+func newPairView(market *Market) (*pairView, error) {
+	cursor, err := market.Cursor.FindCursor()
+	if err != nil {
+		return nil, err
+	}
+	orders, err := openOrders(market)
+	if err != nil {
+		return nil, err
+	}
+	fills, err := market.Fills.FindFills()
+	if err != nil {
+		return nil, err
+	}
+
+	view := &pairView{
+		Hook:        market.Pair.Hook,
+		Decimals0:   market.Pair.Decimals0,
+		Decimals1:   market.Pair.Decimals1,
+		BlockNumber: cursor.BlockNumber,
+		BlockHash:   cursor.BlockHash,
+	}
+	for _, order := range orders {
+		if *order.Type == domain.OrderTypeBuy {
+			view.OpenBuyOrders++
+		} else {
+			view.OpenSellOrders++
+		}
+	}
+	if bids := aggregateLevels(market.Pair, orders, domain.OrderTypeBuy, 1); len(bids) > 0 {
+		view.BestBid = &bids[0].priceView
+	}
+	if asks := aggregateLevels(market.Pair, orders, domain.OrderTypeSell, 1); len(asks) > 0 {
+		view.BestAsk = &asks[0].priceView
+	}
+	if len(fills) > 0 {
+		last := newPriceView(market.Pair, fills[len(fills)-1].SqrtPrice)
+		view.LastTrade = &last
+	}
+	return view, nil
+}
+
+type levelView struct {
+	priceView
+	Quantity string `json:"quantity"`
+	Orders   int    `json:"orders"`
+
+	sqrtPrice *uint256.Int
+	quantity  *uint256.Int
+}
+
+type depthView struct {
+	Hook        common.Address `json:"hook"`
+	BlockNumber uint64         `json:"block_number"`
+	BlockHash   common.Hash    `json:"block_hash"`
+	Bids        []levelView    `json:"bids"`
+	Asks        []levelView    `json:"asks"`
+}
+
+type orderView struct {
+	Side    domain.OrderType `json:"side"`
+	Id      uint64           `json:"id"`
+	Index   uint64           `json:"index"`
+	Account common.Address   `json:"account"`
+	priceView
+	Amount        string             `json:"amount"`
+	MatchedAmount string             `json:"matched_amount"`
+	Remaining     string             `json:"remaining"`
+	Status        domain.OrderStatus `json:"status"`
+}
+
+func newOrderView(pair Pair, order *domain.Order) orderView {
+	return orderView{
+		Side:          *order.Type,
+		Id:            order.Id,
+		Index:         order.Id - 1,
+		Account:       order.Account,
+		priceView:     newPriceView(pair, order.SqrtPrice),
+		Amount:        order.Amount.Dec(),
+		MatchedAmount: order.MatchedAmount.Dec(),
+		Remaining:     order.Remaining().Dec(),
+		Status:        *order.Status,
+	}
+}
+
+type orderDetailView struct {
+	orderView
+	Fills []tradeView `json:"fills"`
+}
+
+type tradeView struct {
+	BlockNumber uint64           `json:"block_number"`
+	BlockHash   common.Hash      `json:"block_hash"`
+	Timestamp   uint64           `json:"timestamp"`
+	TxHash      common.Hash      `json:"tx_hash"`
+	LogIndex    uint             `json:"log_index"`
+	Side        domain.OrderType `json:"side"`
+	OrderId     uint64           `json:"order_id"`
+	Account     common.Address   `json:"account"`
+	priceView
+	Quantity string `json:"quantity"`
+}
+
+func newTradeView(pair Pair, fill *domain.Fill) tradeView {
+	return tradeView{
+		BlockNumber: fill.BlockNumber,
+		BlockHash:   fill.BlockHash,
+		Timestamp:   fill.Timestamp,
+		TxHash:      fill.TxHash,
+		LogIndex:    fill.LogIndex,
+		Side:        fill.OrderType,
+		OrderId:     fill.OrderId,
+		Account:     fill.Account,
+		priceView:   newPriceView(pair, fill.SqrtPrice),
+		Quantity:    fill.Quantity.Dec(),
+	}
+}
+
+type candleView struct {
+	Start  uint64     `json:"start"`
+	Open   priceView  `json:"open"`
+	High   priceView  `json:"high"`
+	Low    priceView  `json:"low"`
+	Close  priceView  `json:"close"`
+	VWAP   *priceView `json:"vwap"`
+	Volume string     `json:"volume"`
+	Trades int        `json:"trades"`
+}
+
+func newCandleView(pair Pair, candle *domain.Candle) candleView {
+	return candleView{
+		Start:  candle.Start,
+		Open:   newPriceView(pair, candle.Open),
+		High:   newPriceView(pair, candle.High),
+		Low:    newPriceView(pair, candle.Low),
+		Close:  newPriceView(pair, candle.Close),
+		VWAP:   optionalPriceView(pair, candle.VWAP()),
+		Volume: candle.Volume.Dec(),
+		Trades: candle.Trades,
+	}
+}
+
+type candlesView struct {
+	Hook     common.Address        `json:"hook"`
+	Interval domain.CandleInterval `json:"interval"`
+	Candles  []candleView          `json:"candles"`
+}
+
+type statsView struct {
+	Hook   common.Address `json:"hook"`
+	From   uint64         `json:"from"`
+	To     uint64         `json:"to"`
+	Trades int            `json:"trades"`
+	Volume string         `json:"volume"`
+	VWAP   *priceView     `json:"vwap"`
+	Open   *priceView     `json:"open"`
+	High   *priceView     `json:"high"`
+	Low    *priceView     `json:"low"`
+	Last   *priceView     `json:"last"`
+}
+
+func newStatsView(pair Pair, stats *domain.MarketStats) statsView {
+	return statsView{
+		Hook:   pair.Hook,
+		From:   stats.From,
+		To:     stats.To,
+		Trades: stats.Trades,
+		Volume: stats.Volume.Dec(),
+		VWAP:   optionalPriceView(pair, stats.VWAP()),
+		Open:   optionalPriceView(pair, stats.Open),
+		High:   optionalPriceView(pair, stats.High),
+		Low:    optionalPriceView(pair, stats.Low),
+		Last:   optionalPriceView(pair, stats.Last),
+	}
+}
+
+type paginationView struct {
+	Offset int `json:"offset"`
+	Limit  int `json:"limit"`
+	Total  int `json:"total"`
+}
+
+type pageView[T any] struct {
+	Data       []T            `json:"data"`
+	Pagination paginationView `json:"pagination"`
+}
+
+type errorBody struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type errorView struct {
+	Error errorBody `json:"error"`
+}
@@ -0,0 +1,216 @@
+package cartesi
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/henriquemarlon/swapx/internal/domain"
+	"github.com/holiman/uint256"
+)
+
+const hookActionsABI = `[
+	{
+		"name": "refundOrder",
+		"type": "function",
+		"stateMutability": "nonpayable",
+		"inputs": [
+			{"name": "orderId", "type": "uint256"},
+			{"name": "isBuy", "type": "bool"}
+		],
+		"outputs": []
+	},
+	{
+		"name": "transferDust",
+		"type": "function",
+		"stateMutability": "nonpayable",
+		"inputs": [
+			{"name": "orderId", "type": "uint256"},
+			{"name": "isBuy", "type": "bool"},
+			{"name": "amount", "type": "uint256"}
+		],
+		"outputs": []
+	},
+	{
+		"name": "swapRemainder",
+		"type": "function",
+		"stateMutability": "nonpayable",
+		"inputs": [
+			{"name": "orderId", "type": "uint256"},
+			{"name": "isBuy", "type": "bool"},
+			{"name": "amount", "type": "uint256"},
+			{"name": "sqrtPriceLimitX96", "type": "uint160"},
+			{"name": "expiryBlock", "type": "uint256"}
+		],
+		"outputs": []
+	}
+]`
+
+const voucherNoticeABI = `[
+	{
+		"name": "ExecuteVouchers",
+		"type": "function",
+		"stateMutability": "nonpayable",
+		"inputs": [
+			{"name": "hook", "type": "address"},
+			{"name": "payloads", "type": "bytes[]"}
+		],
+		"outputs": []
+	}
+]`
+
+type Voucher struct {
+	Destination common.Address `json:"destination"`
+	Payload     []byte         `json:"payload"`
+}
+
+// VoucherNotice carries the hook once, like a SettleBatch notice, and the
+// task manager calls no other destination
+type VoucherNotice struct {
+	Hook     common.Address `json:"hook"`
+	Vouchers []Voucher      `json:"vouchers"`
+}
+
+// NewActionVoucher turns a planned action into a call to the hook. The task
+// manager only forwards calls whose selector is in its whitelist.
+func NewActionVoucher(hook common.Address, action *domain.Action) (*Voucher, error) {
+	parsedABI, err := abi.JSON(strings.NewReader(hookActionsABI))
+	if err != nil {
+		return nil, fmt.Errorf("error parsing ABI: %v", err)
+	}
+
+	// the order ids inside of the dApp are 1-based, the hook arrays are 0-based
+	orderId := new(big.Int).SetUint64(action.OrderId - 1)
+	isBuy := *action.OrderType == domain.OrderTypeBuy
+
+	var payload []byte
+	switch action.Kind {
+	case domain.ActionRefund:
+		payload, err = parsedABI.Pack("refundOrder", orderId, isBuy)
+	case domain.ActionTransfer:
+		payload, err = parsedABI.Pack("transferDust", orderId, isBuy, action.Amount.ToBig())
+	case domain.ActionPoolSwap:
+		payload, err = parsedABI.Pack("swapRemainder", orderId, isBuy, action.Amount.ToBig(), action.SqrtPriceLimit.ToBig(), new(big.Int).SetUint64(action.ExpiryBlock))
+	default:
+		return nil, fmt.Errorf("unsupported action kind: %s", action.Kind)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &Voucher{Destination: hook, Payload: payload}, nil
+}
+
+// DecodeActionVoucher is the inverse of NewActionVoucher, with the order id
+// shifted back to the 1-based ids used inside of the dApp.
+func DecodeActionVoucher(voucher *Voucher) (*domain.Action, error) {
+	parsedABI, err := abi.JSON(strings.NewReader(hookActionsABI))
+	if err != nil {
+		return nil, fmt.Errorf("error parsing ABI: %v", err)
+	}
+
+	if len(voucher.Payload) < 4 {
+		return nil, fmt.Errorf("voucher payload is too short")
+	}
+
+	method, err := parsedABI.MethodById(voucher.Payload[:4])
+	if err != nil {
+		return nil, fmt.Errorf("unsupported action: %v", err)
+	}
+
+	args, err := method.Inputs.Unpack(voucher.Payload[4:])
+	if err != nil {
+		return nil, fmt.Errorf("error decoding voucher: %v", err)
+	}
+
+	orderType := domain.OrderTypeSell
+	if args[1].(bool) {
+		orderType = domain.OrderTypeBuy
+	}
+
+	action := &domain.Action{
+		OrderId:   args[0].(*big.Int).Uint64() + 1,
+		OrderType: &orderType,
+	}
+	switch method.Name {
+	case "refundOrder":
+		action.Kind = domain.ActionRefund
+	case "transferDust":
+		action.Kind = domain.ActionTransfer
+		action.Amount = uint256.MustFromBig(args[2].(*big.Int))
+	case "swapRemainder":
+		action.Kind = domain.ActionPoolSwap
+		action.Amount = uint256.MustFromBig(args[2].(*big.Int))
+		action.SqrtPriceLimit = uint256.MustFromBig(args[3].(*big.Int))
+		action.ExpiryBlock = args[4].(*big.Int).Uint64()
+	}
+	return action, nil
+}
+
+// EncodeVoucherNotice wraps the vouchers to hook in an Outputs.ExecuteVouchers
+// notice, since the coprocessor adapter only relays notices to the task manager.
+func EncodeVoucherNotice(hook common.Address, vouchers []*Voucher) ([]byte, error) {
+	method, err := voucherNoticeMethod()
+	if err != nil {
+		return nil, err
+	}
+
+	payloads := make([][]byte, 0, len(vouchers))
+	for _, voucher := range vouchers {
+		if voucher.Destination != hook {
+			return nil, fmt.Errorf("voucher to %s in a notice for hook %s", voucher.Destination.Hex(), hook.Hex())
+		}
+		payloads = append(payloads, voucher.Payload)
+	}
+
+	args, err := method.Inputs.Pack(hook, payloads)
+	if err != nil {
+		return nil, err
+	}
+	return append(method.ID, args...), nil
+}
+
+func DecodeVoucherNotice(payload []byte) (*VoucherNotice, error) {
+	method, err := voucherNoticeMethod()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(payload) < 4 || !bytes.Equal(payload[:4], method.ID) {
+		return nil, fmt.Errorf("payload is not an ExecuteVouchers notice")
+	}
+
+	args, err := method.Inputs.Unpack(payload[4:])
+	if err != nil {
+		return nil, fmt.Errorf("error decoding notice: %v", err)
+	}
+
+	var decoded struct {
+		Hook     common.Address
+		Payloads [][]byte
+	}
+	if err := method.Inputs.Copy(&decoded, args); err != nil {
+		return nil, fmt.Errorf("error decoding notice: %v", err)
+	}
+	notice := &VoucherNotice{Hook: decoded.Hook, Vouchers: make([]Voucher, 0, len(decoded.Payloads))}
+	for _, payload := range decoded.Payloads {
+		notice.Vouchers = append(notice.Vouchers, Voucher{Destination: decoded.Hook, Payload: payload})
+	}
+	return notice, nil
+}
+
+func voucherNoticeMethod() (abi.Method, error) {
+	parsedABI, err := abi.JSON(strings.NewReader(voucherNoticeABI))
+	if err != nil {
+		return abi.Method{}, fmt.Errorf("error parsing ABI: %v", err)
+	}
+
+	method, exists := parsedABI.Methods["ExecuteVouchers"]
+	if !exists {
+		return abi.Method{}, fmt.Errorf("method ExecuteVouchers not found in ABI")
+	}
+	return method, nil
+}
@@ -0,0 +1,72 @@
+package cartesi
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/henriquemarlon/swapx/internal/domain"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVoucherNoticeRoundTrip(t *testing.T) {
+	refund, err := NewActionVoucher(testHook, &domain.Action{Kind: domain.ActionRefund, OrderId: 3, OrderType: &domain.OrderTypeBuy, Amount: uint256.NewInt(40)})
+	assert.NoError(t, err)
+	transfer, err := NewActionVoucher(testHook, &domain.Action{Kind: domain.ActionTransfer, OrderId: 1, OrderType: &domain.OrderTypeSell, Amount: uint256.NewInt(5)})
+	assert.NoError(t, err)
+
+	notice, err := EncodeVoucherNotice(testHook, []*Voucher{refund, transfer})
+	assert.NoError(t, err)
+
+	decoded, err := DecodeVoucherNotice(notice)
+	assert.NoError(t, err)
+	assert.Equal(t, testHook, decoded.Hook)
+	assert.Equal(t, []Voucher{*refund, *transfer}, decoded.Vouchers)
+
+	// refundOrder(uint256,bool) and transferDust(uint256,bool,uint256)
+	assert.Len(t, decoded.Vouchers[0].Payload, 4+2*32)
+	assert.Len(t, decoded.Vouchers[1].Payload, 4+3*32)
+}
+
+func TestVoucherNoticeOnlyGoesToItsHook(t *testing.T) {
+	refund, err := NewActionVoucher(common.HexToAddress("0xdead"), &domain.Action{Kind: domain.ActionRefund, OrderId: 3, OrderType: &domain.OrderTypeBuy})
+	assert.NoError(t, err)
+
+	_, err = EncodeVoucherNotice(testHook, []*Voucher{refund})
+	assert.Error(t, err)
+}
+
+func TestVoucherNoticeIsNotASettlementNotice(t *testing.T) {
+	notice, err := EncodeVoucherNotice(testHook, nil)
+	assert.NoError(t, err)
+
+	_, err = DecodeSettlementNotice(notice)
+	assert.Error(t, err)
+}
+
+func TestDecodeActionVoucher(t *testing.T) {
+	voucher, err := NewActionVoucher(testHook, &domain.Action{Kind: domain.ActionTransfer, OrderId: 2, OrderType: &domain.OrderTypeSell, Amount: uint256.NewInt(5)})
+	assert.NoError(t, err)
+
+	action, err := DecodeActionVoucher(voucher)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.ActionTransfer, action.Kind)
+	assert.Equal(t, uint64(2), action.OrderId)
+	assert.Equal(t, domain.OrderTypeSell, *action.OrderType)
+	assert.Equal(t, uint256.NewInt(5), action.Amount)
+
+	_, err = DecodeActionVoucher(&Voucher{Destination: testHook, Payload: []byte{0xde, 0xad, 0xbe, 0xef}})
+	assert.Error(t, err)
+}
+
+func TestDecodePoolSwapVoucher(t *testing.T) {
+	swap := &domain.Action{Kind: domain.ActionPoolSwap, OrderId: 4, OrderType: &domain.OrderTypeBuy, Amount: uint256.NewInt(30), SqrtPriceLimit: uint256.NewInt(1 << 62), ExpiryBlock: 12}
+	voucher, err := NewActionVoucher(testHook, swap)
+	assert.NoError(t, err)
+	// swapRemainder(uint256,bool,uint256,uint160,uint256)
+	assert.Len(t, voucher.Payload, 4+5*32)
+
+	action, err := DecodeActionVoucher(voucher)
+	assert.NoError(t, err)
+	assert.Equal(t, swap, action)
+}
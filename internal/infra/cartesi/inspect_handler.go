@@ -0,0 +1,179 @@
+package cartesi
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/henriquemarlon/swapx/internal/domain"
+	"github.com/henriquemarlon/swapx/pkg/coprocessor"
+	"github.com/holiman/uint256"
+)
+
+var ErrInvalidInspect = errors.New("invalid inspect query")
+
+// InspectHandler answers inspect requests with a report. The payload is the
+// hex of a query such as "stats?hook=0x..&at=1700000000" or
+// "candles?hook=0x..&interval=1h&from=0", and decimals0 and decimals1 set the
+// decimals used for human prices, 18 each by default.
+type InspectHandler struct {
+	MarketRepository domain.MarketRepository
+}
+
+func NewInspectHandler(marketRepository domain.MarketRepository) *InspectHandler {
+	return &InspectHandler{MarketRepository: marketRepository}
+}
+
+type inspectPrice struct {
+	SqrtPriceX96 string `json:"sqrt_price_x96"`
+	Price        string `json:"price"`
+}
+
+type candleReport struct {
+	Start  uint64        `json:"start"`
+	Open   inspectPrice  `json:"open"`
+	High   inspectPrice  `json:"high"`
+	Low    inspectPrice  `json:"low"`
+	Close  inspectPrice  `json:"close"`
+	VWAP   *inspectPrice `json:"vwap"`
+	Volume string        `json:"volume"`
+	Trades int           `json:"trades"`
+}
+
+type statsReport struct {
+	From   uint64        `json:"from"`
+	To     uint64        `json:"to"`
+	Trades int           `json:"trades"`
+	Volume string        `json:"volume"`
+	VWAP   *inspectPrice `json:"vwap"`
+	Open   *inspectPrice `json:"open"`
+	High   *inspectPrice `json:"high"`
+	Low    *inspectPrice `json:"low"`
+	Last   *inspectPrice `json:"last"`
+}
+
+func (ih *InspectHandler) InspectHandler(input *coprocessor.InspectResponse) error {
+	payload, err := ih.answer(input.Payload)
+	if err != nil {
+		// the report tells the caller why the request was rejected
+		report, _ := json.Marshal(map[string]string{"error": err.Error()})
+		if _, sendErr := coprocessor.SendReport(&coprocessor.ReportRequest{Payload: "0x" + common.Bytes2Hex(report)}); sendErr != nil {
+			return sendErr
+		}
+		return err
+	}
+	_, err = coprocessor.SendReport(&coprocessor.ReportRequest{Payload: "0x" + common.Bytes2Hex(payload)})
+	return err
+}
+
+func (ih *InspectHandler) answer(hexPayload string) ([]byte, error) {
+	raw, err := hex.DecodeString(strings.TrimPrefix(hexPayload, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("%w: payload is not hex: %v", ErrInvalidInspect, err)
+	}
+	query, err := url.Parse(string(raw))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidInspect, err)
+	}
+	params := query.Query()
+
+	hook := params.Get("hook")
+	if !common.IsHexAddress(hook) {
+		return nil, fmt.Errorf("%w: invalid hook %q", ErrInvalidInspect, hook)
+	}
+	hookAddress := common.HexToAddress(hook)
+
+	decimals0, err := uintParam(params, "decimals0", 18, math.MaxUint8)
+	if err != nil {
+		return nil, err
+	}
+	decimals1, err := uintParam(params, "decimals1", 18, math.MaxUint8)
+	if err != nil {
+		return nil, err
+	}
+	price := func(sqrtPrice *uint256.Int) *inspectPrice {
+		if sqrtPrice == nil {
+			return nil
+		}
+		return &inspectPrice{
+			SqrtPriceX96: sqrtPrice.Dec(),
+			Price:        domain.SqrtPriceX96ToPrice(sqrtPrice, uint8(decimals0), uint8(decimals1)).Text('g', 18),
+		}
+	}
+
+	switch strings.Trim(query.Path, "/") {
+	case "stats":
+		at, err := uintParam(params, "at", 0, math.MaxUint64)
+		if err != nil {
+			return nil, err
+		}
+		stats, err := ih.MarketRepository.FindStats(hookAddress, at)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(statsReport{
+			From:   stats.From,
+			To:     stats.To,
+			Trades: stats.Trades,
+			Volume: stats.Volume.Dec(),
+			VWAP:   price(stats.VWAP()),
+			Open:   price(stats.Open),
+			High:   price(stats.High),
+			Low:    price(stats.Low),
+			Last:   price(stats.Last),
+		})
+
+	case "candles":
+		interval, err := domain.ParseCandleInterval(params.Get("interval"))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidInspect, err)
+		}
+		from, err := uintParam(params, "from", 0, math.MaxUint64)
+		if err != nil {
+			return nil, err
+		}
+		to, err := uintParam(params, "to", math.MaxUint64, math.MaxUint64)
+		if err != nil {
+			return nil, err
+		}
+		candles, err := ih.MarketRepository.FindCandles(hookAddress, interval, from, to)
+		if err != nil {
+			return nil, err
+		}
+		reports := make([]candleReport, 0, len(candles))
+		for _, candle := range candles {
+			reports = append(reports, candleReport{
+				Start:  candle.Start,
+				Open:   *price(candle.Open),
+				High:   *price(candle.High),
+				Low:    *price(candle.Low),
+				Close:  *price(candle.Close),
+				VWAP:   price(candle.VWAP()),
+				Volume: candle.Volume.Dec(),
+				Trades: candle.Trades,
+			})
+		}
+		return json.Marshal(reports)
+
+	default:
+		return nil, fmt.Errorf("%w: unknown query %q, use stats or candles", ErrInvalidInspect, query.Path)
+	}
+}
+
+func uintParam(params url.Values, name string, fallback, highest uint64) (uint64, error) {
+	value := params.Get(name)
+	if value == "" {
+		return fallback, nil
+	}
+	n, err := strconv.ParseUint(value, 10, 64)
+	if err != nil || n > highest {
+		return 0, fmt.Errorf("%w: invalid %s %q", ErrInvalidInspect, name, value)
+	}
+	return n, nil
+}
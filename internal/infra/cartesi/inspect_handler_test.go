@@ -0,0 +1,63 @@
+package cartesi
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/henriquemarlon/swapx/configs"
+	"github.com/henriquemarlon/swapx/internal/domain"
+	"github.com/henriquemarlon/swapx/internal/infra/repository"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInspectAnswersStatsAndCandles(t *testing.T) {
+	db, err := configs.SetupInMemoryDB()
+	require.NoError(t, err)
+	markets := repository.NewMarketRepositoryInMemory(db)
+	hook, other := common.HexToAddress("0xaa"), common.HexToAddress("0xbb")
+	sqrtPrice := new(uint256.Int).Lsh(uint256.NewInt(1), 96) // price 1
+	require.NoError(t, markets.RecordTrades(other, 60, []*domain.Trade{{SqrtPrice: sqrtPrice, Quantity: uint256.NewInt(7)}}))
+	require.NoError(t, markets.RecordTrades(hook, 120, []*domain.Trade{
+		{SqrtPrice: sqrtPrice, Quantity: uint256.NewInt(3)},
+		{SqrtPrice: new(uint256.Int).Lsh(sqrtPrice, 1), Quantity: uint256.NewInt(1)},
+	}))
+	ih := NewInspectHandler(markets)
+	query := func(query string) ([]byte, error) {
+		return ih.answer("0x" + common.Bytes2Hex([]byte(query)))
+	}
+
+	payload, err := query("stats?hook=" + hook.Hex())
+	require.NoError(t, err)
+	var stats statsReport
+	require.NoError(t, json.Unmarshal(payload, &stats))
+	assert.Equal(t, uint64(120), stats.To)
+	assert.Equal(t, 2, stats.Trades)
+	assert.Equal(t, "4", stats.Volume)
+	assert.Equal(t, "4", stats.High.Price)
+	assert.Equal(t, "1", stats.Low.Price)
+
+	payload, err = query("candles?hook=" + hook.Hex() + "&interval=5m&from=0&decimals0=6")
+	require.NoError(t, err)
+	var candles []candleReport
+	require.NoError(t, json.Unmarshal(payload, &candles))
+	require.Len(t, candles, 1)
+	assert.Equal(t, uint64(0), candles[0].Start)
+	assert.Equal(t, "1e-12", candles[0].Open.Price)
+
+	payload, err = query("stats?hook=" + common.HexToAddress("0xcc").Hex())
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(payload, &stats))
+	assert.Equal(t, 0, stats.Trades)
+
+	for _, invalid := range []string{"depth", "candles?interval=2m", "stats?at=soon", "candles?interval=1m&decimals1=300"} {
+		_, err := query(invalid)
+		assert.ErrorIs(t, err, ErrInvalidInspect, invalid)
+	}
+	for _, invalid := range []string{"candles?interval=2m", "stats?at=soon", "candles?interval=1m&decimals1=300"} {
+		_, err := query(invalid + "&hook=" + hook.Hex())
+		assert.ErrorIs(t, err, ErrInvalidInspect, invalid)
+	}
+}
@@ -3,45 +3,65 @@ package cartesi
 import (
 	"encoding/hex"
 	"log/slog"
-	"math/big"
 	"strings"
 
-	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/henriquemarlon/swapx/configs"
 	"github.com/henriquemarlon/swapx/internal/domain"
 	"github.com/henriquemarlon/swapx/internal/infra/service"
 	"github.com/henriquemarlon/swapx/internal/usecase"
 	"github.com/henriquemarlon/swapx/pkg/coprocessor"
+	"github.com/holiman/uint256"
 )
 
 type MatchOrdersHandler struct {
+	Allowlist                   *configs.Allowlist
+	Policy                      *usecase.MatchingPolicy
 	OrderRepository             domain.OrderRepository
+	CursorRepository            domain.CursorRepository
+	MarketRepository            domain.MarketRepository
+	SettlementRepository        domain.SettlementRepository
+	LineageRepository           domain.LineageRepository
 	HookStorageServiceInterface service.OrderStorageServiceInterface
+	BlockHeaderService          service.BlockHeaderServiceInterface
+	PoolStateService            service.PoolStateServiceInterface
 }
 
-func NewMatchOrdersHandler(orderRepository domain.OrderRepository, hookStorageServiceInterface service.OrderStorageServiceInterface) *MatchOrdersHandler {
+func NewMatchOrdersHandler(allowlist *configs.Allowlist, policy *usecase.MatchingPolicy, orderRepository domain.OrderRepository, cursorRepository domain.CursorRepository, marketRepository domain.MarketRepository, settlementRepository domain.SettlementRepository, lineageRepository domain.LineageRepository, hookStorageServiceInterface service.OrderStorageServiceInterface, blockHeaderService service.BlockHeaderServiceInterface, poolStateService service.PoolStateServiceInterface) *MatchOrdersHandler {
 	return &MatchOrdersHandler{
+		Allowlist:                   allowlist,
+		Policy:                      policy,
 		OrderRepository:             orderRepository,
+		CursorRepository:            cursorRepository,
+		MarketRepository:            marketRepository,
+		SettlementRepository:        settlementRepository,
+		LineageRepository:           lineageRepository,
 		HookStorageServiceInterface: hookStorageServiceInterface,
+		BlockHeaderService:          blockHeaderService,
+		PoolStateService:            poolStateService,
 	}
 }
 
 func (oh *MatchOrdersHandler) MatchOrdersHandler(input *coprocessor.AdvanceResponse) error {
-	addressType, _ := abi.NewType("address", "", nil)
-	uint256Type, _ := abi.NewType("uint256", "", nil)
-	inputArgs := abi.Arguments{
-		{Type: uint256Type},
-		{Type: uint256Type},
-		{Type: uint256Type},
-		{Type: uint256Type},
+	decodedData, err := hex.DecodeString(strings.TrimPrefix(input.Payload, "0x"))
+	if err != nil {
+		return err
+	}
+	if IsRematchTask(decodedData) {
+		return oh.rematch(input, decodedData)
 	}
 
-	decodedData, err := hex.DecodeString(strings.TrimPrefix(input.Payload, "0x"))
+	// Reject unknown senders before any base layer access is made on their behalf
+	if err := oh.Allowlist.Check(input.Metadata); err != nil {
+		return err
+	}
+
+	values, err := taskPayloadArguments().Unpack(decodedData)
 	if err != nil {
 		return err
 	}
 
-	values, err := inputArgs.Unpack(decodedData)
+	state, err := oh.prepare(input.Metadata, input.Metadata.MsgSender)
 	if err != nil {
 		return err
 	}
@@ -49,6 +69,8 @@ func (oh *MatchOrdersHandler) MatchOrdersHandler(input *coprocessor.AdvanceRespo
 	matchOrder := usecase.NewMatchOrdersUseCase(
 		oh.OrderRepository,
 		oh.HookStorageServiceInterface,
+		oh.PoolStateService,
+		oh.Policy,
 	)
 	res, err := matchOrder.Execute(&usecase.MatchOrdersInputDTO{
 		UnpackedArgs: values,
@@ -56,30 +78,328 @@ func (oh *MatchOrdersHandler) MatchOrdersHandler(input *coprocessor.AdvanceRespo
 	if err != nil {
 		if err == domain.ErrNoMatch {
 			slog.Info("No match found for order")
-			return nil
+			return oh.finishInput(input.Metadata, state)
+		}
+		if err == domain.ErrOrderPruned {
+			slog.Info("Order was already pruned, ignoring its task")
+			return oh.finishInput(input.Metadata, state)
+		}
+		return err
+	}
+
+	return oh.settle(input.Metadata, input.Metadata.MsgSender, NewTaskId(input.Metadata, res.Order), res, state)
+}
+
+// rematch matches the book of the hook named by a rematch task, which is
+// created by a keeper rather than by the hook
+func (oh *MatchOrdersHandler) rematch(input *coprocessor.AdvanceResponse, payload []byte) error {
+	task, err := DecodeRematchTask(payload)
+	if err != nil {
+		return err
+	}
+	if err := oh.Allowlist.CheckTask(input.Metadata, task.Hook); err != nil {
+		return err
+	}
+
+	state, err := oh.prepare(input.Metadata, task.Hook)
+	if err != nil {
+		return err
+	}
+
+	// a second rematch of the same sender in the same block has the id of the
+	// first, whose fills are still pending, and is left to a later one
+	taskId := NewRematchTaskId(input.Metadata, task.Hook)
+	sent, err := oh.isTaskPending(task.Hook, taskId)
+	if err != nil {
+		return err
+	}
+	if sent {
+		slog.Info("Rematch of this sender already settled in this block, nothing to rematch")
+		return oh.finishInput(input.Metadata, state)
+	}
+
+	res, err := usecase.NewRematchOrdersUseCase(
+		oh.OrderRepository,
+		oh.HookStorageServiceInterface,
+		oh.PoolStateService,
+		oh.Policy,
+	).Execute(&usecase.RematchOrdersInputDTO{Hook: task.Hook}, input.Metadata)
+	if err != nil {
+		if err == domain.ErrNoMatch {
+			slog.Info("Book is not crossed, nothing to rematch")
+			return oh.finishInput(input.Metadata, state)
+		}
+		return err
+	}
+	slog.Info("Rematched the book", "trades", len(res.Trades))
+
+	return oh.settle(input.Metadata, task.Hook, taskId, res, state)
+}
+
+// isTaskPending tells whether a fill or pool swap sent for taskId is still
+// pending
+func (oh *MatchOrdersHandler) isTaskPending(hook common.Address, taskId common.Hash) (bool, error) {
+	fills, err := oh.SettlementRepository.FindPendingFills(hook)
+	if err != nil {
+		return false, err
+	}
+	first := NewFillId(taskId, 0)
+	for _, fill := range fills {
+		if fill.FillId == first {
+			return true, nil
+		}
+	}
+	swaps, err := oh.SettlementRepository.FindPendingPoolSwaps(hook)
+	if err != nil {
+		return false, err
+	}
+	for _, swap := range swaps {
+		if swap.TaskId == taskId {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// inputState is what an input did around matching, for its state report
+type inputState struct {
+	reorg       *usecase.Reorg
+	settlements *usecase.ReconcileSettlementsOutputDTO
+	priceBand   *PriceBandReport
+}
+
+// prepare rolls back the inputs whose blocks left the chain, then confirms the
+// fills the hook has consumed and releases the ones that timed out, before the
+// book of the hook is matched
+func (oh *MatchOrdersHandler) prepare(metadata coprocessor.Metadata, hook common.Address) (*inputState, error) {
+	state := &inputState{}
+	if oh.Policy.ReorgDepthBlocks > 0 {
+		lineage, err := usecase.NewFollowLineageUseCase(
+			oh.LineageRepository,
+			oh.BlockHeaderService,
+			oh.Policy.ReorgDepthBlocks,
+		).Execute(metadata)
+		if err != nil {
+			return nil, err
+		}
+		state.reorg = lineage.Reorg
+	}
+
+	settlements, err := usecase.NewReconcileSettlementsUseCase(
+		oh.OrderRepository,
+		oh.SettlementRepository,
+		oh.HookStorageServiceInterface,
+		oh.Policy,
+	).Execute(&usecase.ReconcileSettlementsInputDTO{Hook: hook}, metadata)
+	if err != nil {
+		return nil, err
+	}
+	if len(settlements.Failed) > 0 || len(settlements.FailedSwaps) > 0 {
+		slog.Info("Released the fills and pool swaps that were not executed", "failed", len(settlements.Failed), "confirmed", settlements.Confirmed, "failed_swaps", len(settlements.FailedSwaps), "confirmed_swaps", settlements.ConfirmedSwaps)
+	}
+	state.settlements = settlements
+	return state, nil
+}
+
+// settle sends the notices of a match to the hook, then tracks their fills,
+// records the trades and finishes the input
+func (oh *MatchOrdersHandler) settle(metadata coprocessor.Metadata, hook common.Address, taskId common.Hash, res *usecase.MatchOrdersOutputDTO, state *inputState) error {
+	state.priceBand = NewPriceBandReport(res)
+	// a match that only held orders back has nothing to settle
+	if len(res.Trades) == 0 && len(res.Actions) == 0 {
+		return oh.finishInput(metadata, state)
+	}
+
+	expiryBlock := oh.Policy.FillExpiryBlock(metadata.BlockNumber)
+	notices, err := EncodeSettlementNotices(hook, taskId, res.Trades, expiryBlock, MAX_FILLS_PER_NOTICE)
+	if err != nil {
+		return err
+	}
+
+	// Actions go out after the settlement, so refunds see the fills of this input
+	if len(res.Actions) > 0 {
+		vouchers := make([]*Voucher, 0, len(res.Actions))
+		for _, action := range res.Actions {
+			if action.Kind == domain.ActionPoolSwap {
+				action.ExpiryBlock = expiryBlock
+			}
+			voucher, err := NewActionVoucher(hook, action)
+			if err != nil {
+				return err
+			}
+			vouchers = append(vouchers, voucher)
+		}
+
+		notice, err := EncodeVoucherNotice(hook, vouchers)
+		if err != nil {
+			return err
 		}
+		notices = append(notices, notice)
+	}
+
+	for _, notice := range notices {
+		if _, err := coprocessor.SendNotice(&coprocessor.NoticeRequest{Payload: "0x" + common.Bytes2Hex(notice)}); err != nil {
+			return err
+		}
+	}
+
+	if err := oh.trackFills(metadata, hook, taskId, expiryBlock, res.Trades); err != nil {
+		return err
+	}
+	if err := oh.trackPoolSwaps(metadata, hook, taskId, expiryBlock, res.Actions); err != nil {
 		return err
 	}
 
-	outputArgs := abi.Arguments{
-		{Type: addressType},
-		{Type: uint256Type},
-		{Type: uint256Type},
+	if err := oh.MarketRepository.RecordTrades(hook, metadata.Timestamp, res.Trades); err != nil {
+		return err
 	}
 
-	sender := input.Metadata.MsgSender
+	return oh.finishInput(metadata, state)
+}
 
-	for _, trade := range res.Trades {
-		encodedData, err := outputArgs.Pack(
-			sender,
-			new(big.Int).SetUint64(trade.BidId-1),
-			new(big.Int).SetUint64(trade.AskId-1),
-		)
+// trackFills records the fills sent for the trades as pending, with the
+// matched amounts each of them leaves on its orders. The orders already carry
+// the amounts of the last trade, so the trades are walked backwards.
+func (oh *MatchOrdersHandler) trackFills(metadata coprocessor.Metadata, hook common.Address, taskId common.Hash, expiryBlock uint64, trades []*domain.Trade) error {
+	matched := map[domain.OrderType]map[uint64]*uint256.Int{
+		domain.OrderTypeBuy:  {},
+		domain.OrderTypeSell: {},
+	}
+	matchedBefore := func(orderType domain.OrderType, id uint64, quantity *uint256.Int) (*uint256.Int, error) {
+		after, ok := matched[orderType][id]
+		if !ok {
+			order, err := oh.OrderRepository.FindOrderById(hook, orderType, id)
+			if err != nil {
+				return nil, err
+			}
+			after = order.MatchedAmount
+		}
+		matched[orderType][id] = new(uint256.Int).Sub(after, quantity)
+		return after, nil
+	}
+
+	fills := make([]*domain.PendingFill, len(trades))
+	for i := len(trades) - 1; i >= 0; i-- {
+		trade := trades[i]
+		bidMatched, err := matchedBefore(domain.OrderTypeBuy, trade.BidId, trade.Quantity)
 		if err != nil {
 			return err
 		}
-		coprocessor.SendNotice(&coprocessor.NoticeRequest{Payload: "0x" + common.Bytes2Hex(encodedData)})
+		askMatched, err := matchedBefore(domain.OrderTypeSell, trade.AskId, trade.Quantity)
+		if err != nil {
+			return err
+		}
+		fills[i] = &domain.PendingFill{
+			FillId:      NewFillId(taskId, uint64(i)),
+			Hook:        hook,
+			BidId:       trade.BidId,
+			AskId:       trade.AskId,
+			Quantity:    trade.Quantity,
+			BidMatched:  bidMatched,
+			AskMatched:  askMatched,
+			SentAt:      metadata.BlockNumber,
+			ExpiryBlock: expiryBlock,
+		}
 	}
 
+	for _, fill := range fills {
+		if err := oh.SettlementRepository.CreatePendingFill(fill); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// trackPoolSwaps records the swaps through the pool as pending, with the
+// matched amount the match left on their orders, which counts the swap and the
+// fills sent before it
+func (oh *MatchOrdersHandler) trackPoolSwaps(metadata coprocessor.Metadata, hook common.Address, taskId common.Hash, expiryBlock uint64, actions []*domain.Action) error {
+	for _, action := range actions {
+		if action.Kind != domain.ActionPoolSwap {
+			continue
+		}
+		order, err := oh.OrderRepository.FindOrderById(hook, *action.OrderType, action.OrderId)
+		if err != nil {
+			return err
+		}
+		swap := &domain.PendingPoolSwap{
+			Hook:        hook,
+			TaskId:      taskId,
+			OrderType:   *action.OrderType,
+			OrderId:     action.OrderId,
+			AmountIn:    action.Amount,
+			Matched:     order.MatchedAmount,
+			SentAt:      metadata.BlockNumber,
+			ExpiryBlock: expiryBlock,
+		}
+		if err := oh.SettlementRepository.CreatePendingPoolSwap(swap); err != nil {
+			return err
+		}
+	}
 	return nil
 }
+
+// finishInput prunes old terminal orders, moves the sync cursor past the input
+// and reports the state hash, the settlements, the reorg the input followed, the
+// orders still queued and the ones the price band kept out
+func (oh *MatchOrdersHandler) finishInput(metadata coprocessor.Metadata, state *inputState) error {
+	pruned, err := usecase.NewPruneOrdersUseCase(oh.OrderRepository, oh.Policy.RetentionBlocks).Execute(metadata.BlockNumber)
+	if err != nil {
+		return err
+	}
+	if pruned.Pruned > 0 {
+		slog.Info("Pruned terminal orders", "pruned", pruned.Pruned, "retention_blocks", oh.Policy.RetentionBlocks)
+	}
+
+	cursor, err := oh.CursorRepository.FindCursor()
+	if err != nil {
+		return err
+	}
+	cursor.BlockNumber = metadata.BlockNumber
+	cursor.BlockHash = common.HexToHash(metadata.BlockHash)
+	cursor.Inputs++
+	if err := oh.CursorRepository.UpdateCursor(cursor); err != nil {
+		return err
+	}
+
+	orders, err := oh.OrderRepository.FindAllOrders()
+	if err != nil && err != domain.ErrNoOrdersFound {
+		return err
+	}
+
+	stats, err := oh.OrderRepository.Stats()
+	if err != nil {
+		return err
+	}
+	report, err := NewStateReport(orders, stats)
+	if err != nil {
+		return err
+	}
+	if oh.Policy.ReportRuntimeMemory {
+		report.Runtime = ReadRuntimeMemory()
+	}
+	pending, err := oh.SettlementRepository.CountPendingFills()
+	if err != nil {
+		return err
+	}
+	pendingSwaps, err := oh.SettlementRepository.CountPendingPoolSwaps()
+	if err != nil {
+		return err
+	}
+	report.Settlements = NewSettlementReport(pending, pendingSwaps, state.settlements)
+	report.Reorg = state.reorg
+	report.PriceBand = state.priceBand
+	for _, order := range orders {
+		if order.IsQueued() {
+			report.Queued = append(report.Queued, domain.NewQueuedOrder(order, oh.Policy.ConfirmationDepthBlocks))
+		}
+	}
+	payload, err := EncodeStateReport(report)
+	if err != nil {
+		return err
+	}
+	slog.Info("State hash", "hash", report.StateHash.Hex(), "orders", report.Orders, "tombstones", stats.BuyTombstones+stats.SellTombstones)
+
+	_, err = coprocessor.SendReport(&coprocessor.ReportRequest{Payload: "0x" + common.Bytes2Hex(payload)})
+	return err
+}
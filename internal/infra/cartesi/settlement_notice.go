@@ -0,0 +1,171 @@
+package cartesi
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/henriquemarlon/swapx/internal/domain"
+	"github.com/henriquemarlon/swapx/pkg/coprocessor"
+)
+
+// Keep in sync with SwapXTaskManager.MAX_FILLS_PER_BATCH
+const MAX_FILLS_PER_NOTICE = 32
+
+const settlementABI = `[
+	{
+		"name": "SettleBatch",
+		"type": "function",
+		"stateMutability": "nonpayable",
+		"inputs": [
+			{"name": "hook", "type": "address"},
+			{
+				"name": "fills",
+				"type": "tuple[]",
+				"components": [
+					{"name": "fillId", "type": "bytes32"},
+					{"name": "buyOrderId", "type": "uint256"},
+					{"name": "sellOrderId", "type": "uint256"},
+					{"name": "quantity", "type": "uint256"},
+					{"name": "expiryBlock", "type": "uint256"}
+				]
+			}
+		],
+		"outputs": []
+	}
+]`
+
+var ErrInvalidBatchSize = errors.New("invalid batch size")
+
+type Fill struct {
+	FillId      [32]byte `json:"fillId"`
+	BuyOrderId  *big.Int `json:"buyOrderId"`
+	SellOrderId *big.Int `json:"sellOrderId"`
+	Quantity    *big.Int `json:"quantity"`
+	ExpiryBlock *big.Int `json:"expiryBlock"`
+}
+
+type SettlementNotice struct {
+	Hook  common.Address `json:"hook"`
+	Fills []Fill         `json:"fills"`
+}
+
+// NewTaskId identifies the task that produced an input: the hook that created
+// it, the block it was created in and the side and index of the incoming order.
+func NewTaskId(metadata coprocessor.Metadata, order *domain.Order) common.Hash {
+	side := big.NewInt(0)
+	if *order.Type == domain.OrderTypeSell {
+		side = big.NewInt(1)
+	}
+	return crypto.Keccak256Hash(
+		common.LeftPadBytes(new(big.Int).SetUint64(metadata.ChainId).Bytes(), 32),
+		common.LeftPadBytes(metadata.MsgSender.Bytes(), 32),
+		common.HexToHash(metadata.BlockHash).Bytes(),
+		common.LeftPadBytes(side.Bytes(), 32),
+		common.LeftPadBytes(new(big.Int).SetUint64(order.Id).Bytes(), 32),
+	)
+}
+
+// NewRematchTaskId identifies a rematch task by the input metadata alone: the
+// chain, the sender of the task and the block and time it was created at,
+// along with the hook it is about. The side word is 2, so it never collides
+// with the id of an order task.
+func NewRematchTaskId(metadata coprocessor.Metadata, hook common.Address) common.Hash {
+	return crypto.Keccak256Hash(
+		common.LeftPadBytes(new(big.Int).SetUint64(metadata.ChainId).Bytes(), 32),
+		common.LeftPadBytes(metadata.MsgSender.Bytes(), 32),
+		common.LeftPadBytes(hook.Bytes(), 32),
+		common.HexToHash(metadata.BlockHash).Bytes(),
+		common.LeftPadBytes(big.NewInt(2).Bytes(), 32),
+		common.LeftPadBytes(new(big.Int).SetUint64(metadata.Timestamp).Bytes(), 32),
+	)
+}
+
+// NewFillId derives the identifier of the fill at the given position of the
+// trades produced for a task. Replaying the same input yields the same ids,
+// which lets the hook refuse to settle a fill twice.
+func NewFillId(taskId common.Hash, position uint64) common.Hash {
+	return crypto.Keccak256Hash(
+		taskId.Bytes(),
+		common.LeftPadBytes(new(big.Int).SetUint64(position).Bytes(), 32),
+	)
+}
+
+// EncodeSettlementNotices packs the trades into Outputs.SettleBatch calls of at
+// most maxFills fills each, so a large match never exceeds what the task
+// manager accepts in a single notice. The hook refuses the fills past
+// expiryBlock.
+func EncodeSettlementNotices(hook common.Address, taskId common.Hash, trades []*domain.Trade, expiryBlock uint64, maxFills int) ([][]byte, error) {
+	if maxFills <= 0 {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidBatchSize, maxFills)
+	}
+
+	method, err := settlementMethod()
+	if err != nil {
+		return nil, err
+	}
+
+	var notices [][]byte
+	for start := 0; start < len(trades); start += maxFills {
+		end := min(start+maxFills, len(trades))
+
+		fills := make([]Fill, 0, end-start)
+		for i, trade := range trades[start:end] {
+			fills = append(fills, Fill{
+				FillId: NewFillId(taskId, uint64(start+i)),
+				// the order ids inside of the dApp are 1-based, the hook arrays are 0-based
+				BuyOrderId:  new(big.Int).SetUint64(trade.BidId - 1),
+				SellOrderId: new(big.Int).SetUint64(trade.AskId - 1),
+				Quantity:    trade.Quantity.ToBig(),
+				ExpiryBlock: new(big.Int).SetUint64(expiryBlock),
+			})
+		}
+
+		args, err := method.Inputs.Pack(hook, fills)
+		if err != nil {
+			return nil, err
+		}
+		notices = append(notices, append(method.ID, args...))
+	}
+	return notices, nil
+}
+
+func DecodeSettlementNotice(payload []byte) (*SettlementNotice, error) {
+	method, err := settlementMethod()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(payload) < 4 || !bytes.Equal(payload[:4], method.ID) {
+		return nil, fmt.Errorf("payload is not a SettleBatch notice")
+	}
+
+	args, err := method.Inputs.Unpack(payload[4:])
+	if err != nil {
+		return nil, fmt.Errorf("error decoding notice: %v", err)
+	}
+
+	var notice SettlementNotice
+	if err := method.Inputs.Copy(&notice, args); err != nil {
+		return nil, fmt.Errorf("error decoding notice: %v", err)
+	}
+	return &notice, nil
+}
+
+func settlementMethod() (abi.Method, error) {
+	parsedABI, err := abi.JSON(strings.NewReader(settlementABI))
+	if err != nil {
+		return abi.Method{}, fmt.Errorf("error parsing ABI: %v", err)
+	}
+
+	method, exists := parsedABI.Methods["SettleBatch"]
+	if !exists {
+		return abi.Method{}, fmt.Errorf("method SettleBatch not found in ABI")
+	}
+	return method, nil
+}
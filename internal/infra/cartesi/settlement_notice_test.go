@@ -0,0 +1,132 @@
+package cartesi
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/henriquemarlon/swapx/internal/domain"
+	"github.com/henriquemarlon/swapx/pkg/coprocessor"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/assert"
+)
+
+var testHook = common.HexToAddress("0x1")
+
+var testMetadata = coprocessor.Metadata{
+	ChainId:     31337,
+	TaskManager: common.HexToAddress("0x2"),
+	MsgSender:   testHook,
+	BlockHash:   "0x41c1b7d4b5e2cba34a0a3c6d4f1e9b6a12f0a7b4f7a2c1e1d9b0c3a4f5e6d7c8",
+	BlockNumber: 10,
+}
+
+func testOrder(id uint64, orderType domain.OrderType) *domain.Order {
+	return &domain.Order{Id: id, Hook: testHook, Type: &orderType}
+}
+
+func TestSettlementNoticeRoundTrip(t *testing.T) {
+	trades := []*domain.Trade{
+		{BidId: 1, AskId: 3, Quantity: uint256.NewInt(60)},
+		{BidId: 1, AskId: 2, Quantity: uint256.NewInt(40)},
+	}
+
+	taskId := NewTaskId(testMetadata, testOrder(1, domain.OrderTypeBuy))
+	notices, err := EncodeSettlementNotices(testHook, taskId, trades, 100, MAX_FILLS_PER_NOTICE)
+	assert.NoError(t, err)
+	assert.Len(t, notices, 1)
+
+	notice, err := DecodeSettlementNotice(notices[0])
+	assert.NoError(t, err)
+	assert.Equal(t, testHook, notice.Hook)
+	assert.Len(t, notice.Fills, 2)
+	assert.Equal(t, [32]byte(NewFillId(taskId, 0)), notice.Fills[0].FillId)
+	assert.Equal(t, [32]byte(NewFillId(taskId, 1)), notice.Fills[1].FillId)
+	assert.Zero(t, notice.Fills[0].BuyOrderId.Sign())
+	assert.Equal(t, big.NewInt(2), notice.Fills[0].SellOrderId)
+	assert.Equal(t, big.NewInt(60), notice.Fills[0].Quantity)
+	assert.Zero(t, notice.Fills[1].BuyOrderId.Sign())
+	assert.Equal(t, big.NewInt(1), notice.Fills[1].SellOrderId)
+	assert.Equal(t, big.NewInt(40), notice.Fills[1].Quantity)
+}
+
+func TestSettlementNoticesAreCappedBySize(t *testing.T) {
+	var trades []*domain.Trade
+	for i := uint64(1); i <= 5; i++ {
+		trades = append(trades, &domain.Trade{BidId: i, AskId: i, Quantity: uint256.NewInt(i)})
+	}
+
+	taskId := NewTaskId(testMetadata, testOrder(5, domain.OrderTypeSell))
+	notices, err := EncodeSettlementNotices(testHook, taskId, trades, 100, 2)
+	assert.NoError(t, err)
+	assert.Len(t, notices, 3)
+
+	var fills []Fill
+	for _, payload := range notices {
+		notice, err := DecodeSettlementNotice(payload)
+		assert.NoError(t, err)
+		assert.LessOrEqual(t, len(notice.Fills), 2)
+		fills = append(fills, notice.Fills...)
+	}
+	assert.Len(t, fills, 5)
+	assert.Equal(t, big.NewInt(5), fills[4].Quantity)
+
+	// positions keep counting across notices, so every fill of the task is unique
+	for i, fill := range fills {
+		assert.Equal(t, [32]byte(NewFillId(taskId, uint64(i))), fill.FillId)
+	}
+}
+
+func TestSettlementNoticesRejectInvalidBatchSize(t *testing.T) {
+	_, err := EncodeSettlementNotices(testHook, common.Hash{}, nil, 100, 0)
+	assert.ErrorIs(t, err, ErrInvalidBatchSize)
+}
+
+func TestDecodeSettlementNoticeRejectsUnknownSelector(t *testing.T) {
+	_, err := DecodeSettlementNotice(common.FromHex("0xdeadbeef"))
+	assert.Error(t, err)
+}
+
+func TestFillIdsAreDeterministic(t *testing.T) {
+	trades := []*domain.Trade{{BidId: 1, AskId: 2, Quantity: uint256.NewInt(50)}}
+
+	first, err := EncodeSettlementNotices(testHook, NewTaskId(testMetadata, testOrder(2, domain.OrderTypeSell)), trades, 100, MAX_FILLS_PER_NOTICE)
+	assert.NoError(t, err)
+	second, err := EncodeSettlementNotices(testHook, NewTaskId(testMetadata, testOrder(2, domain.OrderTypeSell)), trades, 100, MAX_FILLS_PER_NOTICE)
+	assert.NoError(t, err)
+	assert.Equal(t, first, second)
+}
+
+func TestFillIdsAreUniquePerTask(t *testing.T) {
+	buyTask := NewTaskId(testMetadata, testOrder(3, domain.OrderTypeBuy))
+	sellTask := NewTaskId(testMetadata, testOrder(3, domain.OrderTypeSell))
+	assert.NotEqual(t, buyTask, sellTask)
+
+	nextBlock := testMetadata
+	nextBlock.BlockHash = "0x9c3e5a2f1d0b8e7c6a5f4d3c2b1a0e9f8d7c6b5a4f3e2d1c0b9a8f7e6d5c4b3a"
+	assert.NotEqual(t, buyTask, NewTaskId(nextBlock, testOrder(3, domain.OrderTypeBuy)))
+
+	otherHook := testMetadata
+	otherHook.MsgSender = common.HexToAddress("0x3")
+	assert.NotEqual(t, buyTask, NewTaskId(otherHook, testOrder(3, domain.OrderTypeBuy)))
+
+	assert.NotEqual(t, NewFillId(buyTask, 0), NewFillId(buyTask, 1))
+	assert.NotEqual(t, NewFillId(buyTask, 0), NewFillId(sellTask, 0))
+}
+
+func TestRematchTaskIdsOnlyDependOnTheInput(t *testing.T) {
+	hook := common.HexToAddress("0xaa")
+	rematch := NewRematchTaskId(testMetadata, hook)
+	assert.Equal(t, rematch, NewRematchTaskId(testMetadata, hook))
+	assert.NotEqual(t, rematch, NewTaskId(testMetadata, testOrder(2, domain.OrderTypeBuy)))
+
+	otherSender := testMetadata
+	otherSender.MsgSender = common.HexToAddress("0x3")
+	assert.NotEqual(t, rematch, NewRematchTaskId(otherSender, hook))
+
+	later := testMetadata
+	later.Timestamp++
+	assert.NotEqual(t, rematch, NewRematchTaskId(later, hook))
+
+	assert.NotEqual(t, rematch, NewRematchTaskId(testMetadata, common.HexToAddress("0xbb")))
+}
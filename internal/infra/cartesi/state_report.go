@@ -0,0 +1,106 @@
+package cartesi
+
+import (
+	"encoding/json"
+	"runtime"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/henriquemarlon/swapx/internal/domain"
+	"github.com/henriquemarlon/swapx/internal/usecase"
+	"github.com/holiman/uint256"
+)
+
+// StateReport is emitted after every accepted input so operators can compare
+// their books without exchanging them. Reports do not reach the base layer.
+type StateReport struct {
+	StateHash   common.Hash           `json:"state_hash"`
+	Orders      int                   `json:"orders"`
+	Book        *domain.BookStats     `json:"book,omitempty"`
+	Runtime     *RuntimeMemory        `json:"runtime,omitempty"`
+	Settlements *SettlementReport     `json:"settlements,omitempty"`
+	Reorg       *usecase.Reorg        `json:"reorg,omitempty"`
+	Queued      []*domain.QueuedOrder `json:"queued,omitempty"`
+	PriceBand   *PriceBandReport      `json:"price_band,omitempty"`
+}
+
+// PriceBandReport lists the orders the price band kept out of the match of the
+// input, next to the spot sqrtPriceX96 it was centered on
+type PriceBandReport struct {
+	Spot   *uint256.Int          `json:"spot"`
+	Banded []*domain.BandedOrder `json:"banded"`
+}
+
+// SettlementReport counts the fills and pool swaps still waiting for the hook
+// and lists the ones given up on by this input
+type SettlementReport struct {
+	Pending        int                       `json:"pending"`
+	Confirmed      int                       `json:"confirmed"`
+	Failed         []*usecase.FailedFill     `json:"failed,omitempty"`
+	PendingSwaps   int                       `json:"pending_swaps,omitempty"`
+	ConfirmedSwaps int                       `json:"confirmed_swaps,omitempty"`
+	FailedSwaps    []*usecase.FailedPoolSwap `json:"failed_swaps,omitempty"`
+}
+
+// RuntimeMemory is taken from runtime.MemStats, in bytes
+type RuntimeMemory struct {
+	HeapAlloc uint64 `json:"heap_alloc"`
+	HeapInuse uint64 `json:"heap_inuse"`
+	Sys       uint64 `json:"sys"`
+	NumGC     uint32 `json:"num_gc"`
+}
+
+func NewStateReport(orders []*domain.Order, stats *domain.BookStats) (*StateReport, error) {
+	hash, err := domain.StateHash(orders)
+	if err != nil {
+		return nil, err
+	}
+	return &StateReport{StateHash: hash, Orders: len(orders), Book: stats}, nil
+}
+
+// NewSettlementReport returns nil when nothing is followed, so books that never
+// settled anything keep reporting what they did before
+func NewSettlementReport(pending, pendingSwaps int, settlements *usecase.ReconcileSettlementsOutputDTO) *SettlementReport {
+	report := &SettlementReport{Pending: pending, PendingSwaps: pendingSwaps}
+	if settlements != nil {
+		report.Confirmed = settlements.Confirmed
+		report.Failed = settlements.Failed
+		report.ConfirmedSwaps = settlements.ConfirmedSwaps
+		report.FailedSwaps = settlements.FailedSwaps
+	}
+	if report.Pending == 0 && report.Confirmed == 0 && len(report.Failed) == 0 &&
+		report.PendingSwaps == 0 && report.ConfirmedSwaps == 0 && len(report.FailedSwaps) == 0 {
+		return nil
+	}
+	return report
+}
+
+// NewPriceBandReport returns nil when the band kept no order out
+func NewPriceBandReport(res *usecase.MatchOrdersOutputDTO) *PriceBandReport {
+	if len(res.Banded) == 0 {
+		return nil
+	}
+	return &PriceBandReport{Spot: res.Spot, Banded: res.Banded}
+}
+
+func ReadRuntimeMemory() *RuntimeMemory {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	return &RuntimeMemory{
+		HeapAlloc: stats.HeapAlloc,
+		HeapInuse: stats.HeapInuse,
+		Sys:       stats.Sys,
+		NumGC:     stats.NumGC,
+	}
+}
+
+func EncodeStateReport(report *StateReport) ([]byte, error) {
+	return json.Marshal(report)
+}
+
+func DecodeStateReport(payload []byte) (*StateReport, error) {
+	var report StateReport
+	if err := json.Unmarshal(payload, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
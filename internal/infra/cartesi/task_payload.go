@@ -0,0 +1,153 @@
+package cartesi
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/henriquemarlon/swapx/internal/domain"
+	"github.com/henriquemarlon/swapx/pkg/coprocessor"
+	"github.com/holiman/uint256"
+)
+
+const rematchTaskABI = `[
+	{
+		"name": "Rematch",
+		"type": "function",
+		"stateMutability": "nonpayable",
+		"inputs": [
+			{"name": "hook", "type": "address"}
+		],
+		"outputs": []
+	}
+]`
+
+// TaskPayload is the payload SwapXHook hands to the task manager for every order:
+// abi.encode(orderId, sqrtPrice, amount, side, flags), where the order id is the
+// length of the order array right after the push and side is 0 for buy orders.
+type TaskPayload struct {
+	OrderId   uint64           `json:"order_id"`
+	SqrtPrice *uint256.Int     `json:"sqrt_price"`
+	Amount    *uint256.Int     `json:"amount"`
+	Type      domain.OrderType `json:"type"`
+	Flags     domain.OrderFlag `json:"flags"`
+}
+
+func EncodeTaskPayload(task *TaskPayload) ([]byte, error) {
+	side := big.NewInt(1)
+	if task.Type == domain.OrderTypeBuy {
+		side = big.NewInt(0)
+	}
+
+	return taskPayloadArguments().Pack(
+		new(big.Int).SetUint64(task.OrderId),
+		task.SqrtPrice.ToBig(),
+		task.Amount.ToBig(),
+		side,
+		new(big.Int).SetUint64(uint64(task.Flags)),
+	)
+}
+
+func DecodeTaskPayload(payload []byte) (*TaskPayload, error) {
+	values, err := taskPayloadArguments().Unpack(payload)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding task payload: %v", err)
+	}
+
+	orderType := domain.OrderTypeSell
+	if values[3].(*big.Int).Sign() == 0 {
+		orderType = domain.OrderTypeBuy
+	}
+
+	return &TaskPayload{
+		OrderId:   values[0].(*big.Int).Uint64(),
+		SqrtPrice: uint256.MustFromBig(values[1].(*big.Int)),
+		Amount:    uint256.MustFromBig(values[2].(*big.Int)),
+		Type:      orderType,
+		Flags:     domain.OrderFlag(values[4].(*big.Int).Uint64()),
+	}, nil
+}
+
+// RematchTask asks for the book of a hook to be matched again without a new
+// order: Tasks.Rematch(hook). Anyone can create it, so the hook is checked
+// against the allowlist instead of the sender of the input.
+type RematchTask struct {
+	Hook common.Address `json:"hook"`
+}
+
+func EncodeRematchTask(task *RematchTask) ([]byte, error) {
+	method, err := rematchTaskMethod()
+	if err != nil {
+		return nil, err
+	}
+	args, err := method.Inputs.Pack(task.Hook)
+	if err != nil {
+		return nil, err
+	}
+	return append(method.ID, args...), nil
+}
+
+// IsRematchTask tells a rematch task from an order payload by its selector,
+// order payloads are made of whole words and have none
+func IsRematchTask(payload []byte) bool {
+	method, err := rematchTaskMethod()
+	return err == nil && len(payload) == 4+32 && bytes.Equal(payload[:4], method.ID)
+}
+
+func DecodeRematchTask(payload []byte) (*RematchTask, error) {
+	if !IsRematchTask(payload) {
+		return nil, fmt.Errorf("error decoding rematch task: not a Rematch call")
+	}
+	method, err := rematchTaskMethod()
+	if err != nil {
+		return nil, err
+	}
+	values, err := method.Inputs.Unpack(payload[4:])
+	if err != nil {
+		return nil, fmt.Errorf("error decoding rematch task: %v", err)
+	}
+	return &RematchTask{Hook: values[0].(common.Address)}, nil
+}
+
+// InputLogArgs are the attributes that tie a log line to the input being
+// handled. The task index is left out when the payload is not a task.
+func InputLogArgs(input *coprocessor.AdvanceResponse) []any {
+	args := []any{
+		"block_number", input.Metadata.BlockNumber,
+		"msg_sender", input.Metadata.MsgSender.Hex(),
+	}
+	payload := common.FromHex(input.Payload)
+	if task, err := DecodeRematchTask(payload); err == nil {
+		args = append(args, "rematch_hook", task.Hook.Hex())
+	} else if task, err := DecodeTaskPayload(payload); err == nil {
+		args = append(args, "task_index", task.OrderId)
+	}
+	return args
+}
+
+func taskPayloadArguments() abi.Arguments {
+	uint256Type, _ := abi.NewType("uint256", "", nil)
+	return abi.Arguments{
+		{Type: uint256Type},
+		{Type: uint256Type},
+		{Type: uint256Type},
+		{Type: uint256Type},
+		{Type: uint256Type},
+	}
+}
+
+func rematchTaskMethod() (abi.Method, error) {
+	parsedABI, err := abi.JSON(strings.NewReader(rematchTaskABI))
+	if err != nil {
+		return abi.Method{}, fmt.Errorf("error parsing ABI: %v", err)
+	}
+
+	method, exists := parsedABI.Methods["Rematch"]
+	if !exists {
+		return abi.Method{}, fmt.Errorf("method Rematch not found in ABI")
+	}
+	return method, nil
+}
@@ -0,0 +1,51 @@
+package cartesi
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/henriquemarlon/swapx/internal/domain"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTaskPayloadRoundTrip(t *testing.T) {
+	task := &TaskPayload{
+		OrderId:   3,
+		SqrtPrice: uint256.NewInt(1000),
+		Amount:    uint256.NewInt(100),
+		Type:      domain.OrderTypeSell,
+		Flags:     domain.OrderFlagImmediateOrCancel,
+	}
+
+	payload, err := EncodeTaskPayload(task)
+	assert.NoError(t, err)
+	assert.Len(t, payload, 5*32)
+	// side is the fourth word, 1 for sell orders
+	assert.Equal(t, byte(1), payload[4*32-1])
+
+	decoded, err := DecodeTaskPayload(payload)
+	assert.NoError(t, err)
+	assert.Equal(t, task, decoded)
+
+	_, err = DecodeTaskPayload(payload[:4*32])
+	assert.Error(t, err)
+}
+
+func TestRematchTaskIsToldFromOrderPayloads(t *testing.T) {
+	hook := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	payload, err := EncodeRematchTask(&RematchTask{Hook: hook})
+	assert.NoError(t, err)
+	assert.Len(t, payload, 4+32)
+	assert.True(t, IsRematchTask(payload))
+
+	task, err := DecodeRematchTask(payload)
+	assert.NoError(t, err)
+	assert.Equal(t, hook, task.Hook)
+
+	order, err := EncodeTaskPayload(&TaskPayload{OrderId: 1, SqrtPrice: uint256.NewInt(1), Amount: uint256.NewInt(1), Type: domain.OrderTypeBuy})
+	assert.NoError(t, err)
+	assert.False(t, IsRematchTask(order))
+	_, err = DecodeRematchTask(order)
+	assert.Error(t, err)
+}
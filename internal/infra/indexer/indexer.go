@@ -0,0 +1,366 @@
+package indexer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/henriquemarlon/swapx/configs"
+	"github.com/henriquemarlon/swapx/internal/domain"
+	"github.com/henriquemarlon/swapx/internal/infra/repository"
+	"github.com/henriquemarlon/swapx/internal/usecase"
+	"github.com/henriquemarlon/swapx/pkg/swapx"
+	"github.com/holiman/uint256"
+)
+
+// DEFAULT_REORG_DEPTH is the number of recent blocks kept to roll back from
+const DEFAULT_REORG_DEPTH = 64
+
+var ErrReorgTooDeep = errors.New("reorg deeper than the blocks kept")
+
+type Block struct {
+	Number     uint64      `json:"number"`
+	Hash       common.Hash `json:"hash"`
+	ParentHash common.Hash `json:"parent_hash"`
+	Timestamp  uint64      `json:"timestamp"`
+}
+
+// Chain is what the indexer reads from a node. BlockByNumber returns
+// ethereum.NotFound past the head.
+type Chain interface {
+	HeadNumber(ctx context.Context) (uint64, error)
+	BlockByNumber(ctx context.Context, number uint64) (*Block, error)
+	FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error)
+}
+
+// Publisher is told about the changes of the book once they are applied: the
+// events of each block that has any, and the block a reorg went back to.
+type Publisher interface {
+	PublishBlock(block Block, events []*domain.HookEvent)
+	PublishRollback(block Block)
+}
+
+type hookEvent struct {
+	name      string
+	eventType domain.HookEventType
+}
+
+type checkpoint struct {
+	block    uint64
+	snapshot *repository.Snapshot
+}
+
+// Indexer mirrors the book of a hook from its events. Blocks are followed one
+// by one through their parent hash, and the state of the book before each
+// block that changed it is kept for the last ReorgDepth blocks, so a reorg is
+// undone by going back to the common ancestor and following the new branch.
+type Indexer struct {
+	Chain      Chain
+	Hook       common.Address
+	FromBlock  uint64
+	ReorgDepth uint64
+	Publisher  Publisher
+
+	db          *configs.InMemoryDB
+	fills       domain.FillRepository
+	cursor      domain.CursorRepository
+	applyEvent  *usecase.ApplyHookEventUseCase
+	contract    *bind.BoundContract
+	events      map[common.Hash]hookEvent
+	blocks      []Block
+	checkpoints []checkpoint
+}
+
+type SyncOutputDTO struct {
+	Blocks int    `json:"blocks"`
+	Events int    `json:"events"`
+	Reorgs int    `json:"reorgs"`
+	Head   uint64 `json:"head"`
+	// HeadHash is the hash of the Head block
+	HeadHash common.Hash `json:"head_hash"`
+}
+
+func NewIndexer(chain Chain, db *configs.InMemoryDB, hook common.Address, fromBlock, reorgDepth uint64) (*Indexer, error) {
+	hookAbi, err := swapx.SwapXHookMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	if reorgDepth == 0 {
+		reorgDepth = DEFAULT_REORG_DEPTH
+	}
+
+	events := make(map[common.Hash]hookEvent)
+	for name, eventType := range map[string]domain.HookEventType{
+		"OrderCreated":            domain.HookEventOrderCreated,
+		"OrderFulfilled":          domain.HookEventOrderFulfilled,
+		"OrderPartiallyFulfilled": domain.HookEventOrderPartiallyFulfilled,
+		"OrderCancelled":          domain.HookEventOrderCancelled,
+		"OrderRefunded":           domain.HookEventOrderRefunded,
+		"OrderDustReturned":       domain.HookEventOrderDustReturned,
+		"OrderRoutedToPool":       domain.HookEventOrderRoutedToPool,
+	} {
+		events[hookAbi.Events[name].ID] = hookEvent{name: name, eventType: eventType}
+	}
+
+	fills := repository.NewFillRepositoryInMemory(db)
+	return &Indexer{
+		Chain:      chain,
+		Hook:       hook,
+		FromBlock:  fromBlock,
+		ReorgDepth: reorgDepth,
+		db:         db,
+		fills:      fills,
+		cursor:     repository.NewCursorRepositoryInMemory(db),
+		applyEvent: usecase.NewApplyHookEventUseCase(repository.NewOrderRepositoryInMemory(db), fills),
+		contract:   bind.NewBoundContract(hook, *hookAbi, nil, nil, nil),
+		events:     events,
+	}, nil
+}
+
+// Run syncs to the head of the chain every interval until ctx is done
+func (ix *Indexer) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		output, err := ix.Sync(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		if output.Blocks > 0 {
+			slog.Info("Indexer synced", "head", output.Head, "blocks", output.Blocks, "events", output.Events, "reorgs", output.Reorgs)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sync follows the chain up to its current head
+func (ix *Indexer) Sync(ctx context.Context) (*SyncOutputDTO, error) {
+	head, err := ix.Chain.HeadNumber(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	output := &SyncOutputDTO{}
+	next := ix.FromBlock
+	if last := ix.lastBlock(); last != nil {
+		next = last.Number + 1
+
+		// a reorg that replaced the last block without a longer branch yet
+		current, err := ix.Chain.BlockByNumber(ctx, last.Number)
+		if err != nil && !errors.Is(err, ethereum.NotFound) {
+			return nil, err
+		}
+		if err == nil && current.Hash != last.Hash {
+			slog.Warn("Reorg detected", "block_number", last.Number, "hash", current.Hash.Hex(), "known_hash", last.Hash.Hex())
+			next = last.Number
+			if err := ix.rollback(next); err != nil {
+				return nil, err
+			}
+			output.Reorgs++
+		}
+	}
+
+	for next <= head {
+		block, err := ix.Chain.BlockByNumber(ctx, next)
+		if errors.Is(err, ethereum.NotFound) {
+			// the head moved back while syncing, the next sync picks it up
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if last := ix.lastBlock(); last != nil && block.ParentHash != last.Hash {
+			slog.Warn("Reorg detected", "block_number", block.Number, "parent_hash", block.ParentHash.Hex(), "known_hash", last.Hash.Hex())
+			next = last.Number
+			if err := ix.rollback(next); err != nil {
+				return nil, err
+			}
+			output.Reorgs++
+			continue
+		}
+
+		events, err := ix.applyBlock(ctx, block)
+		if err != nil {
+			return nil, err
+		}
+		output.Blocks++
+		output.Events += events
+		next++
+	}
+
+	if last := ix.lastBlock(); last != nil {
+		output.Head, output.HeadHash = last.Number, last.Hash
+	}
+	return output, nil
+}
+
+func (ix *Indexer) lastBlock() *Block {
+	if len(ix.blocks) == 0 {
+		return nil
+	}
+	return &ix.blocks[len(ix.blocks)-1]
+}
+
+func (ix *Indexer) applyBlock(ctx context.Context, block *Block) (int, error) {
+	hash := block.Hash
+	logs, err := ix.Chain.FilterLogs(ctx, ethereum.FilterQuery{
+		BlockHash: &hash,
+		Addresses: []common.Address{ix.Hook},
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	events := make([]*domain.HookEvent, 0, len(logs))
+	for _, log := range logs {
+		event, err := ix.decodeEvent(log, block)
+		if err != nil {
+			return 0, err
+		}
+		if event != nil {
+			events = append(events, event)
+		}
+	}
+
+	if len(events) > 0 {
+		snapshot, err := repository.ExportSnapshot(ix.db)
+		if err != nil {
+			return 0, err
+		}
+		ix.checkpoints = append(ix.checkpoints, checkpoint{block: block.Number, snapshot: snapshot})
+	}
+	for _, event := range events {
+		err := ix.applyEvent.Execute(event)
+		if errors.Is(err, domain.ErrOrderNotFound) {
+			slog.Warn("Event of an order created before the first indexed block", "type", event.Type, "order_type", event.OrderType, "order_id", event.OrderId, "block_number", event.BlockNumber)
+			continue
+		}
+		if err != nil {
+			return 0, fmt.Errorf("block %d, log %d: %w", event.BlockNumber, event.LogIndex, err)
+		}
+	}
+
+	ix.blocks = append(ix.blocks, *block)
+	if uint64(len(ix.blocks)) > ix.ReorgDepth {
+		ix.blocks = ix.blocks[uint64(len(ix.blocks))-ix.ReorgDepth:]
+	}
+	// a checkpoint is the state before its block, rollbacks never go below the oldest block kept
+	for len(ix.checkpoints) > 0 && ix.checkpoints[0].block <= ix.blocks[0].Number {
+		ix.checkpoints = ix.checkpoints[1:]
+	}
+
+	if err := ix.cursor.UpdateCursor(&domain.SyncCursor{BlockNumber: block.Number, BlockHash: block.Hash}); err != nil {
+		return 0, err
+	}
+	if ix.Publisher != nil && len(events) > 0 {
+		ix.Publisher.PublishBlock(*block, events)
+	}
+	return len(events), nil
+}
+
+// rollback undoes the blocks from the given one on, restoring the book as it
+// was at the end of the block before
+func (ix *Indexer) rollback(from uint64) error {
+	if len(ix.blocks) == 0 || ix.blocks[0].Number >= from {
+		return fmt.Errorf("%w: %d blocks", ErrReorgTooDeep, ix.ReorgDepth)
+	}
+
+	for i, cp := range ix.checkpoints {
+		if cp.block >= from {
+			if err := repository.ImportSnapshot(ix.db, cp.snapshot); err != nil {
+				return err
+			}
+			ix.checkpoints = ix.checkpoints[:i]
+			break
+		}
+	}
+	if err := ix.fills.DeleteFillsAfter(from - 1); err != nil {
+		return err
+	}
+
+	for ix.lastBlock().Number >= from {
+		ix.blocks = ix.blocks[:len(ix.blocks)-1]
+	}
+	last := ix.lastBlock()
+	if err := ix.cursor.UpdateCursor(&domain.SyncCursor{BlockNumber: last.Number, BlockHash: last.Hash}); err != nil {
+		return err
+	}
+	if ix.Publisher != nil {
+		ix.Publisher.PublishRollback(*last)
+	}
+	return nil
+}
+
+// orderLog is the content of the order events, each one filling the fields it has
+type orderLog struct {
+	OrderId   *big.Int
+	Account   common.Address
+	SqrtPrice *big.Int
+	Amount    *big.Int
+	AmountIn  *big.Int
+	AmountOut *big.Int
+	IsBuy     bool
+}
+
+func (ix *Indexer) decodeEvent(log types.Log, block *Block) (*domain.HookEvent, error) {
+	if len(log.Topics) == 0 {
+		return nil, nil
+	}
+	event, ok := ix.events[log.Topics[0]]
+	if !ok {
+		return nil, nil
+	}
+
+	var parsed orderLog
+	if err := ix.contract.UnpackLog(&parsed, event.name, log); err != nil {
+		return nil, fmt.Errorf("%s in log %d of block %d: %w", event.name, log.Index, block.Number, err)
+	}
+	if !parsed.OrderId.IsUint64() || parsed.OrderId.Uint64() == math.MaxUint64 {
+		return nil, fmt.Errorf("%s in log %d of block %d has an order id out of range", event.name, log.Index, block.Number)
+	}
+
+	orderId := parsed.OrderId.Uint64()
+	if event.eventType != domain.HookEventOrderCreated {
+		orderId++
+	}
+	orderType := domain.OrderTypeSell
+	if parsed.IsBuy {
+		orderType = domain.OrderTypeBuy
+	}
+	if event.eventType == domain.HookEventOrderRoutedToPool {
+		parsed.Amount = parsed.AmountIn
+	}
+	return &domain.HookEvent{
+		Type:        event.eventType,
+		Hook:        log.Address,
+		OrderType:   orderType,
+		OrderId:     orderId,
+		Account:     parsed.Account,
+		SqrtPrice:   uint256.MustFromBig(parsed.SqrtPrice),
+		Amount:      uint256.MustFromBig(parsed.Amount),
+		AmountOut:   uint256.MustFromBig(parsed.AmountOut),
+		BlockNumber: block.Number,
+		BlockHash:   block.Hash,
+		Timestamp:   block.Timestamp,
+		TxHash:      log.TxHash,
+		LogIndex:    log.Index,
+	}, nil
+}
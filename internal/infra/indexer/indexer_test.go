@@ -0,0 +1,257 @@
+package indexer
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/henriquemarlon/swapx/configs"
+	"github.com/henriquemarlon/swapx/internal/domain"
+	"github.com/henriquemarlon/swapx/internal/infra/repository"
+	"github.com/henriquemarlon/swapx/pkg/swapx"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testHook    = common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	testAccount = common.HexToAddress("0x0000000000000000000000000000000000000b01")
+)
+
+// logEmitterCode emits LOG3 with the first three words of the calldata as
+// topics and the rest as data, standing in for the hook events.
+var logEmitterCode = []byte{
+	0x60, 0x60, 0x36, 0x03, // PUSH1 0x60 CALLDATASIZE SUB: data size
+	0x80, 0x60, 0x60, 0x60, 0x00, 0x37, // DUP1, CALLDATACOPY(0, 0x60, size)
+	0x60, 0x40, 0x35, 0x60, 0x20, 0x35, 0x60, 0x00, 0x35, // the three topics, last one first
+	0x83, 0x60, 0x00, 0xa3, 0x00, // DUP4 PUSH1 0 LOG3 STOP
+}
+
+// simulatedChain recomputes hashes from the headers, which is fine for the
+// blocks the simulated backend makes itself.
+type simulatedChain struct {
+	backend *backends.SimulatedBackend
+}
+
+func (c *simulatedChain) HeadNumber(ctx context.Context) (uint64, error) {
+	header, err := c.backend.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	return header.Number.Uint64(), nil
+}
+
+func (c *simulatedChain) BlockByNumber(ctx context.Context, number uint64) (*Block, error) {
+	head, err := c.HeadNumber(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if number > head {
+		return nil, ethereum.NotFound
+	}
+	header, err := c.backend.HeaderByNumber(ctx, new(big.Int).SetUint64(number))
+	if err != nil {
+		return nil, err
+	}
+	return &Block{Number: number, Hash: header.Hash(), ParentHash: header.ParentHash, Timestamp: header.Time}, nil
+}
+
+func (c *simulatedChain) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	return c.backend.FilterLogs(ctx, query)
+}
+
+type recordingPublisher struct {
+	blocks    []uint64
+	rollbacks []uint64
+}
+
+func (p *recordingPublisher) PublishBlock(block Block, events []*domain.HookEvent) {
+	p.blocks = append(p.blocks, block.Number)
+}
+
+func (p *recordingPublisher) PublishRollback(block Block) {
+	p.rollbacks = append(p.rollbacks, block.Number)
+}
+
+type testEmitter struct {
+	t        *testing.T
+	backend  *backends.SimulatedBackend
+	opts     *bind.TransactOpts
+	contract *bind.BoundContract
+}
+
+func newTestEmitter(t *testing.T) *testEmitter {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	backend := backends.NewSimulatedBackend(core.GenesisAlloc{
+		crypto.PubkeyToAddress(key.PublicKey): {Balance: big.NewInt(1e18)},
+		testHook:                              {Code: logEmitterCode},
+	}, 30_000_000)
+	t.Cleanup(func() { backend.Close() })
+
+	opts, err := bind.NewKeyedTransactorWithChainID(key, big.NewInt(1337))
+	require.NoError(t, err)
+	hookAbi, err := swapx.SwapXHookMetaData.GetAbi()
+	require.NoError(t, err)
+	return &testEmitter{t: t, backend: backend, opts: opts, contract: bind.NewBoundContract(testHook, *hookAbi, backend, backend, backend)}
+}
+
+// emit sends the event the hook would emit, orderId as the hook puts it in the event
+func (e *testEmitter) emit(name string, orderId, sqrtPrice, amount int64, isBuy bool) {
+	e.emitValues(name, orderId, isBuy, sqrtPrice, amount)
+}
+
+// emitValues sends an event whose data is the given amounts followed by isBuy
+func (e *testEmitter) emitValues(name string, orderId int64, isBuy bool, values ...int64) {
+	hookAbi, err := swapx.SwapXHookMetaData.GetAbi()
+	require.NoError(e.t, err)
+	event := hookAbi.Events[name]
+	args := make([]interface{}, 0, len(values)+1)
+	for _, value := range values {
+		args = append(args, big.NewInt(value))
+	}
+	data, err := event.Inputs.NonIndexed().Pack(append(args, isBuy)...)
+	require.NoError(e.t, err)
+
+	calldata := append(event.ID.Bytes(), common.BigToHash(big.NewInt(orderId)).Bytes()...)
+	calldata = append(calldata, common.BytesToHash(testAccount.Bytes()).Bytes()...)
+	_, err = e.contract.RawTransact(e.opts, append(calldata, data...))
+	require.NoError(e.t, err)
+}
+
+func TestIndexerMirrorsTheBookAndFollowsReorgs(t *testing.T) {
+	emitter := newTestEmitter(t)
+	db, err := configs.SetupInMemoryDB()
+	require.NoError(t, err)
+	indexer, err := NewIndexer(&simulatedChain{emitter.backend}, db, testHook, 0, 8)
+	require.NoError(t, err)
+	publisher := &recordingPublisher{}
+	indexer.Publisher = publisher
+	orders := repository.NewOrderRepositoryInMemory(db)
+	fills := repository.NewFillRepositoryInMemory(db)
+
+	emitter.emit("OrderCreated", 1, 10, 100, true)
+	emitter.emit("OrderCreated", 1, 9, 100, false)
+	forkPoint := emitter.backend.Commit()
+	emitter.emit("OrderPartiallyFulfilled", 0, 10, 40, true)
+	emitter.emit("OrderFulfilled", 0, 9, 100, false)
+	filled := emitter.backend.Commit()
+
+	output, err := indexer.Sync(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, &SyncOutputDTO{Blocks: 3, Events: 4, Head: 2, HeadHash: filled}, output)
+
+	buy, err := orders.FindOrderById(testHook, domain.OrderTypeBuy, 1)
+	require.NoError(t, err)
+	assert.Equal(t, uint256.NewInt(40), buy.MatchedAmount)
+	assert.Equal(t, domain.OrderNotCancelledOrFulfilled, *buy.Status)
+	sell, err := orders.FindOrderById(testHook, domain.OrderTypeSell, 1)
+	require.NoError(t, err)
+	assert.Equal(t, domain.OrderCancelledOrFulfilled, *sell.Status)
+	history, err := fills.FindFills()
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Equal(t, uint64(2), history[0].BlockNumber)
+
+	// a longer branch where the buy order is cancelled instead of filled
+	require.NoError(t, emitter.backend.Fork(context.Background(), forkPoint))
+	emitter.emit("OrderCancelled", 0, 10, 100, true)
+	emitter.backend.Commit()
+	head := emitter.backend.Commit()
+
+	output, err = indexer.Sync(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, &SyncOutputDTO{Blocks: 2, Events: 1, Reorgs: 1, Head: 3, HeadHash: head}, output)
+
+	buy, err = orders.FindOrderById(testHook, domain.OrderTypeBuy, 1)
+	require.NoError(t, err)
+	assert.Equal(t, uint256.NewInt(0), buy.MatchedAmount)
+	assert.Equal(t, domain.OrderCancelledOrFulfilled, *buy.Status)
+	sell, err = orders.FindOrderById(testHook, domain.OrderTypeSell, 1)
+	require.NoError(t, err)
+	assert.Equal(t, domain.OrderNotCancelledOrFulfilled, *sell.Status)
+	history, err = fills.FindFills()
+	require.NoError(t, err)
+	assert.Empty(t, history)
+	assert.Equal(t, head, db.Cursor.BlockHash)
+	assert.Equal(t, []uint64{1, 2, 2}, publisher.blocks)
+	assert.Equal(t, []uint64{1}, publisher.rollbacks)
+}
+
+func TestIndexerAppliesRefundsDustAndPoolRoutes(t *testing.T) {
+	emitter := newTestEmitter(t)
+	db, err := configs.SetupInMemoryDB()
+	require.NoError(t, err)
+	indexer, err := NewIndexer(&simulatedChain{emitter.backend}, db, testHook, 0, 8)
+	require.NoError(t, err)
+	orders := repository.NewOrderRepositoryInMemory(db)
+	fills := repository.NewFillRepositoryInMemory(db)
+
+	emitter.emit("OrderCreated", 1, 10, 100, true)
+	emitter.emit("OrderCreated", 1, 9, 100, false)
+	emitter.emit("OrderCreated", 2, 9, 100, false)
+	emitter.backend.Commit()
+	// the pool takes 30 of the buy order and its last 70 are dust
+	emitter.emitValues("OrderRoutedToPool", 0, true, 30, 25)
+	emitter.emitValues("OrderDustReturned", 0, true, 70)
+	emitter.emitValues("OrderRefunded", 0, false, 100)
+	emitter.emitValues("OrderDustReturned", 1, false, 10)
+	emitter.backend.Commit()
+
+	output, err := indexer.Sync(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 7, output.Events)
+
+	buy, err := orders.FindOrderById(testHook, domain.OrderTypeBuy, 1)
+	require.NoError(t, err)
+	assert.Equal(t, uint256.NewInt(30), buy.Amount)
+	assert.Equal(t, uint256.NewInt(30), buy.MatchedAmount)
+	assert.Equal(t, domain.OrderCancelledOrFulfilled, *buy.Status)
+
+	refunded, err := orders.FindOrderById(testHook, domain.OrderTypeSell, 1)
+	require.NoError(t, err)
+	assert.Equal(t, uint256.NewInt(0), refunded.MatchedAmount)
+	assert.Equal(t, domain.OrderCancelledOrFulfilled, *refunded.Status)
+
+	dusted, err := orders.FindOrderById(testHook, domain.OrderTypeSell, 2)
+	require.NoError(t, err)
+	assert.Equal(t, uint256.NewInt(90), dusted.Amount)
+	assert.Equal(t, domain.OrderNotCancelledOrFulfilled, *dusted.Status)
+
+	// none of them is a trade between two orders
+	recorded, err := fills.FindFills()
+	require.NoError(t, err)
+	assert.Empty(t, recorded)
+}
+
+func TestRollbackBelowTheKeptBlocksFails(t *testing.T) {
+	emitter := newTestEmitter(t)
+	db, err := configs.SetupInMemoryDB()
+	require.NoError(t, err)
+	indexer, err := NewIndexer(&simulatedChain{emitter.backend}, db, testHook, 0, 2)
+	require.NoError(t, err)
+
+	forkPoint := emitter.backend.Commit()
+	for range 3 {
+		emitter.backend.Commit()
+	}
+	_, err = indexer.Sync(context.Background())
+	require.NoError(t, err)
+
+	// empty blocks on the fork would be the same blocks again
+	require.NoError(t, emitter.backend.Fork(context.Background(), forkPoint))
+	emitter.emit("OrderCreated", 1, 10, 100, true)
+	for range 4 {
+		emitter.backend.Commit()
+	}
+	_, err = indexer.Sync(context.Background())
+	assert.ErrorIs(t, err, ErrReorgTooDeep)
+}
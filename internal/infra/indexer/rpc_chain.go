@@ -0,0 +1,55 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// RpcChain reads blocks from a JSON-RPC node. Hashes are taken as reported
+// rather than recomputed from the header, which depends on the fork rules of
+// the client, so parent hashes always chain up.
+type RpcChain struct {
+	Client *ethclient.Client
+}
+
+func NewRpcChain(client *ethclient.Client) *RpcChain {
+	return &RpcChain{Client: client}
+}
+
+func (c *RpcChain) HeadNumber(ctx context.Context) (uint64, error) {
+	return c.Client.BlockNumber(ctx)
+}
+
+func (c *RpcChain) BlockByNumber(ctx context.Context, number uint64) (*Block, error) {
+	var header *struct {
+		Hash       common.Hash    `json:"hash"`
+		ParentHash common.Hash    `json:"parentHash"`
+		Number     hexutil.Uint64 `json:"number"`
+		Timestamp  hexutil.Uint64 `json:"timestamp"`
+	}
+	if err := c.Client.Client().CallContext(ctx, &header, "eth_getBlockByNumber", hexutil.EncodeUint64(number), false); err != nil {
+		return nil, err
+	}
+	if header == nil {
+		return nil, ethereum.NotFound
+	}
+	if uint64(header.Number) != number {
+		return nil, fmt.Errorf("asked for block %d, got block %d", number, uint64(header.Number))
+	}
+	return &Block{
+		Number:     number,
+		Hash:       header.Hash,
+		ParentHash: header.ParentHash,
+		Timestamp:  uint64(header.Timestamp),
+	}, nil
+}
+
+func (c *RpcChain) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	return c.Client.FilterLogs(ctx, query)
+}
@@ -0,0 +1,278 @@
+package keeper
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/henriquemarlon/swapx/configs"
+	"github.com/henriquemarlon/swapx/internal/domain"
+	"github.com/henriquemarlon/swapx/internal/infra/indexer"
+	"github.com/henriquemarlon/swapx/internal/infra/repository"
+	"github.com/henriquemarlon/swapx/internal/usecase"
+	"github.com/holiman/uint256"
+)
+
+const (
+	DEFAULT_MIN_AGE  = 10
+	DEFAULT_COOLDOWN = 20
+	DEFAULT_BACKOFF  = usecase.DEFAULT_SETTLEMENT_TIMEOUT_BLOCKS
+)
+
+// Rematcher asks the coprocessor to match the book of a hook again
+type Rematcher interface {
+	Rematch(ctx context.Context, hook common.Address) (common.Hash, error)
+}
+
+// SpotPricer reads the spot price of the pool of a hook at a block
+type SpotPricer interface {
+	SpotSqrtPrice(hook common.Address, blockHash common.Hash) (*uint256.Int, error)
+}
+
+type orderKey struct {
+	orderType domain.OrderType
+	id        uint64
+}
+
+// Keeper watches the book mirrored by an indexer and asks for a rematch when
+// a bid and an ask have been crossing for MinAge blocks, which happens when
+// the task of the order that crossed them failed or its settlement reverted.
+// It is the Publisher of the indexer, which tells it when orders were created.
+type Keeper struct {
+	Hook      common.Address
+	Rematcher Rematcher
+	// MinAge is how many blocks both orders of a crossing have to be in the
+	// book, so the coprocessor gets the time to match them on its own
+	MinAge uint64
+	// Cooldown is how many blocks to wait after a rematch before another one
+	Cooldown uint64
+	// Backoff is how many blocks a crossing a rematch left unmatched waits
+	// before another rematch, so the fills the coprocessor may still hold for
+	// its orders time out first
+	Backoff uint64
+	// BandBps skips the crossings the coprocessor keeps out of the match for
+	// being too far from the spot price Spot reads, nil rematches at any price
+	BandBps *uint64
+	Spot    SpotPricer
+
+	orders      domain.OrderRepository
+	createdAt   map[orderKey]uint64
+	lastRematch uint64
+	rematched   bool
+	// lastCross is the crossing the last rematch was sent for, as it was then
+	lastCross *crossState
+}
+
+// crossState tells whether a crossing changed since a rematch was sent for it
+type crossState struct {
+	bid, ask               orderKey
+	bidMatched, askMatched *uint256.Int
+}
+
+func newCrossState(cross *Cross) *crossState {
+	return &crossState{
+		bid:        orderKey{domain.OrderTypeBuy, cross.Bid.Id},
+		ask:        orderKey{domain.OrderTypeSell, cross.Ask.Id},
+		bidMatched: new(uint256.Int).Set(cross.Bid.MatchedAmount),
+		askMatched: new(uint256.Int).Set(cross.Ask.MatchedAmount),
+	}
+}
+
+func (c *crossState) equal(other *crossState) bool {
+	return c.bid == other.bid && c.ask == other.ask && c.bidMatched.Eq(other.bidMatched) && c.askMatched.Eq(other.askMatched)
+}
+
+// Cross is the best bid and the best ask among the orders old enough
+type Cross struct {
+	Bid *domain.Order `json:"bid"`
+	Ask *domain.Order `json:"ask"`
+}
+
+// CheckOutputDTO tells with Unmatched that the last rematch was sent for the
+// same crossing and left it as it was
+type CheckOutputDTO struct {
+	Cross     *Cross       `json:"cross"`
+	Unmatched bool         `json:"unmatched"`
+	TxHash    *common.Hash `json:"tx_hash"`
+}
+
+func NewKeeper(hook common.Address, db *configs.InMemoryDB, rematcher Rematcher, minAge, cooldown uint64) *Keeper {
+	return &Keeper{
+		Hook:      hook,
+		Rematcher: rematcher,
+		MinAge:    minAge,
+		Cooldown:  cooldown,
+		Backoff:   DEFAULT_BACKOFF,
+		orders:    repository.NewOrderRepositoryInMemory(db),
+		createdAt: make(map[orderKey]uint64),
+	}
+}
+
+func (k *Keeper) PublishBlock(block indexer.Block, events []*domain.HookEvent) {
+	for _, event := range events {
+		if event.Type == domain.HookEventOrderCreated {
+			k.createdAt[orderKey{event.OrderType, event.OrderId}] = block.Number
+		}
+	}
+}
+
+// PublishRollback forgets the orders of the blocks that are gone, and the
+// rematch sent in one of them, whose task may be gone too
+func (k *Keeper) PublishRollback(block indexer.Block) {
+	for key, createdAt := range k.createdAt {
+		if createdAt > block.Number {
+			delete(k.createdAt, key)
+		}
+	}
+	if k.rematched && k.lastRematch > block.Number {
+		k.rematched, k.lastCross = false, nil
+	}
+}
+
+// Run syncs the indexer every interval and checks the book after each sync
+// until ctx is done
+func (k *Keeper) Run(ctx context.Context, ix *indexer.Indexer, interval time.Duration) error {
+	ix.Publisher = k
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		output, err := ix.Sync(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		if output.Head > 0 {
+			head := indexer.Block{Number: output.Head, Hash: output.HeadHash}
+			check, err := k.Check(ctx, head)
+			if err != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+				// the next sync tries again
+				slog.Error("Could not send a rematch", "head", output.Head, "err", err)
+			} else if check.TxHash != nil {
+				slog.Info("Rematch sent", "head", output.Head, "tx_hash", check.TxHash.Hex(), "bid", check.Cross.Bid.Id, "ask", check.Cross.Ask.Id, "unmatched", check.Unmatched)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check sends a rematch when the book is crossed at head by orders of at
+// least MinAge blocks, unless one was sent less than Cooldown blocks ago. A
+// crossing the last rematch left as it was waits Backoff blocks instead, since
+// another rematch of the same book would not match it either.
+func (k *Keeper) Check(ctx context.Context, head indexer.Block) (*CheckOutputDTO, error) {
+	cross, err := k.FindStaleCross(head)
+	if err != nil || cross == nil {
+		return &CheckOutputDTO{}, err
+	}
+	state := newCrossState(cross)
+	output := &CheckOutputDTO{Cross: cross, Unmatched: k.lastCross != nil && k.lastCross.equal(state)}
+	wait := k.Cooldown
+	if output.Unmatched {
+		wait = max(k.Cooldown, k.Backoff)
+	}
+	if k.rematched && head.Number < k.lastRematch+wait {
+		return output, nil
+	}
+
+	txHash, err := k.Rematcher.Rematch(ctx, k.Hook)
+	if err != nil {
+		return nil, err
+	}
+	k.lastRematch, k.rematched, k.lastCross = head.Number, true, state
+	output.TxHash = &txHash
+	return output, nil
+}
+
+// FindStaleCross returns the best bid and ask the coprocessor would match
+// among the open orders created at least MinAge blocks before head, or nil
+// when there are none. Like the matching, it passes over the orders the price
+// band keeps out of the match. Orders the keeper did not see created are taken
+// as old enough.
+func (k *Keeper) FindStaleCross(head indexer.Block) (*Cross, error) {
+	bids, err := k.staleOrders(domain.OrderTypeBuy, head.Number)
+	if err != nil || len(bids) == 0 {
+		return nil, err
+	}
+	asks, err := k.staleOrders(domain.OrderTypeSell, head.Number)
+	if err != nil || len(asks) == 0 {
+		return nil, err
+	}
+	band, err := k.priceBand(head)
+	if err != nil {
+		return nil, err
+	}
+	// without a spot price the coprocessor holds every match
+	if band != nil && band.Spot.IsZero() {
+		return nil, nil
+	}
+
+	for len(bids) > 0 && len(asks) > 0 {
+		bid, ask := bids[0], asks[0]
+		if bid.SqrtPrice.Lt(ask.SqrtPrice) {
+			return nil, nil
+		}
+		if band != nil {
+			// a match executes at the ask price
+			switch band.Compare(ask.SqrtPrice) {
+			case -1:
+				asks = asks[1:]
+				continue
+			case 1:
+				bids = bids[1:]
+				continue
+			}
+		}
+		return &Cross{Bid: bid, Ask: ask}, nil
+	}
+	return nil, nil
+}
+
+// priceBand returns the band around the spot price at head, nil without BandBps
+func (k *Keeper) priceBand(head indexer.Block) (*domain.PriceBand, error) {
+	if k.BandBps == nil {
+		return nil, nil
+	}
+	spot, err := k.Spot.SpotSqrtPrice(k.Hook, head.Hash)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.PriceBand{BandBps: *k.BandBps, Spot: spot}, nil
+}
+
+// staleOrders returns the open orders with a remainder created at least
+// MinAge blocks before head, best price first
+func (k *Keeper) staleOrders(orderType domain.OrderType, head uint64) ([]*domain.Order, error) {
+	orders, err := k.orders.FindOrdersByTypeAndStatus(k.Hook, orderType, domain.OrderNotCancelledOrFulfilled)
+	if err != nil && err != domain.ErrNoOrdersFound {
+		return nil, err
+	}
+
+	var stale []*domain.Order
+	for _, order := range orders {
+		if k.createdAt[orderKey{orderType, order.Id}]+k.MinAge > head || order.Remaining().IsZero() {
+			continue
+		}
+		stale = append(stale, order)
+	}
+	// orders come by id, which breaks the ties between equal prices
+	sort.SliceStable(stale, func(i, j int) bool {
+		if orderType == domain.OrderTypeBuy {
+			return stale[i].SqrtPrice.Gt(stale[j].SqrtPrice)
+		}
+		return stale[i].SqrtPrice.Lt(stale[j].SqrtPrice)
+	})
+	return stale, nil
+}
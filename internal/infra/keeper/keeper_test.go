@@ -0,0 +1,172 @@
+package keeper
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/henriquemarlon/swapx/configs"
+	"github.com/henriquemarlon/swapx/internal/domain"
+	"github.com/henriquemarlon/swapx/internal/infra/indexer"
+	"github.com/henriquemarlon/swapx/internal/infra/repository"
+	"github.com/henriquemarlon/swapx/internal/usecase"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testHook = common.HexToAddress("0x00000000000000000000000000000000000000aa")
+
+type countingRematcher struct {
+	hooks []common.Address
+}
+
+func (r *countingRematcher) Rematch(ctx context.Context, hook common.Address) (common.Hash, error) {
+	r.hooks = append(r.hooks, hook)
+	return common.BigToHash(big.NewInt(int64(len(r.hooks)))), nil
+}
+
+type testBook struct {
+	t      *testing.T
+	keeper *Keeper
+	apply  *usecase.ApplyHookEventUseCase
+}
+
+// create applies an OrderCreated event in block and publishes it, as the indexer does
+func (b *testBook) create(block uint64, orderType domain.OrderType, id, sqrtPrice uint64) {
+	event := &domain.HookEvent{Type: domain.HookEventOrderCreated, Hook: testHook, OrderType: orderType, OrderId: id, SqrtPrice: uint256.NewInt(sqrtPrice), Amount: uint256.NewInt(10), BlockNumber: block}
+	require.NoError(b.t, b.apply.Execute(event))
+	b.keeper.PublishBlock(indexer.Block{Number: block}, []*domain.HookEvent{event})
+}
+
+func newTestBook(t *testing.T, rematcher Rematcher) *testBook {
+	db, err := configs.SetupInMemoryDB()
+	require.NoError(t, err)
+	return &testBook{
+		t:      t,
+		keeper: NewKeeper(testHook, db, rematcher, 5, 10),
+		apply:  usecase.NewApplyHookEventUseCase(repository.NewOrderRepositoryInMemory(db), repository.NewFillRepositoryInMemory(db)),
+	}
+}
+
+func TestKeeperRematchesACrossingOnceItIsOldEnough(t *testing.T) {
+	rematcher := &countingRematcher{}
+	book := newTestBook(t, rematcher)
+	book.create(1, domain.OrderTypeBuy, 1, 100)
+	book.create(2, domain.OrderTypeSell, 1, 120)
+	book.create(3, domain.OrderTypeSell, 2, 90)
+
+	check, err := book.keeper.Check(context.Background(), indexer.Block{Number: 7})
+	require.NoError(t, err)
+	assert.Nil(t, check.Cross, "the crossing ask is only 4 blocks old")
+
+	check, err = book.keeper.Check(context.Background(), indexer.Block{Number: 8})
+	require.NoError(t, err)
+	require.NotNil(t, check.Cross)
+	assert.Equal(t, uint64(1), check.Cross.Bid.Id)
+	assert.Equal(t, uint64(2), check.Cross.Ask.Id)
+	require.NotNil(t, check.TxHash)
+	assert.Equal(t, []common.Address{testHook}, rematcher.hooks)
+
+	// still crossed, but the last rematch is too recent
+	check, err = book.keeper.Check(context.Background(), indexer.Block{Number: 17})
+	require.NoError(t, err)
+	assert.NotNil(t, check.Cross)
+	assert.Nil(t, check.TxHash)
+
+	// a better ask changes the crossing
+	book.create(10, domain.OrderTypeSell, 3, 80)
+	check, err = book.keeper.Check(context.Background(), indexer.Block{Number: 18})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), check.Cross.Ask.Id)
+	assert.False(t, check.Unmatched)
+	assert.NotNil(t, check.TxHash)
+	assert.Len(t, rematcher.hooks, 2)
+}
+
+func TestKeeperBacksOffACrossingARematchLeftUnmatched(t *testing.T) {
+	rematcher := &countingRematcher{}
+	book := newTestBook(t, rematcher)
+	book.keeper.Backoff = 30
+	book.create(1, domain.OrderTypeBuy, 1, 100)
+	book.create(1, domain.OrderTypeSell, 1, 90)
+
+	_, err := book.keeper.Check(context.Background(), indexer.Block{Number: 6})
+	require.NoError(t, err)
+	require.Len(t, rematcher.hooks, 1)
+
+	// past the cooldown, but the rematch matched nothing
+	check, err := book.keeper.Check(context.Background(), indexer.Block{Number: 16})
+	require.NoError(t, err)
+	assert.True(t, check.Unmatched)
+	assert.Nil(t, check.TxHash)
+
+	check, err = book.keeper.Check(context.Background(), indexer.Block{Number: 36})
+	require.NoError(t, err)
+	assert.NotNil(t, check.TxHash)
+	assert.Len(t, rematcher.hooks, 2)
+
+	// a partial fill changes the crossing, which waits the cooldown only
+	fill := &domain.HookEvent{Type: domain.HookEventOrderPartiallyFulfilled, Hook: testHook, OrderType: domain.OrderTypeBuy, OrderId: 1, SqrtPrice: uint256.NewInt(90), Amount: uint256.NewInt(4), BlockNumber: 37}
+	require.NoError(t, book.apply.Execute(fill))
+	check, err = book.keeper.Check(context.Background(), indexer.Block{Number: 46})
+	require.NoError(t, err)
+	assert.False(t, check.Unmatched)
+	assert.NotNil(t, check.TxHash)
+}
+
+type fixedSpot struct {
+	spot *uint256.Int
+}
+
+func (s *fixedSpot) SpotSqrtPrice(hook common.Address, blockHash common.Hash) (*uint256.Int, error) {
+	return s.spot, nil
+}
+
+func TestKeeperSkipsCrossingsOutsideTheBand(t *testing.T) {
+	rematcher := &countingRematcher{}
+	book := newTestBook(t, rematcher)
+	spot := &fixedSpot{spot: uint256.NewInt(100)}
+	bandBps := uint64(500)
+	book.keeper.BandBps, book.keeper.Spot = &bandBps, spot
+	book.create(1, domain.OrderTypeBuy, 1, 120)
+	book.create(1, domain.OrderTypeBuy, 2, 101)
+	book.create(1, domain.OrderTypeSell, 1, 110)
+
+	// the ask would sell 21% above the spot
+	check, err := book.keeper.Check(context.Background(), indexer.Block{Number: 6})
+	require.NoError(t, err)
+	assert.Nil(t, check.Cross)
+	assert.Empty(t, rematcher.hooks)
+
+	// the matching passes over the best bid the ask is kept out with
+	book.create(1, domain.OrderTypeSell, 2, 100)
+	cross, err := book.keeper.FindStaleCross(indexer.Block{Number: 6})
+	require.NoError(t, err)
+	require.NotNil(t, cross)
+	assert.Equal(t, uint64(1), cross.Bid.Id)
+	assert.Equal(t, uint64(2), cross.Ask.Id)
+
+	spot.spot = uint256.NewInt(0)
+	cross, err = book.keeper.FindStaleCross(indexer.Block{Number: 6})
+	require.NoError(t, err)
+	assert.Nil(t, cross, "without a spot price nothing is matched")
+}
+
+func TestKeeperForgetsTheRematchOfABlockThatIsGone(t *testing.T) {
+	rematcher := &countingRematcher{}
+	book := newTestBook(t, rematcher)
+	book.create(1, domain.OrderTypeBuy, 1, 100)
+	book.create(1, domain.OrderTypeSell, 1, 100)
+
+	_, err := book.keeper.Check(context.Background(), indexer.Block{Number: 6})
+	require.NoError(t, err)
+	require.Len(t, rematcher.hooks, 1)
+
+	book.keeper.PublishRollback(indexer.Block{Number: 5})
+	check, err := book.keeper.Check(context.Background(), indexer.Block{Number: 6})
+	require.NoError(t, err)
+	assert.NotNil(t, check.TxHash)
+	assert.Len(t, rematcher.hooks, 2)
+}
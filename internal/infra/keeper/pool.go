@@ -0,0 +1,27 @@
+package keeper
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/henriquemarlon/swapx/internal/infra/service"
+	"github.com/holiman/uint256"
+)
+
+// PoolSpotPricer reads the spot price of the pool of a hook from the pool
+// manager, the way the coprocessor does for its price bands
+type PoolSpotPricer struct {
+	Pools       service.PoolStateServiceInterface
+	PoolManager common.Address
+	PoolKeySlot common.Hash
+}
+
+func NewPoolSpotPricer(pools service.PoolStateServiceInterface, poolManager common.Address, poolKeySlot common.Hash) *PoolSpotPricer {
+	return &PoolSpotPricer{Pools: pools, PoolManager: poolManager, PoolKeySlot: poolKeySlot}
+}
+
+func (p *PoolSpotPricer) SpotSqrtPrice(hook common.Address, blockHash common.Hash) (*uint256.Int, error) {
+	key, err := p.Pools.FindPoolKey(hook, blockHash, p.PoolKeySlot)
+	if err != nil {
+		return nil, err
+	}
+	return p.Pools.FindSpotSqrtPrice(p.PoolManager, key.Id(), blockHash)
+}
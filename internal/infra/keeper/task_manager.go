@@ -0,0 +1,35 @@
+package keeper
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/henriquemarlon/swapx/internal/infra/cartesi"
+	"github.com/henriquemarlon/swapx/pkg/swapx"
+)
+
+// TaskManagerRematcher creates rematch tasks through the task manager of the
+// hook, with the account of Opts paying for them
+type TaskManagerRematcher struct {
+	TaskManager *swapx.SwapXTaskManager
+	Opts        *bind.TransactOpts
+}
+
+func NewTaskManagerRematcher(taskManager *swapx.SwapXTaskManager, opts *bind.TransactOpts) *TaskManagerRematcher {
+	return &TaskManagerRematcher{TaskManager: taskManager, Opts: opts}
+}
+
+func (r *TaskManagerRematcher) Rematch(ctx context.Context, hook common.Address) (common.Hash, error) {
+	payload, err := cartesi.EncodeRematchTask(&cartesi.RematchTask{Hook: hook})
+	if err != nil {
+		return common.Hash{}, err
+	}
+	opts := *r.Opts
+	opts.Context = ctx
+	tx, err := r.TaskManager.CreateTask(&opts, payload)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return tx.Hash(), nil
+}
@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"sync"
+
+	"github.com/henriquemarlon/swapx/configs"
+	"github.com/henriquemarlon/swapx/internal/domain"
+)
+
+type CursorRepositoryInMemory struct {
+	Cursor *domain.SyncCursor
+	Mutex  *sync.RWMutex
+}
+
+func NewCursorRepositoryInMemory(db *configs.InMemoryDB) *CursorRepositoryInMemory {
+	return &CursorRepositoryInMemory{
+		Cursor: db.Cursor,
+		Mutex:  db.Mutex,
+	}
+}
+
+func (r *CursorRepositoryInMemory) FindCursor() (*domain.SyncCursor, error) {
+	r.Mutex.RLock()
+	defer r.Mutex.RUnlock()
+
+	cursor := *r.Cursor
+	return &cursor, nil
+}
+
+func (r *CursorRepositoryInMemory) UpdateCursor(cursor *domain.SyncCursor) error {
+	r.Mutex.Lock()
+	defer r.Mutex.Unlock()
+
+	*r.Cursor = *cursor
+	return nil
+}
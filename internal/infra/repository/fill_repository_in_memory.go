@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"sync"
+
+	"github.com/henriquemarlon/swapx/configs"
+	"github.com/henriquemarlon/swapx/internal/domain"
+)
+
+type FillRepositoryInMemory struct {
+	Fills *[]*domain.Fill
+	Mutex *sync.RWMutex
+}
+
+func NewFillRepositoryInMemory(db *configs.InMemoryDB) *FillRepositoryInMemory {
+	return &FillRepositoryInMemory{
+		Fills: db.Fills,
+		Mutex: db.Mutex,
+	}
+}
+
+func (r *FillRepositoryInMemory) CreateFill(fill *domain.Fill) error {
+	r.Mutex.Lock()
+	defer r.Mutex.Unlock()
+
+	*r.Fills = append(*r.Fills, fill)
+	return nil
+}
+
+func (r *FillRepositoryInMemory) FindFills() ([]*domain.Fill, error) {
+	r.Mutex.RLock()
+	defer r.Mutex.RUnlock()
+
+	return append([]*domain.Fill{}, *r.Fills...), nil
+}
+
+func (r *FillRepositoryInMemory) FindFillsByOrder(orderType domain.OrderType, id uint64) ([]*domain.Fill, error) {
+	r.Mutex.RLock()
+	defer r.Mutex.RUnlock()
+
+	fills := []*domain.Fill{}
+	for _, fill := range *r.Fills {
+		if fill.OrderType == orderType && fill.OrderId == id {
+			fills = append(fills, fill)
+		}
+	}
+	return fills, nil
+}
+
+// DeleteFillsAfter relies on fills being created in chain order
+func (r *FillRepositoryInMemory) DeleteFillsAfter(blockNumber uint64) error {
+	r.Mutex.Lock()
+	defer r.Mutex.Unlock()
+
+	fills := *r.Fills
+	for len(fills) > 0 && fills[len(fills)-1].BlockNumber > blockNumber {
+		fills = fills[:len(fills)-1]
+	}
+	*r.Fills = fills
+	return nil
+}
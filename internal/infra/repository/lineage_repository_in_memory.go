@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"fmt"
+	"maps"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/henriquemarlon/swapx/configs"
+	"github.com/henriquemarlon/swapx/internal/domain"
+)
+
+// LineageRepositoryInMemory copies the whole database into its checkpoints.
+// Orders are copied one level deep, the code replaces their fields rather
+// than changing them in place.
+type LineageRepositoryInMemory struct {
+	DB *configs.InMemoryDB
+}
+
+func NewLineageRepositoryInMemory(db *configs.InMemoryDB) *LineageRepositoryInMemory {
+	return &LineageRepositoryInMemory{DB: db}
+}
+
+func (r *LineageRepositoryInMemory) FindCheckpoints() ([]domain.BlockRef, error) {
+	r.DB.Mutex.RLock()
+	defer r.DB.Mutex.RUnlock()
+
+	blocks := make([]domain.BlockRef, 0, len(*r.DB.Checkpoints))
+	for _, checkpoint := range *r.DB.Checkpoints {
+		blocks = append(blocks, checkpoint.Block)
+	}
+	return blocks, nil
+}
+
+func (r *LineageRepositoryInMemory) SaveCheckpoint(block domain.BlockRef) error {
+	r.DB.Mutex.Lock()
+	defer r.DB.Mutex.Unlock()
+
+	*r.DB.Checkpoints = append(*r.DB.Checkpoints, &domain.Checkpoint{
+		Block:          block,
+		BuyOrders:      copyOrders(sortedOrders(r.DB.BuyOrders, nil)),
+		SellOrders:     copyOrders(sortedOrders(r.DB.SellOrders, nil)),
+		BuyTombstones:  copyTombstones(r.DB.BuyTombstones),
+		SellTombstones: copyTombstones(r.DB.SellTombstones),
+		Cursor:         *r.DB.Cursor,
+		PendingFills:   append([]*domain.PendingFill{}, *r.DB.PendingFills...),
+		PendingSwaps:   append([]*domain.PendingPoolSwap{}, *r.DB.PendingPoolSwaps...),
+		Candles:        copyCandles(r.DB.Candles),
+	})
+	return nil
+}
+
+func (r *LineageRepositoryInMemory) RestoreCheckpoint(index int) error {
+	r.DB.Mutex.Lock()
+	defer r.DB.Mutex.Unlock()
+
+	checkpoints := *r.DB.Checkpoints
+	if index < 0 || index >= len(checkpoints) {
+		return fmt.Errorf("no checkpoint %d out of %d", index, len(checkpoints))
+	}
+	checkpoint := checkpoints[index]
+
+	// later checkpoints are dropped, so the copies can go live
+	clear(r.DB.BuyOrders)
+	for _, order := range checkpoint.BuyOrders {
+		r.DB.BuyOrders[domain.OrderKey{Hook: order.Hook, Id: order.Id}] = order
+	}
+	clear(r.DB.SellOrders)
+	for _, order := range checkpoint.SellOrders {
+		r.DB.SellOrders[domain.OrderKey{Hook: order.Hook, Id: order.Id}] = order
+	}
+	clear(r.DB.BuyTombstones)
+	maps.Copy(r.DB.BuyTombstones, checkpoint.BuyTombstones)
+	clear(r.DB.SellTombstones)
+	maps.Copy(r.DB.SellTombstones, checkpoint.SellTombstones)
+	*r.DB.Cursor = checkpoint.Cursor
+	*r.DB.PendingFills = checkpoint.PendingFills
+	*r.DB.PendingPoolSwaps = checkpoint.PendingSwaps
+	clear(r.DB.Candles)
+	maps.Copy(r.DB.Candles, checkpoint.Candles)
+	*r.DB.Checkpoints = checkpoints[:index]
+	return nil
+}
+
+// PruneCheckpoints relies on the checkpoints of an unbroken lineage having
+// increasing block numbers
+func (r *LineageRepositoryInMemory) PruneCheckpoints(number uint64) error {
+	r.DB.Mutex.Lock()
+	defer r.DB.Mutex.Unlock()
+
+	checkpoints := *r.DB.Checkpoints
+	for len(checkpoints) > 0 && checkpoints[0].Block.Number <= number {
+		checkpoints = checkpoints[1:]
+	}
+	*r.DB.Checkpoints = append([]*domain.Checkpoint{}, checkpoints...)
+	return nil
+}
+
+func copyTombstones(tombstones map[common.Address]*domain.TombstoneSet) map[common.Address]*domain.TombstoneSet {
+	copies := make(map[common.Address]*domain.TombstoneSet, len(tombstones))
+	for hook, set := range tombstones {
+		copies[hook] = domain.RestoreTombstoneSet(set.Floor(), set.Ids())
+	}
+	return copies
+}
+
+func copyCandles(candles map[common.Address]*domain.Candles) map[common.Address]*domain.Candles {
+	copies := make(map[common.Address]*domain.Candles, len(candles))
+	for hook, hookCandles := range candles {
+		copies[hook] = hookCandles.Copy()
+	}
+	return copies
+}
+
+func copyOrders(orders []*domain.Order) []*domain.Order {
+	copies := make([]*domain.Order, 0, len(orders))
+	for _, order := range orders {
+		copied := *order
+		copies = append(copies, &copied)
+	}
+	return copies
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/henriquemarlon/swapx/configs"
+	"github.com/henriquemarlon/swapx/internal/domain"
+)
+
+type MarketRepositoryInMemory struct {
+	Candles map[common.Address]*domain.Candles
+	Mutex   *sync.RWMutex
+}
+
+func NewMarketRepositoryInMemory(db *configs.InMemoryDB) *MarketRepositoryInMemory {
+	return &MarketRepositoryInMemory{
+		Candles: db.Candles,
+		Mutex:   db.Mutex,
+	}
+}
+
+func (r *MarketRepositoryInMemory) RecordTrades(hook common.Address, timestamp uint64, trades []*domain.Trade) error {
+	r.Mutex.Lock()
+	defer r.Mutex.Unlock()
+
+	if len(trades) == 0 {
+		return nil
+	}
+	candles, ok := r.Candles[hook]
+	if !ok {
+		candles = domain.NewCandles(domain.CANDLES_KEPT)
+		r.Candles[hook] = candles
+	}
+	for _, trade := range trades {
+		candles.Add(domain.PricedTrade{SqrtPrice: trade.SqrtPrice, Quantity: trade.Quantity, Timestamp: timestamp})
+	}
+	return nil
+}
+
+// FindCandles returns copies, candles are updated in place as trades come in
+func (r *MarketRepositoryInMemory) FindCandles(hook common.Address, interval domain.CandleInterval, from, to uint64) ([]*domain.Candle, error) {
+	r.Mutex.RLock()
+	defer r.Mutex.RUnlock()
+
+	candles := r.candles(hook).Find(interval, from, to)
+	for i, candle := range candles {
+		copied := *candle
+		candles[i] = &copied
+	}
+	return candles, nil
+}
+
+func (r *MarketRepositoryInMemory) FindStats(hook common.Address, at uint64) (*domain.MarketStats, error) {
+	r.Mutex.RLock()
+	defer r.Mutex.RUnlock()
+
+	candles := r.candles(hook)
+	if at == 0 {
+		at = candles.LastTimestamp
+	}
+	return candles.Stats(at), nil
+}
+
+// candles of a hook without trades yet are empty, not missing
+func (r *MarketRepositoryInMemory) candles(hook common.Address) *domain.Candles {
+	if candles, ok := r.Candles[hook]; ok {
+		return candles
+	}
+	return domain.NewCandles(domain.CANDLES_KEPT)
+}
@@ -1,23 +1,30 @@
 package repository
 
 import (
+	"bytes"
+	"sort"
 	"sync"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/henriquemarlon/swapx/configs"
 	"github.com/henriquemarlon/swapx/internal/domain"
 )
 
 type OrderRepositoryInMemory struct {
-	BuyOrders  map[uint64]*domain.Order
-	SellOrders map[uint64]*domain.Order
-	Mutex      *sync.RWMutex
+	BuyOrders      map[domain.OrderKey]*domain.Order
+	SellOrders     map[domain.OrderKey]*domain.Order
+	BuyTombstones  map[common.Address]*domain.TombstoneSet
+	SellTombstones map[common.Address]*domain.TombstoneSet
+	Mutex          *sync.RWMutex
 }
 
 func NewOrderRepositoryInMemory(db *configs.InMemoryDB) *OrderRepositoryInMemory {
 	return &OrderRepositoryInMemory{
-		BuyOrders:  db.BuyOrders,
-		SellOrders: db.SellOrders,
-		Mutex:      db.Mutex,
+		BuyOrders:      db.BuyOrders,
+		SellOrders:     db.SellOrders,
+		BuyTombstones:  db.BuyTombstones,
+		SellTombstones: db.SellTombstones,
+		Mutex:          db.Mutex,
 	}
 }
 
@@ -25,12 +32,30 @@ func (r *OrderRepositoryInMemory) CreateOrder(order *domain.Order) (*domain.Orde
 	r.Mutex.Lock()
 	defer r.Mutex.Unlock()
 
-	orderMap := r.getOrderMap((*domain.OrderType)(order.Type))
-	if _, exists := orderMap[order.Id]; exists {
+	if tombstones, ok := r.getTombstones(order.Type)[order.Hook]; ok && tombstones.Contains(order.Id) {
+		return nil, domain.ErrOrderPruned
+	}
+	orderMap := r.getOrderMap(order.Type)
+	key := domain.OrderKey{Hook: order.Hook, Id: order.Id}
+	if _, exists := orderMap[key]; exists {
 		return nil, domain.ErrOrderAlreadyExists
 	}
 
-	orderMap[order.Id] = order
+	orderMap[key] = order
+	return order, nil
+}
+
+func (r *OrderRepositoryInMemory) UpdateOrder(order *domain.Order) (*domain.Order, error) {
+	r.Mutex.Lock()
+	defer r.Mutex.Unlock()
+
+	orderMap := r.getOrderMap(order.Type)
+	key := domain.OrderKey{Hook: order.Hook, Id: order.Id}
+	if _, exists := orderMap[key]; !exists {
+		return nil, domain.ErrOrderNotFound
+	}
+
+	orderMap[key] = order
 	return order, nil
 }
 
@@ -38,13 +63,7 @@ func (r *OrderRepositoryInMemory) FindAllOrders() ([]*domain.Order, error) {
 	r.Mutex.RLock()
 	defer r.Mutex.RUnlock()
 
-	var orders []*domain.Order
-	for _, order := range r.BuyOrders {
-		orders = append(orders, order)
-	}
-	for _, order := range r.SellOrders {
-		orders = append(orders, order)
-	}
+	orders := append(sortedOrders(r.BuyOrders, nil), sortedOrders(r.SellOrders, nil)...)
 
 	if len(orders) == 0 {
 		return nil, domain.ErrNoOrdersFound
@@ -52,27 +71,25 @@ func (r *OrderRepositoryInMemory) FindAllOrders() ([]*domain.Order, error) {
 	return orders, nil
 }
 
-func (r *OrderRepositoryInMemory) FindOrderById(orderType domain.OrderType, id uint64) (*domain.Order, error) {
+func (r *OrderRepositoryInMemory) FindOrderById(hook common.Address, orderType domain.OrderType, id uint64) (*domain.Order, error) {
 	r.Mutex.RLock()
 	defer r.Mutex.RUnlock()
 
 	orderMap := r.getOrderMap(&orderType)
-	order, exists := orderMap[id]
+	order, exists := orderMap[domain.OrderKey{Hook: hook, Id: id}]
 	if !exists {
 		return nil, domain.ErrOrderNotFound
 	}
 	return order, nil
 }
 
-func (r *OrderRepositoryInMemory) FindOrdersByType(orderType domain.OrderType) ([]*domain.Order, error) {
+func (r *OrderRepositoryInMemory) FindOrdersByType(hook common.Address, orderType domain.OrderType) ([]*domain.Order, error) {
 	r.Mutex.RLock()
 	defer r.Mutex.RUnlock()
 
-	orderMap := r.getOrderMap(&orderType)
-	var orders []*domain.Order
-	for _, order := range orderMap {
-		orders = append(orders, order)
-	}
+	orders := sortedOrders(r.getOrderMap(&orderType), func(order *domain.Order) bool {
+		return order.Hook == hook
+	})
 
 	if len(orders) == 0 {
 		return nil, domain.ErrNoOrdersFound
@@ -80,27 +97,97 @@ func (r *OrderRepositoryInMemory) FindOrdersByType(orderType domain.OrderType) (
 	return orders, nil
 }
 
-func (r *OrderRepositoryInMemory) FindOrdersByTypeAndStatus(orderType domain.OrderType, orderStatus domain.OrderStatus) ([]*domain.Order, error) {
+func (r *OrderRepositoryInMemory) FindOrdersByTypeAndStatus(hook common.Address, orderType domain.OrderType, orderStatus domain.OrderStatus) ([]*domain.Order, error) {
 	r.Mutex.RLock()
 	defer r.Mutex.RUnlock()
 
+	orders := sortedOrders(r.getOrderMap(&orderType), func(order *domain.Order) bool {
+		return order.Hook == hook && *order.Status == orderStatus
+	})
+
+	if len(orders) == 0 {
+		return nil, domain.ErrNoOrdersFound
+	}
+	return orders, nil
+}
+
+func (r *OrderRepositoryInMemory) DeleteOrder(hook common.Address, orderType domain.OrderType, id uint64) error {
+	r.Mutex.Lock()
+	defer r.Mutex.Unlock()
+
 	orderMap := r.getOrderMap(&orderType)
+	key := domain.OrderKey{Hook: hook, Id: id}
+	if _, exists := orderMap[key]; !exists {
+		return domain.ErrOrderNotFound
+	}
+
+	delete(orderMap, key)
+	tombstoneMap := r.getTombstones(&orderType)
+	tombstones, ok := tombstoneMap[hook]
+	if !ok {
+		tombstones = domain.NewTombstoneSet()
+		tombstoneMap[hook] = tombstones
+	}
+	tombstones.Add(id)
+	return nil
+}
+
+func (r *OrderRepositoryInMemory) Stats() (*domain.BookStats, error) {
+	r.Mutex.RLock()
+	defer r.Mutex.RUnlock()
+
+	stats := &domain.BookStats{
+		BuyOrders:  len(r.BuyOrders),
+		SellOrders: len(r.SellOrders),
+	}
+	for _, tombstones := range r.BuyTombstones {
+		stats.BuyTombstones += tombstones.Len()
+	}
+	for _, tombstones := range r.SellTombstones {
+		stats.SellTombstones += tombstones.Len()
+	}
+	for _, orderMap := range []map[domain.OrderKey]*domain.Order{r.BuyOrders, r.SellOrders} {
+		for _, order := range orderMap {
+			if order.IsTerminal() {
+				stats.TerminalOrders++
+			}
+			if order.IsQueued() {
+				stats.QueuedOrders++
+			}
+		}
+	}
+	return stats, nil
+}
+
+// sortedOrders walks the map by ascending id and then hook, since map
+// iteration order is random and every operator has to see the orders in the
+// same order.
+func sortedOrders(orderMap map[domain.OrderKey]*domain.Order, keep func(*domain.Order) bool) []*domain.Order {
 	var orders []*domain.Order
 	for _, order := range orderMap {
-		if *order.Status == orderStatus {
+		if keep == nil || keep(order) {
 			orders = append(orders, order)
 		}
 	}
-
-	if len(orders) == 0 {
-		return nil, domain.ErrNoOrdersFound
-	}
-	return orders, nil
+	sort.Slice(orders, func(i, j int) bool {
+		if orders[i].Id != orders[j].Id {
+			return orders[i].Id < orders[j].Id
+		}
+		return bytes.Compare(orders[i].Hook[:], orders[j].Hook[:]) < 0
+	})
+	return orders
 }
 
-func (r *OrderRepositoryInMemory) getOrderMap(orderType *domain.OrderType) map[uint64]*domain.Order {
+func (r *OrderRepositoryInMemory) getOrderMap(orderType *domain.OrderType) map[domain.OrderKey]*domain.Order {
 	if *orderType == domain.OrderTypeBuy {
 		return r.BuyOrders
 	}
 	return r.SellOrders
 }
+
+func (r *OrderRepositoryInMemory) getTombstones(orderType *domain.OrderType) map[common.Address]*domain.TombstoneSet {
+	if *orderType == domain.OrderTypeBuy {
+		return r.BuyTombstones
+	}
+	return r.SellTombstones
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/henriquemarlon/swapx/configs"
+	"github.com/henriquemarlon/swapx/internal/domain"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestOrder(t *testing.T, hook common.Address, id uint64, orderType *domain.OrderType) *domain.Order {
+	order, err := domain.NewOrder(id, hook, uint256.NewInt(10), uint256.NewInt(100), uint256.NewInt(0), orderType, &domain.OrderNotCancelledOrFulfilled)
+	require.NoError(t, err)
+	return order
+}
+
+func TestCreateOrderRefusesAKnownOrder(t *testing.T) {
+	db, err := configs.SetupInMemoryDB()
+	require.NoError(t, err)
+	orders := NewOrderRepositoryInMemory(db)
+
+	_, err = orders.CreateOrder(newTestOrder(t, testHook, 1, &domain.OrderTypeBuy))
+	require.NoError(t, err)
+	_, err = orders.CreateOrder(newTestOrder(t, testHook, 1, &domain.OrderTypeBuy))
+	assert.Equal(t, domain.ErrOrderAlreadyExists, err)
+
+	// the same id on the other side or of another hook is another order
+	_, err = orders.CreateOrder(newTestOrder(t, testHook, 1, &domain.OrderTypeSell))
+	assert.NoError(t, err)
+	_, err = orders.CreateOrder(newTestOrder(t, common.HexToAddress("0xbb"), 1, &domain.OrderTypeBuy))
+	assert.NoError(t, err)
+}
+
+func TestUpdateOrderReplacesAKnownOrder(t *testing.T) {
+	db, err := configs.SetupInMemoryDB()
+	require.NoError(t, err)
+	orders := NewOrderRepositoryInMemory(db)
+
+	_, err = orders.UpdateOrder(newTestOrder(t, testHook, 1, &domain.OrderTypeBuy))
+	assert.Equal(t, domain.ErrOrderNotFound, err)
+
+	_, err = orders.CreateOrder(newTestOrder(t, testHook, 1, &domain.OrderTypeBuy))
+	require.NoError(t, err)
+
+	updated := newTestOrder(t, testHook, 1, &domain.OrderTypeBuy)
+	updated.MatchedAmount = uint256.NewInt(100)
+	updated.Status = &domain.OrderCancelledOrFulfilled
+	_, err = orders.UpdateOrder(updated)
+	require.NoError(t, err)
+
+	found, err := orders.FindOrderById(testHook, domain.OrderTypeBuy, 1)
+	require.NoError(t, err)
+	assert.Equal(t, uint256.NewInt(100), found.MatchedAmount)
+	assert.Equal(t, domain.OrderCancelledOrFulfilled, *found.Status)
+
+	_, err = orders.FindOrdersByTypeAndStatus(testHook, domain.OrderTypeBuy, domain.OrderNotCancelledOrFulfilled)
+	assert.Equal(t, domain.ErrNoOrdersFound, err)
+}
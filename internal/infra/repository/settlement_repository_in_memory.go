@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/henriquemarlon/swapx/configs"
+	"github.com/henriquemarlon/swapx/internal/domain"
+)
+
+type SettlementRepositoryInMemory struct {
+	PendingFills     *[]*domain.PendingFill
+	PendingPoolSwaps *[]*domain.PendingPoolSwap
+	Mutex            *sync.RWMutex
+}
+
+func NewSettlementRepositoryInMemory(db *configs.InMemoryDB) *SettlementRepositoryInMemory {
+	return &SettlementRepositoryInMemory{
+		PendingFills:     db.PendingFills,
+		PendingPoolSwaps: db.PendingPoolSwaps,
+		Mutex:            db.Mutex,
+	}
+}
+
+func (r *SettlementRepositoryInMemory) CreatePendingFill(fill *domain.PendingFill) error {
+	r.Mutex.Lock()
+	defer r.Mutex.Unlock()
+
+	*r.PendingFills = append(*r.PendingFills, fill)
+	return nil
+}
+
+func (r *SettlementRepositoryInMemory) FindPendingFills(hook common.Address) ([]*domain.PendingFill, error) {
+	r.Mutex.RLock()
+	defer r.Mutex.RUnlock()
+
+	fills := []*domain.PendingFill{}
+	for _, fill := range *r.PendingFills {
+		if fill.Hook == hook {
+			fills = append(fills, fill)
+		}
+	}
+	return fills, nil
+}
+
+func (r *SettlementRepositoryInMemory) DeletePendingFill(fillId common.Hash) error {
+	r.Mutex.Lock()
+	defer r.Mutex.Unlock()
+
+	fills := (*r.PendingFills)[:0]
+	for _, fill := range *r.PendingFills {
+		if fill.FillId != fillId {
+			fills = append(fills, fill)
+		}
+	}
+	*r.PendingFills = fills
+	return nil
+}
+
+func (r *SettlementRepositoryInMemory) CountPendingFills() (int, error) {
+	r.Mutex.RLock()
+	defer r.Mutex.RUnlock()
+
+	return len(*r.PendingFills), nil
+}
+
+func (r *SettlementRepositoryInMemory) CreatePendingPoolSwap(swap *domain.PendingPoolSwap) error {
+	r.Mutex.Lock()
+	defer r.Mutex.Unlock()
+
+	*r.PendingPoolSwaps = append(*r.PendingPoolSwaps, swap)
+	return nil
+}
+
+func (r *SettlementRepositoryInMemory) FindPendingPoolSwaps(hook common.Address) ([]*domain.PendingPoolSwap, error) {
+	r.Mutex.RLock()
+	defer r.Mutex.RUnlock()
+
+	swaps := []*domain.PendingPoolSwap{}
+	for _, swap := range *r.PendingPoolSwaps {
+		if swap.Hook == hook {
+			swaps = append(swaps, swap)
+		}
+	}
+	return swaps, nil
+}
+
+// UpdatePendingPoolSwap swaps the pointer rather than the fields, the
+// checkpoints share the pending swaps with the live state
+func (r *SettlementRepositoryInMemory) UpdatePendingPoolSwap(swap *domain.PendingPoolSwap) error {
+	r.Mutex.Lock()
+	defer r.Mutex.Unlock()
+
+	swaps := make([]*domain.PendingPoolSwap, len(*r.PendingPoolSwaps))
+	for i, pending := range *r.PendingPoolSwaps {
+		if pending.Is(swap) {
+			pending = swap
+		}
+		swaps[i] = pending
+	}
+	*r.PendingPoolSwaps = swaps
+	return nil
+}
+
+func (r *SettlementRepositoryInMemory) DeletePendingPoolSwap(swap *domain.PendingPoolSwap) error {
+	r.Mutex.Lock()
+	defer r.Mutex.Unlock()
+
+	swaps := make([]*domain.PendingPoolSwap, 0, len(*r.PendingPoolSwaps))
+	for _, pending := range *r.PendingPoolSwaps {
+		if !pending.Is(swap) {
+			swaps = append(swaps, pending)
+		}
+	}
+	*r.PendingPoolSwaps = swaps
+	return nil
+}
+
+func (r *SettlementRepositoryInMemory) CountPendingPoolSwaps() (int, error) {
+	r.Mutex.RLock()
+	defer r.Mutex.RUnlock()
+
+	return len(*r.PendingPoolSwaps), nil
+}
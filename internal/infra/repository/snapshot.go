@@ -0,0 +1,510 @@
+package repository
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"os"
+	"slices"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/henriquemarlon/swapx/configs"
+	"github.com/henriquemarlon/swapx/internal/domain"
+)
+
+// SNAPSHOT_VERSION is bumped whenever the meaning of a field changes or a new
+// one is required to restore the book. Older versions are migrated forward by
+// migrateSnapshot, newer ones are refused.
+const SNAPSHOT_VERSION = 7
+
+// OLDEST_SNAPSHOT_VERSION is the first version DecodeSnapshot can migrate
+const OLDEST_SNAPSHOT_VERSION = 1
+
+var ErrInvalidSnapshot = errors.New("invalid snapshot")
+
+// Snapshot is the content of the in-memory database in the canonical order of
+// domain.CanonicalState, so its StateHash is the one the state reports carry.
+type Snapshot struct {
+	Version   int                 `json:"version"`
+	StateHash common.Hash         `json:"state_hash"`
+	Cursor    domain.SyncCursor   `json:"cursor"`
+	Orders    []domain.OrderState `json:"orders"`
+	// Tombstones are the pruned orders of each hook, by ascending hook
+	Tombstones []HookTombstones `json:"tombstones"`
+	// PendingFills are the fills sent and not yet seen executed, in the order
+	// they were sent. Like the tombstones, they are not part of the state hash.
+	PendingFills []*domain.PendingFill `json:"pending_fills"`
+	// PendingPoolSwaps are the swaps through the pool sent and not yet seen
+	// executed, in the order they were sent
+	PendingPoolSwaps []*domain.PendingPoolSwap `json:"pending_pool_swaps"`
+}
+
+// TombstoneState lists the pruned order ids as domain.TombstoneSet keeps them
+type TombstoneState struct {
+	Floor uint64   `json:"floor"`
+	Ids   []uint64 `json:"ids"`
+}
+
+func NewTombstoneState(set *domain.TombstoneSet) TombstoneState {
+	if set == nil {
+		return TombstoneState{Ids: []uint64{}}
+	}
+	return TombstoneState{Floor: set.Floor(), Ids: set.Ids()}
+}
+
+type HookTombstones struct {
+	Hook common.Address `json:"hook"`
+	Buy  TombstoneState `json:"buy"`
+	Sell TombstoneState `json:"sell"`
+}
+
+// NewHookTombstones lists the tombstones of every hook that pruned an order
+func NewHookTombstones(buy, sell map[common.Address]*domain.TombstoneSet) []HookTombstones {
+	hooks := make([]common.Address, 0, len(buy)+len(sell))
+	for hook := range buy {
+		hooks = append(hooks, hook)
+	}
+	for hook := range sell {
+		if _, ok := buy[hook]; !ok {
+			hooks = append(hooks, hook)
+		}
+	}
+	sort.Slice(hooks, func(i, j int) bool { return bytes.Compare(hooks[i][:], hooks[j][:]) < 0 })
+
+	tombstones := make([]HookTombstones, 0, len(hooks))
+	for _, hook := range hooks {
+		tombstones = append(tombstones, HookTombstones{Hook: hook, Buy: NewTombstoneState(buy[hook]), Sell: NewTombstoneState(sell[hook])})
+	}
+	return tombstones
+}
+
+func ExportSnapshot(db *configs.InMemoryDB) (*Snapshot, error) {
+	db.Mutex.RLock()
+	defer db.Mutex.RUnlock()
+
+	orders := append(sortedOrders(db.BuyOrders, nil), sortedOrders(db.SellOrders, nil)...)
+	hash, err := domain.StateHash(orders)
+	if err != nil {
+		return nil, err
+	}
+	snapshot := &Snapshot{
+		Version:          SNAPSHOT_VERSION,
+		StateHash:        hash,
+		Cursor:           *db.Cursor,
+		Orders:           domain.CanonicalOrderStates(orders),
+		Tombstones:       NewHookTombstones(db.BuyTombstones, db.SellTombstones),
+		PendingFills:     append([]*domain.PendingFill{}, *db.PendingFills...),
+		PendingPoolSwaps: append([]*domain.PendingPoolSwap{}, *db.PendingPoolSwaps...),
+	}
+	return snapshot, nil
+}
+
+// ImportSnapshot replaces the content of the database with the snapshot
+func ImportSnapshot(db *configs.InMemoryDB, snapshot *Snapshot) error {
+	buyOrders := make(map[domain.OrderKey]*domain.Order)
+	sellOrders := make(map[domain.OrderKey]*domain.Order)
+	buyTombstones := make(map[common.Address]*domain.TombstoneSet)
+	sellTombstones := make(map[common.Address]*domain.TombstoneSet)
+	for _, hook := range snapshot.Tombstones {
+		if _, exists := buyTombstones[hook.Hook]; exists {
+			return fmt.Errorf("%w: tombstones of hook %s are listed twice", ErrInvalidSnapshot, hook.Hook.Hex())
+		}
+		buyTombstones[hook.Hook] = domain.RestoreTombstoneSet(hook.Buy.Floor, hook.Buy.Ids)
+		sellTombstones[hook.Hook] = domain.RestoreTombstoneSet(hook.Sell.Floor, hook.Sell.Ids)
+	}
+	for _, state := range snapshot.Orders {
+		order, err := state.Order()
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidSnapshot, err)
+		}
+		orderMap, tombstones := buyOrders, buyTombstones
+		if *order.Type == domain.OrderTypeSell {
+			orderMap, tombstones = sellOrders, sellTombstones
+		}
+		if set, ok := tombstones[order.Hook]; ok && set.Contains(order.Id) {
+			return fmt.Errorf("%w: %s order %d of hook %s is both listed and pruned", ErrInvalidSnapshot, *order.Type, order.Id, order.Hook.Hex())
+		}
+		key := domain.OrderKey{Hook: order.Hook, Id: order.Id}
+		if _, exists := orderMap[key]; exists {
+			return fmt.Errorf("%w: %s order %d of hook %s is listed twice", ErrInvalidSnapshot, *order.Type, order.Id, order.Hook.Hex())
+		}
+		orderMap[key] = order
+	}
+	pendingFills := make(map[common.Hash]struct{}, len(snapshot.PendingFills))
+	for _, fill := range snapshot.PendingFills {
+		if fill == nil || fill.Quantity == nil || fill.BidMatched == nil || fill.AskMatched == nil {
+			return fmt.Errorf("%w: incomplete pending fill", ErrInvalidSnapshot)
+		}
+		if _, exists := pendingFills[fill.FillId]; exists {
+			return fmt.Errorf("%w: fill %s is pending twice", ErrInvalidSnapshot, fill.FillId.Hex())
+		}
+		pendingFills[fill.FillId] = struct{}{}
+	}
+	for i, swap := range snapshot.PendingPoolSwaps {
+		if swap == nil || swap.AmountIn == nil || swap.Matched == nil {
+			return fmt.Errorf("%w: incomplete pending pool swap", ErrInvalidSnapshot)
+		}
+		for _, other := range snapshot.PendingPoolSwaps[:i] {
+			if other.Is(swap) {
+				return fmt.Errorf("%w: swap of %s order %d of task %s is pending twice", ErrInvalidSnapshot, swap.OrderType, swap.OrderId, swap.TaskId.Hex())
+			}
+		}
+	}
+
+	db.Mutex.Lock()
+	defer db.Mutex.Unlock()
+
+	clear(db.BuyOrders)
+	clear(db.SellOrders)
+	maps.Copy(db.BuyOrders, buyOrders)
+	maps.Copy(db.SellOrders, sellOrders)
+	clear(db.BuyTombstones)
+	maps.Copy(db.BuyTombstones, buyTombstones)
+	clear(db.SellTombstones)
+	maps.Copy(db.SellTombstones, sellTombstones)
+	*db.Cursor = snapshot.Cursor
+	*db.PendingFills = append([]*domain.PendingFill{}, snapshot.PendingFills...)
+	*db.PendingPoolSwaps = append([]*domain.PendingPoolSwap{}, snapshot.PendingPoolSwaps...)
+	return nil
+}
+
+func EncodeSnapshot(snapshot *Snapshot) ([]byte, error) {
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// DecodeSnapshot refuses unknown versions and snapshots whose orders do not
+// hash to the state hash they claim, which catches hand edits and truncation.
+// Snapshots of older versions are checked against the hash they were written
+// with and then migrated to SNAPSHOT_VERSION.
+func DecodeSnapshot(data []byte) (*Snapshot, error) {
+	var header struct {
+		Version   int             `json:"version"`
+		StateHash common.Hash     `json:"state_hash"`
+		Orders    json.RawMessage `json:"orders"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSnapshot, err)
+	}
+	if header.Version < OLDEST_SNAPSHOT_VERSION || header.Version > SNAPSHOT_VERSION {
+		return nil, fmt.Errorf("%w: unsupported version %d, expected %d to %d", ErrInvalidSnapshot, header.Version, OLDEST_SNAPSHOT_VERSION, SNAPSHOT_VERSION)
+	}
+
+	snapshot, err := migrateSnapshot(data, header.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	orders := make([]*domain.Order, 0, len(snapshot.Orders))
+	for _, state := range snapshot.Orders {
+		order, err := state.Order()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidSnapshot, err)
+		}
+		orders = append(orders, order)
+	}
+	hash, err := domain.StateHash(orders)
+	if err != nil {
+		return nil, err
+	}
+	if header.Version < 2 {
+		// the orders of version 1 had no terminal block, so they hash as written
+		var written bytes.Buffer
+		if err := json.Compact(&written, header.Orders); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidSnapshot, err)
+		}
+		if writtenHash := crypto.Keccak256Hash(written.Bytes()); writtenHash != header.StateHash {
+			return nil, fmt.Errorf("%w: orders hash to %s, not to %s", ErrInvalidSnapshot, writtenHash.Hex(), header.StateHash.Hex())
+		}
+		snapshot.StateHash = hash
+	}
+	if hash != snapshot.StateHash {
+		return nil, fmt.Errorf("%w: orders hash to %s, not to %s", ErrInvalidSnapshot, hash.Hex(), snapshot.StateHash.Hex())
+	}
+	return snapshot, nil
+}
+
+// legacySnapshot is the layout of the versions before 5, which kept a single
+// set of tombstones for the one hook the book knew
+type legacySnapshot struct {
+	Snapshot
+	Tombstones struct {
+		Buy  TombstoneState `json:"buy"`
+		Sell TombstoneState `json:"sell"`
+	} `json:"tombstones"`
+}
+
+// migrateSnapshot decodes a snapshot of any supported version into the current
+// layout, giving what an older version did not record the value it had then:
+// no tombstones before 2, no pending fills before 3, no expiry for the fills
+// sent before 6 and no pending pool swaps before 7. Orders are never queued
+// before 4 and a terminal order of version 1 restarts its retention window.
+func migrateSnapshot(data []byte, version int) (*Snapshot, error) {
+	var snapshot Snapshot
+	if version < 5 {
+		var legacy legacySnapshot
+		if err := json.Unmarshal(data, &legacy); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidSnapshot, err)
+		}
+		snapshot = legacy.Snapshot
+		tombstones, err := legacyTombstones(&snapshot, legacy.Tombstones.Buy, legacy.Tombstones.Sell)
+		if err != nil {
+			return nil, err
+		}
+		snapshot.Tombstones = tombstones
+	} else if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSnapshot, err)
+	}
+
+	if version < 6 {
+		// the notices did not carry an expiry block, the hook executes these
+		// fills at any time so they are waited for forever
+		for _, fill := range snapshot.PendingFills {
+			if fill != nil {
+				fill.ExpiryBlock = math.MaxUint64
+			}
+		}
+	}
+	if snapshot.Tombstones == nil {
+		snapshot.Tombstones = []HookTombstones{}
+	}
+	if snapshot.PendingFills == nil {
+		snapshot.PendingFills = []*domain.PendingFill{}
+	}
+	if snapshot.PendingPoolSwaps == nil {
+		snapshot.PendingPoolSwaps = []*domain.PendingPoolSwap{}
+	}
+	snapshot.Version = SNAPSHOT_VERSION
+	return &snapshot, nil
+}
+
+// legacyTombstones gives the tombstones of a version before 5 to the hook of
+// the orders and pending fills. They cannot be given to any hook when the
+// snapshot names none or several, such a snapshot has to be taken again.
+func legacyTombstones(snapshot *Snapshot, buy, sell TombstoneState) ([]HookTombstones, error) {
+	if buy.Floor == 0 && len(buy.Ids) == 0 && sell.Floor == 0 && len(sell.Ids) == 0 {
+		return []HookTombstones{}, nil
+	}
+	hooks := []common.Address{}
+	addHook := func(hook common.Address) {
+		if !slices.Contains(hooks, hook) {
+			hooks = append(hooks, hook)
+		}
+	}
+	for _, state := range snapshot.Orders {
+		addHook(state.Hook)
+	}
+	for _, fill := range snapshot.PendingFills {
+		if fill != nil {
+			addHook(fill.Hook)
+		}
+	}
+	if len(hooks) != 1 {
+		return nil, fmt.Errorf("%w: the tombstones of version %d belong to one hook, the snapshot names %d", ErrInvalidSnapshot, snapshot.Version, len(hooks))
+	}
+	if buy.Ids == nil {
+		buy.Ids = []uint64{}
+	}
+	if sell.Ids == nil {
+		sell.Ids = []uint64{}
+	}
+	return []HookTombstones{{Hook: hooks[0], Buy: buy, Sell: sell}}, nil
+}
+
+func LoadSnapshot(path string) (*Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeSnapshot(data)
+}
+
+func ImportSnapshotFile(db *configs.InMemoryDB, path string) (*Snapshot, error) {
+	snapshot, err := LoadSnapshot(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := ImportSnapshot(db, snapshot); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+func WriteSnapshot(path string, snapshot *Snapshot) error {
+	data, err := EncodeSnapshot(snapshot)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+type SnapshotChange struct {
+	Type   domain.OrderType `json:"type,omitempty"`
+	Id     uint64           `json:"id,omitempty"`
+	Hook   *common.Address  `json:"hook,omitempty"`
+	Field  string           `json:"field"`
+	Before string           `json:"before"`
+	After  string           `json:"after"`
+}
+
+// DiffSnapshots lists the differences between two snapshots field by field,
+// cursor, tombstones and pending fills first and then orders in canonical order. A missing
+// order shows as a single change of its "order" field.
+func DiffSnapshots(before, after *Snapshot) []SnapshotChange {
+	changes := []SnapshotChange{}
+	for _, field := range []struct {
+		name          string
+		before, after string
+	}{
+		{"cursor.block_number", fmt.Sprint(before.Cursor.BlockNumber), fmt.Sprint(after.Cursor.BlockNumber)},
+		{"cursor.block_hash", before.Cursor.BlockHash.Hex(), after.Cursor.BlockHash.Hex()},
+		{"cursor.inputs", fmt.Sprint(before.Cursor.Inputs), fmt.Sprint(after.Cursor.Inputs)},
+	} {
+		if field.before != field.after {
+			changes = append(changes, SnapshotChange{Field: field.name, Before: field.before, After: field.after})
+		}
+	}
+	changes = append(changes, diffTombstones(before.Tombstones, after.Tombstones)...)
+	if before, after := pendingFillIds(before.PendingFills), pendingFillIds(after.PendingFills); before != after {
+		changes = append(changes, SnapshotChange{Field: "pending_fills", Before: before, After: after})
+	}
+	if before, after := pendingPoolSwapIds(before.PendingPoolSwaps), pendingPoolSwapIds(after.PendingPoolSwaps); before != after {
+		changes = append(changes, SnapshotChange{Field: "pending_pool_swaps", Before: before, After: after})
+	}
+
+	type key struct {
+		orderType domain.OrderType
+		id        uint64
+		hook      common.Address
+	}
+	index := func(snapshot *Snapshot) map[key]domain.OrderState {
+		states := make(map[key]domain.OrderState, len(snapshot.Orders))
+		for _, state := range snapshot.Orders {
+			states[key{state.Type, state.Id, state.Hook}] = state
+		}
+		return states
+	}
+	beforeOrders, afterOrders := index(before), index(after)
+
+	var keys []key
+	for k := range beforeOrders {
+		keys = append(keys, k)
+	}
+	for k := range afterOrders {
+		if _, ok := beforeOrders[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].orderType != keys[j].orderType {
+			return keys[i].orderType == domain.OrderTypeBuy
+		}
+		if keys[i].id != keys[j].id {
+			return keys[i].id < keys[j].id
+		}
+		return bytes.Compare(keys[i].hook[:], keys[j].hook[:]) < 0
+	})
+
+	for _, k := range keys {
+		b, inBefore := beforeOrders[k]
+		a, inAfter := afterOrders[k]
+		hook := k.hook
+		switch {
+		case !inBefore:
+			changes = append(changes, SnapshotChange{Type: k.orderType, Id: k.id, Hook: &hook, Field: "order", Before: "missing", After: "present"})
+		case !inAfter:
+			changes = append(changes, SnapshotChange{Type: k.orderType, Id: k.id, Hook: &hook, Field: "order", Before: "present", After: "missing"})
+		default:
+			for _, field := range []struct {
+				name          string
+				before, after string
+			}{
+				{"account", accountHex(b.Account), accountHex(a.Account)},
+				{"sqrt_price", b.SqrtPrice, a.SqrtPrice},
+				{"amount", b.Amount, a.Amount},
+				{"matched_amount", b.MatchedAmount, a.MatchedAmount},
+				{"status", string(b.Status), string(a.Status)},
+				{"flags", fmt.Sprint(uint64(b.Flags)), fmt.Sprint(uint64(a.Flags))},
+			} {
+				if field.before != field.after {
+					changes = append(changes, SnapshotChange{Type: k.orderType, Id: k.id, Hook: &hook, Field: field.name, Before: field.before, After: field.after})
+				}
+			}
+		}
+	}
+	return changes
+}
+
+// diffTombstones compares the tombstones hook by hook, a hook missing on one
+// side compares as a hook without tombstones
+func diffTombstones(before, after []HookTombstones) []SnapshotChange {
+	index := func(tombstones []HookTombstones) map[common.Address]HookTombstones {
+		hooks := make(map[common.Address]HookTombstones, len(tombstones))
+		for _, hook := range tombstones {
+			hooks[hook.Hook] = hook
+		}
+		return hooks
+	}
+	beforeHooks, afterHooks := index(before), index(after)
+	var hooks []common.Address
+	for hook := range beforeHooks {
+		hooks = append(hooks, hook)
+	}
+	for hook := range afterHooks {
+		if _, ok := beforeHooks[hook]; !ok {
+			hooks = append(hooks, hook)
+		}
+	}
+	sort.Slice(hooks, func(i, j int) bool { return bytes.Compare(hooks[i][:], hooks[j][:]) < 0 })
+
+	changes := []SnapshotChange{}
+	for _, hook := range hooks {
+		b, a := beforeHooks[hook], afterHooks[hook]
+		for _, field := range []struct {
+			name          string
+			before, after string
+		}{
+			{"tombstones.buy.floor", fmt.Sprint(b.Buy.Floor), fmt.Sprint(a.Buy.Floor)},
+			{"tombstones.buy.ids", fmt.Sprint(b.Buy.Ids), fmt.Sprint(a.Buy.Ids)},
+			{"tombstones.sell.floor", fmt.Sprint(b.Sell.Floor), fmt.Sprint(a.Sell.Floor)},
+			{"tombstones.sell.ids", fmt.Sprint(b.Sell.Ids), fmt.Sprint(a.Sell.Ids)},
+		} {
+			if field.before != field.after {
+				hook := hook
+				changes = append(changes, SnapshotChange{Hook: &hook, Field: field.name, Before: field.before, After: field.after})
+			}
+		}
+	}
+	return changes
+}
+
+func pendingFillIds(fills []*domain.PendingFill) string {
+	ids := make([]string, 0, len(fills))
+	for _, fill := range fills {
+		ids = append(ids, fill.FillId.Hex())
+	}
+	return fmt.Sprint(ids)
+}
+
+func pendingPoolSwapIds(swaps []*domain.PendingPoolSwap) string {
+	ids := make([]string, 0, len(swaps))
+	for _, swap := range swaps {
+		ids = append(ids, fmt.Sprintf("%s/%s/%d", swap.TaskId.Hex(), swap.OrderType, swap.OrderId))
+	}
+	return fmt.Sprint(ids)
+}
+
+func accountHex(account *common.Address) string {
+	if account == nil {
+		return "unknown"
+	}
+	return account.Hex()
+}
@@ -0,0 +1,242 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/henriquemarlon/swapx/configs"
+	"github.com/henriquemarlon/swapx/internal/domain"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testHook = common.HexToAddress("0x00000000000000000000000000000000000000aa")
+
+func newTestDB(t *testing.T) *configs.InMemoryDB {
+	db, err := configs.SetupInMemoryDB()
+	require.NoError(t, err)
+
+	orders := NewOrderRepositoryInMemory(db)
+	for _, order := range []struct {
+		id        uint64
+		orderType *domain.OrderType
+		matched   uint64
+	}{
+		{2, &domain.OrderTypeBuy, 0},
+		{1, &domain.OrderTypeBuy, 10},
+		{1, &domain.OrderTypeSell, 10},
+	} {
+		o, err := domain.NewOrder(order.id, testHook, uint256.NewInt(100), uint256.NewInt(50), uint256.NewInt(order.matched), order.orderType, &domain.OrderNotCancelledOrFulfilled)
+		require.NoError(t, err)
+		_, err = orders.CreateOrder(o)
+		require.NoError(t, err)
+	}
+	require.NoError(t, NewCursorRepositoryInMemory(db).UpdateCursor(&domain.SyncCursor{BlockNumber: 7, BlockHash: common.HexToHash("0x7"), Inputs: 3}))
+	require.NoError(t, NewSettlementRepositoryInMemory(db).CreatePendingFill(&domain.PendingFill{
+		FillId:     common.HexToHash("0xf1"),
+		Hook:       testHook,
+		BidId:      1,
+		AskId:      1,
+		Quantity:   uint256.NewInt(10),
+		BidMatched: uint256.NewInt(10),
+		AskMatched: uint256.NewInt(10),
+		SentAt:     7,
+	}))
+	require.NoError(t, NewSettlementRepositoryInMemory(db).CreatePendingPoolSwap(&domain.PendingPoolSwap{
+		Hook:      testHook,
+		TaskId:    common.HexToHash("0x7a"),
+		OrderType: domain.OrderTypeBuy,
+		OrderId:   1,
+		AmountIn:  uint256.NewInt(5),
+		Matched:   uint256.NewInt(10),
+		SentAt:    7,
+	}))
+	return db
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	db := newTestDB(t)
+	snapshot, err := ExportSnapshot(db)
+	require.NoError(t, err)
+
+	orders, err := NewOrderRepositoryInMemory(db).FindAllOrders()
+	require.NoError(t, err)
+	hash, err := domain.StateHash(orders)
+	require.NoError(t, err)
+	assert.Equal(t, hash, snapshot.StateHash)
+
+	data, err := EncodeSnapshot(snapshot)
+	require.NoError(t, err)
+	decoded, err := DecodeSnapshot(data)
+	require.NoError(t, err)
+
+	restored, err := configs.SetupInMemoryDB()
+	require.NoError(t, err)
+	require.NoError(t, ImportSnapshot(restored, decoded))
+
+	again, err := ExportSnapshot(restored)
+	require.NoError(t, err)
+	againData, err := EncodeSnapshot(again)
+	require.NoError(t, err)
+	assert.Equal(t, string(data), string(againData))
+	assert.Empty(t, DiffSnapshots(snapshot, again))
+}
+
+func TestDecodeSnapshotRejectsTamperingAndOtherVersions(t *testing.T) {
+	snapshot, err := ExportSnapshot(newTestDB(t))
+	require.NoError(t, err)
+	data, err := EncodeSnapshot(snapshot)
+	require.NoError(t, err)
+
+	_, err = DecodeSnapshot([]byte(strings.Replace(string(data), `"matched_amount": "10"`, `"matched_amount": "0"`, 1)))
+	assert.ErrorIs(t, err, ErrInvalidSnapshot)
+
+	for _, version := range []int{OLDEST_SNAPSHOT_VERSION - 1, SNAPSHOT_VERSION + 1} {
+		_, err = DecodeSnapshot([]byte(strings.Replace(string(data), fmt.Sprintf(`"version": %d`, SNAPSHOT_VERSION), fmt.Sprintf(`"version": %d`, version), 1)))
+		assert.ErrorIs(t, err, ErrInvalidSnapshot)
+	}
+}
+
+// encodeLegacySnapshot writes the snapshot in the layout of an older version
+func encodeLegacySnapshot(t *testing.T, snapshot *Snapshot, version int) []byte {
+	data, err := EncodeSnapshot(snapshot)
+	require.NoError(t, err)
+	var fields map[string]any
+	require.NoError(t, json.Unmarshal(data, &fields))
+
+	fields["version"] = version
+	if version < 7 {
+		delete(fields, "pending_pool_swaps")
+	}
+	if version < 6 {
+		for _, fill := range fields["pending_fills"].([]any) {
+			delete(fill.(map[string]any), "expiry_block")
+		}
+	}
+	if version < 5 {
+		tombstones := fields["tombstones"].([]any)
+		require.Len(t, tombstones, 1)
+		hook := tombstones[0].(map[string]any)
+		fields["tombstones"] = map[string]any{"buy": hook["buy"], "sell": hook["sell"]}
+	}
+	if version < 3 {
+		delete(fields, "pending_fills")
+	}
+	if version < 2 {
+		delete(fields, "tombstones")
+		for _, order := range fields["orders"].([]any) {
+			delete(order.(map[string]any), "terminal_since")
+		}
+		orders, err := json.Marshal(fields["orders"])
+		require.NoError(t, err)
+		fields["state_hash"] = crypto.Keccak256Hash(orders)
+	}
+
+	data, err = json.MarshalIndent(fields, "", "  ")
+	require.NoError(t, err)
+	return data
+}
+
+func TestDecodeSnapshotMigratesOlderVersions(t *testing.T) {
+	db := newTestDB(t)
+	require.NoError(t, NewOrderRepositoryInMemory(db).DeleteOrder(testHook, domain.OrderTypeBuy, 2))
+	current, err := ExportSnapshot(db)
+	require.NoError(t, err)
+
+	for version := OLDEST_SNAPSHOT_VERSION; version < SNAPSHOT_VERSION; version++ {
+		t.Run(fmt.Sprintf("version %d", version), func(t *testing.T) {
+			snapshot, err := DecodeSnapshot(encodeLegacySnapshot(t, current, version))
+			require.NoError(t, err)
+
+			assert.Equal(t, SNAPSHOT_VERSION, snapshot.Version)
+			assert.Equal(t, current.StateHash, snapshot.StateHash)
+			assert.Equal(t, current.Cursor, snapshot.Cursor)
+			assert.Equal(t, current.Orders, snapshot.Orders)
+			assert.Empty(t, snapshot.PendingPoolSwaps)
+			if version < 2 {
+				assert.Empty(t, snapshot.Tombstones)
+			} else {
+				assert.Equal(t, current.Tombstones, snapshot.Tombstones)
+			}
+			if version < 3 {
+				assert.Empty(t, snapshot.PendingFills)
+			} else {
+				require.Len(t, snapshot.PendingFills, 1)
+				if version < 6 {
+					assert.Equal(t, uint64(math.MaxUint64), snapshot.PendingFills[0].ExpiryBlock)
+				} else {
+					assert.Equal(t, current.PendingFills[0].ExpiryBlock, snapshot.PendingFills[0].ExpiryBlock)
+				}
+			}
+
+			restored, err := configs.SetupInMemoryDB()
+			require.NoError(t, err)
+			require.NoError(t, ImportSnapshot(restored, snapshot))
+		})
+	}
+
+	t.Run("tombstones of no known hook", func(t *testing.T) {
+		empty := *current
+		empty.Orders = []domain.OrderState{}
+		empty.PendingFills = []*domain.PendingFill{}
+		empty.StateHash, err = domain.StateHash(nil)
+		require.NoError(t, err)
+		_, err := DecodeSnapshot(encodeLegacySnapshot(t, &empty, 4))
+		assert.ErrorIs(t, err, ErrInvalidSnapshot)
+	})
+
+	t.Run("tampered version 1", func(t *testing.T) {
+		data := encodeLegacySnapshot(t, current, 1)
+		_, err := DecodeSnapshot([]byte(strings.Replace(string(data), `"matched_amount": "10"`, `"matched_amount": "0"`, 1)))
+		assert.ErrorIs(t, err, ErrInvalidSnapshot)
+	})
+}
+
+func TestImportSnapshotKeepsTombstones(t *testing.T) {
+	db := newTestDB(t)
+	require.NoError(t, NewOrderRepositoryInMemory(db).DeleteOrder(testHook, domain.OrderTypeBuy, 2))
+	snapshot, err := ExportSnapshot(db)
+	require.NoError(t, err)
+	assert.Equal(t, []HookTombstones{{Hook: testHook, Buy: TombstoneState{Ids: []uint64{2}}, Sell: TombstoneState{Ids: []uint64{}}}}, snapshot.Tombstones)
+
+	restored, err := configs.SetupInMemoryDB()
+	require.NoError(t, err)
+	require.NoError(t, ImportSnapshot(restored, snapshot))
+
+	o, err := domain.NewOrder(2, testHook, uint256.NewInt(100), uint256.NewInt(50), uint256.NewInt(0), &domain.OrderTypeBuy, &domain.OrderNotCancelledOrFulfilled)
+	require.NoError(t, err)
+	_, err = NewOrderRepositoryInMemory(restored).CreateOrder(o)
+	assert.ErrorIs(t, err, domain.ErrOrderPruned)
+
+	snapshot.Tombstones[0].Buy.Ids = []uint64{1}
+	assert.ErrorIs(t, ImportSnapshot(restored, snapshot), ErrInvalidSnapshot)
+}
+
+func TestDiffSnapshots(t *testing.T) {
+	before, err := ExportSnapshot(newTestDB(t))
+	require.NoError(t, err)
+
+	db := newTestDB(t)
+	db.BuyOrders[domain.OrderKey{Hook: testHook, Id: 1}].MatchedAmount = uint256.NewInt(50)
+	require.NoError(t, NewOrderRepositoryInMemory(db).DeleteOrder(testHook, domain.OrderTypeSell, 1))
+	db.Cursor.Inputs = 4
+	require.NoError(t, NewSettlementRepositoryInMemory(db).DeletePendingFill(common.HexToHash("0xf1")))
+	require.NoError(t, NewSettlementRepositoryInMemory(db).DeletePendingPoolSwap(&domain.PendingPoolSwap{Hook: testHook, TaskId: common.HexToHash("0x7a"), OrderType: domain.OrderTypeBuy, OrderId: 1}))
+	after, err := ExportSnapshot(db)
+	require.NoError(t, err)
+
+	assert.Equal(t, []SnapshotChange{
+		{Field: "cursor.inputs", Before: "3", After: "4"},
+		{Hook: &testHook, Field: "tombstones.sell.floor", Before: "0", After: "1"},
+		{Field: "pending_fills", Before: "[" + common.HexToHash("0xf1").Hex() + "]", After: "[]"},
+		{Field: "pending_pool_swaps", Before: "[" + common.HexToHash("0x7a").Hex() + "/buy/1]", After: "[]"},
+		{Type: domain.OrderTypeBuy, Id: 1, Hook: &testHook, Field: "matched_amount", Before: "10", After: "50"},
+		{Type: domain.OrderTypeSell, Id: 1, Hook: &testHook, Field: "order", Before: "present", After: "missing"},
+	}, DiffSnapshots(before, after))
+}
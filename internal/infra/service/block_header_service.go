@@ -0,0 +1,39 @@
+package service
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/henriquemarlon/swapx/internal/domain"
+	"github.com/henriquemarlon/swapx/pkg/gio"
+)
+
+type BlockHeaderService struct {
+	GioHandlerFactory gio.GioHandlerFactory
+}
+
+type BlockHeaderServiceInterface interface {
+	FindBlockHeader(blockHash common.Hash) (*domain.BlockHeader, error)
+}
+
+func NewBlockHeaderService(gioHandlerFactory gio.GioHandlerFactory) *BlockHeaderService {
+	return &BlockHeaderService{GioHandlerFactory: gioHandlerFactory}
+}
+
+func (s *BlockHeaderService) FindBlockHeader(blockHash common.Hash) (*domain.BlockHeader, error) {
+	factory, ok := s.GioHandlerFactory.(gio.GioHeaderHandlerFactory)
+	if !ok {
+		return nil, gio.ErrDomainNotSupported
+	}
+	handler, err := factory.NewGioHeaderHandler()
+	if err != nil {
+		return nil, err
+	}
+
+	header, err := handler.HandleHeader(blockHash)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.BlockHeader{
+		BlockRef:   domain.BlockRef{Number: header.Number.Uint64(), Hash: blockHash},
+		ParentHash: header.ParentHash,
+	}, nil
+}
@@ -0,0 +1,39 @@
+package service
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// ORDER_STRUCT_SIZE is the number of slots taken by SwapXHook.Order, one per field.
+const ORDER_STRUCT_SIZE = 4
+
+const (
+	ORDER_ACCOUNT_FIELD        = 0
+	ORDER_SQRT_PRICE_FIELD     = 1
+	ORDER_AMOUNT_FIELD         = 2
+	ORDER_MATCHED_AMOUNT_FIELD = 3
+)
+
+// OrderFieldSlot follows the Solidity layout of a dynamic array of structs: the
+// length sits at arraySlot, the elements start at keccak(arraySlot) and each one
+// takes ORDER_STRUCT_SIZE slots.
+func OrderFieldSlot(arraySlot common.Hash, index, field uint64) common.Hash {
+	base := crypto.Keccak256Hash(arraySlot.Bytes()).Big()
+	offset := new(big.Int).SetUint64(index*ORDER_STRUCT_SIZE + field)
+	return common.BigToHash(new(big.Int).Add(base, offset))
+}
+
+// CancelledSlot is the slot of the cancel flag of the order at index, kept in a
+// mapping(uint256 => bool) at statusSlot.
+func CancelledSlot(statusSlot common.Hash, index uint64) common.Hash {
+	return MappingSlot(common.BigToHash(new(big.Int).SetUint64(index)), statusSlot)
+}
+
+// MappingSlot follows the Solidity layout of a mapping: the value of key sits at
+// keccak(key . mappingSlot).
+func MappingSlot(key, mappingSlot common.Hash) common.Hash {
+	return crypto.Keccak256Hash(key.Bytes(), mappingSlot.Bytes())
+}
@@ -0,0 +1,31 @@
+package service
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrderFieldSlot(t *testing.T) {
+	buyOrders := common.BigToHash(big.NewInt(8))
+
+	// keccak256(uint256(8)), where buyOrders[0].account sits
+	base := common.HexToHash("0xf3f7a9fe364faab93b216da50a3214154f22a0a2b415b23a84c8169e8b636ee3")
+	assert.Equal(t, base, OrderFieldSlot(buyOrders, 0, ORDER_ACCOUNT_FIELD))
+
+	// buyOrders[1].amount is 4 + 2 slots further
+	expected := common.BigToHash(new(big.Int).Add(base.Big(), big.NewInt(6)))
+	assert.Equal(t, expected, OrderFieldSlot(buyOrders, 1, ORDER_AMOUNT_FIELD))
+}
+
+func TestCancelledSlot(t *testing.T) {
+	buyOrderCancelled := common.BigToHash(big.NewInt(6))
+
+	key := common.LeftPadBytes(big.NewInt(3).Bytes(), 32)
+	expected := crypto.Keccak256Hash(key, buyOrderCancelled.Bytes())
+	assert.Equal(t, expected, CancelledSlot(buyOrderCancelled, 3))
+	assert.NotEqual(t, CancelledSlot(buyOrderCancelled, 3), CancelledSlot(common.BigToHash(big.NewInt(7)), 3))
+}
@@ -8,7 +8,6 @@ import (
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/henriquemarlon/swapx/internal/domain"
 	"github.com/henriquemarlon/swapx/pkg/gio"
 	"github.com/holiman/uint256"
@@ -22,20 +21,22 @@ type OrderStorageService struct {
 
 type OrderStorageServiceInterface interface {
 	FindOrderStatus(hookAddress common.Address, orderId *big.Int, blockHash, slot common.Hash) (*bool, error)
-	FindOrdersBySlot(hookAddress common.Address, blockHash, ordersSlot, statusSlot common.Hash) ([]*domain.Order, error)
+	FindOrdersBySlot(hookAddress common.Address, blockHash, ordersSlot, statusSlot, flagsSlot common.Hash) ([]*domain.Order, error)
+	FindOrderMatchedAmount(hookAddress common.Address, orderId *big.Int, blockHash, ordersSlot common.Hash) (*uint256.Int, error)
+	IsFillConsumed(hookAddress common.Address, fillId, blockHash, slot common.Hash) (bool, error)
 }
 
 func NewOrderStorageService(gioHandlerFactory gio.GioHandlerFactory) *OrderStorageService {
 	return &OrderStorageService{GioHandlerFactory: gioHandlerFactory}
 }
 
-func (s *OrderStorageService) FindOrdersBySlot(hookAddress common.Address, blockHash, ordersSlot, statusSlot common.Hash) ([]*domain.Order, error) {
+func (s *OrderStorageService) FindOrdersBySlot(hookAddress common.Address, blockHash, ordersSlot, statusSlot, flagsSlot common.Hash) ([]*domain.Order, error) {
 	handler, err := s.GioHandlerFactory.NewGioHandler(0x27)
 	if err != nil {
 		return nil, err
 	}
 
-	slog.Info("/====================== Looking for orders at", "slot =====================", fmt.Sprintf("> %v", new(big.Int).SetBytes(ordersSlot.Bytes())))
+	slog.Debug("/====================== Looking for orders at", "slot =====================", fmt.Sprintf("> %v", new(big.Int).SetBytes(ordersSlot.Bytes())))
 
 	res, err := handler.Handle(blockHash, hookAddress, ordersSlot)
 	if err != nil {
@@ -50,32 +51,35 @@ func (s *OrderStorageService) FindOrdersBySlot(hookAddress common.Address, block
 	slog.Info("Total orders found in storage", "count", arrayLength.Int64())
 
 	orders := make([]*domain.Order, 0, arrayLength.Int64())
-	slotHash := crypto.Keccak256Hash(ordersSlot.Bytes())
 
 	for i := int64(0); i < arrayLength.Int64(); i++ {
 		var orderRawData [4]uint256.Int
 
 		for j := 0; j < 4; j++ {
-			data, err := handler.Handle(blockHash, hookAddress, slotHash)
+			data, err := handler.Handle(blockHash, hookAddress, OrderFieldSlot(ordersSlot, uint64(i), uint64(j)))
 			if err != nil {
 				return nil, err
 			}
 
 			orderRawData[j] = *uint256.MustFromBig(new(big.Int).SetBytes(common.FromHex(data.Response)))
-			slotHash = common.BigToHash(new(big.Int).Add(new(big.Int).SetBytes(slotHash.Bytes()), big.NewInt(1)))
 		}
 
 		orderRawDataBytes, err := json.Marshal(orderRawData)
 		if err != nil {
 			return nil, err
 		}
-		slog.Info("Raw order data collected from base layer with", " id", i, "data", string(orderRawDataBytes))
+		slog.Debug("Raw order data collected from base layer with", " id", i, "data", string(orderRawDataBytes))
 
 		isCancelled, err := s.FindOrderStatus(hookAddress, big.NewInt(i), blockHash, statusSlot)
 		if err != nil {
 			return nil, err
 		}
 
+		flags, err := handler.Handle(blockHash, hookAddress, MappingSlot(common.BigToHash(big.NewInt(i)), flagsSlot))
+		if err != nil {
+			return nil, err
+		}
+
 		isFulfilled := new(uint256.Int).Sub(&orderRawData[2], &orderRawData[3]).IsZero()
 
 		orderStatus := domain.OrderNotCancelledOrFulfilled
@@ -95,12 +99,13 @@ func (s *OrderStorageService) FindOrdersBySlot(hookAddress common.Address, block
 		if err != nil {
 			return nil, err
 		}
+		order.Flags = domain.OrderFlag(new(big.Int).SetBytes(common.FromHex(flags.Response)).Uint64())
 
 		orderBytes, err := json.Marshal(order)
 		if err != nil {
 			return nil, err
 		}
-		slog.Info("Order found", "info", string(orderBytes))
+		slog.Debug("Order found", "info", string(orderBytes))
 
 		orders = append(orders, order)
 	}
@@ -114,14 +119,43 @@ func (s *OrderStorageService) FindOrderStatus(hookAddress common.Address, orderI
 		return nil, err
 	}
 
-	slog.Info("/====================== Looking for order status at", "slot =====================", fmt.Sprintf("> %v", new(big.Int).SetBytes(slot.Bytes())))
+	slog.Debug("/====================== Looking for order status at", "slot =====================", fmt.Sprintf("> %v", new(big.Int).SetBytes(slot.Bytes())))
 
-	slotHash := crypto.Keccak256Hash(common.BigToHash(orderId).Bytes(), slot.Bytes())
-	res, err := handler.Handle(blockHash, hookAddress, slotHash)
+	res, err := handler.Handle(blockHash, hookAddress, CancelledSlot(slot, orderId.Uint64()))
 	if err != nil {
 		return nil, err
 	}
 
 	status := new(big.Int).SetBytes(common.FromHex(res.Response)).Cmp(big.NewInt(1)) == 0
 	return &status, nil
-}
\ No newline at end of file
+}
+
+// FindOrderMatchedAmount reads the matched amount of the order at the 0-based
+// orderId of the array at ordersSlot
+func (s *OrderStorageService) FindOrderMatchedAmount(hookAddress common.Address, orderId *big.Int, blockHash, ordersSlot common.Hash) (*uint256.Int, error) {
+	handler, err := s.GioHandlerFactory.NewGioHandler(0x27)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := handler.Handle(blockHash, hookAddress, OrderFieldSlot(ordersSlot, orderId.Uint64(), ORDER_MATCHED_AMOUNT_FIELD))
+	if err != nil {
+		return nil, err
+	}
+	return uint256.MustFromBig(new(big.Int).SetBytes(common.FromHex(res.Response))), nil
+}
+
+// IsFillConsumed tells whether the hook executed the fill, from its
+// mapping(bytes32 => bool) of consumed fills at slot
+func (s *OrderStorageService) IsFillConsumed(hookAddress common.Address, fillId, blockHash, slot common.Hash) (bool, error) {
+	handler, err := s.GioHandlerFactory.NewGioHandler(0x27)
+	if err != nil {
+		return false, err
+	}
+
+	res, err := handler.Handle(blockHash, hookAddress, MappingSlot(fillId, slot))
+	if err != nil {
+		return false, err
+	}
+	return new(big.Int).SetBytes(common.FromHex(res.Response)).Sign() != 0, nil
+}
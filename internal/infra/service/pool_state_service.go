@@ -0,0 +1,143 @@
+package service
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/henriquemarlon/swapx/internal/domain"
+	"github.com/henriquemarlon/swapx/pkg/gio"
+	"github.com/holiman/uint256"
+)
+
+// POOLS_STORAGE_SLOT is the slot of the _pools mapping of the Uniswap v4
+// PoolManager, the first word of a pool is its Slot0
+const POOLS_STORAGE_SLOT = 6
+
+// Offsets of the Pool.State fields from the slot of the pool, as in StateLibrary
+const (
+	POOL_LIQUIDITY_OFFSET   = 3
+	POOL_TICKS_OFFSET       = 4
+	POOL_TICK_BITMAP_OFFSET = 5
+)
+
+type PoolStateService struct {
+	GioHandlerFactory gio.GioHandlerFactory
+}
+
+type PoolStateServiceInterface interface {
+	FindPoolKey(hookAddress common.Address, blockHash, slot common.Hash) (*domain.PoolKey, error)
+	FindSpotSqrtPrice(poolManager common.Address, poolId, blockHash common.Hash) (*uint256.Int, error)
+	FindPoolState(poolManager common.Address, poolId, blockHash common.Hash) (*domain.PoolState, error)
+	FindTickBitmap(poolManager common.Address, poolId common.Hash, wordPos int16, blockHash common.Hash) (*uint256.Int, error)
+	FindLiquidityNet(poolManager common.Address, poolId common.Hash, tick int32, blockHash common.Hash) (*big.Int, error)
+}
+
+func NewPoolStateService(gioHandlerFactory gio.GioHandlerFactory) *PoolStateService {
+	return &PoolStateService{GioHandlerFactory: gioHandlerFactory}
+}
+
+// FindPoolKey reads the PoolKey the hook stores at slot. It takes three slots:
+// currency0, then currency1 packed with the fee and the tick spacing, then hooks.
+func (s *PoolStateService) FindPoolKey(hookAddress common.Address, blockHash, slot common.Hash) (*domain.PoolKey, error) {
+	words := make([][]byte, 3)
+	for i := range words {
+		word, err := s.readWord(hookAddress, blockHash, common.BigToHash(new(big.Int).Add(slot.Big(), big.NewInt(int64(i)))))
+		if err != nil {
+			return nil, err
+		}
+		words[i] = word
+	}
+
+	packed := words[1]
+	return &domain.PoolKey{
+		Currency0:   common.BytesToAddress(words[0][12:]),
+		Currency1:   common.BytesToAddress(packed[12:]),
+		Fee:         uint32(new(big.Int).SetBytes(packed[9:12]).Uint64()),
+		TickSpacing: int32(uint32(new(big.Int).SetBytes(packed[6:9]).Uint64())<<8) >> 8,
+		Hooks:       common.BytesToAddress(words[2][12:]),
+	}, nil
+}
+
+// FindSpotSqrtPrice reads the sqrtPriceX96 of the pool, zero when it is not initialized
+func (s *PoolStateService) FindSpotSqrtPrice(poolManager common.Address, poolId, blockHash common.Hash) (*uint256.Int, error) {
+	word, err := s.readWord(poolManager, blockHash, PoolStateSlot(poolId))
+	if err != nil {
+		return nil, err
+	}
+	return new(uint256.Int).SetBytes(word[12:]), nil
+}
+
+// FindPoolState reads the Slot0 and the active liquidity of the pool. Slot0
+// packs, from the lowest bits, sqrtPriceX96, tick, protocolFee and lpFee.
+func (s *PoolStateService) FindPoolState(poolManager common.Address, poolId, blockHash common.Hash) (*domain.PoolState, error) {
+	stateSlot := PoolStateSlot(poolId)
+	slot0, err := s.readWord(poolManager, blockHash, stateSlot)
+	if err != nil {
+		return nil, err
+	}
+	liquidity, err := s.readWord(poolManager, blockHash, offsetSlot(stateSlot, POOL_LIQUIDITY_OFFSET))
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.PoolState{
+		SqrtPriceX96: new(uint256.Int).SetBytes(slot0[12:]),
+		Tick:         int32(uint32(new(big.Int).SetBytes(slot0[9:12]).Uint64())<<8) >> 8,
+		ProtocolFee:  uint32(new(big.Int).SetBytes(slot0[6:9]).Uint64()),
+		LpFee:        uint32(new(big.Int).SetBytes(slot0[3:6]).Uint64()),
+		Liquidity:    new(uint256.Int).SetBytes(liquidity[16:]),
+	}, nil
+}
+
+// FindTickBitmap reads the word of the tick bitmap of the pool at wordPos
+func (s *PoolStateService) FindTickBitmap(poolManager common.Address, poolId common.Hash, wordPos int16, blockHash common.Hash) (*uint256.Int, error) {
+	slot := crypto.Keccak256Hash(signedWord(int64(wordPos)), offsetSlot(PoolStateSlot(poolId), POOL_TICK_BITMAP_OFFSET).Bytes())
+	word, err := s.readWord(poolManager, blockHash, slot)
+	if err != nil {
+		return nil, err
+	}
+	return new(uint256.Int).SetBytes(word), nil
+}
+
+// FindLiquidityNet reads the liquidityNet of the tick, the upper half of the
+// first word of its TickInfo
+func (s *PoolStateService) FindLiquidityNet(poolManager common.Address, poolId common.Hash, tick int32, blockHash common.Hash) (*big.Int, error) {
+	slot := crypto.Keccak256Hash(signedWord(int64(tick)), offsetSlot(PoolStateSlot(poolId), POOL_TICKS_OFFSET).Bytes())
+	word, err := s.readWord(poolManager, blockHash, slot)
+	if err != nil {
+		return nil, err
+	}
+	net := new(big.Int).SetBytes(word[:16])
+	if net.Bit(127) == 1 {
+		net.Sub(net, new(big.Int).Lsh(big.NewInt(1), 128))
+	}
+	return net, nil
+}
+
+// PoolStateSlot is the slot of _pools[poolId] in the PoolManager
+func PoolStateSlot(poolId common.Hash) common.Hash {
+	return crypto.Keccak256Hash(poolId.Bytes(), common.BigToHash(big.NewInt(POOLS_STORAGE_SLOT)).Bytes())
+}
+
+func offsetSlot(slot common.Hash, offset int64) common.Hash {
+	return common.BigToHash(new(big.Int).Add(slot.Big(), big.NewInt(offset)))
+}
+
+// signedWord is the abi encoding of a signed integer, sign extended to 32 bytes
+func signedWord(value int64) []byte {
+	return math.U256Bytes(big.NewInt(value))
+}
+
+func (s *PoolStateService) readWord(address common.Address, blockHash, slot common.Hash) ([]byte, error) {
+	handler, err := s.GioHandlerFactory.NewGioHandler(0x27)
+	if err != nil {
+		return nil, err
+	}
+	res, err := handler.Handle(blockHash, address, slot)
+	if err != nil {
+		return nil, err
+	}
+	return common.BytesToHash(common.FromHex(res.Response)).Bytes(), nil
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/henriquemarlon/swapx/internal/domain"
+	"github.com/henriquemarlon/swapx/pkg/gio"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type storageFactory map[common.Address]map[common.Hash]common.Hash
+
+func (f storageFactory) NewGioHandler(domain uint16) (gio.GioHandler, error) {
+	return f, nil
+}
+
+func (f storageFactory) Handle(blockHash common.Hash, address common.Address, slot common.Hash) (*gio.GioResponse, error) {
+	return &gio.GioResponse{Response: f[address][slot].Hex()}, nil
+}
+
+func TestPoolStateServiceReadsThePackedPoolKey(t *testing.T) {
+	hook := common.HexToAddress("0x10")
+	poolManager := common.HexToAddress("0x20")
+	key := &domain.PoolKey{
+		Currency0:   common.HexToAddress("0xa0"),
+		Currency1:   common.HexToAddress("0xb0"),
+		Fee:         3000,
+		TickSpacing: -60,
+		Hooks:       hook,
+	}
+
+	// currency1, then the fee and the tick spacing as two's complement int24
+	packed := make([]byte, 32)
+	copy(packed[12:], key.Currency1.Bytes())
+	copy(packed[9:12], []byte{0x00, 0x0b, 0xb8})
+	copy(packed[6:9], []byte{0xff, 0xff, 0xc4})
+	q96 := new(uint256.Int).Lsh(uint256.NewInt(1), 96)
+	slot0 := common.BytesToHash(q96.Bytes())
+	// the tick and the fees sit above the price
+	slot0[5] = 0x01
+
+	storage := storageFactory{
+		hook: {
+			common.HexToHash("0x0"): common.BytesToHash(key.Currency0.Bytes()),
+			common.HexToHash("0x1"): common.BytesToHash(packed),
+			common.HexToHash("0x2"): common.BytesToHash(hook.Bytes()),
+		},
+		poolManager: {PoolStateSlot(key.Id()): slot0},
+	}
+	service := NewPoolStateService(storage)
+
+	found, err := service.FindPoolKey(hook, common.Hash{}, common.Hash{})
+	require.NoError(t, err)
+	assert.Equal(t, key, found)
+
+	spot, err := service.FindSpotSqrtPrice(poolManager, found.Id(), common.Hash{})
+	require.NoError(t, err)
+	assert.Equal(t, q96, spot)
+}
+
+func TestPoolStateServiceReadsSlot0LiquidityAndTicks(t *testing.T) {
+	poolManager := common.HexToAddress("0x20")
+	poolId := common.HexToHash("0x01")
+	stateSlot := PoolStateSlot(poolId)
+
+	// lpFee 3000, protocolFee 0x001002, tick -2, sqrtPriceX96 2^96
+	slot0 := common.HexToHash("0x000000000bb8001002fffffe0000000000000001000000000000000000000000")
+	liquidity := common.BigToHash(big.NewInt(1e18))
+	// liquidityNet -1 above liquidityGross 5
+	tickInfo := common.HexToHash("0xffffffffffffffffffffffffffffffff00000000000000000000000000000005")
+	minusOne := common.HexToHash("0xffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff")
+	tickSlot := crypto.Keccak256Hash(minusOne.Bytes(), common.BigToHash(new(big.Int).Add(stateSlot.Big(), big.NewInt(4))).Bytes())
+	bitmapSlot := crypto.Keccak256Hash(minusOne.Bytes(), common.BigToHash(new(big.Int).Add(stateSlot.Big(), big.NewInt(5))).Bytes())
+
+	storage := storageFactory{
+		poolManager: {
+			stateSlot: slot0,
+			common.BigToHash(new(big.Int).Add(stateSlot.Big(), big.NewInt(3))): liquidity,
+			tickSlot:   tickInfo,
+			bitmapSlot: common.BigToHash(big.NewInt(0x80)),
+		},
+	}
+	service := NewPoolStateService(storage)
+
+	state, err := service.FindPoolState(poolManager, poolId, common.Hash{})
+	require.NoError(t, err)
+	assert.Equal(t, &domain.PoolState{
+		SqrtPriceX96: new(uint256.Int).Lsh(uint256.NewInt(1), 96),
+		Tick:         -2,
+		ProtocolFee:  0x001002,
+		LpFee:        3000,
+		Liquidity:    uint256.NewInt(1e18),
+	}, state)
+
+	net, err := service.FindLiquidityNet(poolManager, poolId, -1, common.Hash{})
+	require.NoError(t, err)
+	assert.Equal(t, big.NewInt(-1), net)
+
+	word, err := service.FindTickBitmap(poolManager, poolId, -1, common.Hash{})
+	require.NoError(t, err)
+	assert.Equal(t, uint256.NewInt(0x80), word)
+}
@@ -0,0 +1,649 @@
+package simulator
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/henriquemarlon/swapx/internal/domain"
+	"github.com/henriquemarlon/swapx/internal/infra/cartesi"
+	"github.com/henriquemarlon/swapx/internal/infra/service"
+	"github.com/henriquemarlon/swapx/internal/usecase"
+	"github.com/henriquemarlon/swapx/pkg/gio"
+	"github.com/holiman/uint256"
+)
+
+// Storage slots of SwapXHook that are not read by the use case, see storage-layout
+const (
+	POOL_KEY_PACKED_STORAGE_SLOT = 1
+	POOL_KEY_HOOKS_STORAGE_SLOT  = 2
+	CURRENCY0_STORAGE_SLOT       = 3
+	CURRENCY1_STORAGE_SLOT       = 4
+	TASK_MANAGER_STORAGE_SLOT    = 5
+)
+
+// Mirrors the custom errors of SwapXHook
+var (
+	ErrOrderWasCancelled         = errors.New("OrderWasCancelled")
+	ErrOrderDoesNotExist         = errors.New("OrderDoesNotExist")
+	ErrOrderAlreadyFulfilled     = errors.New("OrderAlreadyFulfilled")
+	ErrOnlyOrderCreatorCanCancel = errors.New("OnlyOrderCreatorCanCancel")
+	ErrOrderSqrtPricesDoNotMatch = errors.New("OrderSqrtPricesDoNotMatch")
+	ErrInvalidFillQuantity       = errors.New("InvalidFillQuantity")
+	ErrFillAlreadyConsumed       = errors.New("FillAlreadyConsumed")
+	ErrFillExpired               = errors.New("FillExpired")
+	ErrSwapExpired               = errors.New("SwapExpired")
+	ErrOnlyTaskManager           = errors.New("OnlyTaskManager")
+	ErrUnsupportedNotice         = errors.New("UnsupportedNotice")
+	ErrBatchTooLarge             = errors.New("BatchTooLarge")
+	ErrUnknownHook               = errors.New("notice targets another hook")
+	ErrUnknownBlock              = errors.New("unknown block")
+	ErrPoolNotInitialized        = errors.New("PoolNotInitialized")
+	ErrPriceLimitAlreadyExceeded = errors.New("PriceLimitAlreadyExceeded")
+)
+
+// MAX_FILLS_PER_BATCH mirrors SwapXTaskManager.MAX_FILLS_PER_BATCH
+const MAX_FILLS_PER_BATCH = 32
+
+type Transfer struct {
+	Currency common.Address `json:"currency"`
+	To       common.Address `json:"to"`
+	Amount   *uint256.Int   `json:"amount"`
+}
+
+// HookSimulator keeps the state of a SwapXHook, and the task manager in front of
+// it, as the raw storage slots the contract would have. Every mutation writes
+// the slots the Solidity code writes, so reads through GIO see exactly what an
+// eth_getStorageAt against the real contract would return.
+type HookSimulator struct {
+	Address     common.Address
+	TaskManager common.Address
+	Currency0   common.Address
+	Currency1   common.Address
+	// PoolManager is set once the pool is initialized, it only holds the Slot0
+	// and the liquidity of the pool of the hook, whose liquidity is a single
+	// position over the whole range of prices
+	PoolManager common.Address
+	Transfers   []Transfer
+	slots       map[common.Hash]common.Hash
+	blocks      map[common.Hash]map[common.Hash]common.Hash
+	poolSlot    common.Hash
+	tickSpacing int32
+	pool        *domain.PoolState
+	pools       map[common.Hash]*domain.PoolState
+	headers     map[common.Hash]*types.Header
+	head        common.Hash
+	blockNumber uint64
+	mined       uint64
+	mutex       *sync.RWMutex
+}
+
+func NewHookSimulator(address, taskManager, currency0, currency1 common.Address) *HookSimulator {
+	s := &HookSimulator{
+		Address:     address,
+		TaskManager: taskManager,
+		Currency0:   currency0,
+		Currency1:   currency1,
+		slots:       make(map[common.Hash]common.Hash),
+		blocks:      make(map[common.Hash]map[common.Hash]common.Hash),
+		pools:       make(map[common.Hash]*domain.PoolState),
+		headers:     make(map[common.Hash]*types.Header),
+		mutex:       &sync.RWMutex{},
+	}
+	s.store(slotOf(CURRENCY0_STORAGE_SLOT), common.BytesToHash(currency0.Bytes()))
+	s.store(slotOf(CURRENCY1_STORAGE_SLOT), common.BytesToHash(currency1.Bytes()))
+	s.store(slotOf(TASK_MANAGER_STORAGE_SLOT), common.BytesToHash(taskManager.Bytes()))
+	return s
+}
+
+// Mine freezes the current state under a new block hash, which is what the
+// coprocessor reads from since tasks carry the hash of the previous block.
+// The block gets a header chained to the previous one.
+func (s *HookSimulator) Mine() common.Hash {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.blockNumber++
+	s.mined++
+	// the extra data tells apart the blocks mined at the same height after a Rewind
+	header := &types.Header{
+		ParentHash: s.head,
+		Number:     new(big.Int).SetUint64(s.blockNumber),
+		Difficulty: new(big.Int),
+		Time:       s.blockNumber,
+		Extra:      new(big.Int).SetUint64(s.mined).Bytes(),
+	}
+	blockHash := header.Hash()
+
+	snapshot := make(map[common.Hash]common.Hash, len(s.slots))
+	for slot, value := range s.slots {
+		snapshot[slot] = value
+	}
+	s.blocks[blockHash] = snapshot
+	s.pools[blockHash] = copyPool(s.pool)
+	s.headers[blockHash] = header
+	s.head = blockHash
+	return blockHash
+}
+
+// Rewind makes a mined block the head again and brings back the state it
+// froze, the blocks mined next fork from it
+func (s *HookSimulator) Rewind(blockHash common.Hash) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	header, ok := s.headers[blockHash]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownBlock, blockHash.Hex())
+	}
+	s.slots = make(map[common.Hash]common.Hash, len(s.blocks[blockHash]))
+	for slot, value := range s.blocks[blockHash] {
+		s.slots[slot] = value
+	}
+	s.pool = copyPool(s.pools[blockHash])
+	s.head = blockHash
+	s.blockNumber = header.Number.Uint64()
+	return nil
+}
+
+// InitializePool has the effects of initializing the pool of the hook at
+// sqrtPriceX96: _beforeInitialize stores the PoolKey, and the PoolManager the
+// Slot0 of the pool
+func (s *HookSimulator) InitializePool(poolManager common.Address, fee uint32, tickSpacing int32, sqrtPriceX96 *uint256.Int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	key := domain.PoolKey{Currency0: s.Currency0, Currency1: s.Currency1, Fee: fee, TickSpacing: tickSpacing, Hooks: s.Address}
+	packed := new(big.Int).SetBytes(s.Currency1.Bytes())
+	packed.Or(packed, new(big.Int).Lsh(big.NewInt(int64(fee)), 160))
+	packed.Or(packed, new(big.Int).Lsh(big.NewInt(int64(uint32(tickSpacing)&0xffffff)), 184))
+
+	s.store(slotOf(usecase.POOL_KEY_STORAGE_SLOT), common.BytesToHash(s.Currency0.Bytes()))
+	s.store(slotOf(POOL_KEY_PACKED_STORAGE_SLOT), common.BigToHash(packed))
+	s.store(slotOf(POOL_KEY_HOOKS_STORAGE_SLOT), common.BytesToHash(s.Address.Bytes()))
+	s.PoolManager = poolManager
+	s.poolSlot = service.PoolStateSlot(key.Id())
+	s.tickSpacing = tickSpacing
+	s.pool = &domain.PoolState{
+		SqrtPriceX96: new(uint256.Int).Set(sqrtPriceX96),
+		Tick:         domain.TickAtSqrtPrice(sqrtPriceX96.ToBig()),
+		LpFee:        fee,
+		Liquidity:    uint256.NewInt(0),
+	}
+}
+
+// SetSpotPrice moves the price of the pool, as a swap against it would
+func (s *HookSimulator) SetSpotPrice(sqrtPriceX96 *uint256.Int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.pool.SqrtPriceX96 = new(uint256.Int).Set(sqrtPriceX96)
+	s.pool.Tick = domain.TickAtSqrtPrice(sqrtPriceX96.ToBig())
+}
+
+// AddLiquidity adds liquidity to the position of the pool over the whole range
+func (s *HookSimulator) AddLiquidity(liquidity *uint256.Int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.pool.Liquidity = new(uint256.Int).Add(s.pool.Liquidity, liquidity)
+}
+
+// Pool is the current state of the pool of the hook
+func (s *HookSimulator) Pool() domain.PoolState {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return *s.pool
+}
+
+func (s *HookSimulator) HeaderByHash(blockHash common.Hash) (*types.Header, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	header, ok := s.headers[blockHash]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownBlock, blockHash.Hex())
+	}
+	return types.CopyHeader(header), nil
+}
+
+// GetStorageAt reads from the block when it was mined by this simulator, and
+// from the latest state otherwise. The PoolManager only has the Slot0 and the
+// liquidity of the pool, other addresses have empty storage.
+func (s *HookSimulator) GetStorageAt(blockHash common.Hash, address common.Address, slot common.Hash) (common.Hash, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	if address == s.PoolManager && s.PoolManager != (common.Address{}) {
+		pool, ok := s.pools[blockHash]
+		if !ok {
+			pool = s.pool
+		}
+		if pool == nil {
+			return common.Hash{}, nil
+		}
+		switch slot {
+		case s.poolSlot:
+			return encodeSlot0(pool), nil
+		case common.BigToHash(new(big.Int).Add(s.poolSlot.Big(), big.NewInt(service.POOL_LIQUIDITY_OFFSET))):
+			return common.Hash(pool.Liquidity.Bytes32()), nil
+		}
+		return common.Hash{}, nil
+	}
+	if address != s.Address {
+		return common.Hash{}, nil
+	}
+	if snapshot, ok := s.blocks[blockHash]; ok {
+		return snapshot[slot], nil
+	}
+	return s.slots[slot], nil
+}
+
+func (s *HookSimulator) NewGioHandler(domain uint16) (gio.GioHandler, error) {
+	switch domain {
+	case 0x27:
+		return &gioGetStorage{simulator: s}, nil
+	default:
+		return nil, errors.New("domain not supported")
+	}
+}
+
+// PlaceOrder has the effects of a swap carrying hookData on _beforeSwap and
+// returns the payload of the task created for it.
+func (s *HookSimulator) PlaceOrder(account common.Address, sqrtPrice, amount *uint256.Int, isBuy bool, flags uint64) (uint64, []byte, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	arraySlot := ordersSlot(isBuy)
+	index := s.load(arraySlot).Uint64()
+
+	s.storeOrderField(isBuy, index, service.ORDER_ACCOUNT_FIELD, new(uint256.Int).SetBytes(account.Bytes()))
+	s.storeOrderField(isBuy, index, service.ORDER_SQRT_PRICE_FIELD, sqrtPrice)
+	s.storeOrderField(isBuy, index, service.ORDER_AMOUNT_FIELD, amount)
+	s.storeOrderField(isBuy, index, service.ORDER_MATCHED_AMOUNT_FIELD, uint256.NewInt(0))
+	s.store(arraySlot, common.Hash(uint256.NewInt(index+1).Bytes32()))
+
+	if flags != 0 {
+		s.store(s.flagsSlot(isBuy, index), common.Hash(uint256.NewInt(flags).Bytes32()))
+	}
+
+	orderType := domain.OrderTypeSell
+	if isBuy {
+		orderType = domain.OrderTypeBuy
+	}
+
+	payload, err := cartesi.EncodeTaskPayload(&cartesi.TaskPayload{
+		OrderId:   index + 1,
+		SqrtPrice: sqrtPrice,
+		Amount:    amount,
+		Type:      orderType,
+		Flags:     domain.OrderFlag(flags),
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+	return index, payload, nil
+}
+
+// CancelOrder has the effects of cancelBuyOrder and cancelSellOrder.
+func (s *HookSimulator) CancelOrder(sender common.Address, orderId uint64, isBuy bool) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if orderId >= s.load(ordersSlot(isBuy)).Uint64() {
+		return ErrOrderDoesNotExist
+	}
+	if s.isCancelled(isBuy, orderId) {
+		return ErrOrderWasCancelled
+	}
+
+	order := s.loadOrder(isBuy, orderId)
+	if order.Account != sender {
+		return ErrOnlyOrderCreatorCanCancel
+	}
+	if order.MatchedAmount.Eq(order.Amount) {
+		return ErrOrderAlreadyFulfilled
+	}
+
+	s.store(s.cancelledSlot(isBuy, orderId), common.BigToHash(big.NewInt(1)))
+
+	remaining := new(uint256.Int).Sub(order.Amount, order.MatchedAmount)
+	if !remaining.IsZero() {
+		s.transfer(s.escrowCurrency(isBuy), order.Account, remaining)
+	}
+	return nil
+}
+
+// ExecuteAsyncSwap has the effects of SwapXHook.executeAsyncSwap.
+func (s *HookSimulator) ExecuteAsyncSwap(sender common.Address, fillId common.Hash, buyOrderId, sellOrderId uint64, quantity *uint256.Int, expiryBlock uint64) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if sender != s.TaskManager {
+		return ErrOnlyTaskManager
+	}
+	if s.load(s.consumedFillSlot(fillId)).Sign() != 0 {
+		return fmt.Errorf("%w(%s)", ErrFillAlreadyConsumed, fillId.Hex())
+	}
+	// the call runs in the block after the last one mined
+	if s.blockNumber+1 > expiryBlock {
+		return fmt.Errorf("%w(%s)", ErrFillExpired, fillId.Hex())
+	}
+	if buyOrderId >= s.load(ordersSlot(true)).Uint64() || sellOrderId >= s.load(ordersSlot(false)).Uint64() {
+		return ErrOrderDoesNotExist
+	}
+	if s.isCancelled(true, buyOrderId) || s.isCancelled(false, sellOrderId) {
+		return ErrOrderWasCancelled
+	}
+
+	buyOrder := s.loadOrder(true, buyOrderId)
+	sellOrder := s.loadOrder(false, sellOrderId)
+
+	if buyOrder.MatchedAmount.Eq(buyOrder.Amount) || sellOrder.MatchedAmount.Eq(sellOrder.Amount) {
+		return ErrOrderAlreadyFulfilled
+	}
+	if buyOrder.SqrtPrice.Lt(sellOrder.SqrtPrice) {
+		return ErrOrderSqrtPricesDoNotMatch
+	}
+	if quantity.IsZero() ||
+		quantity.Gt(new(uint256.Int).Sub(buyOrder.Amount, buyOrder.MatchedAmount)) ||
+		quantity.Gt(new(uint256.Int).Sub(sellOrder.Amount, sellOrder.MatchedAmount)) {
+		return ErrInvalidFillQuantity
+	}
+
+	s.store(s.consumedFillSlot(fillId), common.BigToHash(big.NewInt(1)))
+
+	s.transfer(s.Currency1, buyOrder.Account, quantity)
+	s.transfer(s.Currency0, sellOrder.Account, quantity)
+
+	s.storeOrderField(true, buyOrderId, service.ORDER_MATCHED_AMOUNT_FIELD, new(uint256.Int).Add(buyOrder.MatchedAmount, quantity))
+	s.storeOrderField(false, sellOrderId, service.ORDER_MATCHED_AMOUNT_FIELD, new(uint256.Int).Add(sellOrder.MatchedAmount, quantity))
+	return nil
+}
+
+// RefundOrder has the effects of SwapXHook.refundOrder.
+func (s *HookSimulator) RefundOrder(sender common.Address, orderId uint64, isBuy bool) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if sender != s.TaskManager {
+		return ErrOnlyTaskManager
+	}
+	order, err := s.openOrder(orderId, isBuy)
+	if err != nil {
+		return err
+	}
+
+	s.store(s.cancelledSlot(isBuy, orderId), common.BigToHash(big.NewInt(1)))
+	s.transfer(s.escrowCurrency(isBuy), order.Account, new(uint256.Int).Sub(order.Amount, order.MatchedAmount))
+	return nil
+}
+
+// TransferDust has the effects of SwapXHook.transferDust.
+func (s *HookSimulator) TransferDust(sender common.Address, orderId uint64, isBuy bool, amount *uint256.Int) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if sender != s.TaskManager {
+		return ErrOnlyTaskManager
+	}
+	order, err := s.openOrder(orderId, isBuy)
+	if err != nil {
+		return err
+	}
+	if amount.IsZero() || amount.Gt(new(uint256.Int).Sub(order.Amount, order.MatchedAmount)) {
+		return ErrInvalidFillQuantity
+	}
+
+	s.storeOrderField(isBuy, orderId, service.ORDER_AMOUNT_FIELD, new(uint256.Int).Sub(order.Amount, amount))
+	s.transfer(s.escrowCurrency(isBuy), order.Account, amount)
+	return nil
+}
+
+// SwapRemainder has the effects of SwapXHook.swapRemainder, the swap follows
+// the math of the PoolManager over the liquidity of the pool
+func (s *HookSimulator) SwapRemainder(sender common.Address, orderId uint64, isBuy bool, amount, sqrtPriceLimitX96 *uint256.Int, expiryBlock uint64) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if sender != s.TaskManager {
+		return ErrOnlyTaskManager
+	}
+	if s.blockNumber+1 > expiryBlock {
+		return ErrSwapExpired
+	}
+	order, err := s.openOrder(orderId, isBuy)
+	if err != nil {
+		return err
+	}
+	if amount.IsZero() || amount.Gt(new(uint256.Int).Sub(order.Amount, order.MatchedAmount)) {
+		return ErrInvalidFillQuantity
+	}
+	if s.pool == nil {
+		return ErrPoolNotInitialized
+	}
+	if isBuy && !sqrtPriceLimitX96.Lt(s.pool.SqrtPriceX96) || !isBuy && !sqrtPriceLimitX96.Gt(s.pool.SqrtPriceX96) {
+		return ErrPriceLimitAlreadyExceeded
+	}
+
+	quote, err := domain.QuoteExactInput(s.pool, s.tickSpacing, isBuy, amount, sqrtPriceLimitX96, noTicks{})
+	if err != nil {
+		return err
+	}
+
+	s.pool = quote.State
+	s.storeOrderField(isBuy, orderId, service.ORDER_MATCHED_AMOUNT_FIELD, new(uint256.Int).Add(order.MatchedAmount, quote.AmountIn))
+	s.transfer(s.escrowCurrency(!isBuy), order.Account, quote.AmountOut)
+	return nil
+}
+
+// HandleNotice runs a notice through SwapXTaskManager.handleNotice. The returned
+// error is a revert of the whole notice, failures are the fills and vouchers the
+// task manager would have reported through FillFailed and VoucherFailed.
+func (s *HookSimulator) HandleNotice(payload []byte) (failures []error, err error) {
+	if settlement, err := cartesi.DecodeSettlementNotice(payload); err == nil {
+		if len(settlement.Fills) > MAX_FILLS_PER_BATCH {
+			return nil, ErrBatchTooLarge
+		}
+		if settlement.Hook != s.Address {
+			return nil, ErrUnknownHook
+		}
+		for _, fill := range settlement.Fills {
+			err := s.ExecuteAsyncSwap(
+				s.TaskManager,
+				fill.FillId,
+				fill.BuyOrderId.Uint64(),
+				fill.SellOrderId.Uint64(),
+				uint256.MustFromBig(fill.Quantity),
+				fill.ExpiryBlock.Uint64(),
+			)
+			if err != nil {
+				failures = append(failures, fmt.Errorf("fill %s: %w", common.Hash(fill.FillId).Hex(), err))
+			}
+		}
+		return failures, nil
+	}
+
+	notice, err := cartesi.DecodeVoucherNotice(payload)
+	if err != nil {
+		return nil, ErrUnsupportedNotice
+	}
+	if notice.Hook != s.Address {
+		return nil, ErrUnknownHook
+	}
+	for _, voucher := range notice.Vouchers {
+		action, err := cartesi.DecodeActionVoucher(&voucher)
+		if err != nil {
+			failures = append(failures, fmt.Errorf("voucher to %s: %w", voucher.Destination.Hex(), err))
+			continue
+		}
+
+		isBuy := *action.OrderType == domain.OrderTypeBuy
+		switch action.Kind {
+		case domain.ActionRefund:
+			err = s.RefundOrder(s.TaskManager, action.OrderId-1, isBuy)
+		case domain.ActionTransfer:
+			err = s.TransferDust(s.TaskManager, action.OrderId-1, isBuy, action.Amount)
+		case domain.ActionPoolSwap:
+			err = s.SwapRemainder(s.TaskManager, action.OrderId-1, isBuy, action.Amount, action.SqrtPriceLimit, action.ExpiryBlock)
+		}
+		if err != nil {
+			failures = append(failures, fmt.Errorf("%s of order %d: %w", action.Kind, action.OrderId, err))
+		}
+	}
+	return failures, nil
+}
+
+type simulatedOrder struct {
+	Account       common.Address
+	SqrtPrice     *uint256.Int
+	Amount        *uint256.Int
+	MatchedAmount *uint256.Int
+}
+
+func (s *HookSimulator) openOrder(orderId uint64, isBuy bool) (*simulatedOrder, error) {
+	if orderId >= s.load(ordersSlot(isBuy)).Uint64() {
+		return nil, ErrOrderDoesNotExist
+	}
+	if s.isCancelled(isBuy, orderId) {
+		return nil, ErrOrderWasCancelled
+	}
+	order := s.loadOrder(isBuy, orderId)
+	if order.MatchedAmount.Eq(order.Amount) {
+		return nil, ErrOrderAlreadyFulfilled
+	}
+	return order, nil
+}
+
+func (s *HookSimulator) loadOrder(isBuy bool, orderId uint64) *simulatedOrder {
+	field := func(f uint64) *uint256.Int {
+		return s.load(service.OrderFieldSlot(ordersSlot(isBuy), orderId, f))
+	}
+	return &simulatedOrder{
+		Account:       common.BytesToAddress(field(service.ORDER_ACCOUNT_FIELD).Bytes()),
+		SqrtPrice:     field(service.ORDER_SQRT_PRICE_FIELD),
+		Amount:        field(service.ORDER_AMOUNT_FIELD),
+		MatchedAmount: field(service.ORDER_MATCHED_AMOUNT_FIELD),
+	}
+}
+
+func (s *HookSimulator) storeOrderField(isBuy bool, orderId, field uint64, value *uint256.Int) {
+	s.store(service.OrderFieldSlot(ordersSlot(isBuy), orderId, field), common.Hash(value.Bytes32()))
+}
+
+func (s *HookSimulator) isCancelled(isBuy bool, orderId uint64) bool {
+	return s.load(s.cancelledSlot(isBuy, orderId)).Sign() != 0
+}
+
+func (s *HookSimulator) cancelledSlot(isBuy bool, orderId uint64) common.Hash {
+	slot := slotOf(usecase.SELL_ORDERS_STATUS_STORAGE_SLOT)
+	if isBuy {
+		slot = slotOf(usecase.BUY_ORDERS_STATUS_STORAGE_SLOT)
+	}
+	return service.CancelledSlot(slot, orderId)
+}
+
+func (s *HookSimulator) flagsSlot(isBuy bool, orderId uint64) common.Hash {
+	slot := slotOf(usecase.SELL_ORDER_FLAGS_STORAGE_SLOT)
+	if isBuy {
+		slot = slotOf(usecase.BUY_ORDER_FLAGS_STORAGE_SLOT)
+	}
+	return service.MappingSlot(common.BigToHash(new(big.Int).SetUint64(orderId)), slot)
+}
+
+func (s *HookSimulator) consumedFillSlot(fillId common.Hash) common.Hash {
+	return service.MappingSlot(fillId, slotOf(usecase.CONSUMED_FILLS_STORAGE_SLOT))
+}
+
+// escrowCurrency is the currency taken from the owner when the order was placed.
+func (s *HookSimulator) escrowCurrency(isBuy bool) common.Address {
+	if isBuy {
+		return s.Currency0
+	}
+	return s.Currency1
+}
+
+func (s *HookSimulator) transfer(currency, to common.Address, amount *uint256.Int) {
+	s.Transfers = append(s.Transfers, Transfer{Currency: currency, To: to, Amount: new(uint256.Int).Set(amount)})
+}
+
+func (s *HookSimulator) load(slot common.Hash) *uint256.Int {
+	return new(uint256.Int).SetBytes(s.slots[slot].Bytes())
+}
+
+// store drops zeroed slots, so the state compares equal to a fresh one holding the same values.
+func (s *HookSimulator) store(slot common.Hash, value common.Hash) {
+	if value == (common.Hash{}) {
+		delete(s.slots, slot)
+		return
+	}
+	s.slots[slot] = value
+}
+
+// encodeSlot0 packs the pool as the PoolManager does, sqrtPriceX96 then the
+// tick, the protocol fee and the LP fee
+func encodeSlot0(pool *domain.PoolState) common.Hash {
+	slot0 := pool.SqrtPriceX96.ToBig()
+	slot0.Or(slot0, new(big.Int).Lsh(big.NewInt(int64(uint32(pool.Tick)&0xffffff)), 160))
+	slot0.Or(slot0, new(big.Int).Lsh(big.NewInt(int64(pool.ProtocolFee)), 184))
+	slot0.Or(slot0, new(big.Int).Lsh(big.NewInt(int64(pool.LpFee)), 208))
+	return common.BigToHash(slot0)
+}
+
+func copyPool(pool *domain.PoolState) *domain.PoolState {
+	if pool == nil {
+		return nil
+	}
+	copied := *pool
+	return &copied
+}
+
+// noTicks is the tick bitmap of a pool without initialized ticks
+type noTicks struct{}
+
+func (noTicks) TickBitmap(int16) (*uint256.Int, error) {
+	return uint256.NewInt(0), nil
+}
+
+func (noTicks) LiquidityNet(int32) (*big.Int, error) {
+	return new(big.Int), nil
+}
+
+func ordersSlot(isBuy bool) common.Hash {
+	if isBuy {
+		return slotOf(usecase.BUY_ORDERS_STORAGE_SLOT)
+	}
+	return slotOf(usecase.SELL_ORDERS_STORAGE_SLOT)
+}
+
+func slotOf(slot int64) common.Hash {
+	return common.BigToHash(big.NewInt(slot))
+}
+
+func (s *HookSimulator) NewGioHeaderHandler() (gio.GioHeaderHandler, error) {
+	return &gioGetBlockHeader{simulator: s}, nil
+}
+
+type gioGetBlockHeader struct {
+	simulator *HookSimulator
+}
+
+func (h *gioGetBlockHeader) HandleHeader(blockHash common.Hash) (*types.Header, error) {
+	return h.simulator.HeaderByHash(blockHash)
+}
+
+type gioGetStorage struct {
+	simulator *HookSimulator
+}
+
+func (h *gioGetStorage) Handle(blockHash common.Hash, address common.Address, slot common.Hash) (*gio.GioResponse, error) {
+	value, err := h.simulator.GetStorageAt(blockHash, address, slot)
+	if err != nil {
+		return nil, err
+	}
+	return &gio.GioResponse{ResponseCode: 0, Response: value.Hex()}, nil
+}
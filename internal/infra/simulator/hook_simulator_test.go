@@ -0,0 +1,1055 @@
+package simulator
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/henriquemarlon/swapx/configs"
+	"github.com/henriquemarlon/swapx/internal/domain"
+	"github.com/henriquemarlon/swapx/internal/infra/cartesi"
+	"github.com/henriquemarlon/swapx/internal/infra/repository"
+	"github.com/henriquemarlon/swapx/internal/infra/service"
+	"github.com/henriquemarlon/swapx/internal/usecase"
+	"github.com/henriquemarlon/swapx/pkg/coprocessor"
+	"github.com/henriquemarlon/swapx/pkg/devserver"
+	"github.com/henriquemarlon/swapx/pkg/gio"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testHook        = common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	testTaskManager = common.HexToAddress("0x00000000000000000000000000000000000000bb")
+	testCurrency0   = common.HexToAddress("0x00000000000000000000000000000000000000c0")
+	testCurrency1   = common.HexToAddress("0x00000000000000000000000000000000000000c1")
+	testBuyer       = common.HexToAddress("0x0000000000000000000000000000000000000b01")
+	testSeller      = common.HexToAddress("0x0000000000000000000000000000000000000501")
+)
+
+func newTestSimulator() *HookSimulator {
+	return NewHookSimulator(testHook, testTaskManager, testCurrency0, testCurrency1)
+}
+
+func findOrders(t *testing.T, sim *HookSimulator, blockHash common.Hash, isBuy bool) []*domain.Order {
+	ordersSlot, statusSlot, flagsSlot := int64(usecase.SELL_ORDERS_STORAGE_SLOT), int64(usecase.SELL_ORDERS_STATUS_STORAGE_SLOT), int64(usecase.SELL_ORDER_FLAGS_STORAGE_SLOT)
+	if isBuy {
+		ordersSlot, statusSlot, flagsSlot = usecase.BUY_ORDERS_STORAGE_SLOT, usecase.BUY_ORDERS_STATUS_STORAGE_SLOT, usecase.BUY_ORDER_FLAGS_STORAGE_SLOT
+	}
+
+	orders, err := service.NewOrderStorageService(sim).FindOrdersBySlot(testHook, blockHash, slotOf(ordersSlot), slotOf(statusSlot), slotOf(flagsSlot))
+	require.NoError(t, err)
+	return orders
+}
+
+func TestOrderStorageServiceReadsSimulatedLayout(t *testing.T) {
+	sim := newTestSimulator()
+
+	_, _, err := sim.PlaceOrder(testBuyer, uint256.NewInt(10), uint256.NewInt(100), true, 0)
+	require.NoError(t, err)
+	_, _, err = sim.PlaceOrder(testBuyer, uint256.NewInt(11), uint256.NewInt(200), true, 0)
+	require.NoError(t, err)
+	_, _, err = sim.PlaceOrder(testSeller, uint256.NewInt(9), uint256.NewInt(50), false, uint64(domain.OrderFlagRouteToPool))
+	require.NoError(t, err)
+	require.NoError(t, sim.CancelOrder(testBuyer, 0, true))
+
+	blockHash := sim.Mine()
+
+	buyOrders := findOrders(t, sim, blockHash, true)
+	require.Len(t, buyOrders, 2)
+	assert.Equal(t, uint64(1), buyOrders[0].Id)
+	assert.Equal(t, domain.OrderCancelledOrFulfilled, *buyOrders[0].Status)
+	assert.Equal(t, uint64(2), buyOrders[1].Id)
+	assert.Equal(t, uint256.NewInt(11), buyOrders[1].SqrtPrice)
+	assert.Equal(t, uint256.NewInt(200), buyOrders[1].Amount)
+	assert.Equal(t, domain.OrderNotCancelledOrFulfilled, *buyOrders[1].Status)
+
+	sellOrders := findOrders(t, sim, blockHash, false)
+	require.Len(t, sellOrders, 1)
+	assert.Equal(t, uint256.NewInt(9), sellOrders[0].SqrtPrice)
+	assert.Equal(t, domain.OrderFlagRouteToPool, sellOrders[0].Flags)
+	assert.Zero(t, buyOrders[1].Flags)
+
+	// the cancelled buy order got its currency0 escrow back
+	assert.Equal(t, []Transfer{{Currency: testCurrency0, To: testBuyer, Amount: uint256.NewInt(100)}}, sim.Transfers)
+}
+
+func TestMinedBlocksDoNotSeeLaterChanges(t *testing.T) {
+	sim := newTestSimulator()
+
+	_, _, err := sim.PlaceOrder(testBuyer, uint256.NewInt(10), uint256.NewInt(100), true, 0)
+	require.NoError(t, err)
+	blockHash := sim.Mine()
+
+	_, _, err = sim.PlaceOrder(testBuyer, uint256.NewInt(10), uint256.NewInt(100), true, 0)
+	require.NoError(t, err)
+
+	assert.Len(t, findOrders(t, sim, blockHash, true), 1)
+	assert.Len(t, findOrders(t, sim, sim.Mine(), true), 2)
+}
+
+func TestPlaceOrderReturnsTheTaskPayload(t *testing.T) {
+	sim := newTestSimulator()
+
+	orderId, payload, err := sim.PlaceOrder(testSeller, uint256.NewInt(9), uint256.NewInt(50), false, uint64(domain.OrderFlagImmediateOrCancel))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), orderId)
+
+	// abi.encode(orderId, sqrtPrice, amount, side, flags) with the 1-based order id
+	require.Len(t, payload, 5*32)
+	words := make([]uint64, 5)
+	for i := range words {
+		words[i] = new(big.Int).SetBytes(payload[i*32 : (i+1)*32]).Uint64()
+	}
+	assert.Equal(t, []uint64{1, 9, 50, 1, 1}, words)
+
+	flags, err := sim.GetStorageAt(common.Hash{}, testHook, sim.flagsSlot(false, 0))
+	require.NoError(t, err)
+	assert.Equal(t, common.BigToHash(big.NewInt(1)), flags)
+}
+
+func TestExecuteAsyncSwapFollowsTheHookChecks(t *testing.T) {
+	sim := newTestSimulator()
+
+	_, _, err := sim.PlaceOrder(testBuyer, uint256.NewInt(10), uint256.NewInt(100), true, 0)
+	require.NoError(t, err)
+	_, _, err = sim.PlaceOrder(testSeller, uint256.NewInt(9), uint256.NewInt(50), false, 0)
+	require.NoError(t, err)
+
+	fillId := common.HexToHash("0x01")
+
+	assert.ErrorIs(t, sim.ExecuteAsyncSwap(testBuyer, fillId, 0, 0, uint256.NewInt(10), 100), ErrOnlyTaskManager)
+	assert.ErrorIs(t, sim.ExecuteAsyncSwap(testTaskManager, fillId, 1, 0, uint256.NewInt(10), 100), ErrOrderDoesNotExist)
+	assert.ErrorIs(t, sim.ExecuteAsyncSwap(testTaskManager, fillId, 0, 0, uint256.NewInt(51), 100), ErrInvalidFillQuantity)
+	assert.ErrorIs(t, sim.ExecuteAsyncSwap(testTaskManager, fillId, 0, 0, uint256.NewInt(0), 100), ErrInvalidFillQuantity)
+
+	assert.NoError(t, sim.ExecuteAsyncSwap(testTaskManager, fillId, 0, 0, uint256.NewInt(30), 100))
+	assert.ErrorIs(t, sim.ExecuteAsyncSwap(testTaskManager, fillId, 0, 0, uint256.NewInt(10), 100), ErrFillAlreadyConsumed)
+
+	assert.NoError(t, sim.ExecuteAsyncSwap(testTaskManager, common.HexToHash("0x02"), 0, 0, uint256.NewInt(20), 100))
+	assert.ErrorIs(t, sim.ExecuteAsyncSwap(testTaskManager, common.HexToHash("0x03"), 0, 0, uint256.NewInt(1), 100), ErrOrderAlreadyFulfilled)
+
+	buyOrders := findOrders(t, sim, sim.Mine(), true)
+	assert.Equal(t, uint256.NewInt(50), buyOrders[0].MatchedAmount)
+}
+
+// runInputs drives the handler through the rollup HTTP API of the devserver,
+// the same way the coprocessor loop does, until the input queue is drained.
+func runInputs(t *testing.T, server *devserver.Server, handler *cartesi.MatchOrdersHandler) {
+	url, shutdown, err := server.Listen("127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { shutdown() })
+	coprocessor.ROLLUP_HTTP_SERVER_URL = url
+
+	require.NoError(t, devserver.Drive(handler.MatchOrdersHandler))
+}
+
+func newTestInput(t *testing.T, blockHash common.Hash, payload []byte, blockNumber uint64) string {
+	return newTestInputFrom(t, testHook, blockHash, payload, blockNumber)
+}
+
+func newTestInputFrom(t *testing.T, sender common.Address, blockHash common.Hash, payload []byte, blockNumber uint64) string {
+	input, err := coprocessor.EvmAdvanceEncoder(coprocessor.AdvanceResponse{
+		Metadata: coprocessor.Metadata{
+			ChainId:     31337,
+			TaskManager: testTaskManager,
+			MsgSender:   sender,
+			BlockHash:   blockHash.Hex(),
+			BlockNumber: blockNumber,
+			Timestamp:   blockNumber,
+			PrevRandao:  "1",
+		},
+		Payload: common.Bytes2Hex(payload),
+	})
+	require.NoError(t, err)
+	return input
+}
+
+func newTestHandler(t *testing.T, sim *HookSimulator) *cartesi.MatchOrdersHandler {
+	return newTestHandlerWithPolicy(t, sim, usecase.DefaultMatchingPolicy())
+}
+
+// newTestHandlerWithPolicy reads the chain through chain, a simulator or the
+// hookChain of several
+func newTestHandlerWithPolicy(t *testing.T, chain gio.GioHandlerFactory, policy *usecase.MatchingPolicy) *cartesi.MatchOrdersHandler {
+	db, err := configs.SetupInMemoryDB()
+	require.NoError(t, err)
+	allowlist, err := configs.NewAllowlist(nil, nil, nil)
+	require.NoError(t, err)
+	return cartesi.NewMatchOrdersHandler(
+		allowlist,
+		policy,
+		repository.NewOrderRepositoryInMemory(db),
+		repository.NewCursorRepositoryInMemory(db),
+		repository.NewMarketRepositoryInMemory(db),
+		repository.NewSettlementRepositoryInMemory(db),
+		repository.NewLineageRepositoryInMemory(db),
+		service.NewOrderStorageService(chain),
+		service.NewBlockHeaderService(chain),
+		service.NewPoolStateService(chain),
+	)
+}
+
+// hookChain is a chain holding several hooks, each kept by its own simulator,
+// whose block hashes never collide
+type hookChain []*HookSimulator
+
+func (c hookChain) GetStorageAt(blockHash common.Hash, address common.Address, slot common.Hash) (common.Hash, error) {
+	for _, sim := range c {
+		if sim.Address == address {
+			return sim.GetStorageAt(blockHash, address, slot)
+		}
+	}
+	return common.Hash{}, nil
+}
+
+func (c hookChain) HeaderByHash(blockHash common.Hash) (*types.Header, error) {
+	for _, sim := range c {
+		if header, err := sim.HeaderByHash(blockHash); err == nil {
+			return header, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnknownBlock, blockHash.Hex())
+}
+
+func (c hookChain) NewGioHandler(domain uint16) (gio.GioHandler, error) {
+	if domain != 0x27 {
+		return nil, errors.New("domain not supported")
+	}
+	return c, nil
+}
+
+func (c hookChain) Handle(blockHash common.Hash, address common.Address, slot common.Hash) (*gio.GioResponse, error) {
+	value, err := c.GetStorageAt(blockHash, address, slot)
+	if err != nil {
+		return nil, err
+	}
+	return &gio.GioResponse{ResponseCode: 0, Response: value.Hex()}, nil
+}
+
+func (c hookChain) NewGioHeaderHandler() (gio.GioHeaderHandler, error) {
+	return c, nil
+}
+
+func (c hookChain) HandleHeader(blockHash common.Hash) (*types.Header, error) {
+	return c.HeaderByHash(blockHash)
+}
+
+// failingChain fails every read of one slot of the hook, as a GIO server that
+// cannot reach the base layer would
+type failingChain struct {
+	*HookSimulator
+	slot common.Hash
+}
+
+func (c failingChain) NewGioHandler(domain uint16) (gio.GioHandler, error) {
+	if domain != 0x27 {
+		return nil, errors.New("domain not supported")
+	}
+	return c, nil
+}
+
+func (c failingChain) Handle(blockHash common.Hash, address common.Address, slot common.Hash) (*gio.GioResponse, error) {
+	if slot == c.slot {
+		return nil, errors.New("storage unavailable")
+	}
+	value, err := c.GetStorageAt(blockHash, address, slot)
+	if err != nil {
+		return nil, err
+	}
+	return &gio.GioResponse{ResponseCode: 0, Response: value.Hex()}, nil
+}
+
+func outputsOfType(outputs []devserver.Output, outputType string) []devserver.Output {
+	var filtered []devserver.Output
+	for _, output := range outputs {
+		if output.Type == outputType {
+			filtered = append(filtered, output)
+		}
+	}
+	return filtered
+}
+
+func TestInputToNoticeToSettlement(t *testing.T) {
+	sim := newTestSimulator()
+
+	// Tasks carry the hash of the previous block, which does not hold their own order yet
+	blockHash := sim.Mine()
+	_, buyPayload, err := sim.PlaceOrder(testBuyer, uint256.NewInt(10), uint256.NewInt(100), true, 0)
+	require.NoError(t, err)
+	buyInput := newTestInput(t, blockHash, buyPayload, 1)
+
+	blockHash = sim.Mine()
+	_, sellPayload, err := sim.PlaceOrder(testSeller, uint256.NewInt(9), uint256.NewInt(150), false, uint64(domain.OrderFlagImmediateOrCancel))
+	require.NoError(t, err)
+	sellInput := newTestInput(t, blockHash, sellPayload, 2)
+
+	server := devserver.NewServer([]string{buyInput, sellInput}, sim)
+	runInputs(t, server, newTestHandler(t, sim))
+
+	outputs, results := server.Snapshot()
+	require.Len(t, results, 2)
+	for _, result := range results {
+		assert.Equal(t, "accept", result.Status)
+	}
+
+	// every input reports the state hash of the book it leaves behind
+	reports := outputsOfType(outputs, devserver.OutputReport)
+	require.Len(t, reports, 2)
+	for i, output := range reports {
+		report, err := cartesi.DecodeStateReport(common.FromHex(output.Payload))
+		require.NoError(t, err)
+		assert.Equal(t, i, output.Input)
+		assert.Equal(t, i+1, report.Orders)
+	}
+
+	// a settlement notice for the fill and a voucher notice refunding the
+	// immediate-or-cancel remainder of the sell order
+	notices := outputsOfType(outputs, devserver.OutputNotice)
+	require.Len(t, notices, 2)
+	for _, output := range notices {
+		assert.Equal(t, 1, output.Input)
+
+		failures, err := sim.HandleNotice(common.FromHex(output.Payload))
+		require.NoError(t, err)
+		assert.Empty(t, failures)
+	}
+
+	assert.Equal(t, []Transfer{
+		{Currency: testCurrency1, To: testBuyer, Amount: uint256.NewInt(100)},
+		{Currency: testCurrency0, To: testSeller, Amount: uint256.NewInt(100)},
+		{Currency: testCurrency1, To: testSeller, Amount: uint256.NewInt(50)},
+	}, sim.Transfers)
+
+	blockHash = sim.Mine()
+	buyOrders := findOrders(t, sim, blockHash, true)
+	assert.Equal(t, uint256.NewInt(100), buyOrders[0].MatchedAmount)
+	assert.Equal(t, domain.OrderCancelledOrFulfilled, *buyOrders[0].Status)
+	sellOrders := findOrders(t, sim, blockHash, false)
+	assert.Equal(t, uint256.NewInt(100), sellOrders[0].MatchedAmount)
+	assert.Equal(t, domain.OrderCancelledOrFulfilled, *sellOrders[0].Status)
+
+	// Relaying the same notices again settles nothing twice
+	for _, output := range notices {
+		failures, err := sim.HandleNotice(common.FromHex(output.Payload))
+		require.NoError(t, err)
+		require.Len(t, failures, 1)
+		assert.True(t, errors.Is(failures[0], ErrFillAlreadyConsumed) || errors.Is(failures[0], ErrOrderWasCancelled))
+	}
+	assert.Len(t, sim.Transfers, 3)
+}
+
+// The hook only shows a fill once it is settled, so until then the storage
+// lags behind the book of the machine. Reading it again, or receiving the task
+// of a known order again, must not reopen what was already matched.
+func TestSyncKeepsFillsNotSettledYet(t *testing.T) {
+	sim := newTestSimulator()
+
+	blockHash := sim.Mine()
+	_, buyPayload, err := sim.PlaceOrder(testBuyer, uint256.NewInt(10), uint256.NewInt(100), true, 0)
+	require.NoError(t, err)
+	buyInput := newTestInput(t, blockHash, buyPayload, 1)
+
+	blockHash = sim.Mine()
+	_, sellPayload, err := sim.PlaceOrder(testSeller, uint256.NewInt(9), uint256.NewInt(150), false, 0)
+	require.NoError(t, err)
+	sellInput := newTestInput(t, blockHash, sellPayload, 2)
+
+	// the notice of the fill is not relayed, the same sell task comes again
+	blockHash = sim.Mine()
+	server := devserver.NewServer([]string{buyInput, sellInput, newTestInput(t, blockHash, sellPayload, 3)}, sim)
+	runInputs(t, server, newTestHandler(t, sim))
+
+	outputs, results := server.Snapshot()
+	require.Len(t, results, 3)
+	for _, result := range results {
+		assert.Equal(t, "accept", result.Status)
+	}
+
+	notices := outputsOfType(outputs, devserver.OutputNotice)
+	require.Len(t, notices, 1)
+	assert.Equal(t, 1, notices[0].Input)
+
+	// the filled buy order is terminal and the sell order keeps its remainder
+	reports := outputsOfType(outputs, devserver.OutputReport)
+	require.Len(t, reports, 3)
+	for _, output := range reports[1:] {
+		report, err := cartesi.DecodeStateReport(common.FromHex(output.Payload))
+		require.NoError(t, err)
+		assert.Equal(t, 2, report.Orders)
+		assert.Equal(t, 1, report.Book.TerminalOrders)
+	}
+}
+
+// A book read only in part would match the orders it saw against an empty
+// other side, so a failed read rejects the input instead.
+func TestFailedStorageReadRejectsTheInput(t *testing.T) {
+	sim := newTestSimulator()
+
+	_, _, err := sim.PlaceOrder(testBuyer, uint256.NewInt(10), uint256.NewInt(100), true, 0)
+	require.NoError(t, err)
+	blockHash := sim.Mine()
+	_, sellPayload, err := sim.PlaceOrder(testSeller, uint256.NewInt(9), uint256.NewInt(100), false, 0)
+	require.NoError(t, err)
+
+	server := devserver.NewServer([]string{newTestInput(t, blockHash, sellPayload, 2)}, sim)
+	runInputs(t, server, newTestHandlerWithPolicy(t, failingChain{sim, slotOf(usecase.BUY_ORDERS_STORAGE_SLOT)}, usecase.DefaultMatchingPolicy()))
+
+	outputs, results := server.Snapshot()
+	require.Len(t, results, 1)
+	assert.Equal(t, "reject", results[0].Status)
+	assert.Empty(t, outputsOfType(outputs, devserver.OutputNotice))
+
+	// a side without orders yet is not a failure
+	sim = newTestSimulator()
+	blockHash = sim.Mine()
+	_, buyPayload, err := sim.PlaceOrder(testBuyer, uint256.NewInt(10), uint256.NewInt(100), true, 0)
+	require.NoError(t, err)
+	server = devserver.NewServer([]string{newTestInput(t, blockHash, buyPayload, 1)}, sim)
+	runInputs(t, server, newTestHandler(t, sim))
+
+	_, results = server.Snapshot()
+	require.Len(t, results, 1)
+	assert.Equal(t, "accept", results[0].Status)
+}
+
+// Operators run the same inputs on their own machines and must emit the same
+// bytes. The book below has price ties and several orders per side, so any
+// dependency on map iteration order shows up within a few runs.
+func TestOutputsAreDeterministic(t *testing.T) {
+	sim := newTestSimulator()
+	orders := []struct {
+		account   common.Address
+		sqrtPrice uint64
+		amount    uint64
+		isBuy     bool
+		flags     domain.OrderFlag
+	}{
+		{testBuyer, 10, 40, true, 0},
+		{testBuyer, 12, 25, true, 0},
+		{testBuyer, 10, 30, true, 0},
+		{testBuyer, 11, 15, true, 0},
+		{testSeller, 13, 20, false, 0},
+		{testSeller, 9, 70, false, 0},
+		{testBuyer, 14, 60, true, domain.OrderFlagImmediateOrCancel},
+		{testSeller, 10, 90, false, domain.OrderFlagImmediateOrCancel},
+	}
+
+	var inputs []string
+	for i, order := range orders {
+		blockHash := sim.Mine()
+		_, payload, err := sim.PlaceOrder(order.account, uint256.NewInt(order.sqrtPrice), uint256.NewInt(order.amount), order.isBuy, uint64(order.flags))
+		require.NoError(t, err)
+		inputs = append(inputs, newTestInput(t, blockHash, payload, uint64(i+1)))
+	}
+
+	var expected []devserver.Output
+	for run := 0; run < 25; run++ {
+		server := devserver.NewServer(inputs, sim)
+		runInputs(t, server, newTestHandler(t, sim))
+
+		outputs, results := server.Snapshot()
+		for _, result := range results {
+			require.Equal(t, "accept", result.Status)
+		}
+		if run == 0 {
+			require.NotEmpty(t, outputsOfType(outputs, devserver.OutputNotice))
+			require.Len(t, outputsOfType(outputs, devserver.OutputReport), len(inputs))
+			expected = outputs
+			continue
+		}
+		require.Equal(t, expected, outputs, "run %d diverged", run)
+	}
+}
+
+// Filled orders are still in the hook storage after they are pruned, the
+// tombstones keep the next inputs from importing them again.
+func TestFilledOrdersArePrunedAfterTheRetention(t *testing.T) {
+	sim := newTestSimulator()
+	orders := []struct {
+		account   common.Address
+		sqrtPrice uint64
+		amount    uint64
+		isBuy     bool
+	}{
+		{testBuyer, 10, 100, true},
+		{testSeller, 9, 100, false},
+		{testBuyer, 5, 10, true},
+		{testBuyer, 5, 10, true},
+		{testBuyer, 5, 10, true},
+	}
+
+	var inputs []string
+	for i, order := range orders {
+		blockHash := sim.Mine()
+		_, payload, err := sim.PlaceOrder(order.account, uint256.NewInt(order.sqrtPrice), uint256.NewInt(order.amount), order.isBuy, 0)
+		require.NoError(t, err)
+		inputs = append(inputs, newTestInput(t, blockHash, payload, uint64(i+1)))
+	}
+
+	policy := usecase.DefaultMatchingPolicy()
+	policy.RetentionBlocks = 2
+	server := devserver.NewServer(inputs, sim)
+	runInputs(t, server, newTestHandlerWithPolicy(t, sim, policy))
+
+	outputs, results := server.Snapshot()
+	for _, result := range results {
+		require.Equal(t, "accept", result.Status)
+	}
+
+	var books []domain.BookStats
+	for _, output := range outputsOfType(outputs, devserver.OutputReport) {
+		report, err := cartesi.DecodeStateReport(common.FromHex(output.Payload))
+		require.NoError(t, err)
+		require.NotNil(t, report.Book)
+		assert.Nil(t, report.Runtime)
+		books = append(books, *report.Book)
+	}
+
+	// filled at block 2 and pruned at block 4, the block 5 input still reads
+	// both of them from the hook storage
+	assert.Equal(t, []domain.BookStats{
+		{BuyOrders: 1},
+		{BuyOrders: 1, SellOrders: 1, TerminalOrders: 2},
+		{BuyOrders: 2, SellOrders: 1, TerminalOrders: 2},
+		{BuyOrders: 2, BuyTombstones: 1, SellTombstones: 1},
+		{BuyOrders: 3, BuyTombstones: 1, SellTombstones: 1},
+	}, books)
+}
+
+// A task that never made it leaves the book crossed until a keeper asks for a
+// rematch, which settles on the hook named in the task rather than the sender.
+func TestRematchSettlesACrossedBook(t *testing.T) {
+	sim := newTestSimulator()
+	keeper := common.HexToAddress("0x000000000000000000000000000000000000ee01")
+
+	blockHash := sim.Mine()
+	_, buyPayload, err := sim.PlaceOrder(testBuyer, uint256.NewInt(10), uint256.NewInt(100), true, 0)
+	require.NoError(t, err)
+	buyInput := newTestInput(t, blockHash, buyPayload, 1)
+	// the task of this order is lost
+	_, _, err = sim.PlaceOrder(testSeller, uint256.NewInt(9), uint256.NewInt(60), false, 0)
+	require.NoError(t, err)
+
+	blockHash = sim.Mine()
+	rematch, err := cartesi.EncodeRematchTask(&cartesi.RematchTask{Hook: testHook})
+	require.NoError(t, err)
+	inputs := []string{
+		buyInput,
+		newTestInputFrom(t, keeper, blockHash, rematch, 2),
+		// the book is no longer crossed
+		newTestInputFrom(t, keeper, blockHash, rematch, 2),
+	}
+
+	db, err := configs.SetupInMemoryDB()
+	require.NoError(t, err)
+	allowlist, err := configs.NewAllowlist([]string{testHook.Hex()}, nil, nil)
+	require.NoError(t, err)
+	handler := cartesi.NewMatchOrdersHandler(
+		allowlist,
+		usecase.DefaultMatchingPolicy(),
+		repository.NewOrderRepositoryInMemory(db),
+		repository.NewCursorRepositoryInMemory(db),
+		repository.NewMarketRepositoryInMemory(db),
+		repository.NewSettlementRepositoryInMemory(db),
+		repository.NewLineageRepositoryInMemory(db),
+		service.NewOrderStorageService(sim),
+		service.NewBlockHeaderService(sim),
+		service.NewPoolStateService(sim),
+	)
+	server := devserver.NewServer(inputs, sim)
+	runInputs(t, server, handler)
+
+	outputs, results := server.Snapshot()
+	require.Len(t, results, 3)
+	for _, result := range results {
+		assert.Equal(t, "accept", result.Status)
+	}
+	notices := outputsOfType(outputs, devserver.OutputNotice)
+	require.Len(t, notices, 1)
+	assert.Equal(t, 1, notices[0].Input)
+
+	failures, err := sim.HandleNotice(common.FromHex(notices[0].Payload))
+	require.NoError(t, err)
+	assert.Empty(t, failures)
+	sellOrders := findOrders(t, sim, sim.Mine(), false)
+	assert.Equal(t, domain.OrderCancelledOrFulfilled, *sellOrders[0].Status)
+
+	// a rematch of a hook that is not allowed is rejected
+	other, err := cartesi.EncodeRematchTask(&cartesi.RematchTask{Hook: keeper})
+	require.NoError(t, err)
+	server = devserver.NewServer([]string{newTestInputFrom(t, keeper, blockHash, other, 3)}, sim)
+	runInputs(t, server, handler)
+	_, results = server.Snapshot()
+	assert.Equal(t, "reject", results[0].Status)
+}
+
+// A notice that never reaches the hook keeps its fill pending until it
+// expires, then the quantity goes back to the book and is matched again with
+// new fill ids, which the hook executes. The hook refuses the lost notice
+// from then on.
+func TestUnexecutedFillIsReleasedAndMatchedAgain(t *testing.T) {
+	sim := newTestSimulator()
+	place := func(account common.Address, sqrtPrice, amount uint64, isBuy bool, blockNumber uint64) string {
+		blockHash := sim.Mine()
+		_, payload, err := sim.PlaceOrder(account, uint256.NewInt(sqrtPrice), uint256.NewInt(amount), isBuy, 0)
+		require.NoError(t, err)
+		return newTestInput(t, blockHash, payload, blockNumber)
+	}
+	reportsOf := func(server *devserver.Server) []*cartesi.StateReport {
+		outputs, results := server.Snapshot()
+		for _, result := range results {
+			require.Equal(t, "accept", result.Status)
+		}
+		var reports []*cartesi.StateReport
+		for _, output := range outputsOfType(outputs, devserver.OutputReport) {
+			report, err := cartesi.DecodeStateReport(common.FromHex(output.Payload))
+			require.NoError(t, err)
+			reports = append(reports, report)
+		}
+		return reports
+	}
+
+	policy := usecase.DefaultMatchingPolicy()
+	policy.SettlementTimeoutBlocks = 5
+	handler := newTestHandlerWithPolicy(t, sim, policy)
+
+	inputs := []string{
+		place(testBuyer, 10, 100, true, 1),
+		// the notice of this match is lost
+		place(testSeller, 9, 60, false, 2),
+		place(testBuyer, 5, 10, true, 3),
+		// the fill expired, the book is crossed again
+		place(testBuyer, 5, 10, true, 8),
+	}
+	server := devserver.NewServer(inputs, sim)
+	runInputs(t, server, handler)
+
+	reports := reportsOf(server)
+	require.Len(t, reports, 4)
+	assert.Nil(t, reports[0].Settlements)
+	assert.Equal(t, &cartesi.SettlementReport{Pending: 1}, reports[1].Settlements)
+	assert.Equal(t, &cartesi.SettlementReport{Pending: 1}, reports[2].Settlements)
+
+	released := reports[3].Settlements
+	require.NotNil(t, released)
+	assert.Equal(t, 1, released.Pending)
+	require.Len(t, released.Failed, 1)
+	failed := released.Failed[0]
+	assert.Equal(t, uint64(2), failed.SentAt)
+	assert.Equal(t, uint256.NewInt(60), failed.Quantity)
+	assert.Equal(t, uint256.NewInt(60), failed.ExpectedBidMatched)
+	assert.Equal(t, uint256.NewInt(0), failed.ChainBidMatched)
+	assert.Equal(t, uint256.NewInt(60), failed.ExpectedAskMatched)
+	assert.Equal(t, uint256.NewInt(0), failed.ChainAskMatched)
+
+	outputs, _ := server.Snapshot()
+	notices := outputsOfType(outputs, devserver.OutputNotice)
+	require.Len(t, notices, 2)
+	assert.Equal(t, 3, notices[1].Input)
+	first, err := cartesi.DecodeSettlementNotice(common.FromHex(notices[0].Payload))
+	require.NoError(t, err)
+	again, err := cartesi.DecodeSettlementNotice(common.FromHex(notices[1].Payload))
+	require.NoError(t, err)
+	assert.NotEqual(t, first.Fills[0].FillId, again.Fills[0].FillId)
+	assert.Equal(t, big.NewInt(7), first.Fills[0].ExpiryBlock)
+	assert.Equal(t, big.NewInt(13), again.Fills[0].ExpiryBlock)
+
+	for sim.blockNumber < 8 {
+		sim.Mine()
+	}
+	failures, err := sim.HandleNotice(common.FromHex(notices[1].Payload))
+	require.NoError(t, err)
+	assert.Empty(t, failures)
+	// relayed late, the first notice would execute the quantity a second time
+	failures, err = sim.HandleNotice(common.FromHex(notices[0].Payload))
+	require.NoError(t, err)
+	require.Len(t, failures, 1)
+	assert.ErrorIs(t, failures[0], ErrFillExpired)
+
+	server = devserver.NewServer([]string{place(testBuyer, 5, 10, true, 9)}, sim)
+	runInputs(t, server, handler)
+	reports = reportsOf(server)
+	require.Len(t, reports, 1)
+	assert.Equal(t, &cartesi.SettlementReport{Confirmed: 1}, reports[0].Settlements)
+}
+
+func TestReorgRollsBackTheInputsOfDroppedBlocks(t *testing.T) {
+	sim := newTestSimulator()
+	place := func(blockHash common.Hash, account common.Address, sqrtPrice, amount uint64, isBuy bool, blockNumber uint64) string {
+		_, payload, err := sim.PlaceOrder(account, uint256.NewInt(sqrtPrice), uint256.NewInt(amount), isBuy, 0)
+		require.NoError(t, err)
+		return newTestInput(t, blockHash, payload, blockNumber)
+	}
+
+	policy := usecase.DefaultMatchingPolicy()
+	policy.ReorgDepthBlocks = 64
+	handler := newTestHandlerWithPolicy(t, sim, policy)
+
+	h1 := sim.Mine()
+	first := place(h1, testBuyer, 10, 100, true, 1)
+	h2 := sim.Mine()
+	h3 := sim.Mine()
+	// matches the buy order, but block 3 is about to be replaced
+	second := place(h3, testSeller, 9, 60, false, 3)
+	server := devserver.NewServer([]string{first, second}, sim)
+	runInputs(t, server, handler)
+
+	outputs, _ := server.Snapshot()
+	require.Len(t, outputsOfType(outputs, devserver.OutputNotice), 1)
+
+	require.NoError(t, sim.Rewind(h2))
+	h3b := sim.Mine()
+	server = devserver.NewServer([]string{place(h3b, testBuyer, 5, 10, true, 3)}, sim)
+	runInputs(t, server, handler)
+
+	outputs, results := server.Snapshot()
+	require.Len(t, results, 1)
+	assert.Equal(t, "accept", results[0].Status)
+	assert.Empty(t, outputsOfType(outputs, devserver.OutputNotice))
+	reports := outputsOfType(outputs, devserver.OutputReport)
+	require.Len(t, reports, 1)
+	report, err := cartesi.DecodeStateReport(common.FromHex(reports[0].Payload))
+	require.NoError(t, err)
+
+	require.NotNil(t, report.Reorg)
+	assert.Equal(t, 1, report.Reorg.DroppedInputs)
+	assert.Equal(t, domain.BlockRef{Number: 3, Hash: h3}, report.Reorg.DroppedBlock)
+	assert.Nil(t, report.Settlements, "the fill of the dropped input is forgotten")
+	require.NotNil(t, report.Book)
+	assert.Equal(t, 2, report.Book.BuyOrders)
+	assert.Equal(t, 0, report.Book.SellOrders)
+	assert.Equal(t, 0, report.Book.TerminalOrders)
+
+	stats, err := handler.MarketRepository.FindStats(testHook, 0)
+	require.NoError(t, err)
+	assert.Zero(t, stats.Trades, "the trade of the dropped input is forgotten")
+}
+
+func TestYoungOrdersAreQueuedUntilTheConfirmationDepth(t *testing.T) {
+	sim := newTestSimulator()
+	place := func(account common.Address, sqrtPrice, amount uint64, isBuy bool, flags domain.OrderFlag, blockNumber uint64) string {
+		blockHash := sim.Mine()
+		_, payload, err := sim.PlaceOrder(account, uint256.NewInt(sqrtPrice), uint256.NewInt(amount), isBuy, uint64(flags))
+		require.NoError(t, err)
+		return newTestInput(t, blockHash, payload, blockNumber)
+	}
+
+	policy := usecase.DefaultMatchingPolicy()
+	policy.ConfirmationDepthBlocks = 3
+	inputs := []string{
+		place(testBuyer, 10, 100, true, 0, 1),
+		place(testSeller, 9, 150, false, domain.OrderFlagImmediateOrCancel, 2),
+		// both orders have waited, the new one has not
+		place(testBuyer, 5, 10, true, 0, 5),
+	}
+	server := devserver.NewServer(inputs, sim)
+	runInputs(t, server, newTestHandlerWithPolicy(t, sim, policy))
+
+	outputs, results := server.Snapshot()
+	require.Len(t, results, 3)
+	var reports []*cartesi.StateReport
+	for _, output := range outputsOfType(outputs, devserver.OutputReport) {
+		report, err := cartesi.DecodeStateReport(common.FromHex(output.Payload))
+		require.NoError(t, err)
+		reports = append(reports, report)
+	}
+	require.Len(t, reports, 3)
+
+	assert.Equal(t, []*domain.QueuedOrder{
+		{Id: 1, Hook: testHook, Type: domain.OrderTypeBuy, QueuedAt: 1, MatchableAt: 4, Reason: domain.QueueReasonConfirmationDepth},
+	}, reports[0].Queued)
+	assert.Len(t, reports[1].Queued, 2)
+	assert.Equal(t, 2, reports[1].Book.QueuedOrders)
+	assert.Equal(t, []*domain.QueuedOrder{
+		{Id: 2, Hook: testHook, Type: domain.OrderTypeBuy, QueuedAt: 5, MatchableAt: 8, Reason: domain.QueueReasonConfirmationDepth},
+	}, reports[2].Queued)
+
+	// the fill and the refund of the immediate-or-cancel remainder wait for the
+	// sell order to be opened
+	notices := outputsOfType(outputs, devserver.OutputNotice)
+	require.Len(t, notices, 2)
+	for _, output := range notices {
+		assert.Equal(t, 2, output.Input)
+		failures, err := sim.HandleNotice(common.FromHex(output.Payload))
+		require.NoError(t, err)
+		assert.Empty(t, failures)
+	}
+	assert.Contains(t, sim.Transfers, Transfer{Currency: testCurrency1, To: testSeller, Amount: uint256.NewInt(50)})
+}
+
+func TestPriceBandHoldsOrRejectsMatchesFarFromSpot(t *testing.T) {
+	poolManager := common.HexToAddress("0x00000000000000000000000000000000000000dd")
+	run := func(mode domain.BandMode, moveSpot bool) (*HookSimulator, []devserver.Output, []*cartesi.StateReport) {
+		sim := newTestSimulator()
+		sim.InitializePool(poolManager, 3000, 60, uint256.NewInt(10))
+		place := func(account common.Address, sqrtPrice, amount uint64, isBuy bool, blockNumber uint64) string {
+			blockHash := sim.Mine()
+			_, payload, err := sim.PlaceOrder(account, uint256.NewInt(sqrtPrice), uint256.NewInt(amount), isBuy, 0)
+			require.NoError(t, err)
+			return newTestInput(t, blockHash, payload, blockNumber)
+		}
+
+		policy := usecase.DefaultMatchingPolicy()
+		policy.PoolManager = poolManager
+		policy.PriceBands = map[common.Address]*domain.PriceBand{testHook: {BandBps: 500, Mode: mode}}
+		handler := newTestHandlerWithPolicy(t, sim, policy)
+
+		// the sell order asks for 64% of the spot price
+		inputs := []string{
+			place(testBuyer, 10, 100, true, 1),
+			place(testSeller, 8, 60, false, 2),
+		}
+		if moveSpot {
+			sim.SetSpotPrice(uint256.NewInt(8))
+		}
+		inputs = append(inputs, place(testBuyer, 5, 10, true, 3))
+
+		server := devserver.NewServer(inputs, sim)
+		runInputs(t, server, handler)
+		outputs, results := server.Snapshot()
+		var reports []*cartesi.StateReport
+		for i, output := range outputsOfType(outputs, devserver.OutputReport) {
+			assert.Equal(t, "accept", results[i].Status)
+			report, err := cartesi.DecodeStateReport(common.FromHex(output.Payload))
+			require.NoError(t, err)
+			reports = append(reports, report)
+		}
+		require.Len(t, reports, 3)
+		return sim, outputs, reports
+	}
+
+	t.Run("hold", func(t *testing.T) {
+		sim, outputs, reports := run(domain.BandModeHold, true)
+		assert.Equal(t, &cartesi.PriceBandReport{
+			Spot:   uint256.NewInt(10),
+			Banded: []*domain.BandedOrder{{Id: 1, Type: domain.OrderTypeSell, SqrtPrice: uint256.NewInt(8), Mode: domain.BandModeHold}},
+		}, reports[1].PriceBand)
+		assert.Equal(t, 0, reports[1].Book.TerminalOrders)
+
+		// the spot came down to the ask, which matches
+		assert.Nil(t, reports[2].PriceBand)
+		notices := outputsOfType(outputs, devserver.OutputNotice)
+		require.Len(t, notices, 1)
+		assert.Equal(t, 2, notices[0].Input)
+		notice, err := cartesi.DecodeSettlementNotice(common.FromHex(notices[0].Payload))
+		require.NoError(t, err)
+		require.Len(t, notice.Fills, 1)
+		failures, err := sim.HandleNotice(common.FromHex(notices[0].Payload))
+		require.NoError(t, err)
+		assert.Empty(t, failures)
+	})
+
+	t.Run("reject", func(t *testing.T) {
+		sim, outputs, reports := run(domain.BandModeReject, false)
+		require.NotNil(t, reports[1].PriceBand)
+		assert.Equal(t, domain.BandModeReject, reports[1].PriceBand.Banded[0].Mode)
+		assert.Equal(t, 1, reports[1].Book.TerminalOrders)
+
+		// the sell order is refunded and never matched
+		notices := outputsOfType(outputs, devserver.OutputNotice)
+		require.Len(t, notices, 1)
+		assert.Equal(t, 1, notices[0].Input)
+		failures, err := sim.HandleNotice(common.FromHex(notices[0].Payload))
+		require.NoError(t, err)
+		assert.Empty(t, failures)
+		assert.Equal(t, []Transfer{{Currency: testCurrency1, To: testSeller, Amount: uint256.NewInt(60)}}, sim.Transfers)
+	})
+}
+
+func TestRemainderOfFlaggedOrdersIsRoutedToThePool(t *testing.T) {
+	poolManager := common.HexToAddress("0x00000000000000000000000000000000000000dd")
+	sim := newTestSimulator()
+	spot := uint256.MustFromBig(domain.SqrtPriceAtTick(0))
+	sim.InitializePool(poolManager, 3000, 60, spot)
+	sim.AddLiquidity(uint256.NewInt(1e18))
+
+	place := func(account common.Address, tick int32, amount uint64, isBuy bool, flags domain.OrderFlag, blockNumber uint64) string {
+		blockHash := sim.Mine()
+		_, payload, err := sim.PlaceOrder(account, uint256.MustFromBig(domain.SqrtPriceAtTick(tick)), uint256.NewInt(amount), isBuy, uint64(flags))
+		require.NoError(t, err)
+		return newTestInput(t, blockHash, payload, blockNumber)
+	}
+
+	policy := usecase.DefaultMatchingPolicy()
+	policy.PoolManager = poolManager
+	policy.RouteToPool = true
+	handler := newTestHandlerWithPolicy(t, sim, policy)
+
+	// the book takes 60 of the buy order, the pool is above its price and takes the rest
+	inputs := []string{
+		place(testSeller, -1200, 60, false, 0, 1),
+		place(testBuyer, -600, 100, true, domain.OrderFlagRouteToPool, 2),
+	}
+	server := devserver.NewServer(inputs, sim)
+	runInputs(t, server, handler)
+	outputs, _ := server.Snapshot()
+
+	notices := outputsOfType(outputs, devserver.OutputNotice)
+	require.Len(t, notices, 2)
+	voucherNotice, err := cartesi.DecodeVoucherNotice(common.FromHex(notices[1].Payload))
+	require.NoError(t, err)
+	assert.Equal(t, testHook, voucherNotice.Hook)
+	require.Len(t, voucherNotice.Vouchers, 1)
+	action, err := cartesi.DecodeActionVoucher(&voucherNotice.Vouchers[0])
+	require.NoError(t, err)
+	assert.Equal(t, domain.ActionPoolSwap, action.Kind)
+	assert.Equal(t, uint256.NewInt(40), action.Amount)
+	assert.Equal(t, domain.SqrtPriceAtTick(-600), action.SqrtPriceLimit.ToBig())
+
+	for _, output := range notices {
+		failures, err := sim.HandleNotice(common.FromHex(output.Payload))
+		require.NoError(t, err)
+		assert.Empty(t, failures)
+	}
+
+	// the fill pays 60, the pool swaps 40 less its fee, rounded down
+	assert.Equal(t, []Transfer{
+		{Currency: testCurrency1, To: testBuyer, Amount: uint256.NewInt(60)},
+		{Currency: testCurrency0, To: testSeller, Amount: uint256.NewInt(60)},
+		{Currency: testCurrency1, To: testBuyer, Amount: uint256.NewInt(38)},
+	}, sim.Transfers)
+	assert.True(t, sim.Pool().SqrtPriceX96.Lt(spot))
+	buyOrders := findOrders(t, sim, sim.Mine(), true)
+	assert.Equal(t, buyOrders[0].Amount, buyOrders[0].MatchedAmount)
+}
+
+func TestUnexecutedPoolSwapReopensTheOrder(t *testing.T) {
+	poolManager := common.HexToAddress("0x00000000000000000000000000000000000000dd")
+	sim := newTestSimulator()
+	sim.InitializePool(poolManager, 3000, 60, uint256.MustFromBig(domain.SqrtPriceAtTick(0)))
+	sim.AddLiquidity(uint256.NewInt(1e18))
+
+	place := func(account common.Address, tick int32, amount uint64, isBuy bool, flags domain.OrderFlag, blockNumber uint64) string {
+		blockHash := sim.Mine()
+		_, payload, err := sim.PlaceOrder(account, uint256.MustFromBig(domain.SqrtPriceAtTick(tick)), uint256.NewInt(amount), isBuy, uint64(flags))
+		require.NoError(t, err)
+		return newTestInput(t, blockHash, payload, blockNumber)
+	}
+	reportOf := func(server *devserver.Server) *cartesi.StateReport {
+		outputs, _ := server.Snapshot()
+		reports := outputsOfType(outputs, devserver.OutputReport)
+		require.NotEmpty(t, reports)
+		report, err := cartesi.DecodeStateReport(common.FromHex(reports[len(reports)-1].Payload))
+		require.NoError(t, err)
+		return report
+	}
+
+	policy := usecase.DefaultMatchingPolicy()
+	policy.PoolManager = poolManager
+	policy.RouteToPool = true
+	policy.SettlementTimeoutBlocks = 5
+	handler := newTestHandlerWithPolicy(t, sim, policy)
+
+	server := devserver.NewServer([]string{
+		place(testSeller, -1200, 60, false, 0, 1),
+		place(testBuyer, -600, 100, true, domain.OrderFlagRouteToPool, 2),
+	}, sim)
+	runInputs(t, server, handler)
+	assert.Equal(t, &cartesi.SettlementReport{Pending: 1, PendingSwaps: 1}, reportOf(server).Settlements)
+
+	// the fill is relayed, the swap is not
+	outputs, _ := server.Snapshot()
+	notices := outputsOfType(outputs, devserver.OutputNotice)
+	require.Len(t, notices, 2)
+	failures, err := sim.HandleNotice(common.FromHex(notices[0].Payload))
+	require.NoError(t, err)
+	assert.Empty(t, failures)
+
+	// the swap can still execute in block 7, an order off the book keeps the inputs coming
+	server = devserver.NewServer([]string{place(testSeller, 1200, 10, false, 0, 7)}, sim)
+	runInputs(t, server, handler)
+	assert.Equal(t, &cartesi.SettlementReport{Confirmed: 1, PendingSwaps: 1}, reportOf(server).Settlements)
+	order, err := handler.OrderRepository.FindOrderById(testHook, domain.OrderTypeBuy, 1)
+	require.NoError(t, err)
+	assert.True(t, order.IsTerminal())
+
+	server = devserver.NewServer([]string{place(testSeller, 1200, 10, false, 0, 8)}, sim)
+	runInputs(t, server, handler)
+	released := reportOf(server).Settlements
+	require.NotNil(t, released)
+	assert.Zero(t, released.PendingSwaps)
+	require.Len(t, released.FailedSwaps, 1)
+	failed := released.FailedSwaps[0]
+	assert.Equal(t, domain.OrderTypeBuy, failed.OrderType)
+	assert.Equal(t, uint64(1), failed.OrderId)
+	assert.Equal(t, uint64(2), failed.SentAt)
+	assert.Equal(t, uint256.NewInt(40), failed.AmountIn)
+	assert.Equal(t, uint256.NewInt(40), failed.Released)
+	assert.Equal(t, uint256.NewInt(100), failed.ExpectedMatched)
+	assert.Equal(t, uint256.NewInt(60), failed.ChainMatched)
+
+	order, err = handler.OrderRepository.FindOrderById(testHook, domain.OrderTypeBuy, 1)
+	require.NoError(t, err)
+	assert.False(t, order.IsTerminal())
+	assert.Equal(t, uint256.NewInt(40), order.Remaining())
+
+	// relayed late, the swap would take the amount the book matches again
+	for sim.blockNumber < 8 {
+		sim.Mine()
+	}
+	failures, err = sim.HandleNotice(common.FromHex(notices[1].Payload))
+	require.NoError(t, err)
+	require.Len(t, failures, 1)
+	assert.ErrorIs(t, failures[0], ErrSwapExpired)
+}
+
+// Every hook numbers its orders from 1, so both hooks below have a buy order 1
+// and a sell order 1. Each input only matches the book of its own hook.
+func TestHooksKeepTheirOwnBooks(t *testing.T) {
+	otherHook := common.HexToAddress("0x00000000000000000000000000000000000000ab")
+	sim := newTestSimulator()
+	other := NewHookSimulator(otherHook, testTaskManager, testCurrency0, testCurrency1)
+
+	var inputs []string
+	for _, order := range []struct {
+		sim       *HookSimulator
+		account   common.Address
+		sqrtPrice uint64
+		amount    uint64
+		isBuy     bool
+	}{
+		{sim, testBuyer, 10, 100, true},
+		{other, testBuyer, 5, 40, true},
+		// crosses the buy order 1 of the first hook, not the one of its own
+		{other, testSeller, 9, 100, false},
+		{sim, testSeller, 10, 60, false},
+	} {
+		blockHash := order.sim.Mine()
+		index, payload, err := order.sim.PlaceOrder(order.account, uint256.NewInt(order.sqrtPrice), uint256.NewInt(order.amount), order.isBuy, 0)
+		require.NoError(t, err)
+		require.Zero(t, index, "the order 1 of its side")
+		inputs = append(inputs, newTestInputFrom(t, order.sim.Address, blockHash, payload, uint64(len(inputs)+1)))
+	}
+
+	server := devserver.NewServer(inputs, hookChain{sim, other})
+	runInputs(t, server, newTestHandlerWithPolicy(t, hookChain{sim, other}, usecase.DefaultMatchingPolicy()))
+
+	outputs, results := server.Snapshot()
+	require.Len(t, results, 4)
+	for i, output := range outputsOfType(outputs, devserver.OutputReport) {
+		assert.Equal(t, "accept", results[i].Status)
+		report, err := cartesi.DecodeStateReport(common.FromHex(output.Payload))
+		require.NoError(t, err)
+		assert.Equal(t, i+1, report.Orders, "no order overwrites the one of another hook")
+	}
+
+	notices := outputsOfType(outputs, devserver.OutputNotice)
+	require.Len(t, notices, 1)
+	assert.Equal(t, 3, notices[0].Input)
+	_, err := other.HandleNotice(common.FromHex(notices[0].Payload))
+	assert.ErrorIs(t, err, ErrUnknownHook)
+	failures, err := sim.HandleNotice(common.FromHex(notices[0].Payload))
+	require.NoError(t, err)
+	assert.Empty(t, failures)
+
+	assert.Equal(t, []Transfer{
+		{Currency: testCurrency1, To: testBuyer, Amount: uint256.NewInt(60)},
+		{Currency: testCurrency0, To: testSeller, Amount: uint256.NewInt(60)},
+	}, sim.Transfers)
+	assert.Empty(t, other.Transfers)
+}
@@ -0,0 +1,109 @@
+package usecase
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/henriquemarlon/swapx/internal/domain"
+	"github.com/holiman/uint256"
+)
+
+type ApplyHookEventUseCase struct {
+	OrderRepository domain.OrderRepository
+	FillRepository  domain.FillRepository
+}
+
+func NewApplyHookEventUseCase(orderRepository domain.OrderRepository, fillRepository domain.FillRepository) *ApplyHookEventUseCase {
+	return &ApplyHookEventUseCase{
+		OrderRepository: orderRepository,
+		FillRepository:  fillRepository,
+	}
+}
+
+// Execute mirrors an event of the hook on the book. Fills are recorded even
+// when the order itself is unknown, which happens when indexing starts after
+// the hook was deployed; domain.ErrOrderNotFound is returned afterwards.
+func (u *ApplyHookEventUseCase) Execute(event *domain.HookEvent) error {
+	orderType := event.OrderType
+
+	switch event.Type {
+	case domain.HookEventOrderCreated:
+		// Flags are not part of the event, the book only knows the ones of the task payload
+		order, err := domain.NewOrder(event.OrderId, event.Hook, event.SqrtPrice, event.Amount, uint256.NewInt(0), &orderType, &domain.OrderNotCancelledOrFulfilled)
+		if err != nil {
+			return err
+		}
+		order.Account = event.Account
+		_, err = u.OrderRepository.CreateOrder(order)
+		return err
+
+	case domain.HookEventOrderFulfilled, domain.HookEventOrderPartiallyFulfilled:
+		if err := u.FillRepository.CreateFill(&domain.Fill{
+			Hook:        event.Hook,
+			OrderType:   event.OrderType,
+			OrderId:     event.OrderId,
+			Account:     event.Account,
+			SqrtPrice:   event.SqrtPrice,
+			Quantity:    event.Amount,
+			BlockNumber: event.BlockNumber,
+			BlockHash:   event.BlockHash,
+			Timestamp:   event.Timestamp,
+			TxHash:      event.TxHash,
+			LogIndex:    event.LogIndex,
+		}); err != nil {
+			return err
+		}
+
+		order, err := u.updatableOrder(event.Hook, orderType, event.OrderId)
+		if err != nil {
+			return err
+		}
+		order.MatchedAmount = new(uint256.Int).Add(order.MatchedAmount, event.Amount)
+		if event.Type == domain.HookEventOrderFulfilled {
+			order.Status = &domain.OrderCancelledOrFulfilled
+		}
+		_, err = u.OrderRepository.UpdateOrder(order)
+		return err
+
+	case domain.HookEventOrderCancelled, domain.HookEventOrderRefunded:
+		order, err := u.updatableOrder(event.Hook, orderType, event.OrderId)
+		if err != nil {
+			return err
+		}
+		order.Status = &domain.OrderCancelledOrFulfilled
+		_, err = u.OrderRepository.UpdateOrder(order)
+		return err
+
+	// Dust leaves the order, a pool route matches it. Either one closes the
+	// order once nothing is left, as the hook sees it.
+	case domain.HookEventOrderDustReturned, domain.HookEventOrderRoutedToPool:
+		order, err := u.updatableOrder(event.Hook, orderType, event.OrderId)
+		if err != nil {
+			return err
+		}
+		if event.Type == domain.HookEventOrderDustReturned {
+			order.Amount = new(uint256.Int).Sub(order.Amount, event.Amount)
+		} else {
+			order.MatchedAmount = new(uint256.Int).Add(order.MatchedAmount, event.Amount)
+		}
+		if order.Remaining().IsZero() {
+			order.Status = &domain.OrderCancelledOrFulfilled
+		}
+		_, err = u.OrderRepository.UpdateOrder(order)
+		return err
+
+	default:
+		return fmt.Errorf("unknown hook event %q", event.Type)
+	}
+}
+
+// updatableOrder returns a copy of the order, the one in the repository may be
+// read concurrently until UpdateOrder swaps it
+func (u *ApplyHookEventUseCase) updatableOrder(hook common.Address, orderType domain.OrderType, id uint64) (*domain.Order, error) {
+	order, err := u.OrderRepository.FindOrderById(hook, orderType, id)
+	if err != nil {
+		return nil, err
+	}
+	updated := *order
+	return &updated, nil
+}
@@ -0,0 +1,76 @@
+package usecase
+
+import (
+	"log/slog"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/henriquemarlon/swapx/internal/domain"
+	"github.com/henriquemarlon/swapx/internal/infra/service"
+	"github.com/henriquemarlon/swapx/pkg/coprocessor"
+)
+
+// FollowLineageUseCase checks that the block an input reads at descends from
+// the blocks the previous inputs read at. When some of them left the chain,
+// the state goes back to the checkpoint taken before the first of those
+// inputs, dropping the orders and pending fills they produced, and the input
+// syncs the book from the new branch as usual.
+type FollowLineageUseCase struct {
+	LineageRepository  domain.LineageRepository
+	BlockHeaderService service.BlockHeaderServiceInterface
+	// ReorgDepthBlocks is how deep a reorg can go, older checkpoints are dropped
+	ReorgDepthBlocks uint64
+}
+
+type FollowLineageOutputDTO struct {
+	Block domain.BlockRef `json:"block"`
+	Reorg *Reorg          `json:"reorg,omitempty"`
+}
+
+// Reorg tells how many inputs were rolled back and the block the first of them read at
+type Reorg struct {
+	DroppedInputs int             `json:"dropped_inputs"`
+	DroppedBlock  domain.BlockRef `json:"dropped_block"`
+}
+
+func NewFollowLineageUseCase(lineageRepository domain.LineageRepository, blockHeaderService service.BlockHeaderServiceInterface, reorgDepthBlocks uint64) *FollowLineageUseCase {
+	return &FollowLineageUseCase{
+		LineageRepository:  lineageRepository,
+		BlockHeaderService: blockHeaderService,
+		ReorgDepthBlocks:   reorgDepthBlocks,
+	}
+}
+
+func (u *FollowLineageUseCase) Execute(metadata coprocessor.Metadata) (*FollowLineageOutputDTO, error) {
+	head, err := u.BlockHeaderService.FindBlockHeader(common.HexToHash(metadata.BlockHash))
+	if err != nil {
+		return nil, err
+	}
+	checkpoints, err := u.LineageRepository.FindCheckpoints()
+	if err != nil {
+		return nil, err
+	}
+
+	keep, err := domain.FindForkPoint(checkpoints, head, u.ReorgDepthBlocks, u.BlockHeaderService.FindBlockHeader)
+	if err != nil {
+		return nil, err
+	}
+
+	output := &FollowLineageOutputDTO{Block: head.BlockRef}
+	if keep < len(checkpoints) {
+		if err := u.LineageRepository.RestoreCheckpoint(keep); err != nil {
+			return nil, err
+		}
+		output.Reorg = &Reorg{DroppedInputs: len(checkpoints) - keep, DroppedBlock: checkpoints[keep]}
+		slog.Warn("Rolled back the inputs read at blocks that left the chain", "dropped_inputs", output.Reorg.DroppedInputs, "dropped_block", checkpoints[keep].Number, "block", head.Number)
+	}
+
+	if head.Number >= u.ReorgDepthBlocks {
+		if err := u.LineageRepository.PruneCheckpoints(head.Number - u.ReorgDepthBlocks); err != nil {
+			return nil, err
+		}
+	}
+	if err := u.LineageRepository.SaveCheckpoint(head.BlockRef); err != nil {
+		return nil, err
+	}
+	return output, nil
+}
@@ -0,0 +1,113 @@
+package usecase_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/henriquemarlon/swapx/configs"
+	"github.com/henriquemarlon/swapx/internal/domain"
+	"github.com/henriquemarlon/swapx/internal/infra/repository"
+	"github.com/henriquemarlon/swapx/internal/usecase"
+	"github.com/henriquemarlon/swapx/pkg/coprocessor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubHeaders knows the headers of the branches it was given and nothing
+// else, the parent of a genesis block included
+type stubHeaders map[common.Hash]*domain.BlockHeader
+
+func (s stubHeaders) FindBlockHeader(blockHash common.Hash) (*domain.BlockHeader, error) {
+	header, ok := s[blockHash]
+	if !ok {
+		return nil, fmt.Errorf("unknown block %s", blockHash.Hex())
+	}
+	return header, nil
+}
+
+// branch adds blocks from up to to on top of parent, named after the branch
+func (s stubHeaders) branch(name string, parent common.Hash, from, to uint64) []domain.BlockRef {
+	var blocks []domain.BlockRef
+	for number := from; number <= to; number++ {
+		block := domain.BlockRef{Number: number, Hash: common.BytesToHash([]byte(fmt.Sprintf("%s%d", name, number)))}
+		s[block.Hash] = &domain.BlockHeader{BlockRef: block, ParentHash: parent}
+		blocks = append(blocks, block)
+		parent = block.Hash
+	}
+	return blocks
+}
+
+func TestFollowLineage(t *testing.T) {
+	headers := stubHeaders{}
+	a := headers.branch("a", common.Hash{}, 0, 5)
+	b := headers.branch("b", a[0].Hash, 1, 6)
+	other := headers.branch("genesis", common.Hash{}, 0, 0)
+
+	for _, tc := range []struct {
+		name        string
+		checkpoints []domain.BlockRef
+		head        domain.BlockRef
+		depth       uint64
+		// dropped is the number of inputs rolled back, 0 for no reorg
+		dropped int
+		after   []domain.BlockRef
+	}{
+		{
+			name:        "same branch",
+			checkpoints: a[1:3],
+			head:        a[3],
+			depth:       10,
+			after:       a[1:4],
+		},
+		{
+			name:        "fork right after genesis",
+			checkpoints: a[0:3],
+			head:        b[2],
+			depth:       10,
+			dropped:     2,
+			after:       []domain.BlockRef{a[0], b[2]},
+		},
+		{
+			name:        "fork at another genesis",
+			checkpoints: other,
+			head:        b[0],
+			depth:       10,
+			dropped:     1,
+			after:       []domain.BlockRef{b[0]},
+		},
+		{
+			name:        "fork deeper than the reorg depth",
+			checkpoints: a[1:6],
+			head:        b[5],
+			depth:       2,
+			dropped:     1,
+			after:       []domain.BlockRef{b[5]},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			db, err := configs.SetupInMemoryDB()
+			require.NoError(t, err)
+			lineage := repository.NewLineageRepositoryInMemory(db)
+			for _, checkpoint := range tc.checkpoints {
+				require.NoError(t, lineage.SaveCheckpoint(checkpoint))
+			}
+
+			follow := usecase.NewFollowLineageUseCase(lineage, headers, tc.depth)
+			output, err := follow.Execute(coprocessor.Metadata{BlockNumber: tc.head.Number + 1, BlockHash: tc.head.Hash.Hex()})
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.head, output.Block)
+			if tc.dropped == 0 {
+				assert.Nil(t, output.Reorg)
+			} else {
+				require.NotNil(t, output.Reorg)
+				assert.Equal(t, tc.dropped, output.Reorg.DroppedInputs)
+				assert.Equal(t, tc.checkpoints[len(tc.checkpoints)-tc.dropped], output.Reorg.DroppedBlock)
+			}
+			checkpoints, err := lineage.FindCheckpoints()
+			require.NoError(t, err)
+			assert.Equal(t, tc.after, checkpoints)
+		})
+	}
+}
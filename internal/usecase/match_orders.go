@@ -1,11 +1,14 @@
 package usecase
 
 import (
+	"container/heap"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
+	"math"
 	"math/big"
+	"slices"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/henriquemarlon/swapx/internal/domain"
@@ -19,25 +22,115 @@ const (
 	BUY_ORDERS_STATUS_STORAGE_SLOT  = 6
 	SELL_ORDERS_STORAGE_SLOT        = 9
 	SELL_ORDERS_STATUS_STORAGE_SLOT = 7
+	CONSUMED_FILLS_STORAGE_SLOT     = 10
+	BUY_ORDER_FLAGS_STORAGE_SLOT    = 11
+	SELL_ORDER_FLAGS_STORAGE_SLOT   = 12
+	POOL_KEY_STORAGE_SLOT           = 0
 )
 
+// DEFAULT_SETTLEMENT_TIMEOUT_BLOCKS is how long a sent fill is waited for
+// before its quantity is given back to the book
+const DEFAULT_SETTLEMENT_TIMEOUT_BLOCKS = 64
+
+// StorageSlots locate the order arrays, their cancel and flags mappings, the
+// consumed fills and the pool key in the hook storage
+type StorageSlots struct {
+	BuyOrders        common.Hash
+	BuyOrdersStatus  common.Hash
+	BuyOrderFlags    common.Hash
+	SellOrders       common.Hash
+	SellOrdersStatus common.Hash
+	SellOrderFlags   common.Hash
+	ConsumedFills    common.Hash
+	PoolKey          common.Hash
+}
+
+var DefaultStorageSlots = StorageSlots{
+	BuyOrders:        common.BigToHash(big.NewInt(BUY_ORDERS_STORAGE_SLOT)),
+	BuyOrdersStatus:  common.BigToHash(big.NewInt(BUY_ORDERS_STATUS_STORAGE_SLOT)),
+	BuyOrderFlags:    common.BigToHash(big.NewInt(BUY_ORDER_FLAGS_STORAGE_SLOT)),
+	SellOrders:       common.BigToHash(big.NewInt(SELL_ORDERS_STORAGE_SLOT)),
+	SellOrdersStatus: common.BigToHash(big.NewInt(SELL_ORDERS_STATUS_STORAGE_SLOT)),
+	SellOrderFlags:   common.BigToHash(big.NewInt(SELL_ORDER_FLAGS_STORAGE_SLOT)),
+	ConsumedFills:    common.BigToHash(big.NewInt(CONSUMED_FILLS_STORAGE_SLOT)),
+	PoolKey:          common.BigToHash(big.NewInt(POOL_KEY_STORAGE_SLOT)),
+}
+
+type MatchingPolicy struct {
+	Slots         StorageSlots
+	DustThreshold *uint256.Int
+	// MaxTradesPerInput caps the trades of a single input, 0 means no cap
+	MaxTradesPerInput int
+	// RetentionBlocks is how long cancelled and fulfilled orders are kept
+	// before being pruned, 0 keeps them forever
+	RetentionBlocks uint64
+	// SettlementTimeoutBlocks is how many blocks after its input a fill or a
+	// pool swap can be executed in, its quantity is released once the hook
+	// refuses it. 0 never expires them and waits for them forever.
+	SettlementTimeoutBlocks uint64
+	// ConfirmationDepthBlocks is how many blocks an order waits, queued, from
+	// the first input that sees it before it is matched, 0 matches it at once
+	ConfirmationDepthBlocks uint64
+	// ReorgDepthBlocks is how many blocks back a reorg is followed by rolling
+	// back the inputs read at the blocks it dropped, 0 does not follow reorgs
+	ReorgDepthBlocks uint64
+	// PoolManager holds the pools whose spot price the price bands follow and
+	// where the remainder of the orders flagged to route to the pool is swapped
+	PoolManager common.Address
+	// PriceBands are the bands of the hooks that have one, without their spot
+	PriceBands map[common.Address]*domain.PriceBand
+	// RouteToPool swaps the remainder of the orders flagged to route to the
+	// pool through it, it needs PoolManager
+	RouteToPool bool
+	// ReportRuntimeMemory adds the Go heap statistics to the state reports,
+	// which then differ between operators
+	ReportRuntimeMemory bool
+}
+
+// FillExpiryBlock is the last block the fills sent by an input of blockNumber
+// can be executed in, so that a fill not consumed past it never will be
+func (p *MatchingPolicy) FillExpiryBlock(blockNumber uint64) uint64 {
+	if p.SettlementTimeoutBlocks == 0 || blockNumber > math.MaxUint64-p.SettlementTimeoutBlocks {
+		return math.MaxUint64
+	}
+	return blockNumber + p.SettlementTimeoutBlocks
+}
+
+func DefaultMatchingPolicy() *MatchingPolicy {
+	return &MatchingPolicy{
+		Slots:                   DefaultStorageSlots,
+		DustThreshold:           uint256.NewInt(0),
+		SettlementTimeoutBlocks: DEFAULT_SETTLEMENT_TIMEOUT_BLOCKS,
+	}
+}
+
 type MatchOrdersUseCase struct {
 	OrderRepository     domain.OrderRepository
 	HookContractService service.OrderStorageServiceInterface
+	PoolStateService    service.PoolStateServiceInterface
+	Policy              *MatchingPolicy
 }
 
 type MatchOrdersInputDTO struct {
 	UnpackedArgs []interface{} `json:"unpacked_args"`
 }
 
+// MatchOrdersOutputDTO carries the spot price and the banded orders when the
+// hook has a price band and it kept orders out of the match
 type MatchOrdersOutputDTO struct {
-	Trades []*domain.Trade `json:"trades"`
+	Order   *domain.Order         `json:"order"`
+	Trades  []*domain.Trade       `json:"trades"`
+	Actions []*domain.Action      `json:"actions"`
+	Spot    *uint256.Int          `json:"spot,omitempty"`
+	Banded  []*domain.BandedOrder `json:"banded,omitempty"`
 }
 
-func NewMatchOrdersUseCase(orderRepository domain.OrderRepository, hookContractService service.OrderStorageServiceInterface) *MatchOrdersUseCase {
+func NewMatchOrdersUseCase(orderRepository domain.OrderRepository, hookContractService service.OrderStorageServiceInterface, poolStateService service.PoolStateServiceInterface, policy *MatchingPolicy) *MatchOrdersUseCase {
 	return &MatchOrdersUseCase{
 		OrderRepository:     orderRepository,
 		HookContractService: hookContractService,
+		PoolStateService:    poolStateService,
+		Policy:              policy,
 	}
 }
 
@@ -47,8 +140,8 @@ func (h *MatchOrdersUseCase) Execute(input *MatchOrdersInputDTO, metadata coproc
 	// Validate input
 	// -----------------------------------------------------------------------------
 
-	if len(input.UnpackedArgs) < 4 {
-		return nil, errors.New("invalid input: UnpackedArgs must have at least 4 elements")
+	if len(input.UnpackedArgs) < 5 {
+		return nil, errors.New("invalid input: UnpackedArgs must have at least 5 elements")
 	}
 
 	index, ok := input.UnpackedArgs[0].(*big.Int)
@@ -71,6 +164,11 @@ func (h *MatchOrdersUseCase) Execute(input *MatchOrdersInputDTO, metadata coproc
 		return nil, errors.New("invalid type for UnpackedArgs[3]: expected *big.Int")
 	}
 
+	flags, ok := input.UnpackedArgs[4].(*big.Int)
+	if !ok {
+		return nil, errors.New("invalid type for UnpackedArgs[4]: expected *big.Int")
+	}
+
 	// -----------------------------------------------------------------------------
 	// Create incoming order
 	// -----------------------------------------------------------------------------
@@ -94,96 +192,130 @@ func (h *MatchOrdersUseCase) Execute(input *MatchOrdersInputDTO, metadata coproc
 	if err != nil {
 		return nil, err
 	}
+	order.Flags = domain.OrderFlag(flags.Uint64())
+	order.Queue(metadata.BlockNumber, h.Policy.ConfirmationDepthBlocks)
 
 	if _, err = h.OrderRepository.CreateOrder(order); err != nil {
-		return nil, err
+		if err != domain.ErrOrderAlreadyExists {
+			return nil, err
+		}
+		// Already known from a previous input, keep the state this machine has for it
+		existing, err := h.OrderRepository.FindOrderById(order.Hook, orderType, order.Id)
+		if err != nil {
+			return nil, err
+		}
+		existing.Flags = order.Flags
+		order = existing
 	}
 
+	return h.match(metadata.MsgSender, metadata, order)
+}
+
+// match syncs the book with the storage of the hook at the block of the input,
+// opens the queued orders old enough, matches the book and plans the actions.
+// incoming is the order of the task, nil when the book is matched again
+// without a new order.
+func (h *MatchOrdersUseCase) match(hook common.Address, metadata coprocessor.Metadata, incoming *domain.Order) (*MatchOrdersOutputDTO, error) {
+	blockHash := common.HexToHash(metadata.BlockHash)
+
 	// -----------------------------------------------------------------------------
 	// Find all previous orders ( Base layer access )
 	// -----------------------------------------------------------------------------
 
 	buyOrders, err := h.HookContractService.FindOrdersBySlot(
-		metadata.MsgSender,
-		common.HexToHash(metadata.BlockHash),
-		common.BigToHash(big.NewInt(BUY_ORDERS_STORAGE_SLOT)),
-		common.BigToHash(big.NewInt(BUY_ORDERS_STATUS_STORAGE_SLOT)),
+		hook,
+		blockHash,
+		h.Policy.Slots.BuyOrders,
+		h.Policy.Slots.BuyOrdersStatus,
+		h.Policy.Slots.BuyOrderFlags,
 	)
 	if err != nil {
-		if err == domain.ErrNoOrdersFound {
-			return nil, fmt.Errorf("%v with type buy", err)
+		// An empty array is a hook without buy orders yet, anything else
+		// would match a partial book
+		if !errors.Is(err, service.ErrNoOrdersFound) {
+			return nil, fmt.Errorf("could not read the buy orders: %w", err)
 		}
 		buyOrders = nil
 	}
 
 	for _, buyOrder := range buyOrders {
 		buyOrder.Type = &domain.OrderTypeBuy
-		if _, err := h.OrderRepository.CreateOrder(buyOrder); err != nil {
+		if err := h.syncOrder(buyOrder, metadata.BlockNumber); err != nil {
 			return nil, err
 		}
 	}
 
 	sellOrders, err := h.HookContractService.FindOrdersBySlot(
-		metadata.MsgSender,
-		common.HexToHash(metadata.BlockHash),
-		common.BigToHash(big.NewInt(SELL_ORDERS_STORAGE_SLOT)),
-		common.BigToHash(big.NewInt(SELL_ORDERS_STATUS_STORAGE_SLOT)),
+		hook,
+		blockHash,
+		h.Policy.Slots.SellOrders,
+		h.Policy.Slots.SellOrdersStatus,
+		h.Policy.Slots.SellOrderFlags,
 	)
 	if err != nil {
-		if err == domain.ErrNoOrdersFound {
-			return nil, fmt.Errorf("%v with type sell", err)
+		// An empty array is a hook without sell orders yet, anything else
+		// would match a partial book
+		if !errors.Is(err, service.ErrNoOrdersFound) {
+			return nil, fmt.Errorf("could not read the sell orders: %w", err)
 		}
 		sellOrders = nil
 	}
 
 	for _, sellOrder := range sellOrders {
 		sellOrder.Type = &domain.OrderTypeSell
-		if _, err := h.OrderRepository.CreateOrder(sellOrder); err != nil {
+		if err := h.syncOrder(sellOrder, metadata.BlockNumber); err != nil {
 			return nil, err
 		}
 	}
 
+	confirmed, err := h.confirmQueuedOrders(hook, metadata.BlockNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	band, err := h.priceBand(hook, blockHash)
+	if err != nil {
+		return nil, err
+	}
+
 	// -----------------------------------------------------------------------------
 	// Match orders
 	// -----------------------------------------------------------------------------
 
 	orderBook := domain.NewOrderBook()
+	orderBook.MaxTrades = h.Policy.MaxTradesPerInput
+	orderBook.Band = band
 
-	bids, err := h.OrderRepository.FindOrdersByTypeAndStatus(domain.OrderTypeBuy, domain.OrderNotCancelledOrFulfilled)
-	if err != nil {
-		if err == domain.ErrNoOrdersFound {
-			return nil, fmt.Errorf("%v with type buy in memory", err)
-		}
+	// An empty side is not an error: an immediate-or-cancel order still has to be refunded
+	bids, err := h.OrderRepository.FindOrdersByTypeAndStatus(hook, domain.OrderTypeBuy, domain.OrderNotCancelledOrFulfilled)
+	if err != nil && err != domain.ErrNoOrdersFound {
 		return nil, err
 	}
 	for _, bid := range bids {
-		orderBook.Bids.Push(bid)
+		heap.Push(orderBook.Bids, bid)
 	}
 
-	asks, err := h.OrderRepository.FindOrdersByTypeAndStatus(domain.OrderTypeSell, domain.OrderNotCancelledOrFulfilled)
-	if err != nil {
-		if err == domain.ErrNoOrdersFound {
-			return nil, fmt.Errorf("%v with type sell in memory", err)
-		}
+	asks, err := h.OrderRepository.FindOrdersByTypeAndStatus(hook, domain.OrderTypeSell, domain.OrderNotCancelledOrFulfilled)
+	if err != nil && err != domain.ErrNoOrdersFound {
 		return nil, err
 	}
 	for _, ask := range asks {
-		orderBook.Asks.Push(ask)
+		heap.Push(orderBook.Asks, ask)
 	}
 
 	orders, err := h.OrderRepository.FindAllOrders()
-	if err != nil {
+	if err != nil && err != domain.ErrNoOrdersFound {
 		return nil, err
 	}
 
-	ordersBytes, err := json.Marshal(orders)
+	ordersBytes, err := domain.CanonicalState(orders)
 	if err != nil {
 		return nil, err
 	}
-	slog.Info("Current state before match", "info", string(ordersBytes))
+	slog.Debug("Current state before match", "info", string(ordersBytes))
 
 	trades, err := orderBook.MatchOrders()
-	if err != nil {
+	if err != nil && err != domain.ErrNoMatch {
 		return nil, err
 	}
 
@@ -191,9 +323,217 @@ func (h *MatchOrdersUseCase) Execute(input *MatchOrdersInputDTO, metadata coproc
 	if err != nil {
 		return nil, err
 	}
-	slog.Info("Selected trades", "info", string(tradesBytes))
+	slog.Debug("Selected trades", "info", string(tradesBytes))
+
+	// -----------------------------------------------------------------------------
+	// Plan refunds and transfers
+	// -----------------------------------------------------------------------------
+
+	var touched []*domain.Order
+	for _, trade := range trades {
+		bid, err := h.OrderRepository.FindOrderById(hook, domain.OrderTypeBuy, trade.BidId)
+		if err != nil {
+			return nil, err
+		}
+		ask, err := h.OrderRepository.FindOrderById(hook, domain.OrderTypeSell, trade.AskId)
+		if err != nil {
+			return nil, err
+		}
+		touched = append(touched, bid, ask)
+	}
+
+	takers := confirmed
+	if incoming != nil && !incoming.IsQueued() && !slices.Contains(takers, incoming) {
+		takers = append([]*domain.Order{incoming}, takers...)
+	}
+	var banded []*domain.BandedOrder
+	var rejected []*domain.Order
+	for _, order := range orderBook.Banded {
+		banded = append(banded, &domain.BandedOrder{Id: order.Id, Type: *order.Type, SqrtPrice: order.SqrtPrice, Mode: band.Mode})
+		if band.Mode == domain.BandModeReject {
+			rejected = append(rejected, order)
+		}
+	}
+	if len(banded) > 0 {
+		slog.Info("Orders kept out of the match by the price band", "banded", len(banded), "mode", band.Mode, "spot", band.Spot.Dec())
+	}
+
+	actions := domain.PlanRejections(rejected)
+	routes, routed, err := h.routeToPool(hook, blockHash, takers, orderBook.Banded)
+	if err != nil {
+		return nil, err
+	}
+	actions = append(actions, routes...)
+	touched = append(touched, routed...)
+	actions = append(actions, domain.PlanActions(takers, touched, h.Policy.DustThreshold)...)
+	if len(trades) == 0 && len(actions) == 0 && len(banded) == 0 {
+		return nil, domain.ErrNoMatch
+	}
+
+	actionsBytes, err := json.Marshal(actions)
+	if err != nil {
+		return nil, err
+	}
+	slog.Debug("Planned actions", "info", string(actionsBytes))
 
-	return &MatchOrdersOutputDTO{
-		Trades: trades,
-	}, nil
-}
\ No newline at end of file
+	output := &MatchOrdersOutputDTO{
+		Order:   incoming,
+		Trades:  trades,
+		Actions: actions,
+		Banded:  banded,
+	}
+	if len(banded) > 0 {
+		output.Spot = band.Spot
+	}
+	return output, nil
+}
+
+// priceBand returns the band of the hook around the spot price of its pool at
+// blockHash, nil when the hook has no band
+func (h *MatchOrdersUseCase) priceBand(hook common.Address, blockHash common.Hash) (*domain.PriceBand, error) {
+	policy, ok := h.Policy.PriceBands[hook]
+	if !ok {
+		return nil, nil
+	}
+	key, err := h.PoolStateService.FindPoolKey(hook, blockHash, h.Policy.Slots.PoolKey)
+	if err != nil {
+		return nil, err
+	}
+	spot, err := h.PoolStateService.FindSpotSqrtPrice(h.Policy.PoolManager, key.Id(), blockHash)
+	if err != nil {
+		return nil, err
+	}
+	// without a spot price no match is within the band, the orders that would
+	// match are held whatever the mode, since no side can be told to be off
+	if spot.IsZero() {
+		slog.Warn("Pool of the hook has no spot price, holding the crossed orders", "hook", hook.Hex(), "pool_id", key.Id().Hex())
+		return &domain.PriceBand{BandBps: policy.BandBps, Mode: domain.BandModeHold, Spot: spot}, nil
+	}
+	return &domain.PriceBand{BandBps: policy.BandBps, Mode: policy.Mode, Spot: spot}, nil
+}
+
+// routeToPool swaps through the pool of the hook the remainder the book left
+// to the takers flagged to route to the pool, as far as their price allows.
+// Orders a price band kept out of the match stay out, and each quote starts
+// from the pool the previous one leaves. It returns the swaps and the orders
+// they are for.
+func (h *MatchOrdersUseCase) routeToPool(hook common.Address, blockHash common.Hash, takers, banded []*domain.Order) ([]*domain.Action, []*domain.Order, error) {
+	if !h.Policy.RouteToPool {
+		return nil, nil, nil
+	}
+	var routable []*domain.Order
+	for _, taker := range takers {
+		if !taker.HasFlag(domain.OrderFlagRouteToPool) || taker.IsTerminal() || taker.Remaining().IsZero() || slices.Contains(banded, taker) {
+			continue
+		}
+		routable = append(routable, taker)
+	}
+	if len(routable) == 0 {
+		return nil, nil, nil
+	}
+
+	key, err := h.PoolStateService.FindPoolKey(hook, blockHash, h.Policy.Slots.PoolKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	pool, err := h.PoolStateService.FindPoolState(h.Policy.PoolManager, key.Id(), blockHash)
+	if err != nil {
+		return nil, nil, err
+	}
+	if pool.SqrtPriceX96.IsZero() {
+		slog.Warn("Pool of the hook is not initialized, nothing is routed to it", "hook", hook.Hex(), "pool_id", key.Id().Hex())
+		return nil, nil, nil
+	}
+	ticks := &poolTicks{service: h.PoolStateService, poolManager: h.Policy.PoolManager, poolId: key.Id(), blockHash: blockHash}
+
+	var actions []*domain.Action
+	var routed []*domain.Order
+	for _, order := range routable {
+		// buy orders escrow currency0, so they swap zeroForOne
+		quote, err := domain.QuoteExactInput(pool, key.TickSpacing, *order.Type == domain.OrderTypeBuy, order.Remaining(), order.SqrtPrice, ticks)
+		if err == domain.ErrPriceLimitOutOfRange {
+			slog.Warn("Order price is not a valid pool price, nothing is routed", "id", order.Id, "type", *order.Type)
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		if quote.AmountIn.IsZero() {
+			continue
+		}
+		slog.Info("Routing the remainder of the order to the pool", "id", order.Id, "type", *order.Type, "amount_in", quote.AmountIn.Dec(), "amount_out", quote.AmountOut.Dec())
+		actions = append(actions, domain.PlanPoolSwap(order, quote.AmountIn))
+		routed = append(routed, order)
+		pool = quote.State
+	}
+	return actions, routed, nil
+}
+
+// poolTicks reads the ticks of a pool at a block
+type poolTicks struct {
+	service     service.PoolStateServiceInterface
+	poolManager common.Address
+	poolId      common.Hash
+	blockHash   common.Hash
+}
+
+func (t *poolTicks) TickBitmap(wordPos int16) (*uint256.Int, error) {
+	return t.service.FindTickBitmap(t.poolManager, t.poolId, wordPos, t.blockHash)
+}
+
+func (t *poolTicks) LiquidityNet(tick int32) (*big.Int, error) {
+	return t.service.FindLiquidityNet(t.poolManager, t.poolId, tick, t.blockHash)
+}
+
+// confirmQueuedOrders opens the queued orders of hook that have waited the
+// confirmation depth at blockNumber and returns them
+func (h *MatchOrdersUseCase) confirmQueuedOrders(hook common.Address, blockNumber uint64) ([]*domain.Order, error) {
+	var confirmed []*domain.Order
+	for _, orderType := range []domain.OrderType{domain.OrderTypeBuy, domain.OrderTypeSell} {
+		queued, err := h.OrderRepository.FindOrdersByTypeAndStatus(hook, orderType, domain.OrderQueued)
+		if err != nil && err != domain.ErrNoOrdersFound {
+			return nil, err
+		}
+		for _, order := range queued {
+			if !order.Confirm(blockNumber, h.Policy.ConfirmationDepthBlocks) {
+				continue
+			}
+			if _, err := h.OrderRepository.UpdateOrder(order); err != nil {
+				return nil, err
+			}
+			confirmed = append(confirmed, order)
+		}
+	}
+	return confirmed, nil
+}
+
+// syncOrder stores an order read from the base layer, queued when it has to
+// wait for the confirmation depth. When the order is already known, fills
+// emitted by this machine are only reflected on chain once settled, so the
+// highest matched amount wins and a terminal status is never reopened.
+func (h *MatchOrdersUseCase) syncOrder(order *domain.Order, blockNumber uint64) error {
+	existing, err := h.OrderRepository.FindOrderById(order.Hook, *order.Type, order.Id)
+	if err != nil {
+		if err == domain.ErrOrderNotFound {
+			order.Queue(blockNumber, h.Policy.ConfirmationDepthBlocks)
+			_, err = h.OrderRepository.CreateOrder(order)
+		}
+		// Pruned orders are still in the hook storage, the tombstone keeps them out
+		if err == domain.ErrOrderPruned {
+			return nil
+		}
+		return err
+	}
+
+	existing.Amount = order.Amount
+	existing.Flags = order.Flags
+	if order.MatchedAmount.Cmp(existing.MatchedAmount) > 0 {
+		existing.MatchedAmount = order.MatchedAmount
+	}
+	if *order.Status == domain.OrderCancelledOrFulfilled {
+		existing.Status = order.Status
+	}
+
+	_, err = h.OrderRepository.UpdateOrder(existing)
+	return err
+}
@@ -0,0 +1,99 @@
+package usecase_test
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/henriquemarlon/swapx/configs"
+	"github.com/henriquemarlon/swapx/internal/domain"
+	"github.com/henriquemarlon/swapx/internal/infra/repository"
+	"github.com/henriquemarlon/swapx/internal/usecase"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubPool is a pool with a spot price and no liquidity to swap against
+type stubPool struct {
+	spot *uint256.Int
+}
+
+func (s *stubPool) FindPoolKey(hookAddress common.Address, blockHash, slot common.Hash) (*domain.PoolKey, error) {
+	return &domain.PoolKey{Currency1: common.HexToAddress("0x1"), Fee: 3000, TickSpacing: 60, Hooks: hookAddress}, nil
+}
+
+func (s *stubPool) FindSpotSqrtPrice(poolManager common.Address, poolId, blockHash common.Hash) (*uint256.Int, error) {
+	return s.spot, nil
+}
+
+func (s *stubPool) FindPoolState(poolManager common.Address, poolId, blockHash common.Hash) (*domain.PoolState, error) {
+	return nil, errors.New("no pool state")
+}
+
+func (s *stubPool) FindTickBitmap(poolManager common.Address, poolId common.Hash, wordPos int16, blockHash common.Hash) (*uint256.Int, error) {
+	return nil, errors.New("no ticks")
+}
+
+func (s *stubPool) FindLiquidityNet(poolManager common.Address, poolId common.Hash, tick int32, blockHash common.Hash) (*big.Int, error) {
+	return nil, errors.New("no ticks")
+}
+
+func TestMatchWithinThePriceBand(t *testing.T) {
+	// the bid at 120 crosses the ask at 110, the match is at the ask price
+	for _, tc := range []struct {
+		name   string
+		mode   domain.BandMode
+		spot   uint64
+		trades int
+		// banded lists the orders kept out of the match, refunded the ones
+		// rejected for it
+		banded   []domain.OrderType
+		refunded []domain.OrderType
+	}{
+		{name: "within the band", mode: domain.BandModeHold, spot: 108, trades: 1},
+		{name: "hold above the band", mode: domain.BandModeHold, spot: 100, banded: []domain.OrderType{domain.OrderTypeBuy}},
+		{name: "reject above the band", mode: domain.BandModeReject, spot: 100, banded: []domain.OrderType{domain.OrderTypeBuy}, refunded: []domain.OrderType{domain.OrderTypeBuy}},
+		{name: "reject below the band", mode: domain.BandModeReject, spot: 120, banded: []domain.OrderType{domain.OrderTypeSell}, refunded: []domain.OrderType{domain.OrderTypeSell}},
+		{name: "hold both sides without a spot price", mode: domain.BandModeReject, spot: 0, banded: []domain.OrderType{domain.OrderTypeBuy, domain.OrderTypeSell}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			db, err := configs.SetupInMemoryDB()
+			require.NoError(t, err)
+			orders := repository.NewOrderRepositoryInMemory(db)
+			storage := &stubHookStorage{
+				buy:  []stubOrder{{sqrtPrice: 120, amount: 10}},
+				sell: []stubOrder{{sqrtPrice: 110, amount: 10}},
+			}
+			policy := usecase.DefaultMatchingPolicy()
+			policy.PriceBands = map[common.Address]*domain.PriceBand{testHook: {BandBps: 500, Mode: tc.mode}}
+
+			rematch := usecase.NewRematchOrdersUseCase(orders, storage, &stubPool{spot: uint256.NewInt(tc.spot)}, policy)
+			output, err := rematch.Execute(&usecase.RematchOrdersInputDTO{Hook: testHook}, newTestMetadata(10))
+			require.NoError(t, err)
+
+			assert.Len(t, output.Trades, tc.trades)
+			banded := []domain.OrderType{}
+			for _, order := range output.Banded {
+				assert.Equal(t, tc.mode == domain.BandModeReject && tc.spot != 0, order.Mode == domain.BandModeReject)
+				banded = append(banded, order.Type)
+			}
+			assert.Equal(t, append([]domain.OrderType{}, tc.banded...), banded)
+
+			refunded := []domain.OrderType{}
+			for _, action := range output.Actions {
+				require.Equal(t, domain.ActionRefund, action.Kind)
+				refunded = append(refunded, *action.OrderType)
+			}
+			assert.Equal(t, append([]domain.OrderType{}, tc.refunded...), refunded)
+
+			// a held order stays open for a later match, a rejected one is closed
+			for _, orderType := range tc.banded {
+				order, err := orders.FindOrderById(testHook, orderType, 1)
+				require.NoError(t, err)
+				assert.Equal(t, len(tc.refunded) > 0, order.IsTerminal())
+			}
+		})
+	}
+}
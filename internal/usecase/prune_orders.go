@@ -0,0 +1,60 @@
+package usecase
+
+import (
+	"github.com/henriquemarlon/swapx/internal/domain"
+)
+
+type PruneOrdersUseCase struct {
+	OrderRepository domain.OrderRepository
+	RetentionBlocks uint64
+}
+
+type PruneOrdersOutputDTO struct {
+	Stamped int `json:"stamped"`
+	Pruned  int `json:"pruned"`
+}
+
+func NewPruneOrdersUseCase(orderRepository domain.OrderRepository, retentionBlocks uint64) *PruneOrdersUseCase {
+	return &PruneOrdersUseCase{
+		OrderRepository: orderRepository,
+		RetentionBlocks: retentionBlocks,
+	}
+}
+
+// Execute stamps the orders that became terminal with the current block and
+// prunes the ones that have been terminal for RetentionBlocks. It only depends
+// on the block numbers of the inputs, so every operator prunes the same orders.
+func (p *PruneOrdersUseCase) Execute(blockNumber uint64) (*PruneOrdersOutputDTO, error) {
+	output := &PruneOrdersOutputDTO{}
+	if p.RetentionBlocks == 0 {
+		return output, nil
+	}
+
+	orders, err := p.OrderRepository.FindAllOrders()
+	if err != nil && err != domain.ErrNoOrdersFound {
+		return nil, err
+	}
+
+	for _, order := range orders {
+		if !order.IsTerminal() {
+			continue
+		}
+
+		if order.TerminalSince == 0 {
+			order.TerminalSince = blockNumber
+			if _, err := p.OrderRepository.UpdateOrder(order); err != nil {
+				return nil, err
+			}
+			output.Stamped++
+			continue
+		}
+
+		if blockNumber >= order.TerminalSince+p.RetentionBlocks {
+			if err := p.OrderRepository.DeleteOrder(order.Hook, *order.Type, order.Id); err != nil {
+				return nil, err
+			}
+			output.Pruned++
+		}
+	}
+	return output, nil
+}
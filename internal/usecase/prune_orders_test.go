@@ -0,0 +1,66 @@
+package usecase_test
+
+import (
+	"testing"
+
+	"github.com/henriquemarlon/swapx/configs"
+	"github.com/henriquemarlon/swapx/internal/domain"
+	"github.com/henriquemarlon/swapx/internal/infra/repository"
+	"github.com/henriquemarlon/swapx/internal/usecase"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPruneOrders(t *testing.T) {
+	// the filled bid 1 is first seen terminal at block 10, the bid 2 stays open
+	for _, tc := range []struct {
+		name       string
+		retention  uint64
+		blocks     []uint64
+		stamped    int
+		pruned     int
+		terminalAt uint64
+	}{
+		{name: "kept forever without retention", retention: 0, blocks: []uint64{10, 100}},
+		{name: "stamped by the first input", retention: 5, blocks: []uint64{10}, stamped: 1, terminalAt: 10},
+		{name: "kept until the retention elapsed", retention: 5, blocks: []uint64{10, 14}, stamped: 1, terminalAt: 10},
+		{name: "pruned once the retention elapsed", retention: 5, blocks: []uint64{10, 15}, stamped: 1, pruned: 1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			db, err := configs.SetupInMemoryDB()
+			require.NoError(t, err)
+			orders := repository.NewOrderRepositoryInMemory(db)
+			newTestBookOrder(t, orders, 1, &domain.OrderTypeBuy, 10, 10)
+			newTestBookOrder(t, orders, 2, &domain.OrderTypeBuy, 10, 0)
+
+			prune := usecase.NewPruneOrdersUseCase(orders, tc.retention)
+			stamped, pruned := 0, 0
+			for _, block := range tc.blocks {
+				output, err := prune.Execute(block)
+				require.NoError(t, err)
+				stamped += output.Stamped
+				pruned += output.Pruned
+			}
+			assert.Equal(t, tc.stamped, stamped)
+			assert.Equal(t, tc.pruned, pruned)
+
+			open, err := orders.FindOrderById(testHook, domain.OrderTypeBuy, 2)
+			require.NoError(t, err)
+			assert.Zero(t, open.TerminalSince)
+
+			filled, err := orders.FindOrderById(testHook, domain.OrderTypeBuy, 1)
+			if tc.pruned > 0 {
+				assert.Equal(t, domain.ErrOrderNotFound, err)
+				// the tombstone keeps it from being read again from the hook
+				order, err := domain.NewOrder(1, testHook, uint256.NewInt(100), uint256.NewInt(10), uint256.NewInt(10), &domain.OrderTypeBuy, &domain.OrderCancelledOrFulfilled)
+				require.NoError(t, err)
+				_, err = orders.CreateOrder(order)
+				assert.Equal(t, domain.ErrOrderPruned, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.terminalAt, filled.TerminalSince)
+		})
+	}
+}
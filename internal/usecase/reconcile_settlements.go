@@ -0,0 +1,249 @@
+package usecase
+
+import (
+	"log/slog"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/henriquemarlon/swapx/internal/domain"
+	"github.com/henriquemarlon/swapx/internal/infra/service"
+	"github.com/henriquemarlon/swapx/pkg/coprocessor"
+	"github.com/holiman/uint256"
+)
+
+// ReconcileSettlementsUseCase follows the fills sent to a hook. A fill is
+// confirmed once the hook has consumed it. A fill still not consumed past its
+// expiry block reverted or was never executed, and the hook refuses it from
+// then on, so its quantity is released and the next match plans it again.
+// The matched amounts cannot tell which of several fills of an order is
+// missing, so they are only read to report the failure.
+//
+// A swap through the pool has no id on chain and may take less than quoted, so
+// it is followed by the matched amount of its order instead. It is confirmed
+// once the chain shows the amount the match left on the order, and past its
+// expiry block whatever the chain still lacks of it is released.
+type ReconcileSettlementsUseCase struct {
+	OrderRepository      domain.OrderRepository
+	SettlementRepository domain.SettlementRepository
+	HookContractService  service.OrderStorageServiceInterface
+	Policy               *MatchingPolicy
+}
+
+type ReconcileSettlementsInputDTO struct {
+	Hook common.Address `json:"hook"`
+}
+
+// FailedFill puts the matched amounts a fill was expected to leave on its
+// orders next to the ones the chain shows
+type FailedFill struct {
+	FillId             common.Hash  `json:"fill_id"`
+	BidId              uint64       `json:"bid_id"`
+	AskId              uint64       `json:"ask_id"`
+	Quantity           *uint256.Int `json:"quantity"`
+	SentAt             uint64       `json:"sent_at"`
+	ExpectedBidMatched *uint256.Int `json:"expected_bid_matched"`
+	ChainBidMatched    *uint256.Int `json:"chain_bid_matched"`
+	ExpectedAskMatched *uint256.Int `json:"expected_ask_matched"`
+	ChainAskMatched    *uint256.Int `json:"chain_ask_matched"`
+}
+
+// FailedPoolSwap is a swap the chain shows less of than was sent once it
+// expired, Released is the part of it given back to the order
+type FailedPoolSwap struct {
+	TaskId          common.Hash      `json:"task_id"`
+	OrderType       domain.OrderType `json:"order_type"`
+	OrderId         uint64           `json:"order_id"`
+	AmountIn        *uint256.Int     `json:"amount_in"`
+	Released        *uint256.Int     `json:"released"`
+	SentAt          uint64           `json:"sent_at"`
+	ExpectedMatched *uint256.Int     `json:"expected_matched"`
+	ChainMatched    *uint256.Int     `json:"chain_matched"`
+}
+
+type ReconcileSettlementsOutputDTO struct {
+	Confirmed      int               `json:"confirmed"`
+	Failed         []*FailedFill     `json:"failed"`
+	ConfirmedSwaps int               `json:"confirmed_swaps"`
+	FailedSwaps    []*FailedPoolSwap `json:"failed_swaps"`
+}
+
+func NewReconcileSettlementsUseCase(orderRepository domain.OrderRepository, settlementRepository domain.SettlementRepository, hookContractService service.OrderStorageServiceInterface, policy *MatchingPolicy) *ReconcileSettlementsUseCase {
+	return &ReconcileSettlementsUseCase{
+		OrderRepository:      orderRepository,
+		SettlementRepository: settlementRepository,
+		HookContractService:  hookContractService,
+		Policy:               policy,
+	}
+}
+
+// Execute runs before the book is synced, so a released order is capped again
+// by what the chain shows and one the chain has closed stays closed.
+func (u *ReconcileSettlementsUseCase) Execute(input *ReconcileSettlementsInputDTO, metadata coprocessor.Metadata) (*ReconcileSettlementsOutputDTO, error) {
+	output := &ReconcileSettlementsOutputDTO{}
+	fills, err := u.SettlementRepository.FindPendingFills(input.Hook)
+	if err != nil {
+		return nil, err
+	}
+	swaps, err := u.SettlementRepository.FindPendingPoolSwaps(input.Hook)
+	if err != nil {
+		return nil, err
+	}
+
+	blockHash := common.HexToHash(metadata.BlockHash)
+	for _, fill := range fills {
+		consumed, err := u.HookContractService.IsFillConsumed(input.Hook, fill.FillId, blockHash, u.Policy.Slots.ConsumedFills)
+		if err != nil {
+			return nil, err
+		}
+		// the storage is the one of the block before the input, the fill can
+		// still execute in the block of the input up to its expiry
+		if !consumed && metadata.BlockNumber <= fill.ExpiryBlock {
+			continue
+		}
+
+		if consumed {
+			output.Confirmed++
+		} else {
+			failed, err := u.release(input.Hook, blockHash, fill, swaps)
+			if err != nil {
+				return nil, err
+			}
+			slog.Warn("Fill was not executed, releasing its quantity", "fill_id", fill.FillId.Hex(), "bid", fill.BidId, "ask", fill.AskId, "quantity", fill.Quantity.Dec(), "sent_at", fill.SentAt)
+			output.Failed = append(output.Failed, failed)
+		}
+		if err := u.SettlementRepository.DeletePendingFill(fill.FillId); err != nil {
+			return nil, err
+		}
+	}
+
+	// the range reads each swap as it gets to it, so it sees the lowered ones
+	for i, swap := range swaps {
+		slot := u.Policy.Slots.SellOrders
+		if swap.OrderType == domain.OrderTypeBuy {
+			slot = u.Policy.Slots.BuyOrders
+		}
+		matched, err := u.HookContractService.FindOrderMatchedAmount(input.Hook, new(big.Int).SetUint64(swap.OrderId-1), blockHash, slot)
+		if err != nil {
+			return nil, err
+		}
+
+		if !matched.Lt(swap.Matched) {
+			output.ConfirmedSwaps++
+		} else if metadata.BlockNumber <= swap.ExpiryBlock {
+			continue
+		} else {
+			failed, err := u.releaseSwap(input.Hook, swap, matched, swaps[i+1:])
+			if err != nil {
+				return nil, err
+			}
+			slog.Warn("Pool swap was not executed in full, releasing what the chain lacks", "task_id", swap.TaskId.Hex(), "id", swap.OrderId, "type", swap.OrderType, "amount_in", swap.AmountIn.Dec(), "released", failed.Released.Dec(), "sent_at", swap.SentAt)
+			output.FailedSwaps = append(output.FailedSwaps, failed)
+		}
+		if err := u.SettlementRepository.DeletePendingPoolSwap(swap); err != nil {
+			return nil, err
+		}
+	}
+	return output, nil
+}
+
+// release gives the quantity of the fill back to both orders, an order pruned
+// in the meantime is left alone
+func (u *ReconcileSettlementsUseCase) release(hook common.Address, blockHash common.Hash, fill *domain.PendingFill, swaps []*domain.PendingPoolSwap) (*FailedFill, error) {
+	failed := &FailedFill{
+		FillId:             fill.FillId,
+		BidId:              fill.BidId,
+		AskId:              fill.AskId,
+		Quantity:           fill.Quantity,
+		SentAt:             fill.SentAt,
+		ExpectedBidMatched: fill.BidMatched,
+		ExpectedAskMatched: fill.AskMatched,
+	}
+
+	for _, side := range []struct {
+		orderType domain.OrderType
+		id        uint64
+		slot      common.Hash
+		chain     **uint256.Int
+	}{
+		{domain.OrderTypeBuy, fill.BidId, u.Policy.Slots.BuyOrders, &failed.ChainBidMatched},
+		{domain.OrderTypeSell, fill.AskId, u.Policy.Slots.SellOrders, &failed.ChainAskMatched},
+	} {
+		// order ids are 1-based, the hook arrays are not
+		matched, err := u.HookContractService.FindOrderMatchedAmount(hook, new(big.Int).SetUint64(side.id-1), blockHash, side.slot)
+		if err != nil {
+			return nil, err
+		}
+		*side.chain = matched
+		if err := u.lowerPendingSwaps(swaps, side.orderType, side.id, fill.SentAt, fill.Quantity); err != nil {
+			return nil, err
+		}
+
+		order, err := u.OrderRepository.FindOrderById(hook, side.orderType, side.id)
+		if err != nil {
+			if err == domain.ErrOrderNotFound {
+				continue
+			}
+			return nil, err
+		}
+		domain.ReleaseFill(order, fill.Quantity)
+		if _, err := u.OrderRepository.UpdateOrder(order); err != nil {
+			return nil, err
+		}
+	}
+	return failed, nil
+}
+
+// releaseSwap gives back to the order what the chain lacks of the matched
+// amount the swap expected, at most the amount sent to the pool
+func (u *ReconcileSettlementsUseCase) releaseSwap(hook common.Address, swap *domain.PendingPoolSwap, matched *uint256.Int, later []*domain.PendingPoolSwap) (*FailedPoolSwap, error) {
+	released := new(uint256.Int).Sub(swap.Matched, matched)
+	if released.Gt(swap.AmountIn) {
+		released = swap.AmountIn
+	}
+	failed := &FailedPoolSwap{
+		TaskId:          swap.TaskId,
+		OrderType:       swap.OrderType,
+		OrderId:         swap.OrderId,
+		AmountIn:        swap.AmountIn,
+		Released:        released,
+		SentAt:          swap.SentAt,
+		ExpectedMatched: swap.Matched,
+		ChainMatched:    matched,
+	}
+	if err := u.lowerPendingSwaps(later, swap.OrderType, swap.OrderId, swap.SentAt, released); err != nil {
+		return nil, err
+	}
+
+	order, err := u.OrderRepository.FindOrderById(hook, swap.OrderType, swap.OrderId)
+	if err != nil {
+		if err == domain.ErrOrderNotFound {
+			return failed, nil
+		}
+		return nil, err
+	}
+	domain.ReleaseFill(order, released)
+	if _, err := u.OrderRepository.UpdateOrder(order); err != nil {
+		return nil, err
+	}
+	return failed, nil
+}
+
+// lowerPendingSwaps takes a released quantity off the matched amount the swaps
+// of the order sent from sentAt on expect, since the chain will never show it
+func (u *ReconcileSettlementsUseCase) lowerPendingSwaps(swaps []*domain.PendingPoolSwap, orderType domain.OrderType, id uint64, sentAt uint64, quantity *uint256.Int) error {
+	for i, swap := range swaps {
+		if swap.OrderType != orderType || swap.OrderId != id || swap.SentAt < sentAt {
+			continue
+		}
+		lowered := *swap
+		lowered.Matched = new(uint256.Int)
+		if swap.Matched.Gt(quantity) {
+			lowered.Matched.Sub(swap.Matched, quantity)
+		}
+		if err := u.SettlementRepository.UpdatePendingPoolSwap(&lowered); err != nil {
+			return err
+		}
+		swaps[i] = &lowered
+	}
+	return nil
+}
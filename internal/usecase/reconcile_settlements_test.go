@@ -0,0 +1,296 @@
+// The use case tests run against the in-memory repositories, which import this
+// package through configs, so they live in an external test package.
+package usecase_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/henriquemarlon/swapx/configs"
+	"github.com/henriquemarlon/swapx/internal/domain"
+	"github.com/henriquemarlon/swapx/internal/infra/repository"
+	"github.com/henriquemarlon/swapx/internal/infra/service"
+	"github.com/henriquemarlon/swapx/internal/usecase"
+	"github.com/henriquemarlon/swapx/pkg/coprocessor"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testHook = common.HexToAddress("0x00000000000000000000000000000000000000aa")
+
+// stubOrder is an order as the hook stores it
+type stubOrder struct {
+	sqrtPrice, amount, matched uint64
+	cancelled                  bool
+	flags                      domain.OrderFlag
+}
+
+// stubHookStorage answers for the storage of testHook at any block: the order
+// arrays of both sides by 0-based index and the fills the hook consumed
+type stubHookStorage struct {
+	buy, sell []stubOrder
+	consumed  map[common.Hash]bool
+}
+
+var _ service.OrderStorageServiceInterface = (*stubHookStorage)(nil)
+
+func (s *stubHookStorage) side(slot common.Hash) []stubOrder {
+	if slot == usecase.DefaultStorageSlots.BuyOrders || slot == usecase.DefaultStorageSlots.BuyOrdersStatus {
+		return s.buy
+	}
+	return s.sell
+}
+
+func (s *stubHookStorage) FindOrderStatus(hookAddress common.Address, orderId *big.Int, blockHash, slot common.Hash) (*bool, error) {
+	cancelled := s.side(slot)[orderId.Uint64()].cancelled
+	return &cancelled, nil
+}
+
+func (s *stubHookStorage) FindOrdersBySlot(hookAddress common.Address, blockHash, ordersSlot, statusSlot, flagsSlot common.Hash) ([]*domain.Order, error) {
+	stored := s.side(ordersSlot)
+	if len(stored) == 0 {
+		return nil, service.ErrNoOrdersFound
+	}
+	orders := make([]*domain.Order, 0, len(stored))
+	for i, o := range stored {
+		status := domain.OrderNotCancelledOrFulfilled
+		if o.cancelled || o.matched == o.amount {
+			status = domain.OrderCancelledOrFulfilled
+		}
+		order, err := domain.NewOrder(uint64(i+1), hookAddress, uint256.NewInt(o.sqrtPrice), uint256.NewInt(o.amount), uint256.NewInt(o.matched), nil, &status)
+		if err != nil {
+			return nil, err
+		}
+		order.Flags = o.flags
+		orders = append(orders, order)
+	}
+	return orders, nil
+}
+
+func (s *stubHookStorage) FindOrderMatchedAmount(hookAddress common.Address, orderId *big.Int, blockHash, ordersSlot common.Hash) (*uint256.Int, error) {
+	return uint256.NewInt(s.side(ordersSlot)[orderId.Uint64()].matched), nil
+}
+
+func (s *stubHookStorage) IsFillConsumed(hookAddress common.Address, fillId, blockHash, slot common.Hash) (bool, error) {
+	return s.consumed[fillId], nil
+}
+
+func newTestMetadata(blockNumber uint64) coprocessor.Metadata {
+	return coprocessor.Metadata{
+		MsgSender:   testHook,
+		BlockNumber: blockNumber,
+		BlockHash:   common.BigToHash(new(big.Int).SetUint64(blockNumber)).Hex(),
+	}
+}
+
+// newTestBookOrder stores an order of testHook in the book
+func newTestBookOrder(t *testing.T, orders domain.OrderRepository, id uint64, orderType *domain.OrderType, amount, matched uint64) {
+	status := domain.OrderNotCancelledOrFulfilled
+	if matched == amount {
+		status = domain.OrderCancelledOrFulfilled
+	}
+	order, err := domain.NewOrder(id, testHook, uint256.NewInt(100), uint256.NewInt(amount), uint256.NewInt(matched), orderType, &status)
+	require.NoError(t, err)
+	_, err = orders.CreateOrder(order)
+	require.NoError(t, err)
+}
+
+func TestReconcileFills(t *testing.T) {
+	fill := &domain.PendingFill{
+		FillId:      common.HexToHash("0xf1"),
+		Hook:        testHook,
+		BidId:       1,
+		AskId:       1,
+		Quantity:    uint256.NewInt(10),
+		BidMatched:  uint256.NewInt(10),
+		AskMatched:  uint256.NewInt(10),
+		SentAt:      5,
+		ExpiryBlock: 10,
+	}
+
+	for _, tc := range []struct {
+		name        string
+		blockNumber uint64
+		consumed    bool
+		pruneBid    bool
+		confirmed   int
+		failed      int
+		pending     int
+		// askMatched is the matched amount the book is left with for the ask
+		askMatched uint64
+	}{
+		{name: "pending before expiry", blockNumber: 9, pending: 1, askMatched: 10},
+		{name: "pending at the expiry block", blockNumber: 10, pending: 1, askMatched: 10},
+		{name: "released past the expiry block", blockNumber: 11, failed: 1, askMatched: 0},
+		{name: "confirmed at the expiry block", blockNumber: 10, consumed: true, confirmed: 1, askMatched: 10},
+		{name: "confirmed past the expiry block", blockNumber: 11, consumed: true, confirmed: 1, askMatched: 10},
+		{name: "released after the bid was pruned", blockNumber: 11, pruneBid: true, failed: 1, askMatched: 0},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			db, err := configs.SetupInMemoryDB()
+			require.NoError(t, err)
+			orders := repository.NewOrderRepositoryInMemory(db)
+			settlements := repository.NewSettlementRepositoryInMemory(db)
+			newTestBookOrder(t, orders, 1, &domain.OrderTypeBuy, 10, 10)
+			newTestBookOrder(t, orders, 1, &domain.OrderTypeSell, 10, 10)
+			if tc.pruneBid {
+				require.NoError(t, orders.DeleteOrder(testHook, domain.OrderTypeBuy, 1))
+			}
+			pending := *fill
+			require.NoError(t, settlements.CreatePendingFill(&pending))
+
+			storage := &stubHookStorage{
+				buy:      []stubOrder{{sqrtPrice: 100, amount: 10}},
+				sell:     []stubOrder{{sqrtPrice: 100, amount: 10}},
+				consumed: map[common.Hash]bool{fill.FillId: tc.consumed},
+			}
+			if tc.consumed {
+				storage.buy[0].matched, storage.sell[0].matched = 10, 10
+			}
+
+			reconcile := usecase.NewReconcileSettlementsUseCase(orders, settlements, storage, usecase.DefaultMatchingPolicy())
+			output, err := reconcile.Execute(&usecase.ReconcileSettlementsInputDTO{Hook: testHook}, newTestMetadata(tc.blockNumber))
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.confirmed, output.Confirmed)
+			require.Len(t, output.Failed, tc.failed)
+			if tc.failed > 0 {
+				assert.Equal(t, uint256.NewInt(0), output.Failed[0].ChainBidMatched)
+				assert.Equal(t, uint256.NewInt(10), output.Failed[0].ExpectedBidMatched)
+			}
+			count, err := settlements.CountPendingFills()
+			require.NoError(t, err)
+			assert.Equal(t, tc.pending, count)
+
+			ask, err := orders.FindOrderById(testHook, domain.OrderTypeSell, 1)
+			require.NoError(t, err)
+			assert.Equal(t, uint256.NewInt(tc.askMatched), ask.MatchedAmount)
+			assert.Equal(t, tc.askMatched == 0, !ask.IsTerminal())
+
+			bid, err := orders.FindOrderById(testHook, domain.OrderTypeBuy, 1)
+			if tc.pruneBid {
+				assert.Equal(t, domain.ErrOrderNotFound, err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, uint256.NewInt(tc.askMatched), bid.MatchedAmount)
+			}
+		})
+	}
+}
+
+func TestReconcilePoolSwapsAfterAnEarlierRelease(t *testing.T) {
+	swap := func(taskId string, sentAt, amountIn, matched uint64) *domain.PendingPoolSwap {
+		return &domain.PendingPoolSwap{
+			Hook:        testHook,
+			TaskId:      common.HexToHash(taskId),
+			OrderType:   domain.OrderTypeBuy,
+			OrderId:     1,
+			AmountIn:    uint256.NewInt(amountIn),
+			Matched:     uint256.NewInt(matched),
+			SentAt:      sentAt,
+			ExpiryBlock: sentAt + 5,
+		}
+	}
+
+	for _, tc := range []struct {
+		name        string
+		fill        bool
+		swaps       []*domain.PendingPoolSwap
+		bookMatched uint64
+		// chainMatched is the matched amount of the bid the hook shows
+		chainMatched uint64
+		blockNumber  uint64
+		confirmed    int
+		released     []uint64
+		// pending are the matched amounts the swaps left pending expect
+		pending   []uint64
+		bookAfter uint64
+	}{
+		{
+			name:         "a released fill lowers the swaps sent after it",
+			fill:         true,
+			swaps:        []*domain.PendingPoolSwap{swap("0xa", 4, 5, 5), swap("0xb", 6, 5, 20)},
+			bookMatched:  20,
+			chainMatched: 10,
+			blockNumber:  11,
+			confirmed:    2,
+			bookAfter:    10,
+		},
+		{
+			name:         "a released fill leaves the swaps sent before it",
+			fill:         true,
+			swaps:        []*domain.PendingPoolSwap{swap("0xa", 4, 5, 5), swap("0xb", 6, 5, 20)},
+			bookMatched:  20,
+			chainMatched: 5,
+			blockNumber:  11,
+			confirmed:    1,
+			pending:      []uint64{10},
+			bookAfter:    10,
+		},
+		{
+			name:         "a released swap lowers the swaps sent after it",
+			swaps:        []*domain.PendingPoolSwap{swap("0xa", 4, 5, 5), swap("0xb", 6, 5, 10)},
+			bookMatched:  10,
+			chainMatched: 0,
+			blockNumber:  10,
+			released:     []uint64{5},
+			pending:      []uint64{5},
+			bookAfter:    5,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			db, err := configs.SetupInMemoryDB()
+			require.NoError(t, err)
+			orders := repository.NewOrderRepositoryInMemory(db)
+			settlements := repository.NewSettlementRepositoryInMemory(db)
+			newTestBookOrder(t, orders, 1, &domain.OrderTypeBuy, 30, tc.bookMatched)
+			newTestBookOrder(t, orders, 1, &domain.OrderTypeSell, 30, 10)
+			if tc.fill {
+				require.NoError(t, settlements.CreatePendingFill(&domain.PendingFill{
+					FillId:      common.HexToHash("0xf1"),
+					Hook:        testHook,
+					BidId:       1,
+					AskId:       1,
+					Quantity:    uint256.NewInt(10),
+					BidMatched:  uint256.NewInt(15),
+					AskMatched:  uint256.NewInt(10),
+					SentAt:      5,
+					ExpiryBlock: 10,
+				}))
+			}
+			for _, s := range tc.swaps {
+				pending := *s
+				require.NoError(t, settlements.CreatePendingPoolSwap(&pending))
+			}
+
+			storage := &stubHookStorage{
+				buy:  []stubOrder{{sqrtPrice: 100, amount: 30, matched: tc.chainMatched}},
+				sell: []stubOrder{{sqrtPrice: 100, amount: 30}},
+			}
+			reconcile := usecase.NewReconcileSettlementsUseCase(orders, settlements, storage, usecase.DefaultMatchingPolicy())
+			output, err := reconcile.Execute(&usecase.ReconcileSettlementsInputDTO{Hook: testHook}, newTestMetadata(tc.blockNumber))
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.confirmed, output.ConfirmedSwaps)
+			released := []uint64{}
+			for _, failed := range output.FailedSwaps {
+				released = append(released, failed.Released.Uint64())
+			}
+			assert.Equal(t, append([]uint64{}, tc.released...), released)
+
+			swaps, err := settlements.FindPendingPoolSwaps(testHook)
+			require.NoError(t, err)
+			pending := []uint64{}
+			for _, s := range swaps {
+				pending = append(pending, s.Matched.Uint64())
+			}
+			assert.Equal(t, append([]uint64{}, tc.pending...), pending)
+
+			bid, err := orders.FindOrderById(testHook, domain.OrderTypeBuy, 1)
+			require.NoError(t, err)
+			assert.Equal(t, uint256.NewInt(tc.bookAfter), bid.MatchedAmount)
+		})
+	}
+}
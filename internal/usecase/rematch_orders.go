@@ -0,0 +1,30 @@
+package usecase
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/henriquemarlon/swapx/internal/domain"
+	"github.com/henriquemarlon/swapx/internal/infra/service"
+	"github.com/henriquemarlon/swapx/pkg/coprocessor"
+)
+
+// RematchOrdersUseCase matches the book of a hook without a new order, for a
+// crossed book that was left unmatched by a failed task
+type RematchOrdersUseCase struct {
+	match *MatchOrdersUseCase
+}
+
+type RematchOrdersInputDTO struct {
+	Hook common.Address `json:"hook"`
+}
+
+func NewRematchOrdersUseCase(orderRepository domain.OrderRepository, hookContractService service.OrderStorageServiceInterface, poolStateService service.PoolStateServiceInterface, policy *MatchingPolicy) *RematchOrdersUseCase {
+	return &RematchOrdersUseCase{
+		match: NewMatchOrdersUseCase(orderRepository, hookContractService, poolStateService, policy),
+	}
+}
+
+// Execute returns an output without an order, or domain.ErrNoMatch when the
+// book is not crossed
+func (u *RematchOrdersUseCase) Execute(input *RematchOrdersInputDTO, metadata coprocessor.Metadata) (*MatchOrdersOutputDTO, error) {
+	return u.match.match(input.Hook, metadata, nil)
+}
@@ -0,0 +1,56 @@
+package usecase_test
+
+import (
+	"testing"
+
+	"github.com/henriquemarlon/swapx/configs"
+	"github.com/henriquemarlon/swapx/internal/domain"
+	"github.com/henriquemarlon/swapx/internal/infra/repository"
+	"github.com/henriquemarlon/swapx/internal/usecase"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRematchOrders(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		buy  []stubOrder
+		sell []stubOrder
+		// trades are the quantities matched, nil when the book is not crossed
+		trades []uint64
+	}{
+		{name: "empty book", trades: nil},
+		{name: "book not crossed", buy: []stubOrder{{sqrtPrice: 100, amount: 10}}, sell: []stubOrder{{sqrtPrice: 110, amount: 10}}},
+		{name: "crossed book", buy: []stubOrder{{sqrtPrice: 110, amount: 10}}, sell: []stubOrder{{sqrtPrice: 100, amount: 4}}, trades: []uint64{4}},
+		{name: "cancelled orders left out", buy: []stubOrder{{sqrtPrice: 110, amount: 10, cancelled: true}}, sell: []stubOrder{{sqrtPrice: 100, amount: 4}}},
+		{name: "matched part left out", buy: []stubOrder{{sqrtPrice: 110, amount: 10, matched: 8}}, sell: []stubOrder{{sqrtPrice: 100, amount: 4}}, trades: []uint64{2}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			db, err := configs.SetupInMemoryDB()
+			require.NoError(t, err)
+			orders := repository.NewOrderRepositoryInMemory(db)
+			storage := &stubHookStorage{buy: tc.buy, sell: tc.sell}
+
+			rematch := usecase.NewRematchOrdersUseCase(orders, storage, &stubPool{}, usecase.DefaultMatchingPolicy())
+			output, err := rematch.Execute(&usecase.RematchOrdersInputDTO{Hook: testHook}, newTestMetadata(10))
+			if tc.trades == nil {
+				assert.Equal(t, domain.ErrNoMatch, err)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Nil(t, output.Order)
+			quantities := []uint64{}
+			for _, trade := range output.Trades {
+				quantities = append(quantities, trade.Quantity.Uint64())
+			}
+			assert.Equal(t, tc.trades, quantities)
+
+			// the orders read from the hook are kept in the book with the match applied
+			bid, err := orders.FindOrderById(testHook, domain.OrderTypeBuy, 1)
+			require.NoError(t, err)
+			assert.Equal(t, uint256.NewInt(tc.buy[0].matched+tc.trades[0]), bid.MatchedAmount)
+		})
+	}
+}
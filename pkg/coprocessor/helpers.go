@@ -3,13 +3,21 @@ package coprocessor
 import (
 	"bytes"
 	"encoding/json"
-	"log"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
 	"os"
 )
 
 var ROLLUP_HTTP_SERVER_URL = os.Getenv("ROLLUP_HTTP_SERVER_URL")
 
+// ErrInvalidRequest is returned when the rollup server answered the finish
+// request with something that is not an EvmAdvance input. The finish request
+// was delivered, so the input has to be rejected on the next one.
+var ErrInvalidRequest = errors.New("invalid rollup request")
+
 func SendPost(endpoint string, jsonData []byte) (*http.Response, error) {
 	req, err := http.NewRequest(http.MethodPost, ROLLUP_HTTP_SERVER_URL+"/"+endpoint, bytes.NewBuffer(jsonData))
 	if err != nil {
@@ -28,17 +36,83 @@ func SendFinish(finish *FinishRequest) (*http.Response, error) {
 	return SendPost("finish", body)
 }
 
+// FetchRequest sends the finish request of the previous input and parses the
+// next request, an inspect_state one or an EvmAdvance input. It returns nil
+// without an error when there is no pending request.
+func FetchRequest(finish *FinishRequest) (*RollupRequest, error) {
+	res, err := SendFinish(finish)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusAccepted {
+		return nil, nil
+	}
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: could not read response body: %v", ErrInvalidRequest, err)
+	}
+
+	var finishResponse FinishResponse
+	if err := json.Unmarshal(body, &finishResponse); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRequest, err)
+	}
+
+	var rawPayload struct {
+		Data string `json:"payload"`
+	}
+	if err := json.Unmarshal(finishResponse.Data, &rawPayload); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRequest, err)
+	}
+
+	if finishResponse.Type == INSPECT_STATE {
+		return &RollupRequest{Inspect: &InspectResponse{Payload: rawPayload.Data}}, nil
+	}
+
+	advance, err := EvmAdvanceParser(rawPayload.Data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRequest, err)
+	}
+	return &RollupRequest{Advance: &advance}, nil
+}
+
+// FetchAdvance is FetchRequest for callers that only handle inputs, an inspect
+// request is invalid for them.
+func FetchAdvance(finish *FinishRequest) (*AdvanceResponse, error) {
+	request, err := FetchRequest(finish)
+	if err != nil || request == nil {
+		return nil, err
+	}
+	if request.Inspect != nil {
+		return nil, fmt.Errorf("%w: unexpected inspect request", ErrInvalidRequest)
+	}
+	return request.Advance, nil
+}
+
 func SendNotice(notice *NoticeRequest) (*http.Response, error) {
 	body, err := json.Marshal(notice)
 	if err != nil {
 		return &http.Response{}, err
 	}
 
-	log.Printf("Sending notice: %s\n", string(body))
+	slog.Debug("Sending notice", "payload", notice.Payload)
 
 	return SendPost("notice", body)
 }
 
+func SendReport(report *ReportRequest) (*http.Response, error) {
+	body, err := json.Marshal(report)
+	if err != nil {
+		return &http.Response{}, err
+	}
+
+	slog.Debug("Sending report", "payload", report.Payload)
+
+	return SendPost("report", body)
+}
+
 func SendException(exception *ExceptionRequest) (*http.Response, error) {
 	body, err := json.Marshal(exception)
 	if err != nil {
@@ -6,6 +6,11 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 )
 
+const (
+	ADVANCE_STATE = "advance_state"
+	INSPECT_STATE = "inspect_state"
+)
+
 type FinishRequest struct {
 	Status string `json:"status"`
 }
@@ -30,10 +35,25 @@ type AdvanceResponse struct {
 	Payload  string   `json:"payload"`
 }
 
+type InspectResponse struct {
+	Payload string `json:"payload"`
+}
+
+// RollupRequest holds the request sent by the rollup server, only one of the
+// fields is set
+type RollupRequest struct {
+	Advance *AdvanceResponse
+	Inspect *InspectResponse
+}
+
 type NoticeRequest struct {
 	Payload string `json:"payload"`
 }
 
+type ReportRequest struct {
+	Payload string `json:"payload"`
+}
+
 type ExceptionRequest struct {
 	Payload string `json:"payload"`
 }
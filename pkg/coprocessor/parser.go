@@ -76,7 +76,38 @@ func EvmAdvanceParser(hexInput string) (AdvanceResponse, error) {
 	if err != nil {
 		return response, fmt.Errorf("error marshaling response: %v", err)
 	}
-	slog.Info("Advance response", "info", string(jsonBytes))
+	slog.Debug("Advance response", "info", string(jsonBytes))
 
 	return response, nil
 }
+
+func EvmAdvanceEncoder(response AdvanceResponse) (string, error) {
+	parsedABI, err := abi.JSON(strings.NewReader(evmAdvanceABI))
+	if err != nil {
+		return "", fmt.Errorf("error parsing ABI: %v", err)
+	}
+
+	prevRandao, ok := new(big.Int).SetString(response.Metadata.PrevRandao, 10)
+	if !ok {
+		if response.Metadata.PrevRandao != "" {
+			return "", fmt.Errorf("invalid prev randao: %s", response.Metadata.PrevRandao)
+		}
+		prevRandao = new(big.Int)
+	}
+
+	input, err := parsedABI.Pack(
+		"EvmAdvance",
+		new(big.Int).SetUint64(response.Metadata.ChainId),
+		response.Metadata.TaskManager,
+		response.Metadata.MsgSender,
+		common.HexToHash(response.Metadata.BlockHash),
+		new(big.Int).SetUint64(response.Metadata.BlockNumber),
+		new(big.Int).SetUint64(response.Metadata.Timestamp),
+		prevRandao,
+		common.FromHex(response.Payload),
+	)
+	if err != nil {
+		return "", fmt.Errorf("error encoding input: %v", err)
+	}
+	return "0x" + common.Bytes2Hex(input), nil
+}
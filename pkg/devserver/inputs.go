@@ -0,0 +1,68 @@
+package devserver
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/henriquemarlon/swapx/pkg/coprocessor"
+)
+
+// LoadInputs reads a JSON array or a JSONL file whose entries are either a raw
+// EvmAdvance hex string or an {"metadata": {...}, "payload": "0x..."} object,
+// and returns them as raw EvmAdvance inputs.
+func LoadInputs(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []json.RawMessage
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &entries); err != nil {
+			return nil, err
+		}
+	} else {
+		scanner := bufio.NewScanner(bytes.NewReader(trimmed))
+		scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+		for scanner.Scan() {
+			line := bytes.TrimSpace(scanner.Bytes())
+			if len(line) == 0 {
+				continue
+			}
+			entries = append(entries, json.RawMessage(append([]byte(nil), line...)))
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	inputs := make([]string, 0, len(entries))
+	for i, entry := range entries {
+		input, err := decodeInput(entry)
+		if err != nil {
+			return nil, fmt.Errorf("input %d: %w", i, err)
+		}
+		inputs = append(inputs, input)
+	}
+	return inputs, nil
+}
+
+func decodeInput(entry json.RawMessage) (string, error) {
+	var raw string
+	if err := json.Unmarshal(entry, &raw); err == nil {
+		if _, err := coprocessor.EvmAdvanceParser(raw); err != nil {
+			return "", err
+		}
+		return raw, nil
+	}
+
+	var advance coprocessor.AdvanceResponse
+	if err := json.Unmarshal(entry, &advance); err != nil {
+		return "", fmt.Errorf("entry is neither a hex string nor an advance object: %w", err)
+	}
+	return coprocessor.EvmAdvanceEncoder(advance)
+}
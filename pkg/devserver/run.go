@@ -0,0 +1,61 @@
+package devserver
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+
+	"github.com/henriquemarlon/swapx/pkg/coprocessor"
+)
+
+// Listen serves the rollup HTTP API on address, use "127.0.0.1:0" for a free
+// port. It returns the base url of the server and a function to stop it.
+func (s *Server) Listen(address string) (string, func() error, error) {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return "", nil, err
+	}
+
+	httpServer := &http.Server{Handler: s.Handler()}
+	go func() {
+		if err := httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("Error: devserver stopped", "err", err)
+		}
+	}()
+
+	shutdown := func() error {
+		return httpServer.Shutdown(context.Background())
+	}
+	return "http://" + listener.Addr().String(), shutdown, nil
+}
+
+// Drive feeds every pending input to handle through the rollup HTTP API at
+// coprocessor.ROLLUP_HTTP_SERVER_URL, one at a time and in order, the same way
+// the coprocessor loop does. Inputs for which handle fails are rejected.
+func Drive(handle func(*coprocessor.AdvanceResponse) error) error {
+	finish := coprocessor.FinishRequest{Status: "accept"}
+	for {
+		advance, err := coprocessor.FetchAdvance(&finish)
+		if err != nil && !errors.Is(err, coprocessor.ErrInvalidRequest) {
+			return err
+		}
+		finish.Status = "accept"
+
+		if err != nil {
+			slog.Error("Error parsing advance response", "err", err)
+			finish.Status = "reject"
+			continue
+		}
+
+		if advance == nil {
+			return nil
+		}
+
+		if err := handle(advance); err != nil {
+			slog.Error("Error handling input", "err", err)
+			finish.Status = "reject"
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package devserver
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/henriquemarlon/swapx/pkg/coprocessor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDriveFeedsEveryInputInOrder(t *testing.T) {
+	input, err := coprocessor.EvmAdvanceEncoder(testAdvance)
+	require.NoError(t, err)
+
+	server := NewServer([]string{input, "0xdeadbeef", input}, NewMemoryStorage())
+	url, shutdown, err := server.Listen("127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { shutdown() })
+	coprocessor.ROLLUP_HTTP_SERVER_URL = url
+
+	var handled int
+	err = Drive(func(advance *coprocessor.AdvanceResponse) error {
+		handled++
+		assert.Equal(t, testAdvance, *advance)
+		if handled == 2 {
+			return errors.New("rejected")
+		}
+		_, err := coprocessor.SendNotice(&coprocessor.NoticeRequest{Payload: "0xaa"})
+		return err
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, handled)
+
+	<-server.Drained()
+	outputs, results := server.Snapshot()
+	assert.Equal(t, []Output{{Input: 0, Type: OutputNotice, Payload: "0xaa"}}, outputs)
+	// the second input is not an EvmAdvance and never reaches the handler
+	assert.Equal(t, []Result{
+		{Input: 0, Status: "accept"},
+		{Input: 1, Status: "reject"},
+		{Input: 2, Status: "reject"},
+	}, results)
+}
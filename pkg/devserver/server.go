@@ -0,0 +1,230 @@
+package devserver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/henriquemarlon/swapx/pkg/coprocessor"
+	"github.com/henriquemarlon/swapx/pkg/gio"
+)
+
+const (
+	OutputNotice    = "notice"
+	OutputReport    = "report"
+	OutputVoucher   = "voucher"
+	OutputException = "exception"
+)
+
+var ErrNoInputInProgress = errors.New("no input in progress")
+
+type Output struct {
+	Input       int    `json:"input"`
+	Type        string `json:"type"`
+	Destination string `json:"destination,omitempty"`
+	Payload     string `json:"payload"`
+}
+
+type Result struct {
+	Input  int    `json:"input"`
+	Status string `json:"status"`
+}
+
+// Server stands in for the rollup HTTP server and the GIO endpoint of the
+// coprocessor, feeding a scripted queue of EvmAdvance inputs and recording
+// every output produced for them.
+type Server struct {
+	Inputs  []string
+	Storage Storage
+	Outputs []Output
+	Results []Result
+	current int
+	next    int
+	drained chan struct{}
+	mutex   *sync.Mutex
+}
+
+func NewServer(inputs []string, storage Storage) *Server {
+	s := &Server{
+		Inputs:  inputs,
+		Storage: storage,
+		current: -1,
+		drained: make(chan struct{}),
+		mutex:   &sync.Mutex{},
+	}
+	if len(inputs) == 0 {
+		close(s.drained)
+	}
+	return s
+}
+
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /finish", s.handleFinish)
+	mux.HandleFunc("POST /notice", s.handleOutput(OutputNotice))
+	mux.HandleFunc("POST /report", s.handleOutput(OutputReport))
+	mux.HandleFunc("POST /voucher", s.handleOutput(OutputVoucher))
+	mux.HandleFunc("POST /exception", s.handleOutput(OutputException))
+	mux.HandleFunc("POST /gio", s.handleGio)
+	mux.HandleFunc("GET /outputs", s.handleListOutputs)
+	return mux
+}
+
+// Drained is closed once every scripted input has been finished.
+func (s *Server) Drained() <-chan struct{} {
+	return s.drained
+}
+
+func (s *Server) Snapshot() ([]Output, []Result) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return append([]Output(nil), s.Outputs...), append([]Result(nil), s.Results...)
+}
+
+func (s *Server) handleFinish(w http.ResponseWriter, r *http.Request) {
+	var finish coprocessor.FinishRequest
+	if err := json.NewDecoder(r.Body).Decode(&finish); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.current >= 0 {
+		s.Results = append(s.Results, Result{Input: s.current, Status: finish.Status})
+		slog.Info("Input finished", "input", s.current, "status", finish.Status)
+		s.current = -1
+		if s.next == len(s.Inputs) {
+			close(s.drained)
+		}
+	}
+
+	if s.next >= len(s.Inputs) {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	s.current = s.next
+	s.next++
+	slog.Info("Sending input", "input", s.current)
+
+	data, err := json.Marshal(map[string]string{"payload": s.Inputs[s.current]})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, coprocessor.FinishResponse{Type: coprocessor.ADVANCE_STATE, Data: data})
+}
+
+func (s *Server) handleOutput(outputType string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Destination string `json:"destination,omitempty"`
+			Payload     string `json:"payload"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+
+		if s.current < 0 {
+			http.Error(w, ErrNoInputInProgress.Error(), http.StatusBadRequest)
+			return
+		}
+
+		s.Outputs = append(s.Outputs, Output{
+			Input:       s.current,
+			Type:        outputType,
+			Destination: request.Destination,
+			Payload:     request.Payload,
+		})
+		slog.Info("Output recorded", "input", s.current, "type", outputType, "payload", request.Payload)
+
+		writeJSON(w, http.StatusOK, coprocessor.IndexResponse{Index: uint64(len(s.Outputs) - 1)})
+	}
+}
+
+func (s *Server) handleGio(w http.ResponseWriter, r *http.Request) {
+	var request gio.GioRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if request.Domain == gio.BLOCK_HEADER_DOMAIN {
+		s.handleGioHeader(w, request)
+		return
+	}
+	if request.Domain != 0x27 {
+		http.Error(w, fmt.Sprintf("domain %#x not supported", request.Domain), http.StatusBadRequest)
+		return
+	}
+
+	id := common.FromHex(request.Id)
+	if len(id) != common.HashLength+common.AddressLength+common.HashLength {
+		http.Error(w, "id must be <block_hash:32_bytes><address:20_bytes><storage_slot:32_bytes>", http.StatusBadRequest)
+		return
+	}
+
+	blockHash := common.BytesToHash(id[:common.HashLength])
+	address := common.BytesToAddress(id[common.HashLength : common.HashLength+common.AddressLength])
+	slot := common.BytesToHash(id[common.HashLength+common.AddressLength:])
+
+	value, err := s.Storage.GetStorageAt(blockHash, address, slot)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, gio.GioResponse{ResponseCode: 0, Response: value.Hex()})
+}
+
+func (s *Server) handleGioHeader(w http.ResponseWriter, request gio.GioRequest) {
+	headers, ok := s.Storage.(HeaderStorage)
+	if !ok {
+		http.Error(w, fmt.Sprintf("domain %#x not supported by this storage", request.Domain), http.StatusBadRequest)
+		return
+	}
+
+	id := common.FromHex(request.Id)
+	if len(id) != common.HashLength {
+		http.Error(w, "id must be <block_hash:32_bytes>", http.StatusBadRequest)
+		return
+	}
+
+	header, err := headers.HeaderByHash(common.BytesToHash(id))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	encoded, err := rlp.EncodeToBytes(header)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, gio.GioResponse{ResponseCode: 0, Response: "0x" + common.Bytes2Hex(encoded)})
+}
+
+func (s *Server) handleListOutputs(w http.ResponseWriter, _ *http.Request) {
+	outputs, results := s.Snapshot()
+	writeJSON(w, http.StatusOK, map[string]any{"outputs": outputs, "results": results})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		slog.Error("Error writing response", "err", err)
+	}
+}
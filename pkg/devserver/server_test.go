@@ -0,0 +1,95 @@
+package devserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/henriquemarlon/swapx/pkg/coprocessor"
+	"github.com/henriquemarlon/swapx/pkg/gio"
+	"github.com/stretchr/testify/assert"
+)
+
+var testAdvance = coprocessor.AdvanceResponse{
+	Metadata: coprocessor.Metadata{
+		ChainId:     31337,
+		TaskManager: common.HexToAddress("0x2"),
+		MsgSender:   common.HexToAddress("0x1"),
+		BlockHash:   common.HexToHash("0x11").Hex(),
+		BlockNumber: 10,
+		Timestamp:   1,
+		PrevRandao:  "1",
+	},
+	Payload: "0x01",
+}
+
+func setupServer(t *testing.T, inputs []string) (*Server, *httptest.Server) {
+	server := NewServer(inputs, NewMemoryStorage())
+	httpServer := httptest.NewServer(server.Handler())
+	t.Cleanup(httpServer.Close)
+
+	coprocessor.ROLLUP_HTTP_SERVER_URL = httpServer.URL
+	return server, httpServer
+}
+
+func TestInputsAreFedAndOutputsRecorded(t *testing.T) {
+	input, err := coprocessor.EvmAdvanceEncoder(testAdvance)
+	assert.NoError(t, err)
+	server, _ := setupServer(t, []string{input})
+
+	res, err := coprocessor.SendFinish(&coprocessor.FinishRequest{Status: "accept"})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	var finish coprocessor.FinishResponse
+	assert.NoError(t, json.NewDecoder(res.Body).Decode(&finish))
+	var payload struct {
+		Data string `json:"payload"`
+	}
+	assert.NoError(t, json.Unmarshal(finish.Data, &payload))
+
+	advance, err := coprocessor.EvmAdvanceParser(payload.Data)
+	assert.NoError(t, err)
+	assert.Equal(t, testAdvance, advance)
+
+	_, err = coprocessor.SendNotice(&coprocessor.NoticeRequest{Payload: "0xaa"})
+	assert.NoError(t, err)
+
+	res, err = coprocessor.SendFinish(&coprocessor.FinishRequest{Status: "reject"})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusAccepted, res.StatusCode)
+
+	<-server.Drained()
+	outputs, results := server.Snapshot()
+	assert.Equal(t, []Output{{Input: 0, Type: OutputNotice, Payload: "0xaa"}}, outputs)
+	assert.Equal(t, []Result{{Input: 0, Status: "reject"}}, results)
+}
+
+func TestOutputsOutsideOfAnInputAreRefused(t *testing.T) {
+	setupServer(t, nil)
+
+	res, err := coprocessor.SendNotice(&coprocessor.NoticeRequest{Payload: "0xaa"})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+}
+
+func TestGioAnswersFromStorage(t *testing.T) {
+	server, httpServer := setupServer(t, nil)
+
+	hook := common.HexToAddress("0x1")
+	slot := common.BigToHash(common.Big1)
+	server.Storage.(*MemoryStorage).SetStorageAt(hook, slot, common.BigToHash(common.Big3))
+
+	handler, err := gio.NewGioHandlerFactory(httpServer.URL).NewGioHandler(0x27)
+	assert.NoError(t, err)
+
+	res, err := handler.Handle(common.HexToHash("0x11"), hook, slot)
+	assert.NoError(t, err)
+	assert.Equal(t, common.BigToHash(common.Big3).Hex(), res.Response)
+
+	res, err = handler.Handle(common.HexToHash("0x11"), hook, common.BigToHash(common.Big2))
+	assert.NoError(t, err)
+	assert.Equal(t, common.Hash{}.Hex(), res.Response)
+}
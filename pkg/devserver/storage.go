@@ -0,0 +1,72 @@
+package devserver
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+type Storage interface {
+	GetStorageAt(blockHash common.Hash, address common.Address, slot common.Hash) (common.Hash, error)
+}
+
+// HeaderStorage is a storage that also knows the headers of its blocks, the
+// server answers the block header domain only for those
+type HeaderStorage interface {
+	HeaderByHash(blockHash common.Hash) (*types.Header, error)
+}
+
+// MemoryStorage answers every block with the same state, which is enough to
+// feed a scripted sequence of inputs. Unknown slots read as zero, like on chain.
+type MemoryStorage struct {
+	Slots map[common.Address]map[common.Hash]common.Hash
+	Mutex *sync.RWMutex
+}
+
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{
+		Slots: make(map[common.Address]map[common.Hash]common.Hash),
+		Mutex: &sync.RWMutex{},
+	}
+}
+
+// LoadMemoryStorage reads a snapshot in the {"<address>": {"<slot>": "<value>"}} format.
+func LoadMemoryStorage(path string) (*MemoryStorage, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshot map[common.Address]map[common.Hash]common.Hash
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, err
+	}
+
+	storage := NewMemoryStorage()
+	for address, slots := range snapshot {
+		for slot, value := range slots {
+			storage.SetStorageAt(address, slot, value)
+		}
+	}
+	return storage, nil
+}
+
+func (s *MemoryStorage) GetStorageAt(_ common.Hash, address common.Address, slot common.Hash) (common.Hash, error) {
+	s.Mutex.RLock()
+	defer s.Mutex.RUnlock()
+
+	return s.Slots[address][slot], nil
+}
+
+func (s *MemoryStorage) SetStorageAt(address common.Address, slot common.Hash, value common.Hash) {
+	s.Mutex.Lock()
+	defer s.Mutex.Unlock()
+
+	if _, ok := s.Slots[address]; !ok {
+		s.Slots[address] = make(map[common.Hash]common.Hash)
+	}
+	s.Slots[address][slot] = value
+}
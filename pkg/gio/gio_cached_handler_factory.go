@@ -0,0 +1,101 @@
+package gio
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// CachedGioHandlerFactory keeps the last responses of the wrapped handlers in
+// memory. Storage read at a block hash never changes, so successful responses
+// can be reused by every later input that reads at the same block.
+type CachedGioHandlerFactory struct {
+	Factory GioHandlerFactory
+	Size    int
+
+	mutex   sync.Mutex
+	entries map[gioCacheKey]*list.Element
+	order   *list.List
+}
+
+type gioCacheKey struct {
+	domain    uint16
+	blockHash common.Hash
+	address   common.Address
+	slot      common.Hash
+}
+
+type gioCacheEntry struct {
+	key      gioCacheKey
+	response GioResponse
+}
+
+// NewCachedGioHandlerFactory wraps factory with a cache of size responses, a
+// size of 0 returns the factory untouched.
+func NewCachedGioHandlerFactory(factory GioHandlerFactory, size int) GioHandlerFactory {
+	if size <= 0 {
+		return factory
+	}
+	return &CachedGioHandlerFactory{
+		Factory: factory,
+		Size:    size,
+		entries: make(map[gioCacheKey]*list.Element),
+		order:   list.New(),
+	}
+}
+
+func (f *CachedGioHandlerFactory) NewGioHandler(domain uint16) (GioHandler, error) {
+	handler, err := f.Factory.NewGioHandler(domain)
+	if err != nil {
+		return nil, err
+	}
+	return &cachedGioHandler{domain: domain, handler: handler, cache: f}, nil
+}
+
+func (f *CachedGioHandlerFactory) get(key gioCacheKey) (*GioResponse, bool) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	element, ok := f.entries[key]
+	if !ok {
+		return nil, false
+	}
+	f.order.MoveToFront(element)
+	response := element.Value.(*gioCacheEntry).response
+	return &response, true
+}
+
+func (f *CachedGioHandlerFactory) put(key gioCacheKey, response *GioResponse) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if element, ok := f.entries[key]; ok {
+		f.order.MoveToFront(element)
+		return
+	}
+	f.entries[key] = f.order.PushFront(&gioCacheEntry{key: key, response: *response})
+	if f.order.Len() > f.Size {
+		oldest := f.order.Back()
+		f.order.Remove(oldest)
+		delete(f.entries, oldest.Value.(*gioCacheEntry).key)
+	}
+}
+
+type cachedGioHandler struct {
+	domain  uint16
+	handler GioHandler
+	cache   *CachedGioHandlerFactory
+}
+
+func (h *cachedGioHandler) Handle(blockHash common.Hash, address common.Address, slot common.Hash) (*GioResponse, error) {
+	key := gioCacheKey{domain: h.domain, blockHash: blockHash, address: address, slot: slot}
+	if response, ok := h.cache.get(key); ok {
+		return response, nil
+	}
+
+	response, err := h.handler.Handle(blockHash, address, slot)
+	if err != nil {
+		return nil, err
+	}
+	h.cache.put(key, response)
+	return response, nil
+}
@@ -0,0 +1,68 @@
+package gio
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type countingFactory struct {
+	calls int
+	fail  bool
+}
+
+func (f *countingFactory) NewGioHandler(domain uint16) (GioHandler, error) {
+	return f, nil
+}
+
+func (f *countingFactory) Handle(blockHash common.Hash, address common.Address, slot common.Hash) (*GioResponse, error) {
+	f.calls++
+	if f.fail {
+		return nil, errors.New("unavailable")
+	}
+	return &GioResponse{Response: slot.Hex()}, nil
+}
+
+func TestCachedGioHandlerReusesResponses(t *testing.T) {
+	inner := &countingFactory{}
+	handler, err := NewCachedGioHandlerFactory(inner, 2).NewGioHandler(0x27)
+	require.NoError(t, err)
+
+	block := common.HexToHash("0x1")
+	hook := common.HexToAddress("0x2")
+	for _, slot := range []string{"0x1", "0x1", "0x2", "0x1"} {
+		response, err := handler.Handle(block, hook, common.HexToHash(slot))
+		require.NoError(t, err)
+		assert.Equal(t, common.HexToHash(slot).Hex(), response.Response)
+	}
+	assert.Equal(t, 2, inner.calls)
+
+	// slot 0x2 is the least recently used one and goes first
+	_, err = handler.Handle(block, hook, common.HexToHash("0x3"))
+	require.NoError(t, err)
+	_, err = handler.Handle(block, hook, common.HexToHash("0x1"))
+	require.NoError(t, err)
+	_, err = handler.Handle(block, hook, common.HexToHash("0x2"))
+	require.NoError(t, err)
+	assert.Equal(t, 4, inner.calls)
+}
+
+func TestCachedGioHandlerSkipsErrors(t *testing.T) {
+	inner := &countingFactory{fail: true}
+	handler, err := NewCachedGioHandlerFactory(inner, 8).NewGioHandler(0x27)
+	require.NoError(t, err)
+
+	for range 2 {
+		_, err := handler.Handle(common.Hash{}, common.Address{}, common.Hash{})
+		assert.Error(t, err)
+	}
+	assert.Equal(t, 2, inner.calls)
+}
+
+func TestZeroSizeDisablesTheCache(t *testing.T) {
+	inner := &countingFactory{}
+	assert.Same(t, GioHandlerFactory(inner), NewCachedGioHandlerFactory(inner, 0))
+}
@@ -0,0 +1,102 @@
+package gio
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// BLOCK_HEADER_DOMAIN asks for the keccak256 preimage of an id. The preimage
+// of a block hash is the RLP encoding of its header, which is checked against
+// the hash so a header can not be answered for another block.
+const BLOCK_HEADER_DOMAIN = 0x2
+
+var (
+	ErrDomainNotSupported = errors.New("domain not supported")
+	ErrHeaderMismatch     = errors.New("header does not hash to the block hash")
+)
+
+type GioHeaderHandler interface {
+	HandleHeader(blockHash common.Hash) (*types.Header, error)
+}
+
+// GioHeaderHandlerFactory is implemented by the factories that can read block
+// headers on top of storage
+type GioHeaderHandlerFactory interface {
+	NewGioHeaderHandler() (GioHeaderHandler, error)
+}
+
+type GioGetBlockHeader struct {
+	BaseUrl string `json:"base_url"`
+}
+
+func NewGioGetBlockHeader(baseUrl string) *GioGetBlockHeader {
+	return &GioGetBlockHeader{BaseUrl: baseUrl}
+}
+
+func (h *GioGetBlockHeader) HandleHeader(blockHash common.Hash) (*types.Header, error) {
+	slog.Debug("Handling block header request", "block_hash", blockHash.Hex())
+
+	reqBody, err := json.Marshal(GioRequest{
+		Domain: BLOCK_HEADER_DOMAIN,
+		Id:     blockHash.Hex(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := http.Post(h.BaseUrl+"/gio", "application/json", bytes.NewBuffer(reqBody))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode != http.StatusAccepted {
+		slog.Warn("Unexpected GIO response", "status", res.StatusCode, "body", string(body))
+		return nil, errors.New("unexpected status code: " + res.Status + ", response: " + string(body))
+	}
+
+	var gioResponse GioResponse
+	if err := json.Unmarshal(body, &gioResponse); err != nil {
+		return nil, errors.New("invalid JSON response format: " + err.Error())
+	}
+	return DecodeBlockHeader(blockHash, common.FromHex(gioResponse.Response))
+}
+
+// DecodeBlockHeader decodes an RLP encoded header and checks it is the one of blockHash
+func DecodeBlockHeader(blockHash common.Hash, encoded []byte) (*types.Header, error) {
+	var header types.Header
+	if err := rlp.DecodeBytes(encoded, &header); err != nil {
+		return nil, fmt.Errorf("invalid block header: %w", err)
+	}
+	if header.Hash() != blockHash {
+		return nil, fmt.Errorf("%w: %s", ErrHeaderMismatch, blockHash.Hex())
+	}
+	return &header, nil
+}
+
+func (f *DefaultGioHandlerFactory) NewGioHeaderHandler() (GioHeaderHandler, error) {
+	return NewGioGetBlockHeader(f.BaseUrl), nil
+}
+
+// NewGioHeaderHandler reads the headers through the wrapped factory, without caching them
+func (f *CachedGioHandlerFactory) NewGioHeaderHandler() (GioHeaderHandler, error) {
+	factory, ok := f.Factory.(GioHeaderHandlerFactory)
+	if !ok {
+		return nil, ErrDomainNotSupported
+	}
+	return factory.NewGioHeaderHandler()
+}
@@ -0,0 +1,35 @@
+package gio
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeBlockHeaderChecksTheHash(t *testing.T) {
+	header := &types.Header{
+		ParentHash: common.HexToHash("0x1"),
+		Number:     big.NewInt(7),
+		Difficulty: new(big.Int),
+	}
+	encoded, err := rlp.EncodeToBytes(header)
+	require.NoError(t, err)
+
+	decoded, err := DecodeBlockHeader(header.Hash(), encoded)
+	require.NoError(t, err)
+	assert.Equal(t, header.ParentHash, decoded.ParentHash)
+	assert.Equal(t, uint64(7), decoded.Number.Uint64())
+
+	_, err = DecodeBlockHeader(common.HexToHash("0x2"), encoded)
+	assert.ErrorIs(t, err, ErrHeaderMismatch)
+
+	cached, ok := NewCachedGioHandlerFactory(&countingFactory{}, 1).(GioHeaderHandlerFactory)
+	require.True(t, ok)
+	_, err = cached.NewGioHeaderHandler()
+	assert.ErrorIs(t, err, ErrDomainNotSupported)
+}
@@ -6,7 +6,7 @@ import (
 	"encoding/json"
 	"errors"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -25,7 +25,7 @@ func NewGioGetStorage(baseUrl string, domain uint16) *GioGetStorage {
 }
 
 func (h *GioGetStorage) Handle(blockHash common.Hash, address common.Address, slot common.Hash) (*GioResponse, error) {
-	log.Printf("Handling storage at block %s, address %s, slot %s\n", blockHash.Hex(), address.Hex(), slot.Hex())
+	slog.Debug("Handling storage request", "block_hash", blockHash.Hex(), "address", address.Hex(), "slot", slot.Hex())
 
 	hexEncoded := append(blockHash[:], address[:]...)
 	hexEncoded = append(hexEncoded, slot[:]...)
@@ -40,7 +40,7 @@ func (h *GioGetStorage) Handle(blockHash common.Hash, address common.Address, sl
 		return nil, err
 	}
 
-	log.Printf("Request body: %s\n", string(reqBody))
+	slog.Debug("GIO request", "body", string(reqBody))
 
 	req, err := http.NewRequest("POST", h.BaseUrl+"/gio", bytes.NewBuffer(reqBody))
 	if err != nil {
@@ -60,7 +60,7 @@ func (h *GioGetStorage) Handle(blockHash common.Hash, address common.Address, sl
 	}
 
 	if res.StatusCode != http.StatusAccepted {
-		log.Printf("Response status: %d, body: %s\n", res.StatusCode, string(body))
+		slog.Warn("Unexpected GIO response", "status", res.StatusCode, "body", string(body))
 		return nil, errors.New("unexpected status code: " + res.Status + ", response: " + string(body))
 	}
 
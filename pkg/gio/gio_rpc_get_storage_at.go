@@ -0,0 +1,59 @@
+package gio
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// GioRpcGetStorage answers the 0x27 domain straight from a node through
+// eth_getStorageAt, which lets the coprocessor reads be checked against any
+// chain, a local anvil included.
+type GioRpcGetStorage struct {
+	Client *ethclient.Client
+}
+
+func NewGioRpcGetStorage(client *ethclient.Client) *GioRpcGetStorage {
+	return &GioRpcGetStorage{Client: client}
+}
+
+func (h *GioRpcGetStorage) Handle(blockHash common.Hash, address common.Address, slot common.Hash) (*GioResponse, error) {
+	value, err := h.Client.StorageAtHash(context.Background(), address, slot, blockHash)
+	if err != nil {
+		return nil, err
+	}
+	return &GioResponse{ResponseCode: 0, Response: common.BytesToHash(value).Hex()}, nil
+}
+
+// GioRpcGetBlockHeader answers the block header domain through eth_getBlockByHash
+type GioRpcGetBlockHeader struct {
+	Client *ethclient.Client
+}
+
+func (h *GioRpcGetBlockHeader) HandleHeader(blockHash common.Hash) (*types.Header, error) {
+	return h.Client.HeaderByHash(context.Background(), blockHash)
+}
+
+type RpcGioHandlerFactory struct {
+	Client *ethclient.Client
+}
+
+func NewRpcGioHandlerFactory(client *ethclient.Client) GioHandlerFactory {
+	return &RpcGioHandlerFactory{Client: client}
+}
+
+func (f *RpcGioHandlerFactory) NewGioHandler(domain uint16) (GioHandler, error) {
+	switch domain {
+	case 0x27:
+		return NewGioRpcGetStorage(f.Client), nil
+	default:
+		return nil, errors.New("domain not supported")
+	}
+}
+
+func (f *RpcGioHandlerFactory) NewGioHeaderHandler() (GioHeaderHandler, error) {
+	return &GioRpcGetBlockHeader{Client: f.Client}, nil
+}
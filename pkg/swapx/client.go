@@ -0,0 +1,229 @@
+package swapx
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+//go:generate go run -mod=mod github.com/ethereum/go-ethereum/cmd/abigen --abi abi/SwapXHook.json --pkg swapx --type SwapXHook --out swapx_hook.go
+//go:generate go run -mod=mod github.com/ethereum/go-ethereum/cmd/abigen --abi abi/SwapXTaskManager.json --pkg swapx --type SwapXTaskManager --out swapx_task_manager.go
+//go:generate go run -mod=mod github.com/ethereum/go-ethereum/cmd/abigen --abi abi/PoolSwapTest.json --pkg swapx --type PoolSwapTest --out pool_swap_test_router.go
+
+const (
+	FLAG_IMMEDIATE_OR_CANCEL uint64 = 1 << 0
+	FLAG_ROUTE_TO_POOL       uint64 = 1 << 1
+)
+
+// MIN_SQRT_PRICE and MAX_SQRT_PRICE are the bounds of TickMath. Orders swap
+// with a limit just inside them, the hook takes the whole amount before the
+// pool price is looked at.
+var (
+	MIN_SQRT_PRICE    = big.NewInt(4295128739)
+	MAX_SQRT_PRICE, _ = new(big.Int).SetString("1461446703485210103287273052203988822378723970342", 10)
+)
+
+var (
+	ErrInvalidHookData      = errors.New("invalid hook data")
+	ErrInvalidOrderAmount   = errors.New("order amount must be positive")
+	ErrOrderCreatedNotFound = errors.New("no OrderCreated event in receipt")
+)
+
+var (
+	uint256Type, _ = abi.NewType("uint256", "", nil)
+	addressType, _ = abi.NewType("address", "", nil)
+
+	hookDataArguments          = abi.Arguments{{Type: uint256Type}, {Type: addressType}}
+	hookDataWithFlagsArguments = abi.Arguments{{Type: uint256Type}, {Type: addressType}, {Type: uint256Type}}
+)
+
+// HookData is decoded by SwapXHook._beforeSwap: the limit price and the
+// account the order belongs to, followed by the order flags when there are any.
+type HookData struct {
+	SqrtPrice *big.Int
+	Sender    common.Address
+	Flags     uint64
+}
+
+// EncodeHookData returns abi.encode(sqrtPrice, sender), or
+// abi.encode(sqrtPrice, sender, flags) when flags are set.
+func EncodeHookData(data HookData) ([]byte, error) {
+	if data.SqrtPrice == nil || data.SqrtPrice.Sign() < 0 {
+		return nil, fmt.Errorf("%w: sqrt price must be set and not negative", ErrInvalidHookData)
+	}
+	if data.Flags == 0 {
+		return hookDataArguments.Pack(data.SqrtPrice, data.Sender)
+	}
+	return hookDataWithFlagsArguments.Pack(data.SqrtPrice, data.Sender, new(big.Int).SetUint64(data.Flags))
+}
+
+func DecodeHookData(data []byte) (*HookData, error) {
+	arguments := hookDataArguments
+	if len(data) >= 96 {
+		arguments = hookDataWithFlagsArguments
+	}
+	values, err := arguments.Unpack(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidHookData, err)
+	}
+
+	hookData := &HookData{
+		SqrtPrice: values[0].(*big.Int),
+		Sender:    values[1].(common.Address),
+	}
+	if len(values) == 3 {
+		flags := values[2].(*big.Int)
+		if !flags.IsUint64() {
+			return nil, fmt.Errorf("%w: flags %s do not fit in 64 bits", ErrInvalidHookData, flags)
+		}
+		hookData.Flags = flags.Uint64()
+	}
+	return hookData, nil
+}
+
+// Client places, cancels and reads the orders of the SwapXHook of one pool.
+// Orders are swaps through a PoolSwapTest router, so the sender has to approve
+// the router for the currency it pays with: currency0 for buy orders and
+// currency1 for sell orders.
+type Client struct {
+	PoolKey PoolKey
+	Hook    *SwapXHook
+	Router  *PoolSwapTest
+}
+
+func NewClient(backend bind.ContractBackend, poolKey PoolKey, router common.Address) (*Client, error) {
+	hook, err := NewSwapXHook(poolKey.Hooks, backend)
+	if err != nil {
+		return nil, err
+	}
+	swapRouter, err := NewPoolSwapTest(router, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{PoolKey: poolKey, Hook: hook, Router: swapRouter}, nil
+}
+
+type LimitOrder struct {
+	IsBuy     bool
+	SqrtPrice *big.Int
+	Amount    *big.Int
+	Flags     uint64
+	// Account receives the fills and is the only one allowed to cancel,
+	// defaults to the sender of the transaction
+	Account common.Address
+}
+
+func (c *Client) PlaceOrder(opts *bind.TransactOpts, order LimitOrder) (*types.Transaction, error) {
+	if order.Amount == nil || order.Amount.Sign() <= 0 {
+		return nil, ErrInvalidOrderAmount
+	}
+
+	account := order.Account
+	if account == (common.Address{}) {
+		account = opts.From
+	}
+	hookData, err := EncodeHookData(HookData{SqrtPrice: order.SqrtPrice, Sender: account, Flags: order.Flags})
+	if err != nil {
+		return nil, err
+	}
+
+	sqrtPriceLimit := new(big.Int).Sub(MAX_SQRT_PRICE, common.Big1)
+	if order.IsBuy {
+		sqrtPriceLimit = new(big.Int).Add(MIN_SQRT_PRICE, common.Big1)
+	}
+	return c.Router.Swap(opts, c.PoolKey, IPoolManagerSwapParams{
+		ZeroForOne:        order.IsBuy,
+		AmountSpecified:   new(big.Int).Neg(order.Amount),
+		SqrtPriceLimitX96: sqrtPriceLimit,
+	}, PoolSwapTestTestSettings{}, hookData)
+}
+
+func (c *Client) CancelOrder(opts *bind.TransactOpts, orderId uint64, isBuy bool) (*types.Transaction, error) {
+	id := new(big.Int).SetUint64(orderId)
+	if isBuy {
+		return c.Hook.CancelBuyOrder(opts, id)
+	}
+	return c.Hook.CancelSellOrder(opts, id)
+}
+
+// OrderCreated finds the order placed by a transaction. The event carries the
+// length of the order array after the push, the id returned is the index that
+// every other function of the hook takes.
+func (c *Client) OrderCreated(receipt *types.Receipt) (orderId uint64, isBuy bool, err error) {
+	for _, log := range receipt.Logs {
+		if log.Address != c.PoolKey.Hooks {
+			continue
+		}
+		event, err := c.Hook.ParseOrderCreated(*log)
+		if err != nil {
+			continue
+		}
+		if !event.OrderId.IsUint64() || event.OrderId.Sign() == 0 {
+			return 0, false, fmt.Errorf("unexpected order id %s in OrderCreated", event.OrderId)
+		}
+		return event.OrderId.Uint64() - 1, event.IsBuy, nil
+	}
+	return 0, false, ErrOrderCreatedNotFound
+}
+
+// Order is the state of an order as stored by the hook
+type Order struct {
+	Id            uint64         `json:"id"`
+	IsBuy         bool           `json:"is_buy"`
+	Account       common.Address `json:"account"`
+	SqrtPrice     *big.Int       `json:"sqrt_price"`
+	Amount        *big.Int       `json:"amount"`
+	MatchedAmount *big.Int       `json:"matched_amount"`
+	Cancelled     bool           `json:"cancelled"`
+	Flags         uint64         `json:"flags"`
+}
+
+func (o *Order) Remaining() *big.Int {
+	return new(big.Int).Sub(o.Amount, o.MatchedAmount)
+}
+
+func (o *Order) IsOpen() bool {
+	return !o.Cancelled && o.Remaining().Sign() > 0
+}
+
+// Order reads an order from the hook, calls revert for ids that do not exist.
+// Pin opts.BlockNumber to read the three getters from the same block.
+func (c *Client) Order(opts *bind.CallOpts, orderId uint64, isBuy bool) (*Order, error) {
+	id := new(big.Int).SetUint64(orderId)
+	getOrder, getCancelled, getFlags := c.Hook.SellOrders, c.Hook.SellOrderCancelled, c.Hook.SellOrderFlags
+	if isBuy {
+		getOrder, getCancelled, getFlags = c.Hook.BuyOrders, c.Hook.BuyOrderCancelled, c.Hook.BuyOrderFlags
+	}
+
+	stored, err := getOrder(opts, id)
+	if err != nil {
+		return nil, err
+	}
+	cancelled, err := getCancelled(opts, id)
+	if err != nil {
+		return nil, err
+	}
+	flags, err := getFlags(opts, id)
+	if err != nil {
+		return nil, err
+	}
+	if !flags.IsUint64() {
+		return nil, fmt.Errorf("order %d has flags %s that do not fit in 64 bits", orderId, flags)
+	}
+
+	return &Order{
+		Id:            orderId,
+		IsBuy:         isBuy,
+		Account:       stored.Account,
+		SqrtPrice:     stored.SqrtPrice,
+		Amount:        stored.Amount,
+		MatchedAmount: stored.MatchedAmount,
+		Cancelled:     cancelled,
+		Flags:         flags.Uint64(),
+	}, nil
+}
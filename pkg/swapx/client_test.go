@@ -0,0 +1,299 @@
+package swapx
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ARTIFACTS_DIR is where forge build writes the compiled contracts
+const ARTIFACTS_DIR = "../../contracts/out"
+
+// v4 reads the permissions of a hook from the lowest 14 bits of its address.
+// SwapXHook uses beforeInitialize, beforeSwap and beforeSwapReturnDelta.
+const (
+	HOOK_FLAGS_MASK  = 1<<14 - 1
+	SWAPX_HOOK_FLAGS = 1<<13 | 1<<7 | 1<<3
+)
+
+// CREATE2_DEPLOYER is the deterministic deployment proxy the deploy scripts
+// mine hook addresses against, it deploys the init code that follows a salt
+var (
+	CREATE2_DEPLOYER    = common.HexToAddress("0x4e59b44847b379578588920cA78FbF26c0B4956C")
+	create2DeployerCode = common.FromHex("0x7fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffe03601600081602082378035828234f58015156039578182fd5b8082525050506014600cf3")
+)
+
+var (
+	SQRT_PRICE_1_1       = new(big.Int).Lsh(common.Big1, 96)
+	testAccount          = common.HexToAddress("0x0000000000000000000000000000000000000b01")
+	testHook             = common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	testRouter           = common.HexToAddress("0x00000000000000000000000000000000000000bb")
+	testChainId          = big.NewInt(1337)
+	testTokenSupply      = new(big.Int).Exp(big.NewInt(10), big.NewInt(24), nil)
+	errArtifactsNotBuilt = errors.New("contracts not built")
+)
+
+type artifact struct {
+	Abi      json.RawMessage `json:"abi"`
+	Bytecode struct {
+		Object string `json:"object"`
+	} `json:"bytecode"`
+}
+
+// loadArtifact reads the ABI and creation code forge build wrote for contract
+func loadArtifact(contract string) (*abi.ABI, []byte, error) {
+	data, err := os.ReadFile(filepath.Join(ARTIFACTS_DIR, contract+".sol", contract+".json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil, errArtifactsNotBuilt
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var compiled artifact
+	if err := json.Unmarshal(data, &compiled); err != nil {
+		return nil, nil, err
+	}
+	parsed, err := abi.JSON(bytes.NewReader(compiled.Abi))
+	if err != nil {
+		return nil, nil, err
+	}
+	bytecode, err := hexutil.Decode(compiled.Bytecode.Object)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &parsed, bytecode, nil
+}
+
+// testChain is a fresh PoolManager with the PoolSwapTest router, two mock
+// tokens and SwapXHook on a pool of them, all deployed from the Foundry
+// artifacts on a simulated chain
+type testChain struct {
+	backend   *simulated.Backend
+	client    simulated.Client
+	opts      *bind.TransactOpts
+	poolKey   PoolKey
+	router    common.Address
+	currency0 *bind.BoundContract
+	currency1 *bind.BoundContract
+}
+
+func newTestChain(t *testing.T) *testChain {
+	if _, _, err := loadArtifact("SwapXHook"); errors.Is(err, errArtifactsNotBuilt) {
+		t.Skipf("no artifacts in %s, run forge build in contracts first", ARTIFACTS_DIR)
+	}
+
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	opts, err := bind.NewKeyedTransactorWithChainID(key, testChainId)
+	require.NoError(t, err)
+	backend := simulated.NewBackend(types.GenesisAlloc{
+		opts.From:        {Balance: new(big.Int).Exp(big.NewInt(10), big.NewInt(21), nil)},
+		CREATE2_DEPLOYER: {Code: create2DeployerCode},
+	}, simulated.WithBlockGasLimit(30_000_000))
+	t.Cleanup(func() { backend.Close() })
+
+	c := &testChain{backend: backend, client: backend.Client(), opts: opts}
+
+	manager, managerAbi := c.deploy(t, "PoolManager", opts.From)
+	c.router, _ = c.deploy(t, "PoolSwapTest", manager)
+	taskManager, _ := c.deploy(t, "SwapXManagerMock")
+
+	tokenA, tokenAbi := c.deploy(t, "MockERC20", "Token0", "TK0", uint8(18))
+	tokenB, _ := c.deploy(t, "MockERC20", "Token1", "TK1", uint8(18))
+	if tokenA.Cmp(tokenB) > 0 {
+		tokenA, tokenB = tokenB, tokenA
+	}
+	c.currency0 = bind.NewBoundContract(tokenA, *tokenAbi, c.client, c.client, c.client)
+	c.currency1 = bind.NewBoundContract(tokenB, *tokenAbi, c.client, c.client, c.client)
+	for _, token := range []*bind.BoundContract{c.currency0, c.currency1} {
+		c.transact(t, token, "mint", opts.From, testTokenSupply)
+		c.transact(t, token, "approve", c.router, testTokenSupply)
+	}
+
+	c.poolKey = PoolKey{
+		Currency0:   tokenA,
+		Currency1:   tokenB,
+		Fee:         big.NewInt(3000),
+		TickSpacing: big.NewInt(60),
+		Hooks:       c.deployHook(t, manager, taskManager),
+	}
+	c.transact(t, bind.NewBoundContract(manager, *managerAbi, c.client, c.client, c.client), "initialize", c.poolKey, SQRT_PRICE_1_1)
+	return c
+}
+
+// mined commits the block holding tx and returns its receipt
+func (c *testChain) mined(t *testing.T, tx *types.Transaction) *types.Receipt {
+	c.backend.Commit()
+	receipt, err := c.client.TransactionReceipt(context.Background(), tx.Hash())
+	require.NoError(t, err)
+	require.Equal(t, types.ReceiptStatusSuccessful, receipt.Status)
+	return receipt
+}
+
+func (c *testChain) transact(t *testing.T, contract *bind.BoundContract, method string, params ...interface{}) *types.Receipt {
+	tx, err := contract.Transact(c.opts, method, params...)
+	require.NoError(t, err, method)
+	return c.mined(t, tx)
+}
+
+func (c *testChain) deploy(t *testing.T, contract string, params ...interface{}) (common.Address, *abi.ABI) {
+	parsed, bytecode, err := loadArtifact(contract)
+	require.NoError(t, err)
+	address, tx, _, err := bind.DeployContract(c.opts, *parsed, bytecode, c.client, params...)
+	require.NoError(t, err, contract)
+	c.mined(t, tx)
+	return address, parsed
+}
+
+// deployHook deploys SwapXHook through the CREATE2 deployer, with the first
+// salt that lands it on an address carrying its flags, as HookMiner does
+func (c *testChain) deployHook(t *testing.T, manager, taskManager common.Address) common.Address {
+	parsed, bytecode, err := loadArtifact("SwapXHook")
+	require.NoError(t, err)
+	args, err := parsed.Pack("", manager, taskManager)
+	require.NoError(t, err)
+	initCode := append(append([]byte{}, bytecode...), args...)
+	codeHash := crypto.Keccak256(initCode)
+
+	for salt := uint64(0); ; salt++ {
+		saltHash := common.BigToHash(new(big.Int).SetUint64(salt))
+		hook := crypto.CreateAddress2(CREATE2_DEPLOYER, saltHash, codeHash)
+		if binary.BigEndian.Uint16(hook[18:])&HOOK_FLAGS_MASK != SWAPX_HOOK_FLAGS {
+			continue
+		}
+
+		deployer := bind.NewBoundContract(CREATE2_DEPLOYER, abi.ABI{}, c.client, c.client, c.client)
+		tx, err := deployer.RawTransact(c.opts, append(saltHash.Bytes(), initCode...))
+		require.NoError(t, err)
+		c.mined(t, tx)
+
+		code, err := c.client.CodeAt(context.Background(), hook, nil)
+		require.NoError(t, err)
+		require.NotEmpty(t, code, "SwapXHook not deployed at %s", hook.Hex())
+		return hook
+	}
+}
+
+func (c *testChain) balanceOf(t *testing.T, token *bind.BoundContract, account common.Address) *big.Int {
+	var out []interface{}
+	require.NoError(t, token.Call(nil, &out, "balanceOf", account))
+	return out[0].(*big.Int)
+}
+
+func TestHookDataRoundTrip(t *testing.T) {
+	for _, data := range []HookData{
+		{SqrtPrice: big.NewInt(50), Sender: testAccount},
+		{SqrtPrice: big.NewInt(50), Sender: testAccount, Flags: FLAG_IMMEDIATE_OR_CANCEL},
+	} {
+		encoded, err := EncodeHookData(data)
+		require.NoError(t, err)
+		if data.Flags == 0 {
+			assert.Len(t, encoded, 64)
+		} else {
+			assert.Len(t, encoded, 96)
+		}
+
+		decoded, err := DecodeHookData(encoded)
+		require.NoError(t, err)
+		assert.Equal(t, data, *decoded)
+	}
+
+	_, err := DecodeHookData(make([]byte, 40))
+	assert.ErrorIs(t, err, ErrInvalidHookData)
+}
+
+func TestPlaceAndCancelOrder(t *testing.T) {
+	c := newTestChain(t)
+	client, err := NewClient(c.client, c.poolKey, c.router)
+	require.NoError(t, err)
+
+	// the hook escrows the whole amount of a buy order in currency0
+	tx, err := client.PlaceOrder(c.opts, LimitOrder{IsBuy: true, SqrtPrice: big.NewInt(50), Amount: big.NewInt(100), Flags: FLAG_IMMEDIATE_OR_CANCEL})
+	require.NoError(t, err)
+	orderId, isBuy, err := client.OrderCreated(c.mined(t, tx))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), orderId)
+	assert.True(t, isBuy)
+	assert.Equal(t, big.NewInt(100), c.balanceOf(t, c.currency0, c.poolKey.Hooks))
+
+	buy, err := client.Order(nil, orderId, true)
+	require.NoError(t, err)
+	assert.Equal(t, &Order{
+		Id:            0,
+		IsBuy:         true,
+		Account:       c.opts.From,
+		SqrtPrice:     big.NewInt(50),
+		Amount:        big.NewInt(100),
+		MatchedAmount: big.NewInt(0),
+		Flags:         FLAG_IMMEDIATE_OR_CANCEL,
+	}, buy)
+	assert.True(t, buy.IsOpen())
+	assert.Equal(t, big.NewInt(100), buy.Remaining())
+
+	// a sell order escrows currency1 and gets it back once cancelled
+	tx, err = client.PlaceOrder(c.opts, LimitOrder{SqrtPrice: big.NewInt(40), Amount: big.NewInt(60)})
+	require.NoError(t, err)
+	orderId, isBuy, err = client.OrderCreated(c.mined(t, tx))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), orderId)
+	assert.False(t, isBuy)
+	assert.Equal(t, big.NewInt(60), c.balanceOf(t, c.currency1, c.poolKey.Hooks))
+
+	tx, err = client.CancelOrder(c.opts, orderId, false)
+	require.NoError(t, err)
+	c.mined(t, tx)
+
+	sell, err := client.Order(nil, orderId, false)
+	require.NoError(t, err)
+	assert.True(t, sell.Cancelled)
+	assert.False(t, sell.IsOpen())
+	assert.Zero(t, c.balanceOf(t, c.currency1, c.poolKey.Hooks).Sign())
+
+	// the getters revert past the end of the order arrays
+	_, err = client.Order(nil, 1, false)
+	assert.Error(t, err)
+
+	_, err = client.PlaceOrder(c.opts, LimitOrder{SqrtPrice: big.NewInt(50), Amount: big.NewInt(0)})
+	assert.ErrorIs(t, err, ErrInvalidOrderAmount)
+}
+
+func TestOrderCreatedTakesTheIndex(t *testing.T) {
+	client, err := NewClient(nil, PoolKey{Hooks: testHook}, testRouter)
+	require.NoError(t, err)
+
+	hookAbi, err := SwapXHookMetaData.GetAbi()
+	require.NoError(t, err)
+	event := hookAbi.Events["OrderCreated"]
+	data, err := event.Inputs.NonIndexed().Pack(big.NewInt(50), big.NewInt(100), false)
+	require.NoError(t, err)
+
+	receipt := &types.Receipt{Logs: []*types.Log{{
+		Address: testHook,
+		Topics:  []common.Hash{event.ID, common.BigToHash(big.NewInt(1)), common.BytesToHash(testAccount.Bytes())},
+		Data:    data,
+	}}}
+	orderId, isBuy, err := client.OrderCreated(receipt)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), orderId)
+	assert.False(t, isBuy)
+
+	_, _, err = client.OrderCreated(&types.Receipt{})
+	assert.ErrorIs(t, err, ErrOrderCreatedNotFound)
+}
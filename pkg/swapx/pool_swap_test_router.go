@@ -0,0 +1,255 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package swapx
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = errors.New
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = ethereum.NotFound
+	_ = bind.Bind
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = event.NewSubscription
+	_ = abi.ConvertType
+)
+
+// IPoolManagerSwapParams is an auto generated low-level Go binding around an user-defined struct.
+type IPoolManagerSwapParams struct {
+	ZeroForOne        bool
+	AmountSpecified   *big.Int
+	SqrtPriceLimitX96 *big.Int
+}
+
+// PoolKey is an auto generated low-level Go binding around an user-defined struct.
+type PoolKey struct {
+	Currency0   common.Address
+	Currency1   common.Address
+	Fee         *big.Int
+	TickSpacing *big.Int
+	Hooks       common.Address
+}
+
+// PoolSwapTestTestSettings is an auto generated low-level Go binding around an user-defined struct.
+type PoolSwapTestTestSettings struct {
+	TakeClaims      bool
+	SettleUsingBurn bool
+}
+
+// PoolSwapTestMetaData contains all meta data concerning the PoolSwapTest contract.
+var PoolSwapTestMetaData = &bind.MetaData{
+	ABI: "[{\"type\":\"function\",\"name\":\"manager\",\"inputs\":[],\"outputs\":[{\"name\":\"\",\"type\":\"address\",\"internalType\":\"contractIPoolManager\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"swap\",\"inputs\":[{\"name\":\"key\",\"type\":\"tuple\",\"internalType\":\"structPoolKey\",\"components\":[{\"name\":\"currency0\",\"type\":\"address\",\"internalType\":\"Currency\"},{\"name\":\"currency1\",\"type\":\"address\",\"internalType\":\"Currency\"},{\"name\":\"fee\",\"type\":\"uint24\",\"internalType\":\"uint24\"},{\"name\":\"tickSpacing\",\"type\":\"int24\",\"internalType\":\"int24\"},{\"name\":\"hooks\",\"type\":\"address\",\"internalType\":\"contractIHooks\"}]},{\"name\":\"params\",\"type\":\"tuple\",\"internalType\":\"structIPoolManager.SwapParams\",\"components\":[{\"name\":\"zeroForOne\",\"type\":\"bool\",\"internalType\":\"bool\"},{\"name\":\"amountSpecified\",\"type\":\"int256\",\"internalType\":\"int256\"},{\"name\":\"sqrtPriceLimitX96\",\"type\":\"uint160\",\"internalType\":\"uint160\"}]},{\"name\":\"testSettings\",\"type\":\"tuple\",\"internalType\":\"structPoolSwapTest.TestSettings\",\"components\":[{\"name\":\"takeClaims\",\"type\":\"bool\",\"internalType\":\"bool\"},{\"name\":\"settleUsingBurn\",\"type\":\"bool\",\"internalType\":\"bool\"}]},{\"name\":\"hookData\",\"type\":\"bytes\",\"internalType\":\"bytes\"}],\"outputs\":[{\"name\":\"delta\",\"type\":\"int256\",\"internalType\":\"BalanceDelta\"}],\"stateMutability\":\"payable\"}]",
+}
+
+// PoolSwapTestABI is the input ABI used to generate the binding from.
+// Deprecated: Use PoolSwapTestMetaData.ABI instead.
+var PoolSwapTestABI = PoolSwapTestMetaData.ABI
+
+// PoolSwapTest is an auto generated Go binding around an Ethereum contract.
+type PoolSwapTest struct {
+	PoolSwapTestCaller     // Read-only binding to the contract
+	PoolSwapTestTransactor // Write-only binding to the contract
+	PoolSwapTestFilterer   // Log filterer for contract events
+}
+
+// PoolSwapTestCaller is an auto generated read-only Go binding around an Ethereum contract.
+type PoolSwapTestCaller struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// PoolSwapTestTransactor is an auto generated write-only Go binding around an Ethereum contract.
+type PoolSwapTestTransactor struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// PoolSwapTestFilterer is an auto generated log filtering Go binding around an Ethereum contract events.
+type PoolSwapTestFilterer struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// PoolSwapTestSession is an auto generated Go binding around an Ethereum contract,
+// with pre-set call and transact options.
+type PoolSwapTestSession struct {
+	Contract     *PoolSwapTest     // Generic contract binding to set the session for
+	CallOpts     bind.CallOpts     // Call options to use throughout this session
+	TransactOpts bind.TransactOpts // Transaction auth options to use throughout this session
+}
+
+// PoolSwapTestCallerSession is an auto generated read-only Go binding around an Ethereum contract,
+// with pre-set call options.
+type PoolSwapTestCallerSession struct {
+	Contract *PoolSwapTestCaller // Generic contract caller binding to set the session for
+	CallOpts bind.CallOpts       // Call options to use throughout this session
+}
+
+// PoolSwapTestTransactorSession is an auto generated write-only Go binding around an Ethereum contract,
+// with pre-set transact options.
+type PoolSwapTestTransactorSession struct {
+	Contract     *PoolSwapTestTransactor // Generic contract transactor binding to set the session for
+	TransactOpts bind.TransactOpts       // Transaction auth options to use throughout this session
+}
+
+// PoolSwapTestRaw is an auto generated low-level Go binding around an Ethereum contract.
+type PoolSwapTestRaw struct {
+	Contract *PoolSwapTest // Generic contract binding to access the raw methods on
+}
+
+// PoolSwapTestCallerRaw is an auto generated low-level read-only Go binding around an Ethereum contract.
+type PoolSwapTestCallerRaw struct {
+	Contract *PoolSwapTestCaller // Generic read-only contract binding to access the raw methods on
+}
+
+// PoolSwapTestTransactorRaw is an auto generated low-level write-only Go binding around an Ethereum contract.
+type PoolSwapTestTransactorRaw struct {
+	Contract *PoolSwapTestTransactor // Generic write-only contract binding to access the raw methods on
+}
+
+// NewPoolSwapTest creates a new instance of PoolSwapTest, bound to a specific deployed contract.
+func NewPoolSwapTest(address common.Address, backend bind.ContractBackend) (*PoolSwapTest, error) {
+	contract, err := bindPoolSwapTest(address, backend, backend, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &PoolSwapTest{PoolSwapTestCaller: PoolSwapTestCaller{contract: contract}, PoolSwapTestTransactor: PoolSwapTestTransactor{contract: contract}, PoolSwapTestFilterer: PoolSwapTestFilterer{contract: contract}}, nil
+}
+
+// NewPoolSwapTestCaller creates a new read-only instance of PoolSwapTest, bound to a specific deployed contract.
+func NewPoolSwapTestCaller(address common.Address, caller bind.ContractCaller) (*PoolSwapTestCaller, error) {
+	contract, err := bindPoolSwapTest(address, caller, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &PoolSwapTestCaller{contract: contract}, nil
+}
+
+// NewPoolSwapTestTransactor creates a new write-only instance of PoolSwapTest, bound to a specific deployed contract.
+func NewPoolSwapTestTransactor(address common.Address, transactor bind.ContractTransactor) (*PoolSwapTestTransactor, error) {
+	contract, err := bindPoolSwapTest(address, nil, transactor, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &PoolSwapTestTransactor{contract: contract}, nil
+}
+
+// NewPoolSwapTestFilterer creates a new log filterer instance of PoolSwapTest, bound to a specific deployed contract.
+func NewPoolSwapTestFilterer(address common.Address, filterer bind.ContractFilterer) (*PoolSwapTestFilterer, error) {
+	contract, err := bindPoolSwapTest(address, nil, nil, filterer)
+	if err != nil {
+		return nil, err
+	}
+	return &PoolSwapTestFilterer{contract: contract}, nil
+}
+
+// bindPoolSwapTest binds a generic wrapper to an already deployed contract.
+func bindPoolSwapTest(address common.Address, caller bind.ContractCaller, transactor bind.ContractTransactor, filterer bind.ContractFilterer) (*bind.BoundContract, error) {
+	parsed, err := PoolSwapTestMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	return bind.NewBoundContract(address, *parsed, caller, transactor, filterer), nil
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_PoolSwapTest *PoolSwapTestRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _PoolSwapTest.Contract.PoolSwapTestCaller.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_PoolSwapTest *PoolSwapTestRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _PoolSwapTest.Contract.PoolSwapTestTransactor.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_PoolSwapTest *PoolSwapTestRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _PoolSwapTest.Contract.PoolSwapTestTransactor.contract.Transact(opts, method, params...)
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_PoolSwapTest *PoolSwapTestCallerRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _PoolSwapTest.Contract.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_PoolSwapTest *PoolSwapTestTransactorRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _PoolSwapTest.Contract.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_PoolSwapTest *PoolSwapTestTransactorRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _PoolSwapTest.Contract.contract.Transact(opts, method, params...)
+}
+
+// Manager is a free data retrieval call binding the contract method 0x481c6a75.
+//
+// Solidity: function manager() view returns(address)
+func (_PoolSwapTest *PoolSwapTestCaller) Manager(opts *bind.CallOpts) (common.Address, error) {
+	var out []interface{}
+	err := _PoolSwapTest.contract.Call(opts, &out, "manager")
+
+	if err != nil {
+		return *new(common.Address), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
+
+	return out0, err
+
+}
+
+// Manager is a free data retrieval call binding the contract method 0x481c6a75.
+//
+// Solidity: function manager() view returns(address)
+func (_PoolSwapTest *PoolSwapTestSession) Manager() (common.Address, error) {
+	return _PoolSwapTest.Contract.Manager(&_PoolSwapTest.CallOpts)
+}
+
+// Manager is a free data retrieval call binding the contract method 0x481c6a75.
+//
+// Solidity: function manager() view returns(address)
+func (_PoolSwapTest *PoolSwapTestCallerSession) Manager() (common.Address, error) {
+	return _PoolSwapTest.Contract.Manager(&_PoolSwapTest.CallOpts)
+}
+
+// Swap is a paid mutator transaction binding the contract method 0x2229d0b4.
+//
+// Solidity: function swap((address,address,uint24,int24,address) key, (bool,int256,uint160) params, (bool,bool) testSettings, bytes hookData) payable returns(int256 delta)
+func (_PoolSwapTest *PoolSwapTestTransactor) Swap(opts *bind.TransactOpts, key PoolKey, params IPoolManagerSwapParams, testSettings PoolSwapTestTestSettings, hookData []byte) (*types.Transaction, error) {
+	return _PoolSwapTest.contract.Transact(opts, "swap", key, params, testSettings, hookData)
+}
+
+// Swap is a paid mutator transaction binding the contract method 0x2229d0b4.
+//
+// Solidity: function swap((address,address,uint24,int24,address) key, (bool,int256,uint160) params, (bool,bool) testSettings, bytes hookData) payable returns(int256 delta)
+func (_PoolSwapTest *PoolSwapTestSession) Swap(key PoolKey, params IPoolManagerSwapParams, testSettings PoolSwapTestTestSettings, hookData []byte) (*types.Transaction, error) {
+	return _PoolSwapTest.Contract.Swap(&_PoolSwapTest.TransactOpts, key, params, testSettings, hookData)
+}
+
+// Swap is a paid mutator transaction binding the contract method 0x2229d0b4.
+//
+// Solidity: function swap((address,address,uint24,int24,address) key, (bool,int256,uint160) params, (bool,bool) testSettings, bytes hookData) payable returns(int256 delta)
+func (_PoolSwapTest *PoolSwapTestTransactorSession) Swap(key PoolKey, params IPoolManagerSwapParams, testSettings PoolSwapTestTestSettings, hookData []byte) (*types.Transaction, error) {
+	return _PoolSwapTest.Contract.Swap(&_PoolSwapTest.TransactOpts, key, params, testSettings, hookData)
+}
@@ -0,0 +1,1898 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package swapx
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = errors.New
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = ethereum.NotFound
+	_ = bind.Bind
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = event.NewSubscription
+	_ = abi.ConvertType
+)
+
+// SwapXHookMetaData contains all meta data concerning the SwapXHook contract.
+var SwapXHookMetaData = &bind.MetaData{
+	ABI: "[{\"type\":\"function\",\"name\":\"FLAG_IMMEDIATE_OR_CANCEL\",\"inputs\":[],\"outputs\":[{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"FLAG_ROUTE_TO_POOL\",\"inputs\":[],\"outputs\":[{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"buyOrderCancelled\",\"inputs\":[{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"outputs\":[{\"name\":\"\",\"type\":\"bool\",\"internalType\":\"bool\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"buyOrderFlags\",\"inputs\":[{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"outputs\":[{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"buyOrders\",\"inputs\":[{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"outputs\":[{\"name\":\"account\",\"type\":\"address\",\"internalType\":\"address\"},{\"name\":\"sqrtPrice\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"amount\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"matchedAmount\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"cancelBuyOrder\",\"inputs\":[{\"name\":\"orderId\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"outputs\":[],\"stateMutability\":\"nonpayable\"},{\"type\":\"function\",\"name\":\"cancelSellOrder\",\"inputs\":[{\"name\":\"orderId\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"outputs\":[],\"stateMutability\":\"nonpayable\"},{\"type\":\"function\",\"name\":\"consumedFills\",\"inputs\":[{\"name\":\"\",\"type\":\"bytes32\",\"internalType\":\"bytes32\"}],\"outputs\":[{\"name\":\"\",\"type\":\"bool\",\"internalType\":\"bool\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"currency0\",\"inputs\":[],\"outputs\":[{\"name\":\"\",\"type\":\"address\",\"internalType\":\"Currency\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"currency1\",\"inputs\":[],\"outputs\":[{\"name\":\"\",\"type\":\"address\",\"internalType\":\"Currency\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"executeAsyncSwap\",\"inputs\":[{\"name\":\"fillId\",\"type\":\"bytes32\",\"internalType\":\"bytes32\"},{\"name\":\"buyOrderId\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"sellOrderId\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"quantity\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"expiryBlock\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"outputs\":[],\"stateMutability\":\"nonpayable\"},{\"type\":\"function\",\"name\":\"poolKey\",\"inputs\":[],\"outputs\":[{\"name\":\"currency0\",\"type\":\"address\",\"internalType\":\"Currency\"},{\"name\":\"currency1\",\"type\":\"address\",\"internalType\":\"Currency\"},{\"name\":\"fee\",\"type\":\"uint24\",\"internalType\":\"uint24\"},{\"name\":\"tickSpacing\",\"type\":\"int24\",\"internalType\":\"int24\"},{\"name\":\"hooks\",\"type\":\"address\",\"internalType\":\"contractIHooks\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"refundOrder\",\"inputs\":[{\"name\":\"orderId\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"isBuy\",\"type\":\"bool\",\"internalType\":\"bool\"}],\"outputs\":[],\"stateMutability\":\"nonpayable\"},{\"type\":\"function\",\"name\":\"sellOrderCancelled\",\"inputs\":[{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"outputs\":[{\"name\":\"\",\"type\":\"bool\",\"internalType\":\"bool\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"sellOrderFlags\",\"inputs\":[{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"outputs\":[{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"sellOrders\",\"inputs\":[{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"outputs\":[{\"name\":\"account\",\"type\":\"address\",\"internalType\":\"address\"},{\"name\":\"sqrtPrice\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"amount\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"matchedAmount\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"swapRemainder\",\"inputs\":[{\"name\":\"orderId\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"isBuy\",\"type\":\"bool\",\"internalType\":\"bool\"},{\"name\":\"amount\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"sqrtPriceLimitX96\",\"type\":\"uint160\",\"internalType\":\"uint160\"},{\"name\":\"expiryBlock\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"outputs\":[],\"stateMutability\":\"nonpayable\"},{\"type\":\"function\",\"name\":\"swapXTaskManager\",\"inputs\":[],\"outputs\":[{\"name\":\"\",\"type\":\"address\",\"internalType\":\"contractISwapXTaskManager\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"transferDust\",\"inputs\":[{\"name\":\"orderId\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"isBuy\",\"type\":\"bool\",\"internalType\":\"bool\"},{\"name\":\"amount\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"outputs\":[],\"stateMutability\":\"nonpayable\"},{\"type\":\"function\",\"name\":\"unlockCallback\",\"inputs\":[{\"name\":\"data\",\"type\":\"bytes\",\"internalType\":\"bytes\"}],\"outputs\":[{\"name\":\"\",\"type\":\"bytes\",\"internalType\":\"bytes\"}],\"stateMutability\":\"nonpayable\"},{\"type\":\"event\",\"name\":\"OrderCancelled\",\"inputs\":[{\"name\":\"orderId\",\"type\":\"uint256\",\"internalType\":\"uint256\",\"indexed\":true},{\"name\":\"account\",\"type\":\"address\",\"internalType\":\"address\",\"indexed\":true},{\"name\":\"sqrtPrice\",\"type\":\"uint256\",\"internalType\":\"uint256\",\"indexed\":false},{\"name\":\"amount\",\"type\":\"uint256\",\"internalType\":\"uint256\",\"indexed\":false},{\"name\":\"isBuy\",\"type\":\"bool\",\"internalType\":\"bool\",\"indexed\":false}],\"anonymous\":false},{\"type\":\"event\",\"name\":\"OrderCreated\",\"inputs\":[{\"name\":\"orderId\",\"type\":\"uint256\",\"internalType\":\"uint256\",\"indexed\":true},{\"name\":\"account\",\"type\":\"address\",\"internalType\":\"address\",\"indexed\":true},{\"name\":\"sqrtPrice\",\"type\":\"uint256\",\"internalType\":\"uint256\",\"indexed\":false},{\"name\":\"amount\",\"type\":\"uint256\",\"internalType\":\"uint256\",\"indexed\":false},{\"name\":\"isBuy\",\"type\":\"bool\",\"internalType\":\"bool\",\"indexed\":false}],\"anonymous\":false},{\"type\":\"event\",\"name\":\"OrderDustReturned\",\"inputs\":[{\"name\":\"orderId\",\"type\":\"uint256\",\"internalType\":\"uint256\",\"indexed\":true},{\"name\":\"account\",\"type\":\"address\",\"internalType\":\"address\",\"indexed\":true},{\"name\":\"amount\",\"type\":\"uint256\",\"internalType\":\"uint256\",\"indexed\":false},{\"name\":\"isBuy\",\"type\":\"bool\",\"internalType\":\"bool\",\"indexed\":false}],\"anonymous\":false},{\"type\":\"event\",\"name\":\"OrderFulfilled\",\"inputs\":[{\"name\":\"orderId\",\"type\":\"uint256\",\"internalType\":\"uint256\",\"indexed\":true},{\"name\":\"account\",\"type\":\"address\",\"internalType\":\"address\",\"indexed\":true},{\"name\":\"sqrtPrice\",\"type\":\"uint256\",\"internalType\":\"uint256\",\"indexed\":false},{\"name\":\"amount\",\"type\":\"uint256\",\"internalType\":\"uint256\",\"indexed\":false},{\"name\":\"isBuy\",\"type\":\"bool\",\"internalType\":\"bool\",\"indexed\":false}],\"anonymous\":false},{\"type\":\"event\",\"name\":\"OrderPartiallyFulfilled\",\"inputs\":[{\"name\":\"orderId\",\"type\":\"uint256\",\"internalType\":\"uint256\",\"indexed\":true},{\"name\":\"account\",\"type\":\"address\",\"internalType\":\"address\",\"indexed\":true},{\"name\":\"sqrtPrice\",\"type\":\"uint256\",\"internalType\":\"uint256\",\"indexed\":false},{\"name\":\"amount\",\"type\":\"uint256\",\"internalType\":\"uint256\",\"indexed\":false},{\"name\":\"isBuy\",\"type\":\"bool\",\"internalType\":\"bool\",\"indexed\":false}],\"anonymous\":false},{\"type\":\"event\",\"name\":\"OrderRefunded\",\"inputs\":[{\"name\":\"orderId\",\"type\":\"uint256\",\"internalType\":\"uint256\",\"indexed\":true},{\"name\":\"account\",\"type\":\"address\",\"internalType\":\"address\",\"indexed\":true},{\"name\":\"amount\",\"type\":\"uint256\",\"internalType\":\"uint256\",\"indexed\":false},{\"name\":\"isBuy\",\"type\":\"bool\",\"internalType\":\"bool\",\"indexed\":false}],\"anonymous\":false},{\"type\":\"event\",\"name\":\"OrderRoutedToPool\",\"inputs\":[{\"name\":\"orderId\",\"type\":\"uint256\",\"internalType\":\"uint256\",\"indexed\":true},{\"name\":\"account\",\"type\":\"address\",\"internalType\":\"address\",\"indexed\":true},{\"name\":\"amountIn\",\"type\":\"uint256\",\"internalType\":\"uint256\",\"indexed\":false},{\"name\":\"amountOut\",\"type\":\"uint256\",\"internalType\":\"uint256\",\"indexed\":false},{\"name\":\"isBuy\",\"type\":\"bool\",\"internalType\":\"bool\",\"indexed\":false}],\"anonymous\":false},{\"type\":\"error\",\"name\":\"FillAlreadyConsumed\",\"inputs\":[{\"name\":\"fillId\",\"type\":\"bytes32\",\"internalType\":\"bytes32\"}]},{\"type\":\"error\",\"name\":\"FillExpired\",\"inputs\":[{\"name\":\"fillId\",\"type\":\"bytes32\",\"internalType\":\"bytes32\"}]},{\"type\":\"error\",\"name\":\"InvalidFillQuantity\",\"inputs\":[]},{\"type\":\"error\",\"name\":\"OnlyOrderCreatorCanCancel\",\"inputs\":[]},{\"type\":\"error\",\"name\":\"OnlyPoolManager\",\"inputs\":[]},{\"type\":\"error\",\"name\":\"OnlyTaskManager\",\"inputs\":[]},{\"type\":\"error\",\"name\":\"OrderAlreadyFulfilled\",\"inputs\":[]},{\"type\":\"error\",\"name\":\"OrderDoesNotExist\",\"inputs\":[]},{\"type\":\"error\",\"name\":\"OrderSqrtPricesDoNotMatch\",\"inputs\":[]},{\"type\":\"error\",\"name\":\"OrderWasCancelled\",\"inputs\":[]},{\"type\":\"error\",\"name\":\"SwapExpired\",\"inputs\":[]}]",
+}
+
+// SwapXHookABI is the input ABI used to generate the binding from.
+// Deprecated: Use SwapXHookMetaData.ABI instead.
+var SwapXHookABI = SwapXHookMetaData.ABI
+
+// SwapXHook is an auto generated Go binding around an Ethereum contract.
+type SwapXHook struct {
+	SwapXHookCaller     // Read-only binding to the contract
+	SwapXHookTransactor // Write-only binding to the contract
+	SwapXHookFilterer   // Log filterer for contract events
+}
+
+// SwapXHookCaller is an auto generated read-only Go binding around an Ethereum contract.
+type SwapXHookCaller struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// SwapXHookTransactor is an auto generated write-only Go binding around an Ethereum contract.
+type SwapXHookTransactor struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// SwapXHookFilterer is an auto generated log filtering Go binding around an Ethereum contract events.
+type SwapXHookFilterer struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// SwapXHookSession is an auto generated Go binding around an Ethereum contract,
+// with pre-set call and transact options.
+type SwapXHookSession struct {
+	Contract     *SwapXHook        // Generic contract binding to set the session for
+	CallOpts     bind.CallOpts     // Call options to use throughout this session
+	TransactOpts bind.TransactOpts // Transaction auth options to use throughout this session
+}
+
+// SwapXHookCallerSession is an auto generated read-only Go binding around an Ethereum contract,
+// with pre-set call options.
+type SwapXHookCallerSession struct {
+	Contract *SwapXHookCaller // Generic contract caller binding to set the session for
+	CallOpts bind.CallOpts    // Call options to use throughout this session
+}
+
+// SwapXHookTransactorSession is an auto generated write-only Go binding around an Ethereum contract,
+// with pre-set transact options.
+type SwapXHookTransactorSession struct {
+	Contract     *SwapXHookTransactor // Generic contract transactor binding to set the session for
+	TransactOpts bind.TransactOpts    // Transaction auth options to use throughout this session
+}
+
+// SwapXHookRaw is an auto generated low-level Go binding around an Ethereum contract.
+type SwapXHookRaw struct {
+	Contract *SwapXHook // Generic contract binding to access the raw methods on
+}
+
+// SwapXHookCallerRaw is an auto generated low-level read-only Go binding around an Ethereum contract.
+type SwapXHookCallerRaw struct {
+	Contract *SwapXHookCaller // Generic read-only contract binding to access the raw methods on
+}
+
+// SwapXHookTransactorRaw is an auto generated low-level write-only Go binding around an Ethereum contract.
+type SwapXHookTransactorRaw struct {
+	Contract *SwapXHookTransactor // Generic write-only contract binding to access the raw methods on
+}
+
+// NewSwapXHook creates a new instance of SwapXHook, bound to a specific deployed contract.
+func NewSwapXHook(address common.Address, backend bind.ContractBackend) (*SwapXHook, error) {
+	contract, err := bindSwapXHook(address, backend, backend, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &SwapXHook{SwapXHookCaller: SwapXHookCaller{contract: contract}, SwapXHookTransactor: SwapXHookTransactor{contract: contract}, SwapXHookFilterer: SwapXHookFilterer{contract: contract}}, nil
+}
+
+// NewSwapXHookCaller creates a new read-only instance of SwapXHook, bound to a specific deployed contract.
+func NewSwapXHookCaller(address common.Address, caller bind.ContractCaller) (*SwapXHookCaller, error) {
+	contract, err := bindSwapXHook(address, caller, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &SwapXHookCaller{contract: contract}, nil
+}
+
+// NewSwapXHookTransactor creates a new write-only instance of SwapXHook, bound to a specific deployed contract.
+func NewSwapXHookTransactor(address common.Address, transactor bind.ContractTransactor) (*SwapXHookTransactor, error) {
+	contract, err := bindSwapXHook(address, nil, transactor, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &SwapXHookTransactor{contract: contract}, nil
+}
+
+// NewSwapXHookFilterer creates a new log filterer instance of SwapXHook, bound to a specific deployed contract.
+func NewSwapXHookFilterer(address common.Address, filterer bind.ContractFilterer) (*SwapXHookFilterer, error) {
+	contract, err := bindSwapXHook(address, nil, nil, filterer)
+	if err != nil {
+		return nil, err
+	}
+	return &SwapXHookFilterer{contract: contract}, nil
+}
+
+// bindSwapXHook binds a generic wrapper to an already deployed contract.
+func bindSwapXHook(address common.Address, caller bind.ContractCaller, transactor bind.ContractTransactor, filterer bind.ContractFilterer) (*bind.BoundContract, error) {
+	parsed, err := SwapXHookMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	return bind.NewBoundContract(address, *parsed, caller, transactor, filterer), nil
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_SwapXHook *SwapXHookRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _SwapXHook.Contract.SwapXHookCaller.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_SwapXHook *SwapXHookRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _SwapXHook.Contract.SwapXHookTransactor.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_SwapXHook *SwapXHookRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _SwapXHook.Contract.SwapXHookTransactor.contract.Transact(opts, method, params...)
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_SwapXHook *SwapXHookCallerRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _SwapXHook.Contract.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_SwapXHook *SwapXHookTransactorRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _SwapXHook.Contract.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_SwapXHook *SwapXHookTransactorRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _SwapXHook.Contract.contract.Transact(opts, method, params...)
+}
+
+// FLAGIMMEDIATEORCANCEL is a free data retrieval call binding the contract method 0x58ff8828.
+//
+// Solidity: function FLAG_IMMEDIATE_OR_CANCEL() view returns(uint256)
+func (_SwapXHook *SwapXHookCaller) FLAGIMMEDIATEORCANCEL(opts *bind.CallOpts) (*big.Int, error) {
+	var out []interface{}
+	err := _SwapXHook.contract.Call(opts, &out, "FLAG_IMMEDIATE_OR_CANCEL")
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// FLAGIMMEDIATEORCANCEL is a free data retrieval call binding the contract method 0x58ff8828.
+//
+// Solidity: function FLAG_IMMEDIATE_OR_CANCEL() view returns(uint256)
+func (_SwapXHook *SwapXHookSession) FLAGIMMEDIATEORCANCEL() (*big.Int, error) {
+	return _SwapXHook.Contract.FLAGIMMEDIATEORCANCEL(&_SwapXHook.CallOpts)
+}
+
+// FLAGIMMEDIATEORCANCEL is a free data retrieval call binding the contract method 0x58ff8828.
+//
+// Solidity: function FLAG_IMMEDIATE_OR_CANCEL() view returns(uint256)
+func (_SwapXHook *SwapXHookCallerSession) FLAGIMMEDIATEORCANCEL() (*big.Int, error) {
+	return _SwapXHook.Contract.FLAGIMMEDIATEORCANCEL(&_SwapXHook.CallOpts)
+}
+
+// FLAGROUTETOPOOL is a free data retrieval call binding the contract method 0x64f787d7.
+//
+// Solidity: function FLAG_ROUTE_TO_POOL() view returns(uint256)
+func (_SwapXHook *SwapXHookCaller) FLAGROUTETOPOOL(opts *bind.CallOpts) (*big.Int, error) {
+	var out []interface{}
+	err := _SwapXHook.contract.Call(opts, &out, "FLAG_ROUTE_TO_POOL")
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// FLAGROUTETOPOOL is a free data retrieval call binding the contract method 0x64f787d7.
+//
+// Solidity: function FLAG_ROUTE_TO_POOL() view returns(uint256)
+func (_SwapXHook *SwapXHookSession) FLAGROUTETOPOOL() (*big.Int, error) {
+	return _SwapXHook.Contract.FLAGROUTETOPOOL(&_SwapXHook.CallOpts)
+}
+
+// FLAGROUTETOPOOL is a free data retrieval call binding the contract method 0x64f787d7.
+//
+// Solidity: function FLAG_ROUTE_TO_POOL() view returns(uint256)
+func (_SwapXHook *SwapXHookCallerSession) FLAGROUTETOPOOL() (*big.Int, error) {
+	return _SwapXHook.Contract.FLAGROUTETOPOOL(&_SwapXHook.CallOpts)
+}
+
+// BuyOrderCancelled is a free data retrieval call binding the contract method 0xaf34209c.
+//
+// Solidity: function buyOrderCancelled(uint256 ) view returns(bool)
+func (_SwapXHook *SwapXHookCaller) BuyOrderCancelled(opts *bind.CallOpts, arg0 *big.Int) (bool, error) {
+	var out []interface{}
+	err := _SwapXHook.contract.Call(opts, &out, "buyOrderCancelled", arg0)
+
+	if err != nil {
+		return *new(bool), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(bool)).(*bool)
+
+	return out0, err
+
+}
+
+// BuyOrderCancelled is a free data retrieval call binding the contract method 0xaf34209c.
+//
+// Solidity: function buyOrderCancelled(uint256 ) view returns(bool)
+func (_SwapXHook *SwapXHookSession) BuyOrderCancelled(arg0 *big.Int) (bool, error) {
+	return _SwapXHook.Contract.BuyOrderCancelled(&_SwapXHook.CallOpts, arg0)
+}
+
+// BuyOrderCancelled is a free data retrieval call binding the contract method 0xaf34209c.
+//
+// Solidity: function buyOrderCancelled(uint256 ) view returns(bool)
+func (_SwapXHook *SwapXHookCallerSession) BuyOrderCancelled(arg0 *big.Int) (bool, error) {
+	return _SwapXHook.Contract.BuyOrderCancelled(&_SwapXHook.CallOpts, arg0)
+}
+
+// BuyOrderFlags is a free data retrieval call binding the contract method 0x19d6a105.
+//
+// Solidity: function buyOrderFlags(uint256 ) view returns(uint256)
+func (_SwapXHook *SwapXHookCaller) BuyOrderFlags(opts *bind.CallOpts, arg0 *big.Int) (*big.Int, error) {
+	var out []interface{}
+	err := _SwapXHook.contract.Call(opts, &out, "buyOrderFlags", arg0)
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// BuyOrderFlags is a free data retrieval call binding the contract method 0x19d6a105.
+//
+// Solidity: function buyOrderFlags(uint256 ) view returns(uint256)
+func (_SwapXHook *SwapXHookSession) BuyOrderFlags(arg0 *big.Int) (*big.Int, error) {
+	return _SwapXHook.Contract.BuyOrderFlags(&_SwapXHook.CallOpts, arg0)
+}
+
+// BuyOrderFlags is a free data retrieval call binding the contract method 0x19d6a105.
+//
+// Solidity: function buyOrderFlags(uint256 ) view returns(uint256)
+func (_SwapXHook *SwapXHookCallerSession) BuyOrderFlags(arg0 *big.Int) (*big.Int, error) {
+	return _SwapXHook.Contract.BuyOrderFlags(&_SwapXHook.CallOpts, arg0)
+}
+
+// BuyOrders is a free data retrieval call binding the contract method 0x35cea288.
+//
+// Solidity: function buyOrders(uint256 ) view returns(address account, uint256 sqrtPrice, uint256 amount, uint256 matchedAmount)
+func (_SwapXHook *SwapXHookCaller) BuyOrders(opts *bind.CallOpts, arg0 *big.Int) (struct {
+	Account       common.Address
+	SqrtPrice     *big.Int
+	Amount        *big.Int
+	MatchedAmount *big.Int
+}, error) {
+	var out []interface{}
+	err := _SwapXHook.contract.Call(opts, &out, "buyOrders", arg0)
+
+	outstruct := new(struct {
+		Account       common.Address
+		SqrtPrice     *big.Int
+		Amount        *big.Int
+		MatchedAmount *big.Int
+	})
+	if err != nil {
+		return *outstruct, err
+	}
+
+	outstruct.Account = *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
+	outstruct.SqrtPrice = *abi.ConvertType(out[1], new(*big.Int)).(**big.Int)
+	outstruct.Amount = *abi.ConvertType(out[2], new(*big.Int)).(**big.Int)
+	outstruct.MatchedAmount = *abi.ConvertType(out[3], new(*big.Int)).(**big.Int)
+
+	return *outstruct, err
+
+}
+
+// BuyOrders is a free data retrieval call binding the contract method 0x35cea288.
+//
+// Solidity: function buyOrders(uint256 ) view returns(address account, uint256 sqrtPrice, uint256 amount, uint256 matchedAmount)
+func (_SwapXHook *SwapXHookSession) BuyOrders(arg0 *big.Int) (struct {
+	Account       common.Address
+	SqrtPrice     *big.Int
+	Amount        *big.Int
+	MatchedAmount *big.Int
+}, error) {
+	return _SwapXHook.Contract.BuyOrders(&_SwapXHook.CallOpts, arg0)
+}
+
+// BuyOrders is a free data retrieval call binding the contract method 0x35cea288.
+//
+// Solidity: function buyOrders(uint256 ) view returns(address account, uint256 sqrtPrice, uint256 amount, uint256 matchedAmount)
+func (_SwapXHook *SwapXHookCallerSession) BuyOrders(arg0 *big.Int) (struct {
+	Account       common.Address
+	SqrtPrice     *big.Int
+	Amount        *big.Int
+	MatchedAmount *big.Int
+}, error) {
+	return _SwapXHook.Contract.BuyOrders(&_SwapXHook.CallOpts, arg0)
+}
+
+// ConsumedFills is a free data retrieval call binding the contract method 0x3ebf3c70.
+//
+// Solidity: function consumedFills(bytes32 ) view returns(bool)
+func (_SwapXHook *SwapXHookCaller) ConsumedFills(opts *bind.CallOpts, arg0 [32]byte) (bool, error) {
+	var out []interface{}
+	err := _SwapXHook.contract.Call(opts, &out, "consumedFills", arg0)
+
+	if err != nil {
+		return *new(bool), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(bool)).(*bool)
+
+	return out0, err
+
+}
+
+// ConsumedFills is a free data retrieval call binding the contract method 0x3ebf3c70.
+//
+// Solidity: function consumedFills(bytes32 ) view returns(bool)
+func (_SwapXHook *SwapXHookSession) ConsumedFills(arg0 [32]byte) (bool, error) {
+	return _SwapXHook.Contract.ConsumedFills(&_SwapXHook.CallOpts, arg0)
+}
+
+// ConsumedFills is a free data retrieval call binding the contract method 0x3ebf3c70.
+//
+// Solidity: function consumedFills(bytes32 ) view returns(bool)
+func (_SwapXHook *SwapXHookCallerSession) ConsumedFills(arg0 [32]byte) (bool, error) {
+	return _SwapXHook.Contract.ConsumedFills(&_SwapXHook.CallOpts, arg0)
+}
+
+// Currency0 is a free data retrieval call binding the contract method 0x79f1232b.
+//
+// Solidity: function currency0() view returns(address)
+func (_SwapXHook *SwapXHookCaller) Currency0(opts *bind.CallOpts) (common.Address, error) {
+	var out []interface{}
+	err := _SwapXHook.contract.Call(opts, &out, "currency0")
+
+	if err != nil {
+		return *new(common.Address), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
+
+	return out0, err
+
+}
+
+// Currency0 is a free data retrieval call binding the contract method 0x79f1232b.
+//
+// Solidity: function currency0() view returns(address)
+func (_SwapXHook *SwapXHookSession) Currency0() (common.Address, error) {
+	return _SwapXHook.Contract.Currency0(&_SwapXHook.CallOpts)
+}
+
+// Currency0 is a free data retrieval call binding the contract method 0x79f1232b.
+//
+// Solidity: function currency0() view returns(address)
+func (_SwapXHook *SwapXHookCallerSession) Currency0() (common.Address, error) {
+	return _SwapXHook.Contract.Currency0(&_SwapXHook.CallOpts)
+}
+
+// Currency1 is a free data retrieval call binding the contract method 0x10d737b8.
+//
+// Solidity: function currency1() view returns(address)
+func (_SwapXHook *SwapXHookCaller) Currency1(opts *bind.CallOpts) (common.Address, error) {
+	var out []interface{}
+	err := _SwapXHook.contract.Call(opts, &out, "currency1")
+
+	if err != nil {
+		return *new(common.Address), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
+
+	return out0, err
+
+}
+
+// Currency1 is a free data retrieval call binding the contract method 0x10d737b8.
+//
+// Solidity: function currency1() view returns(address)
+func (_SwapXHook *SwapXHookSession) Currency1() (common.Address, error) {
+	return _SwapXHook.Contract.Currency1(&_SwapXHook.CallOpts)
+}
+
+// Currency1 is a free data retrieval call binding the contract method 0x10d737b8.
+//
+// Solidity: function currency1() view returns(address)
+func (_SwapXHook *SwapXHookCallerSession) Currency1() (common.Address, error) {
+	return _SwapXHook.Contract.Currency1(&_SwapXHook.CallOpts)
+}
+
+// PoolKey is a free data retrieval call binding the contract method 0x182148ef.
+//
+// Solidity: function poolKey() view returns(address currency0, address currency1, uint24 fee, int24 tickSpacing, address hooks)
+func (_SwapXHook *SwapXHookCaller) PoolKey(opts *bind.CallOpts) (struct {
+	Currency0   common.Address
+	Currency1   common.Address
+	Fee         *big.Int
+	TickSpacing *big.Int
+	Hooks       common.Address
+}, error) {
+	var out []interface{}
+	err := _SwapXHook.contract.Call(opts, &out, "poolKey")
+
+	outstruct := new(struct {
+		Currency0   common.Address
+		Currency1   common.Address
+		Fee         *big.Int
+		TickSpacing *big.Int
+		Hooks       common.Address
+	})
+	if err != nil {
+		return *outstruct, err
+	}
+
+	outstruct.Currency0 = *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
+	outstruct.Currency1 = *abi.ConvertType(out[1], new(common.Address)).(*common.Address)
+	outstruct.Fee = *abi.ConvertType(out[2], new(*big.Int)).(**big.Int)
+	outstruct.TickSpacing = *abi.ConvertType(out[3], new(*big.Int)).(**big.Int)
+	outstruct.Hooks = *abi.ConvertType(out[4], new(common.Address)).(*common.Address)
+
+	return *outstruct, err
+
+}
+
+// PoolKey is a free data retrieval call binding the contract method 0x182148ef.
+//
+// Solidity: function poolKey() view returns(address currency0, address currency1, uint24 fee, int24 tickSpacing, address hooks)
+func (_SwapXHook *SwapXHookSession) PoolKey() (struct {
+	Currency0   common.Address
+	Currency1   common.Address
+	Fee         *big.Int
+	TickSpacing *big.Int
+	Hooks       common.Address
+}, error) {
+	return _SwapXHook.Contract.PoolKey(&_SwapXHook.CallOpts)
+}
+
+// PoolKey is a free data retrieval call binding the contract method 0x182148ef.
+//
+// Solidity: function poolKey() view returns(address currency0, address currency1, uint24 fee, int24 tickSpacing, address hooks)
+func (_SwapXHook *SwapXHookCallerSession) PoolKey() (struct {
+	Currency0   common.Address
+	Currency1   common.Address
+	Fee         *big.Int
+	TickSpacing *big.Int
+	Hooks       common.Address
+}, error) {
+	return _SwapXHook.Contract.PoolKey(&_SwapXHook.CallOpts)
+}
+
+// SellOrderCancelled is a free data retrieval call binding the contract method 0xf80dfc8d.
+//
+// Solidity: function sellOrderCancelled(uint256 ) view returns(bool)
+func (_SwapXHook *SwapXHookCaller) SellOrderCancelled(opts *bind.CallOpts, arg0 *big.Int) (bool, error) {
+	var out []interface{}
+	err := _SwapXHook.contract.Call(opts, &out, "sellOrderCancelled", arg0)
+
+	if err != nil {
+		return *new(bool), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(bool)).(*bool)
+
+	return out0, err
+
+}
+
+// SellOrderCancelled is a free data retrieval call binding the contract method 0xf80dfc8d.
+//
+// Solidity: function sellOrderCancelled(uint256 ) view returns(bool)
+func (_SwapXHook *SwapXHookSession) SellOrderCancelled(arg0 *big.Int) (bool, error) {
+	return _SwapXHook.Contract.SellOrderCancelled(&_SwapXHook.CallOpts, arg0)
+}
+
+// SellOrderCancelled is a free data retrieval call binding the contract method 0xf80dfc8d.
+//
+// Solidity: function sellOrderCancelled(uint256 ) view returns(bool)
+func (_SwapXHook *SwapXHookCallerSession) SellOrderCancelled(arg0 *big.Int) (bool, error) {
+	return _SwapXHook.Contract.SellOrderCancelled(&_SwapXHook.CallOpts, arg0)
+}
+
+// SellOrderFlags is a free data retrieval call binding the contract method 0x447d66af.
+//
+// Solidity: function sellOrderFlags(uint256 ) view returns(uint256)
+func (_SwapXHook *SwapXHookCaller) SellOrderFlags(opts *bind.CallOpts, arg0 *big.Int) (*big.Int, error) {
+	var out []interface{}
+	err := _SwapXHook.contract.Call(opts, &out, "sellOrderFlags", arg0)
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// SellOrderFlags is a free data retrieval call binding the contract method 0x447d66af.
+//
+// Solidity: function sellOrderFlags(uint256 ) view returns(uint256)
+func (_SwapXHook *SwapXHookSession) SellOrderFlags(arg0 *big.Int) (*big.Int, error) {
+	return _SwapXHook.Contract.SellOrderFlags(&_SwapXHook.CallOpts, arg0)
+}
+
+// SellOrderFlags is a free data retrieval call binding the contract method 0x447d66af.
+//
+// Solidity: function sellOrderFlags(uint256 ) view returns(uint256)
+func (_SwapXHook *SwapXHookCallerSession) SellOrderFlags(arg0 *big.Int) (*big.Int, error) {
+	return _SwapXHook.Contract.SellOrderFlags(&_SwapXHook.CallOpts, arg0)
+}
+
+// SellOrders is a free data retrieval call binding the contract method 0x4a8393f3.
+//
+// Solidity: function sellOrders(uint256 ) view returns(address account, uint256 sqrtPrice, uint256 amount, uint256 matchedAmount)
+func (_SwapXHook *SwapXHookCaller) SellOrders(opts *bind.CallOpts, arg0 *big.Int) (struct {
+	Account       common.Address
+	SqrtPrice     *big.Int
+	Amount        *big.Int
+	MatchedAmount *big.Int
+}, error) {
+	var out []interface{}
+	err := _SwapXHook.contract.Call(opts, &out, "sellOrders", arg0)
+
+	outstruct := new(struct {
+		Account       common.Address
+		SqrtPrice     *big.Int
+		Amount        *big.Int
+		MatchedAmount *big.Int
+	})
+	if err != nil {
+		return *outstruct, err
+	}
+
+	outstruct.Account = *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
+	outstruct.SqrtPrice = *abi.ConvertType(out[1], new(*big.Int)).(**big.Int)
+	outstruct.Amount = *abi.ConvertType(out[2], new(*big.Int)).(**big.Int)
+	outstruct.MatchedAmount = *abi.ConvertType(out[3], new(*big.Int)).(**big.Int)
+
+	return *outstruct, err
+
+}
+
+// SellOrders is a free data retrieval call binding the contract method 0x4a8393f3.
+//
+// Solidity: function sellOrders(uint256 ) view returns(address account, uint256 sqrtPrice, uint256 amount, uint256 matchedAmount)
+func (_SwapXHook *SwapXHookSession) SellOrders(arg0 *big.Int) (struct {
+	Account       common.Address
+	SqrtPrice     *big.Int
+	Amount        *big.Int
+	MatchedAmount *big.Int
+}, error) {
+	return _SwapXHook.Contract.SellOrders(&_SwapXHook.CallOpts, arg0)
+}
+
+// SellOrders is a free data retrieval call binding the contract method 0x4a8393f3.
+//
+// Solidity: function sellOrders(uint256 ) view returns(address account, uint256 sqrtPrice, uint256 amount, uint256 matchedAmount)
+func (_SwapXHook *SwapXHookCallerSession) SellOrders(arg0 *big.Int) (struct {
+	Account       common.Address
+	SqrtPrice     *big.Int
+	Amount        *big.Int
+	MatchedAmount *big.Int
+}, error) {
+	return _SwapXHook.Contract.SellOrders(&_SwapXHook.CallOpts, arg0)
+}
+
+// SwapXTaskManager is a free data retrieval call binding the contract method 0xd67eedeb.
+//
+// Solidity: function swapXTaskManager() view returns(address)
+func (_SwapXHook *SwapXHookCaller) SwapXTaskManager(opts *bind.CallOpts) (common.Address, error) {
+	var out []interface{}
+	err := _SwapXHook.contract.Call(opts, &out, "swapXTaskManager")
+
+	if err != nil {
+		return *new(common.Address), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
+
+	return out0, err
+
+}
+
+// SwapXTaskManager is a free data retrieval call binding the contract method 0xd67eedeb.
+//
+// Solidity: function swapXTaskManager() view returns(address)
+func (_SwapXHook *SwapXHookSession) SwapXTaskManager() (common.Address, error) {
+	return _SwapXHook.Contract.SwapXTaskManager(&_SwapXHook.CallOpts)
+}
+
+// SwapXTaskManager is a free data retrieval call binding the contract method 0xd67eedeb.
+//
+// Solidity: function swapXTaskManager() view returns(address)
+func (_SwapXHook *SwapXHookCallerSession) SwapXTaskManager() (common.Address, error) {
+	return _SwapXHook.Contract.SwapXTaskManager(&_SwapXHook.CallOpts)
+}
+
+// CancelBuyOrder is a paid mutator transaction binding the contract method 0xbe5d147f.
+//
+// Solidity: function cancelBuyOrder(uint256 orderId) returns()
+func (_SwapXHook *SwapXHookTransactor) CancelBuyOrder(opts *bind.TransactOpts, orderId *big.Int) (*types.Transaction, error) {
+	return _SwapXHook.contract.Transact(opts, "cancelBuyOrder", orderId)
+}
+
+// CancelBuyOrder is a paid mutator transaction binding the contract method 0xbe5d147f.
+//
+// Solidity: function cancelBuyOrder(uint256 orderId) returns()
+func (_SwapXHook *SwapXHookSession) CancelBuyOrder(orderId *big.Int) (*types.Transaction, error) {
+	return _SwapXHook.Contract.CancelBuyOrder(&_SwapXHook.TransactOpts, orderId)
+}
+
+// CancelBuyOrder is a paid mutator transaction binding the contract method 0xbe5d147f.
+//
+// Solidity: function cancelBuyOrder(uint256 orderId) returns()
+func (_SwapXHook *SwapXHookTransactorSession) CancelBuyOrder(orderId *big.Int) (*types.Transaction, error) {
+	return _SwapXHook.Contract.CancelBuyOrder(&_SwapXHook.TransactOpts, orderId)
+}
+
+// CancelSellOrder is a paid mutator transaction binding the contract method 0x1a46e42a.
+//
+// Solidity: function cancelSellOrder(uint256 orderId) returns()
+func (_SwapXHook *SwapXHookTransactor) CancelSellOrder(opts *bind.TransactOpts, orderId *big.Int) (*types.Transaction, error) {
+	return _SwapXHook.contract.Transact(opts, "cancelSellOrder", orderId)
+}
+
+// CancelSellOrder is a paid mutator transaction binding the contract method 0x1a46e42a.
+//
+// Solidity: function cancelSellOrder(uint256 orderId) returns()
+func (_SwapXHook *SwapXHookSession) CancelSellOrder(orderId *big.Int) (*types.Transaction, error) {
+	return _SwapXHook.Contract.CancelSellOrder(&_SwapXHook.TransactOpts, orderId)
+}
+
+// CancelSellOrder is a paid mutator transaction binding the contract method 0x1a46e42a.
+//
+// Solidity: function cancelSellOrder(uint256 orderId) returns()
+func (_SwapXHook *SwapXHookTransactorSession) CancelSellOrder(orderId *big.Int) (*types.Transaction, error) {
+	return _SwapXHook.Contract.CancelSellOrder(&_SwapXHook.TransactOpts, orderId)
+}
+
+// ExecuteAsyncSwap is a paid mutator transaction binding the contract method 0x016a2cec.
+//
+// Solidity: function executeAsyncSwap(bytes32 fillId, uint256 buyOrderId, uint256 sellOrderId, uint256 quantity, uint256 expiryBlock) returns()
+func (_SwapXHook *SwapXHookTransactor) ExecuteAsyncSwap(opts *bind.TransactOpts, fillId [32]byte, buyOrderId *big.Int, sellOrderId *big.Int, quantity *big.Int, expiryBlock *big.Int) (*types.Transaction, error) {
+	return _SwapXHook.contract.Transact(opts, "executeAsyncSwap", fillId, buyOrderId, sellOrderId, quantity, expiryBlock)
+}
+
+// ExecuteAsyncSwap is a paid mutator transaction binding the contract method 0x016a2cec.
+//
+// Solidity: function executeAsyncSwap(bytes32 fillId, uint256 buyOrderId, uint256 sellOrderId, uint256 quantity, uint256 expiryBlock) returns()
+func (_SwapXHook *SwapXHookSession) ExecuteAsyncSwap(fillId [32]byte, buyOrderId *big.Int, sellOrderId *big.Int, quantity *big.Int, expiryBlock *big.Int) (*types.Transaction, error) {
+	return _SwapXHook.Contract.ExecuteAsyncSwap(&_SwapXHook.TransactOpts, fillId, buyOrderId, sellOrderId, quantity, expiryBlock)
+}
+
+// ExecuteAsyncSwap is a paid mutator transaction binding the contract method 0x016a2cec.
+//
+// Solidity: function executeAsyncSwap(bytes32 fillId, uint256 buyOrderId, uint256 sellOrderId, uint256 quantity, uint256 expiryBlock) returns()
+func (_SwapXHook *SwapXHookTransactorSession) ExecuteAsyncSwap(fillId [32]byte, buyOrderId *big.Int, sellOrderId *big.Int, quantity *big.Int, expiryBlock *big.Int) (*types.Transaction, error) {
+	return _SwapXHook.Contract.ExecuteAsyncSwap(&_SwapXHook.TransactOpts, fillId, buyOrderId, sellOrderId, quantity, expiryBlock)
+}
+
+// RefundOrder is a paid mutator transaction binding the contract method 0x2d773310.
+//
+// Solidity: function refundOrder(uint256 orderId, bool isBuy) returns()
+func (_SwapXHook *SwapXHookTransactor) RefundOrder(opts *bind.TransactOpts, orderId *big.Int, isBuy bool) (*types.Transaction, error) {
+	return _SwapXHook.contract.Transact(opts, "refundOrder", orderId, isBuy)
+}
+
+// RefundOrder is a paid mutator transaction binding the contract method 0x2d773310.
+//
+// Solidity: function refundOrder(uint256 orderId, bool isBuy) returns()
+func (_SwapXHook *SwapXHookSession) RefundOrder(orderId *big.Int, isBuy bool) (*types.Transaction, error) {
+	return _SwapXHook.Contract.RefundOrder(&_SwapXHook.TransactOpts, orderId, isBuy)
+}
+
+// RefundOrder is a paid mutator transaction binding the contract method 0x2d773310.
+//
+// Solidity: function refundOrder(uint256 orderId, bool isBuy) returns()
+func (_SwapXHook *SwapXHookTransactorSession) RefundOrder(orderId *big.Int, isBuy bool) (*types.Transaction, error) {
+	return _SwapXHook.Contract.RefundOrder(&_SwapXHook.TransactOpts, orderId, isBuy)
+}
+
+// SwapRemainder is a paid mutator transaction binding the contract method 0xf1e6ec0e.
+//
+// Solidity: function swapRemainder(uint256 orderId, bool isBuy, uint256 amount, uint160 sqrtPriceLimitX96, uint256 expiryBlock) returns()
+func (_SwapXHook *SwapXHookTransactor) SwapRemainder(opts *bind.TransactOpts, orderId *big.Int, isBuy bool, amount *big.Int, sqrtPriceLimitX96 *big.Int, expiryBlock *big.Int) (*types.Transaction, error) {
+	return _SwapXHook.contract.Transact(opts, "swapRemainder", orderId, isBuy, amount, sqrtPriceLimitX96, expiryBlock)
+}
+
+// SwapRemainder is a paid mutator transaction binding the contract method 0xf1e6ec0e.
+//
+// Solidity: function swapRemainder(uint256 orderId, bool isBuy, uint256 amount, uint160 sqrtPriceLimitX96, uint256 expiryBlock) returns()
+func (_SwapXHook *SwapXHookSession) SwapRemainder(orderId *big.Int, isBuy bool, amount *big.Int, sqrtPriceLimitX96 *big.Int, expiryBlock *big.Int) (*types.Transaction, error) {
+	return _SwapXHook.Contract.SwapRemainder(&_SwapXHook.TransactOpts, orderId, isBuy, amount, sqrtPriceLimitX96, expiryBlock)
+}
+
+// SwapRemainder is a paid mutator transaction binding the contract method 0xf1e6ec0e.
+//
+// Solidity: function swapRemainder(uint256 orderId, bool isBuy, uint256 amount, uint160 sqrtPriceLimitX96, uint256 expiryBlock) returns()
+func (_SwapXHook *SwapXHookTransactorSession) SwapRemainder(orderId *big.Int, isBuy bool, amount *big.Int, sqrtPriceLimitX96 *big.Int, expiryBlock *big.Int) (*types.Transaction, error) {
+	return _SwapXHook.Contract.SwapRemainder(&_SwapXHook.TransactOpts, orderId, isBuy, amount, sqrtPriceLimitX96, expiryBlock)
+}
+
+// TransferDust is a paid mutator transaction binding the contract method 0x64184826.
+//
+// Solidity: function transferDust(uint256 orderId, bool isBuy, uint256 amount) returns()
+func (_SwapXHook *SwapXHookTransactor) TransferDust(opts *bind.TransactOpts, orderId *big.Int, isBuy bool, amount *big.Int) (*types.Transaction, error) {
+	return _SwapXHook.contract.Transact(opts, "transferDust", orderId, isBuy, amount)
+}
+
+// TransferDust is a paid mutator transaction binding the contract method 0x64184826.
+//
+// Solidity: function transferDust(uint256 orderId, bool isBuy, uint256 amount) returns()
+func (_SwapXHook *SwapXHookSession) TransferDust(orderId *big.Int, isBuy bool, amount *big.Int) (*types.Transaction, error) {
+	return _SwapXHook.Contract.TransferDust(&_SwapXHook.TransactOpts, orderId, isBuy, amount)
+}
+
+// TransferDust is a paid mutator transaction binding the contract method 0x64184826.
+//
+// Solidity: function transferDust(uint256 orderId, bool isBuy, uint256 amount) returns()
+func (_SwapXHook *SwapXHookTransactorSession) TransferDust(orderId *big.Int, isBuy bool, amount *big.Int) (*types.Transaction, error) {
+	return _SwapXHook.Contract.TransferDust(&_SwapXHook.TransactOpts, orderId, isBuy, amount)
+}
+
+// UnlockCallback is a paid mutator transaction binding the contract method 0x91dd7346.
+//
+// Solidity: function unlockCallback(bytes data) returns(bytes)
+func (_SwapXHook *SwapXHookTransactor) UnlockCallback(opts *bind.TransactOpts, data []byte) (*types.Transaction, error) {
+	return _SwapXHook.contract.Transact(opts, "unlockCallback", data)
+}
+
+// UnlockCallback is a paid mutator transaction binding the contract method 0x91dd7346.
+//
+// Solidity: function unlockCallback(bytes data) returns(bytes)
+func (_SwapXHook *SwapXHookSession) UnlockCallback(data []byte) (*types.Transaction, error) {
+	return _SwapXHook.Contract.UnlockCallback(&_SwapXHook.TransactOpts, data)
+}
+
+// UnlockCallback is a paid mutator transaction binding the contract method 0x91dd7346.
+//
+// Solidity: function unlockCallback(bytes data) returns(bytes)
+func (_SwapXHook *SwapXHookTransactorSession) UnlockCallback(data []byte) (*types.Transaction, error) {
+	return _SwapXHook.Contract.UnlockCallback(&_SwapXHook.TransactOpts, data)
+}
+
+// SwapXHookOrderCancelledIterator is returned from FilterOrderCancelled and is used to iterate over the raw logs and unpacked data for OrderCancelled events raised by the SwapXHook contract.
+type SwapXHookOrderCancelledIterator struct {
+	Event *SwapXHookOrderCancelled // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *SwapXHookOrderCancelledIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(SwapXHookOrderCancelled)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(SwapXHookOrderCancelled)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *SwapXHookOrderCancelledIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *SwapXHookOrderCancelledIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// SwapXHookOrderCancelled represents a OrderCancelled event raised by the SwapXHook contract.
+type SwapXHookOrderCancelled struct {
+	OrderId   *big.Int
+	Account   common.Address
+	SqrtPrice *big.Int
+	Amount    *big.Int
+	IsBuy     bool
+	Raw       types.Log // Blockchain specific contextual infos
+}
+
+// FilterOrderCancelled is a free log retrieval operation binding the contract event 0xe2f79d8cf55407b6b042d56861cd4f3080af047b848dc8aa1572e11f46a83ea0.
+//
+// Solidity: event OrderCancelled(uint256 indexed orderId, address indexed account, uint256 sqrtPrice, uint256 amount, bool isBuy)
+func (_SwapXHook *SwapXHookFilterer) FilterOrderCancelled(opts *bind.FilterOpts, orderId []*big.Int, account []common.Address) (*SwapXHookOrderCancelledIterator, error) {
+
+	var orderIdRule []interface{}
+	for _, orderIdItem := range orderId {
+		orderIdRule = append(orderIdRule, orderIdItem)
+	}
+	var accountRule []interface{}
+	for _, accountItem := range account {
+		accountRule = append(accountRule, accountItem)
+	}
+
+	logs, sub, err := _SwapXHook.contract.FilterLogs(opts, "OrderCancelled", orderIdRule, accountRule)
+	if err != nil {
+		return nil, err
+	}
+	return &SwapXHookOrderCancelledIterator{contract: _SwapXHook.contract, event: "OrderCancelled", logs: logs, sub: sub}, nil
+}
+
+// WatchOrderCancelled is a free log subscription operation binding the contract event 0xe2f79d8cf55407b6b042d56861cd4f3080af047b848dc8aa1572e11f46a83ea0.
+//
+// Solidity: event OrderCancelled(uint256 indexed orderId, address indexed account, uint256 sqrtPrice, uint256 amount, bool isBuy)
+func (_SwapXHook *SwapXHookFilterer) WatchOrderCancelled(opts *bind.WatchOpts, sink chan<- *SwapXHookOrderCancelled, orderId []*big.Int, account []common.Address) (event.Subscription, error) {
+
+	var orderIdRule []interface{}
+	for _, orderIdItem := range orderId {
+		orderIdRule = append(orderIdRule, orderIdItem)
+	}
+	var accountRule []interface{}
+	for _, accountItem := range account {
+		accountRule = append(accountRule, accountItem)
+	}
+
+	logs, sub, err := _SwapXHook.contract.WatchLogs(opts, "OrderCancelled", orderIdRule, accountRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(SwapXHookOrderCancelled)
+				if err := _SwapXHook.contract.UnpackLog(event, "OrderCancelled", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseOrderCancelled is a log parse operation binding the contract event 0xe2f79d8cf55407b6b042d56861cd4f3080af047b848dc8aa1572e11f46a83ea0.
+//
+// Solidity: event OrderCancelled(uint256 indexed orderId, address indexed account, uint256 sqrtPrice, uint256 amount, bool isBuy)
+func (_SwapXHook *SwapXHookFilterer) ParseOrderCancelled(log types.Log) (*SwapXHookOrderCancelled, error) {
+	event := new(SwapXHookOrderCancelled)
+	if err := _SwapXHook.contract.UnpackLog(event, "OrderCancelled", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// SwapXHookOrderCreatedIterator is returned from FilterOrderCreated and is used to iterate over the raw logs and unpacked data for OrderCreated events raised by the SwapXHook contract.
+type SwapXHookOrderCreatedIterator struct {
+	Event *SwapXHookOrderCreated // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *SwapXHookOrderCreatedIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(SwapXHookOrderCreated)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(SwapXHookOrderCreated)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *SwapXHookOrderCreatedIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *SwapXHookOrderCreatedIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// SwapXHookOrderCreated represents a OrderCreated event raised by the SwapXHook contract.
+type SwapXHookOrderCreated struct {
+	OrderId   *big.Int
+	Account   common.Address
+	SqrtPrice *big.Int
+	Amount    *big.Int
+	IsBuy     bool
+	Raw       types.Log // Blockchain specific contextual infos
+}
+
+// FilterOrderCreated is a free log retrieval operation binding the contract event 0x06e7b9568fe8990c21116c42aadd1ea3184e7a30588ea8e4264a056d0f7f7d97.
+//
+// Solidity: event OrderCreated(uint256 indexed orderId, address indexed account, uint256 sqrtPrice, uint256 amount, bool isBuy)
+func (_SwapXHook *SwapXHookFilterer) FilterOrderCreated(opts *bind.FilterOpts, orderId []*big.Int, account []common.Address) (*SwapXHookOrderCreatedIterator, error) {
+
+	var orderIdRule []interface{}
+	for _, orderIdItem := range orderId {
+		orderIdRule = append(orderIdRule, orderIdItem)
+	}
+	var accountRule []interface{}
+	for _, accountItem := range account {
+		accountRule = append(accountRule, accountItem)
+	}
+
+	logs, sub, err := _SwapXHook.contract.FilterLogs(opts, "OrderCreated", orderIdRule, accountRule)
+	if err != nil {
+		return nil, err
+	}
+	return &SwapXHookOrderCreatedIterator{contract: _SwapXHook.contract, event: "OrderCreated", logs: logs, sub: sub}, nil
+}
+
+// WatchOrderCreated is a free log subscription operation binding the contract event 0x06e7b9568fe8990c21116c42aadd1ea3184e7a30588ea8e4264a056d0f7f7d97.
+//
+// Solidity: event OrderCreated(uint256 indexed orderId, address indexed account, uint256 sqrtPrice, uint256 amount, bool isBuy)
+func (_SwapXHook *SwapXHookFilterer) WatchOrderCreated(opts *bind.WatchOpts, sink chan<- *SwapXHookOrderCreated, orderId []*big.Int, account []common.Address) (event.Subscription, error) {
+
+	var orderIdRule []interface{}
+	for _, orderIdItem := range orderId {
+		orderIdRule = append(orderIdRule, orderIdItem)
+	}
+	var accountRule []interface{}
+	for _, accountItem := range account {
+		accountRule = append(accountRule, accountItem)
+	}
+
+	logs, sub, err := _SwapXHook.contract.WatchLogs(opts, "OrderCreated", orderIdRule, accountRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(SwapXHookOrderCreated)
+				if err := _SwapXHook.contract.UnpackLog(event, "OrderCreated", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseOrderCreated is a log parse operation binding the contract event 0x06e7b9568fe8990c21116c42aadd1ea3184e7a30588ea8e4264a056d0f7f7d97.
+//
+// Solidity: event OrderCreated(uint256 indexed orderId, address indexed account, uint256 sqrtPrice, uint256 amount, bool isBuy)
+func (_SwapXHook *SwapXHookFilterer) ParseOrderCreated(log types.Log) (*SwapXHookOrderCreated, error) {
+	event := new(SwapXHookOrderCreated)
+	if err := _SwapXHook.contract.UnpackLog(event, "OrderCreated", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// SwapXHookOrderDustReturnedIterator is returned from FilterOrderDustReturned and is used to iterate over the raw logs and unpacked data for OrderDustReturned events raised by the SwapXHook contract.
+type SwapXHookOrderDustReturnedIterator struct {
+	Event *SwapXHookOrderDustReturned // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *SwapXHookOrderDustReturnedIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(SwapXHookOrderDustReturned)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(SwapXHookOrderDustReturned)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *SwapXHookOrderDustReturnedIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *SwapXHookOrderDustReturnedIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// SwapXHookOrderDustReturned represents a OrderDustReturned event raised by the SwapXHook contract.
+type SwapXHookOrderDustReturned struct {
+	OrderId *big.Int
+	Account common.Address
+	Amount  *big.Int
+	IsBuy   bool
+	Raw     types.Log // Blockchain specific contextual infos
+}
+
+// FilterOrderDustReturned is a free log retrieval operation binding the contract event 0xa201d503707dccee563c2bf2969fd21c3f881f7a7dd191fe3c6d8b627a21b994.
+//
+// Solidity: event OrderDustReturned(uint256 indexed orderId, address indexed account, uint256 amount, bool isBuy)
+func (_SwapXHook *SwapXHookFilterer) FilterOrderDustReturned(opts *bind.FilterOpts, orderId []*big.Int, account []common.Address) (*SwapXHookOrderDustReturnedIterator, error) {
+
+	var orderIdRule []interface{}
+	for _, orderIdItem := range orderId {
+		orderIdRule = append(orderIdRule, orderIdItem)
+	}
+	var accountRule []interface{}
+	for _, accountItem := range account {
+		accountRule = append(accountRule, accountItem)
+	}
+
+	logs, sub, err := _SwapXHook.contract.FilterLogs(opts, "OrderDustReturned", orderIdRule, accountRule)
+	if err != nil {
+		return nil, err
+	}
+	return &SwapXHookOrderDustReturnedIterator{contract: _SwapXHook.contract, event: "OrderDustReturned", logs: logs, sub: sub}, nil
+}
+
+// WatchOrderDustReturned is a free log subscription operation binding the contract event 0xa201d503707dccee563c2bf2969fd21c3f881f7a7dd191fe3c6d8b627a21b994.
+//
+// Solidity: event OrderDustReturned(uint256 indexed orderId, address indexed account, uint256 amount, bool isBuy)
+func (_SwapXHook *SwapXHookFilterer) WatchOrderDustReturned(opts *bind.WatchOpts, sink chan<- *SwapXHookOrderDustReturned, orderId []*big.Int, account []common.Address) (event.Subscription, error) {
+
+	var orderIdRule []interface{}
+	for _, orderIdItem := range orderId {
+		orderIdRule = append(orderIdRule, orderIdItem)
+	}
+	var accountRule []interface{}
+	for _, accountItem := range account {
+		accountRule = append(accountRule, accountItem)
+	}
+
+	logs, sub, err := _SwapXHook.contract.WatchLogs(opts, "OrderDustReturned", orderIdRule, accountRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(SwapXHookOrderDustReturned)
+				if err := _SwapXHook.contract.UnpackLog(event, "OrderDustReturned", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseOrderDustReturned is a log parse operation binding the contract event 0xa201d503707dccee563c2bf2969fd21c3f881f7a7dd191fe3c6d8b627a21b994.
+//
+// Solidity: event OrderDustReturned(uint256 indexed orderId, address indexed account, uint256 amount, bool isBuy)
+func (_SwapXHook *SwapXHookFilterer) ParseOrderDustReturned(log types.Log) (*SwapXHookOrderDustReturned, error) {
+	event := new(SwapXHookOrderDustReturned)
+	if err := _SwapXHook.contract.UnpackLog(event, "OrderDustReturned", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// SwapXHookOrderFulfilledIterator is returned from FilterOrderFulfilled and is used to iterate over the raw logs and unpacked data for OrderFulfilled events raised by the SwapXHook contract.
+type SwapXHookOrderFulfilledIterator struct {
+	Event *SwapXHookOrderFulfilled // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *SwapXHookOrderFulfilledIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(SwapXHookOrderFulfilled)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(SwapXHookOrderFulfilled)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *SwapXHookOrderFulfilledIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *SwapXHookOrderFulfilledIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// SwapXHookOrderFulfilled represents a OrderFulfilled event raised by the SwapXHook contract.
+type SwapXHookOrderFulfilled struct {
+	OrderId   *big.Int
+	Account   common.Address
+	SqrtPrice *big.Int
+	Amount    *big.Int
+	IsBuy     bool
+	Raw       types.Log // Blockchain specific contextual infos
+}
+
+// FilterOrderFulfilled is a free log retrieval operation binding the contract event 0x422a6e18188971eadf09ce88f07e1850e891eabb82ca9b7ec36538fc790abb9f.
+//
+// Solidity: event OrderFulfilled(uint256 indexed orderId, address indexed account, uint256 sqrtPrice, uint256 amount, bool isBuy)
+func (_SwapXHook *SwapXHookFilterer) FilterOrderFulfilled(opts *bind.FilterOpts, orderId []*big.Int, account []common.Address) (*SwapXHookOrderFulfilledIterator, error) {
+
+	var orderIdRule []interface{}
+	for _, orderIdItem := range orderId {
+		orderIdRule = append(orderIdRule, orderIdItem)
+	}
+	var accountRule []interface{}
+	for _, accountItem := range account {
+		accountRule = append(accountRule, accountItem)
+	}
+
+	logs, sub, err := _SwapXHook.contract.FilterLogs(opts, "OrderFulfilled", orderIdRule, accountRule)
+	if err != nil {
+		return nil, err
+	}
+	return &SwapXHookOrderFulfilledIterator{contract: _SwapXHook.contract, event: "OrderFulfilled", logs: logs, sub: sub}, nil
+}
+
+// WatchOrderFulfilled is a free log subscription operation binding the contract event 0x422a6e18188971eadf09ce88f07e1850e891eabb82ca9b7ec36538fc790abb9f.
+//
+// Solidity: event OrderFulfilled(uint256 indexed orderId, address indexed account, uint256 sqrtPrice, uint256 amount, bool isBuy)
+func (_SwapXHook *SwapXHookFilterer) WatchOrderFulfilled(opts *bind.WatchOpts, sink chan<- *SwapXHookOrderFulfilled, orderId []*big.Int, account []common.Address) (event.Subscription, error) {
+
+	var orderIdRule []interface{}
+	for _, orderIdItem := range orderId {
+		orderIdRule = append(orderIdRule, orderIdItem)
+	}
+	var accountRule []interface{}
+	for _, accountItem := range account {
+		accountRule = append(accountRule, accountItem)
+	}
+
+	logs, sub, err := _SwapXHook.contract.WatchLogs(opts, "OrderFulfilled", orderIdRule, accountRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(SwapXHookOrderFulfilled)
+				if err := _SwapXHook.contract.UnpackLog(event, "OrderFulfilled", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseOrderFulfilled is a log parse operation binding the contract event 0x422a6e18188971eadf09ce88f07e1850e891eabb82ca9b7ec36538fc790abb9f.
+//
+// Solidity: event OrderFulfilled(uint256 indexed orderId, address indexed account, uint256 sqrtPrice, uint256 amount, bool isBuy)
+func (_SwapXHook *SwapXHookFilterer) ParseOrderFulfilled(log types.Log) (*SwapXHookOrderFulfilled, error) {
+	event := new(SwapXHookOrderFulfilled)
+	if err := _SwapXHook.contract.UnpackLog(event, "OrderFulfilled", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// SwapXHookOrderPartiallyFulfilledIterator is returned from FilterOrderPartiallyFulfilled and is used to iterate over the raw logs and unpacked data for OrderPartiallyFulfilled events raised by the SwapXHook contract.
+type SwapXHookOrderPartiallyFulfilledIterator struct {
+	Event *SwapXHookOrderPartiallyFulfilled // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *SwapXHookOrderPartiallyFulfilledIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(SwapXHookOrderPartiallyFulfilled)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(SwapXHookOrderPartiallyFulfilled)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *SwapXHookOrderPartiallyFulfilledIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *SwapXHookOrderPartiallyFulfilledIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// SwapXHookOrderPartiallyFulfilled represents a OrderPartiallyFulfilled event raised by the SwapXHook contract.
+type SwapXHookOrderPartiallyFulfilled struct {
+	OrderId   *big.Int
+	Account   common.Address
+	SqrtPrice *big.Int
+	Amount    *big.Int
+	IsBuy     bool
+	Raw       types.Log // Blockchain specific contextual infos
+}
+
+// FilterOrderPartiallyFulfilled is a free log retrieval operation binding the contract event 0x4f644c6d47480a00c7be0a8055aa272f03719ab19057f99827e6cd1d269d3dbe.
+//
+// Solidity: event OrderPartiallyFulfilled(uint256 indexed orderId, address indexed account, uint256 sqrtPrice, uint256 amount, bool isBuy)
+func (_SwapXHook *SwapXHookFilterer) FilterOrderPartiallyFulfilled(opts *bind.FilterOpts, orderId []*big.Int, account []common.Address) (*SwapXHookOrderPartiallyFulfilledIterator, error) {
+
+	var orderIdRule []interface{}
+	for _, orderIdItem := range orderId {
+		orderIdRule = append(orderIdRule, orderIdItem)
+	}
+	var accountRule []interface{}
+	for _, accountItem := range account {
+		accountRule = append(accountRule, accountItem)
+	}
+
+	logs, sub, err := _SwapXHook.contract.FilterLogs(opts, "OrderPartiallyFulfilled", orderIdRule, accountRule)
+	if err != nil {
+		return nil, err
+	}
+	return &SwapXHookOrderPartiallyFulfilledIterator{contract: _SwapXHook.contract, event: "OrderPartiallyFulfilled", logs: logs, sub: sub}, nil
+}
+
+// WatchOrderPartiallyFulfilled is a free log subscription operation binding the contract event 0x4f644c6d47480a00c7be0a8055aa272f03719ab19057f99827e6cd1d269d3dbe.
+//
+// Solidity: event OrderPartiallyFulfilled(uint256 indexed orderId, address indexed account, uint256 sqrtPrice, uint256 amount, bool isBuy)
+func (_SwapXHook *SwapXHookFilterer) WatchOrderPartiallyFulfilled(opts *bind.WatchOpts, sink chan<- *SwapXHookOrderPartiallyFulfilled, orderId []*big.Int, account []common.Address) (event.Subscription, error) {
+
+	var orderIdRule []interface{}
+	for _, orderIdItem := range orderId {
+		orderIdRule = append(orderIdRule, orderIdItem)
+	}
+	var accountRule []interface{}
+	for _, accountItem := range account {
+		accountRule = append(accountRule, accountItem)
+	}
+
+	logs, sub, err := _SwapXHook.contract.WatchLogs(opts, "OrderPartiallyFulfilled", orderIdRule, accountRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(SwapXHookOrderPartiallyFulfilled)
+				if err := _SwapXHook.contract.UnpackLog(event, "OrderPartiallyFulfilled", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseOrderPartiallyFulfilled is a log parse operation binding the contract event 0x4f644c6d47480a00c7be0a8055aa272f03719ab19057f99827e6cd1d269d3dbe.
+//
+// Solidity: event OrderPartiallyFulfilled(uint256 indexed orderId, address indexed account, uint256 sqrtPrice, uint256 amount, bool isBuy)
+func (_SwapXHook *SwapXHookFilterer) ParseOrderPartiallyFulfilled(log types.Log) (*SwapXHookOrderPartiallyFulfilled, error) {
+	event := new(SwapXHookOrderPartiallyFulfilled)
+	if err := _SwapXHook.contract.UnpackLog(event, "OrderPartiallyFulfilled", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// SwapXHookOrderRefundedIterator is returned from FilterOrderRefunded and is used to iterate over the raw logs and unpacked data for OrderRefunded events raised by the SwapXHook contract.
+type SwapXHookOrderRefundedIterator struct {
+	Event *SwapXHookOrderRefunded // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *SwapXHookOrderRefundedIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(SwapXHookOrderRefunded)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(SwapXHookOrderRefunded)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *SwapXHookOrderRefundedIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *SwapXHookOrderRefundedIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// SwapXHookOrderRefunded represents a OrderRefunded event raised by the SwapXHook contract.
+type SwapXHookOrderRefunded struct {
+	OrderId *big.Int
+	Account common.Address
+	Amount  *big.Int
+	IsBuy   bool
+	Raw     types.Log // Blockchain specific contextual infos
+}
+
+// FilterOrderRefunded is a free log retrieval operation binding the contract event 0x099718a2f60e854a0583833866fa19720729fc8a36af88b3b08b67afea305602.
+//
+// Solidity: event OrderRefunded(uint256 indexed orderId, address indexed account, uint256 amount, bool isBuy)
+func (_SwapXHook *SwapXHookFilterer) FilterOrderRefunded(opts *bind.FilterOpts, orderId []*big.Int, account []common.Address) (*SwapXHookOrderRefundedIterator, error) {
+
+	var orderIdRule []interface{}
+	for _, orderIdItem := range orderId {
+		orderIdRule = append(orderIdRule, orderIdItem)
+	}
+	var accountRule []interface{}
+	for _, accountItem := range account {
+		accountRule = append(accountRule, accountItem)
+	}
+
+	logs, sub, err := _SwapXHook.contract.FilterLogs(opts, "OrderRefunded", orderIdRule, accountRule)
+	if err != nil {
+		return nil, err
+	}
+	return &SwapXHookOrderRefundedIterator{contract: _SwapXHook.contract, event: "OrderRefunded", logs: logs, sub: sub}, nil
+}
+
+// WatchOrderRefunded is a free log subscription operation binding the contract event 0x099718a2f60e854a0583833866fa19720729fc8a36af88b3b08b67afea305602.
+//
+// Solidity: event OrderRefunded(uint256 indexed orderId, address indexed account, uint256 amount, bool isBuy)
+func (_SwapXHook *SwapXHookFilterer) WatchOrderRefunded(opts *bind.WatchOpts, sink chan<- *SwapXHookOrderRefunded, orderId []*big.Int, account []common.Address) (event.Subscription, error) {
+
+	var orderIdRule []interface{}
+	for _, orderIdItem := range orderId {
+		orderIdRule = append(orderIdRule, orderIdItem)
+	}
+	var accountRule []interface{}
+	for _, accountItem := range account {
+		accountRule = append(accountRule, accountItem)
+	}
+
+	logs, sub, err := _SwapXHook.contract.WatchLogs(opts, "OrderRefunded", orderIdRule, accountRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(SwapXHookOrderRefunded)
+				if err := _SwapXHook.contract.UnpackLog(event, "OrderRefunded", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseOrderRefunded is a log parse operation binding the contract event 0x099718a2f60e854a0583833866fa19720729fc8a36af88b3b08b67afea305602.
+//
+// Solidity: event OrderRefunded(uint256 indexed orderId, address indexed account, uint256 amount, bool isBuy)
+func (_SwapXHook *SwapXHookFilterer) ParseOrderRefunded(log types.Log) (*SwapXHookOrderRefunded, error) {
+	event := new(SwapXHookOrderRefunded)
+	if err := _SwapXHook.contract.UnpackLog(event, "OrderRefunded", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// SwapXHookOrderRoutedToPoolIterator is returned from FilterOrderRoutedToPool and is used to iterate over the raw logs and unpacked data for OrderRoutedToPool events raised by the SwapXHook contract.
+type SwapXHookOrderRoutedToPoolIterator struct {
+	Event *SwapXHookOrderRoutedToPool // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *SwapXHookOrderRoutedToPoolIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(SwapXHookOrderRoutedToPool)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(SwapXHookOrderRoutedToPool)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *SwapXHookOrderRoutedToPoolIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *SwapXHookOrderRoutedToPoolIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// SwapXHookOrderRoutedToPool represents a OrderRoutedToPool event raised by the SwapXHook contract.
+type SwapXHookOrderRoutedToPool struct {
+	OrderId   *big.Int
+	Account   common.Address
+	AmountIn  *big.Int
+	AmountOut *big.Int
+	IsBuy     bool
+	Raw       types.Log // Blockchain specific contextual infos
+}
+
+// FilterOrderRoutedToPool is a free log retrieval operation binding the contract event 0x80d0cb26b7f336064ebf15982870e28f220b701d314bd2181fae3fabcdc51c16.
+//
+// Solidity: event OrderRoutedToPool(uint256 indexed orderId, address indexed account, uint256 amountIn, uint256 amountOut, bool isBuy)
+func (_SwapXHook *SwapXHookFilterer) FilterOrderRoutedToPool(opts *bind.FilterOpts, orderId []*big.Int, account []common.Address) (*SwapXHookOrderRoutedToPoolIterator, error) {
+
+	var orderIdRule []interface{}
+	for _, orderIdItem := range orderId {
+		orderIdRule = append(orderIdRule, orderIdItem)
+	}
+	var accountRule []interface{}
+	for _, accountItem := range account {
+		accountRule = append(accountRule, accountItem)
+	}
+
+	logs, sub, err := _SwapXHook.contract.FilterLogs(opts, "OrderRoutedToPool", orderIdRule, accountRule)
+	if err != nil {
+		return nil, err
+	}
+	return &SwapXHookOrderRoutedToPoolIterator{contract: _SwapXHook.contract, event: "OrderRoutedToPool", logs: logs, sub: sub}, nil
+}
+
+// WatchOrderRoutedToPool is a free log subscription operation binding the contract event 0x80d0cb26b7f336064ebf15982870e28f220b701d314bd2181fae3fabcdc51c16.
+//
+// Solidity: event OrderRoutedToPool(uint256 indexed orderId, address indexed account, uint256 amountIn, uint256 amountOut, bool isBuy)
+func (_SwapXHook *SwapXHookFilterer) WatchOrderRoutedToPool(opts *bind.WatchOpts, sink chan<- *SwapXHookOrderRoutedToPool, orderId []*big.Int, account []common.Address) (event.Subscription, error) {
+
+	var orderIdRule []interface{}
+	for _, orderIdItem := range orderId {
+		orderIdRule = append(orderIdRule, orderIdItem)
+	}
+	var accountRule []interface{}
+	for _, accountItem := range account {
+		accountRule = append(accountRule, accountItem)
+	}
+
+	logs, sub, err := _SwapXHook.contract.WatchLogs(opts, "OrderRoutedToPool", orderIdRule, accountRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(SwapXHookOrderRoutedToPool)
+				if err := _SwapXHook.contract.UnpackLog(event, "OrderRoutedToPool", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseOrderRoutedToPool is a log parse operation binding the contract event 0x80d0cb26b7f336064ebf15982870e28f220b701d314bd2181fae3fabcdc51c16.
+//
+// Solidity: event OrderRoutedToPool(uint256 indexed orderId, address indexed account, uint256 amountIn, uint256 amountOut, bool isBuy)
+func (_SwapXHook *SwapXHookFilterer) ParseOrderRoutedToPool(log types.Log) (*SwapXHookOrderRoutedToPool, error) {
+	event := new(SwapXHookOrderRoutedToPool)
+	if err := _SwapXHook.contract.UnpackLog(event, "OrderRoutedToPool", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
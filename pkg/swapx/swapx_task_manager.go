@@ -0,0 +1,536 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package swapx
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = errors.New
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = ethereum.NotFound
+	_ = bind.Bind
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = event.NewSubscription
+	_ = abi.ConvertType
+)
+
+// SwapXTaskManagerMetaData contains all meta data concerning the SwapXTaskManager contract.
+var SwapXTaskManagerMetaData = &bind.MetaData{
+	ABI: "[{\"type\":\"function\",\"name\":\"MAX_FILLS_PER_BATCH\",\"inputs\":[],\"outputs\":[{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"createTask\",\"inputs\":[{\"name\":\"payload\",\"type\":\"bytes\",\"internalType\":\"bytes\"}],\"outputs\":[],\"stateMutability\":\"payable\"},{\"type\":\"event\",\"name\":\"FillFailed\",\"inputs\":[{\"name\":\"hook\",\"type\":\"address\",\"internalType\":\"address\",\"indexed\":true},{\"name\":\"fillId\",\"type\":\"bytes32\",\"internalType\":\"bytes32\",\"indexed\":true},{\"name\":\"buyOrderId\",\"type\":\"uint256\",\"internalType\":\"uint256\",\"indexed\":false},{\"name\":\"sellOrderId\",\"type\":\"uint256\",\"internalType\":\"uint256\",\"indexed\":false},{\"name\":\"quantity\",\"type\":\"uint256\",\"internalType\":\"uint256\",\"indexed\":false},{\"name\":\"reason\",\"type\":\"bytes\",\"internalType\":\"bytes\",\"indexed\":false}],\"anonymous\":false},{\"type\":\"event\",\"name\":\"VoucherFailed\",\"inputs\":[{\"name\":\"destination\",\"type\":\"address\",\"internalType\":\"address\",\"indexed\":true},{\"name\":\"payload\",\"type\":\"bytes\",\"internalType\":\"bytes\",\"indexed\":false},{\"name\":\"reason\",\"type\":\"bytes\",\"internalType\":\"bytes\",\"indexed\":false}],\"anonymous\":false},{\"type\":\"error\",\"name\":\"ActionNotAllowed\",\"inputs\":[{\"name\":\"selector\",\"type\":\"bytes4\",\"internalType\":\"bytes4\"}]},{\"type\":\"error\",\"name\":\"BatchTooLarge\",\"inputs\":[{\"name\":\"batchSize\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"maxBatchSize\",\"type\":\"uint256\",\"internalType\":\"uint256\"}]},{\"type\":\"error\",\"name\":\"InputTooLarge\",\"inputs\":[{\"name\":\"appContract\",\"type\":\"address\",\"internalType\":\"address\"},{\"name\":\"inputLength\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"maxInputLength\",\"type\":\"uint256\",\"internalType\":\"uint256\"}]},{\"type\":\"error\",\"name\":\"UnsupportedNotice\",\"inputs\":[{\"name\":\"selector\",\"type\":\"bytes4\",\"internalType\":\"bytes4\"}]}]",
+}
+
+// SwapXTaskManagerABI is the input ABI used to generate the binding from.
+// Deprecated: Use SwapXTaskManagerMetaData.ABI instead.
+var SwapXTaskManagerABI = SwapXTaskManagerMetaData.ABI
+
+// SwapXTaskManager is an auto generated Go binding around an Ethereum contract.
+type SwapXTaskManager struct {
+	SwapXTaskManagerCaller     // Read-only binding to the contract
+	SwapXTaskManagerTransactor // Write-only binding to the contract
+	SwapXTaskManagerFilterer   // Log filterer for contract events
+}
+
+// SwapXTaskManagerCaller is an auto generated read-only Go binding around an Ethereum contract.
+type SwapXTaskManagerCaller struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// SwapXTaskManagerTransactor is an auto generated write-only Go binding around an Ethereum contract.
+type SwapXTaskManagerTransactor struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// SwapXTaskManagerFilterer is an auto generated log filtering Go binding around an Ethereum contract events.
+type SwapXTaskManagerFilterer struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// SwapXTaskManagerSession is an auto generated Go binding around an Ethereum contract,
+// with pre-set call and transact options.
+type SwapXTaskManagerSession struct {
+	Contract     *SwapXTaskManager // Generic contract binding to set the session for
+	CallOpts     bind.CallOpts     // Call options to use throughout this session
+	TransactOpts bind.TransactOpts // Transaction auth options to use throughout this session
+}
+
+// SwapXTaskManagerCallerSession is an auto generated read-only Go binding around an Ethereum contract,
+// with pre-set call options.
+type SwapXTaskManagerCallerSession struct {
+	Contract *SwapXTaskManagerCaller // Generic contract caller binding to set the session for
+	CallOpts bind.CallOpts           // Call options to use throughout this session
+}
+
+// SwapXTaskManagerTransactorSession is an auto generated write-only Go binding around an Ethereum contract,
+// with pre-set transact options.
+type SwapXTaskManagerTransactorSession struct {
+	Contract     *SwapXTaskManagerTransactor // Generic contract transactor binding to set the session for
+	TransactOpts bind.TransactOpts           // Transaction auth options to use throughout this session
+}
+
+// SwapXTaskManagerRaw is an auto generated low-level Go binding around an Ethereum contract.
+type SwapXTaskManagerRaw struct {
+	Contract *SwapXTaskManager // Generic contract binding to access the raw methods on
+}
+
+// SwapXTaskManagerCallerRaw is an auto generated low-level read-only Go binding around an Ethereum contract.
+type SwapXTaskManagerCallerRaw struct {
+	Contract *SwapXTaskManagerCaller // Generic read-only contract binding to access the raw methods on
+}
+
+// SwapXTaskManagerTransactorRaw is an auto generated low-level write-only Go binding around an Ethereum contract.
+type SwapXTaskManagerTransactorRaw struct {
+	Contract *SwapXTaskManagerTransactor // Generic write-only contract binding to access the raw methods on
+}
+
+// NewSwapXTaskManager creates a new instance of SwapXTaskManager, bound to a specific deployed contract.
+func NewSwapXTaskManager(address common.Address, backend bind.ContractBackend) (*SwapXTaskManager, error) {
+	contract, err := bindSwapXTaskManager(address, backend, backend, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &SwapXTaskManager{SwapXTaskManagerCaller: SwapXTaskManagerCaller{contract: contract}, SwapXTaskManagerTransactor: SwapXTaskManagerTransactor{contract: contract}, SwapXTaskManagerFilterer: SwapXTaskManagerFilterer{contract: contract}}, nil
+}
+
+// NewSwapXTaskManagerCaller creates a new read-only instance of SwapXTaskManager, bound to a specific deployed contract.
+func NewSwapXTaskManagerCaller(address common.Address, caller bind.ContractCaller) (*SwapXTaskManagerCaller, error) {
+	contract, err := bindSwapXTaskManager(address, caller, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &SwapXTaskManagerCaller{contract: contract}, nil
+}
+
+// NewSwapXTaskManagerTransactor creates a new write-only instance of SwapXTaskManager, bound to a specific deployed contract.
+func NewSwapXTaskManagerTransactor(address common.Address, transactor bind.ContractTransactor) (*SwapXTaskManagerTransactor, error) {
+	contract, err := bindSwapXTaskManager(address, nil, transactor, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &SwapXTaskManagerTransactor{contract: contract}, nil
+}
+
+// NewSwapXTaskManagerFilterer creates a new log filterer instance of SwapXTaskManager, bound to a specific deployed contract.
+func NewSwapXTaskManagerFilterer(address common.Address, filterer bind.ContractFilterer) (*SwapXTaskManagerFilterer, error) {
+	contract, err := bindSwapXTaskManager(address, nil, nil, filterer)
+	if err != nil {
+		return nil, err
+	}
+	return &SwapXTaskManagerFilterer{contract: contract}, nil
+}
+
+// bindSwapXTaskManager binds a generic wrapper to an already deployed contract.
+func bindSwapXTaskManager(address common.Address, caller bind.ContractCaller, transactor bind.ContractTransactor, filterer bind.ContractFilterer) (*bind.BoundContract, error) {
+	parsed, err := SwapXTaskManagerMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	return bind.NewBoundContract(address, *parsed, caller, transactor, filterer), nil
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_SwapXTaskManager *SwapXTaskManagerRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _SwapXTaskManager.Contract.SwapXTaskManagerCaller.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_SwapXTaskManager *SwapXTaskManagerRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _SwapXTaskManager.Contract.SwapXTaskManagerTransactor.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_SwapXTaskManager *SwapXTaskManagerRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _SwapXTaskManager.Contract.SwapXTaskManagerTransactor.contract.Transact(opts, method, params...)
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_SwapXTaskManager *SwapXTaskManagerCallerRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _SwapXTaskManager.Contract.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_SwapXTaskManager *SwapXTaskManagerTransactorRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _SwapXTaskManager.Contract.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_SwapXTaskManager *SwapXTaskManagerTransactorRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _SwapXTaskManager.Contract.contract.Transact(opts, method, params...)
+}
+
+// MAXFILLSPERBATCH is a free data retrieval call binding the contract method 0x536c2b27.
+//
+// Solidity: function MAX_FILLS_PER_BATCH() view returns(uint256)
+func (_SwapXTaskManager *SwapXTaskManagerCaller) MAXFILLSPERBATCH(opts *bind.CallOpts) (*big.Int, error) {
+	var out []interface{}
+	err := _SwapXTaskManager.contract.Call(opts, &out, "MAX_FILLS_PER_BATCH")
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// MAXFILLSPERBATCH is a free data retrieval call binding the contract method 0x536c2b27.
+//
+// Solidity: function MAX_FILLS_PER_BATCH() view returns(uint256)
+func (_SwapXTaskManager *SwapXTaskManagerSession) MAXFILLSPERBATCH() (*big.Int, error) {
+	return _SwapXTaskManager.Contract.MAXFILLSPERBATCH(&_SwapXTaskManager.CallOpts)
+}
+
+// MAXFILLSPERBATCH is a free data retrieval call binding the contract method 0x536c2b27.
+//
+// Solidity: function MAX_FILLS_PER_BATCH() view returns(uint256)
+func (_SwapXTaskManager *SwapXTaskManagerCallerSession) MAXFILLSPERBATCH() (*big.Int, error) {
+	return _SwapXTaskManager.Contract.MAXFILLSPERBATCH(&_SwapXTaskManager.CallOpts)
+}
+
+// CreateTask is a paid mutator transaction binding the contract method 0x966e5897.
+//
+// Solidity: function createTask(bytes payload) payable returns()
+func (_SwapXTaskManager *SwapXTaskManagerTransactor) CreateTask(opts *bind.TransactOpts, payload []byte) (*types.Transaction, error) {
+	return _SwapXTaskManager.contract.Transact(opts, "createTask", payload)
+}
+
+// CreateTask is a paid mutator transaction binding the contract method 0x966e5897.
+//
+// Solidity: function createTask(bytes payload) payable returns()
+func (_SwapXTaskManager *SwapXTaskManagerSession) CreateTask(payload []byte) (*types.Transaction, error) {
+	return _SwapXTaskManager.Contract.CreateTask(&_SwapXTaskManager.TransactOpts, payload)
+}
+
+// CreateTask is a paid mutator transaction binding the contract method 0x966e5897.
+//
+// Solidity: function createTask(bytes payload) payable returns()
+func (_SwapXTaskManager *SwapXTaskManagerTransactorSession) CreateTask(payload []byte) (*types.Transaction, error) {
+	return _SwapXTaskManager.Contract.CreateTask(&_SwapXTaskManager.TransactOpts, payload)
+}
+
+// SwapXTaskManagerFillFailedIterator is returned from FilterFillFailed and is used to iterate over the raw logs and unpacked data for FillFailed events raised by the SwapXTaskManager contract.
+type SwapXTaskManagerFillFailedIterator struct {
+	Event *SwapXTaskManagerFillFailed // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *SwapXTaskManagerFillFailedIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(SwapXTaskManagerFillFailed)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(SwapXTaskManagerFillFailed)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *SwapXTaskManagerFillFailedIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *SwapXTaskManagerFillFailedIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// SwapXTaskManagerFillFailed represents a FillFailed event raised by the SwapXTaskManager contract.
+type SwapXTaskManagerFillFailed struct {
+	Hook        common.Address
+	FillId      [32]byte
+	BuyOrderId  *big.Int
+	SellOrderId *big.Int
+	Quantity    *big.Int
+	Reason      []byte
+	Raw         types.Log // Blockchain specific contextual infos
+}
+
+// FilterFillFailed is a free log retrieval operation binding the contract event 0x32ff05010908e56c22635d0cbbdcf99d4c5c18ad61ebb77ec241973d2937c5c6.
+//
+// Solidity: event FillFailed(address indexed hook, bytes32 indexed fillId, uint256 buyOrderId, uint256 sellOrderId, uint256 quantity, bytes reason)
+func (_SwapXTaskManager *SwapXTaskManagerFilterer) FilterFillFailed(opts *bind.FilterOpts, hook []common.Address, fillId [][32]byte) (*SwapXTaskManagerFillFailedIterator, error) {
+
+	var hookRule []interface{}
+	for _, hookItem := range hook {
+		hookRule = append(hookRule, hookItem)
+	}
+	var fillIdRule []interface{}
+	for _, fillIdItem := range fillId {
+		fillIdRule = append(fillIdRule, fillIdItem)
+	}
+
+	logs, sub, err := _SwapXTaskManager.contract.FilterLogs(opts, "FillFailed", hookRule, fillIdRule)
+	if err != nil {
+		return nil, err
+	}
+	return &SwapXTaskManagerFillFailedIterator{contract: _SwapXTaskManager.contract, event: "FillFailed", logs: logs, sub: sub}, nil
+}
+
+// WatchFillFailed is a free log subscription operation binding the contract event 0x32ff05010908e56c22635d0cbbdcf99d4c5c18ad61ebb77ec241973d2937c5c6.
+//
+// Solidity: event FillFailed(address indexed hook, bytes32 indexed fillId, uint256 buyOrderId, uint256 sellOrderId, uint256 quantity, bytes reason)
+func (_SwapXTaskManager *SwapXTaskManagerFilterer) WatchFillFailed(opts *bind.WatchOpts, sink chan<- *SwapXTaskManagerFillFailed, hook []common.Address, fillId [][32]byte) (event.Subscription, error) {
+
+	var hookRule []interface{}
+	for _, hookItem := range hook {
+		hookRule = append(hookRule, hookItem)
+	}
+	var fillIdRule []interface{}
+	for _, fillIdItem := range fillId {
+		fillIdRule = append(fillIdRule, fillIdItem)
+	}
+
+	logs, sub, err := _SwapXTaskManager.contract.WatchLogs(opts, "FillFailed", hookRule, fillIdRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(SwapXTaskManagerFillFailed)
+				if err := _SwapXTaskManager.contract.UnpackLog(event, "FillFailed", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseFillFailed is a log parse operation binding the contract event 0x32ff05010908e56c22635d0cbbdcf99d4c5c18ad61ebb77ec241973d2937c5c6.
+//
+// Solidity: event FillFailed(address indexed hook, bytes32 indexed fillId, uint256 buyOrderId, uint256 sellOrderId, uint256 quantity, bytes reason)
+func (_SwapXTaskManager *SwapXTaskManagerFilterer) ParseFillFailed(log types.Log) (*SwapXTaskManagerFillFailed, error) {
+	event := new(SwapXTaskManagerFillFailed)
+	if err := _SwapXTaskManager.contract.UnpackLog(event, "FillFailed", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// SwapXTaskManagerVoucherFailedIterator is returned from FilterVoucherFailed and is used to iterate over the raw logs and unpacked data for VoucherFailed events raised by the SwapXTaskManager contract.
+type SwapXTaskManagerVoucherFailedIterator struct {
+	Event *SwapXTaskManagerVoucherFailed // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *SwapXTaskManagerVoucherFailedIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(SwapXTaskManagerVoucherFailed)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(SwapXTaskManagerVoucherFailed)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *SwapXTaskManagerVoucherFailedIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *SwapXTaskManagerVoucherFailedIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// SwapXTaskManagerVoucherFailed represents a VoucherFailed event raised by the SwapXTaskManager contract.
+type SwapXTaskManagerVoucherFailed struct {
+	Destination common.Address
+	Payload     []byte
+	Reason      []byte
+	Raw         types.Log // Blockchain specific contextual infos
+}
+
+// FilterVoucherFailed is a free log retrieval operation binding the contract event 0xd056de153044df6e9f254ec552c227a30a012bd248dd4d95826c05435eac7bbb.
+//
+// Solidity: event VoucherFailed(address indexed destination, bytes payload, bytes reason)
+func (_SwapXTaskManager *SwapXTaskManagerFilterer) FilterVoucherFailed(opts *bind.FilterOpts, destination []common.Address) (*SwapXTaskManagerVoucherFailedIterator, error) {
+
+	var destinationRule []interface{}
+	for _, destinationItem := range destination {
+		destinationRule = append(destinationRule, destinationItem)
+	}
+
+	logs, sub, err := _SwapXTaskManager.contract.FilterLogs(opts, "VoucherFailed", destinationRule)
+	if err != nil {
+		return nil, err
+	}
+	return &SwapXTaskManagerVoucherFailedIterator{contract: _SwapXTaskManager.contract, event: "VoucherFailed", logs: logs, sub: sub}, nil
+}
+
+// WatchVoucherFailed is a free log subscription operation binding the contract event 0xd056de153044df6e9f254ec552c227a30a012bd248dd4d95826c05435eac7bbb.
+//
+// Solidity: event VoucherFailed(address indexed destination, bytes payload, bytes reason)
+func (_SwapXTaskManager *SwapXTaskManagerFilterer) WatchVoucherFailed(opts *bind.WatchOpts, sink chan<- *SwapXTaskManagerVoucherFailed, destination []common.Address) (event.Subscription, error) {
+
+	var destinationRule []interface{}
+	for _, destinationItem := range destination {
+		destinationRule = append(destinationRule, destinationItem)
+	}
+
+	logs, sub, err := _SwapXTaskManager.contract.WatchLogs(opts, "VoucherFailed", destinationRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(SwapXTaskManagerVoucherFailed)
+				if err := _SwapXTaskManager.contract.UnpackLog(event, "VoucherFailed", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseVoucherFailed is a log parse operation binding the contract event 0xd056de153044df6e9f254ec552c227a30a012bd248dd4d95826c05435eac7bbb.
+//
+// Solidity: event VoucherFailed(address indexed destination, bytes payload, bytes reason)
+func (_SwapXTaskManager *SwapXTaskManagerFilterer) ParseVoucherFailed(log types.Log) (*SwapXTaskManagerVoucherFailed, error) {
+	event := new(SwapXTaskManagerVoucherFailed)
+	if err := _SwapXTaskManager.contract.UnpackLog(event, "VoucherFailed", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}